@@ -0,0 +1,13 @@
+-- Migration: add_processed_commands
+-- Generated: 2026-10-18T09:00:00+05:45
+
+-- Table: processed_commands
+CREATE TABLE IF NOT EXISTS processed_commands (
+    command_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT pending,
+    result TEXT,
+    error TEXT,
+    created_at DATETIME,
+    completed_at DATETIME
+);
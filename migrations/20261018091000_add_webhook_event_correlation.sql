@@ -0,0 +1,5 @@
+-- Migration: add_webhook_event_correlation
+-- Generated: 2026-10-18T09:10:00+05:45
+
+ALTER TABLE webhook_events ADD COLUMN correlation_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_webhook_events_correlation_id ON webhook_events(correlation_id);
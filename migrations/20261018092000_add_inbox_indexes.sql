@@ -0,0 +1,25 @@
+-- Migration: add_inbox_indexes
+-- Generated: 2026-10-18T09:20:00+05:45
+
+-- Table: tags
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    name TEXT(50) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_org_name ON tags(organization_id, name);
+
+-- Table: conversation_tags
+CREATE TABLE IF NOT EXISTS conversation_tags (
+    conversation_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (conversation_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_conversation_tags_tag_id ON conversation_tags(tag_id);
+
+-- Inbox indexes
+CREATE INDEX IF NOT EXISTS idx_conversations_channel_last_message ON conversations(channel_id, last_message_at);
+CREATE INDEX IF NOT EXISTS idx_conversations_channel_created ON conversations(channel_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_conv_unread ON messages(conversation_id, direction, status);
@@ -0,0 +1,32 @@
+-- Migration: add_agents_and_routing
+-- Generated: 2026-10-18T09:30:00+05:45
+
+-- Table: agents
+CREATE TABLE IF NOT EXISTS agents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    external_id TEXT NOT NULL,
+    name TEXT(100) NOT NULL,
+    status TEXT DEFAULT 'offline',
+    max_concurrent INTEGER DEFAULT 0,
+    last_assigned_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agents_org_external ON agents(organization_id, external_id);
+CREATE INDEX IF NOT EXISTS idx_agents_status ON agents(status);
+
+-- Table: routing_policies
+CREATE TABLE IF NOT EXISTS routing_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel_id INTEGER NOT NULL,
+    strategy TEXT NOT NULL,
+    reassign_after_seconds INTEGER DEFAULT 0,
+    enabled NUMERIC,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_routing_policies_channel_id ON routing_policies(channel_id);
+
+-- Conversations: time of the current assignment
+ALTER TABLE conversations ADD COLUMN assigned_at DATETIME;
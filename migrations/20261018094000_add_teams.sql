@@ -0,0 +1,40 @@
+-- Migration: add_teams
+-- Generated: 2026-10-18T09:40:00+05:45
+
+-- Table: teams
+CREATE TABLE IF NOT EXISTS teams (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    name TEXT(100) NOT NULL,
+    description TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_teams_org_name ON teams(organization_id, name);
+
+-- Table: team_members
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INTEGER NOT NULL,
+    agent_id TEXT NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (team_id, agent_id)
+);
+CREATE INDEX IF NOT EXISTS idx_team_members_agent_id ON team_members(agent_id);
+
+-- Table: conversation_events
+CREATE TABLE IF NOT EXISTS conversation_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    from_value TEXT,
+    to_value TEXT,
+    actor_id TEXT,
+    reason TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_conversation_events_conversation_id ON conversation_events(conversation_id);
+
+-- Team assignment
+ALTER TABLE conversations ADD COLUMN team_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_conversations_team_id ON conversations(team_id);
+ALTER TABLE chat_channels ADD COLUMN default_team_id INTEGER;
@@ -0,0 +1,52 @@
+-- Migration: add_sla
+-- Generated: 2026-10-18T09:50:00+05:45
+
+-- Table: sla_policies
+CREATE TABLE IF NOT EXISTS sla_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    name TEXT(100) NOT NULL,
+    channel_id INTEGER,
+    priority TEXT,
+    first_response_seconds INTEGER DEFAULT 0,
+    next_response_seconds INTEGER DEFAULT 0,
+    resolution_seconds INTEGER DEFAULT 0,
+    warn_before_seconds INTEGER DEFAULT 0,
+    business_hours_only NUMERIC,
+    enabled NUMERIC,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_sla_policies_organization_id ON sla_policies(organization_id);
+
+-- Table: sla_alerts
+CREATE TABLE IF NOT EXISTS sla_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    target TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    due_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sla_alerts_unique ON sla_alerts(conversation_id, target, kind, due_at);
+
+-- Table: business_hours
+CREATE TABLE IF NOT EXISTS business_hours (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL DEFAULT 0,
+    timezone TEXT NOT NULL,
+    windows TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_hours_scope ON business_hours(organization_id, channel_id);
+
+-- Conversation SLA tracking
+ALTER TABLE conversations ADD COLUMN sla_policy_id INTEGER;
+ALTER TABLE conversations ADD COLUMN sla_status TEXT;
+CREATE INDEX IF NOT EXISTS idx_conversations_sla_status ON conversations(sla_status);
+ALTER TABLE conversations ADD COLUMN first_response_at DATETIME;
+ALTER TABLE conversations ADD COLUMN first_response_due_at DATETIME;
+ALTER TABLE conversations ADD COLUMN next_response_due_at DATETIME;
+ALTER TABLE conversations ADD COLUMN resolution_due_at DATETIME;
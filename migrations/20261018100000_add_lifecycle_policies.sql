@@ -0,0 +1,15 @@
+-- Migration: add_lifecycle_policies
+-- Generated: 2026-10-18T10:00:00+05:45
+
+-- Table: lifecycle_policies
+CREATE TABLE IF NOT EXISTS lifecycle_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel_id INTEGER NOT NULL,
+    reopen_within_seconds INTEGER DEFAULT 0,
+    idle_after_seconds INTEGER DEFAULT 0,
+    idle_status TEXT NOT NULL DEFAULT 'resolved',
+    closing_message TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lifecycle_policies_channel_id ON lifecycle_policies(channel_id);
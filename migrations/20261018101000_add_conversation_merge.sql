@@ -0,0 +1,5 @@
+-- Migration: add_conversation_merge
+-- Generated: 2026-10-18T10:10:00+05:45
+
+ALTER TABLE conversations ADD COLUMN merged_into_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_conversations_merged_into_id ON conversations(merged_into_id);
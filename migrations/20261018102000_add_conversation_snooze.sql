@@ -0,0 +1,5 @@
+-- Migration: add_conversation_snooze
+-- Generated: 2026-10-18T10:20:00+05:45
+
+ALTER TABLE conversations ADD COLUMN snoozed_until DATETIME;
+CREATE INDEX IF NOT EXISTS idx_conversations_snoozed_until ON conversations(snoozed_until);
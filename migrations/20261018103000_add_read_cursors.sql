@@ -0,0 +1,13 @@
+-- Migration: add_read_cursors
+-- Generated: 2026-10-18T10:30:00+05:45
+
+-- Table: read_cursors
+CREATE TABLE IF NOT EXISTS read_cursors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    agent_id TEXT NOT NULL,
+    last_read_message_id INTEGER NOT NULL,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_read_cursors_conv_agent ON read_cursors(conversation_id, agent_id);
+CREATE INDEX IF NOT EXISTS idx_read_cursors_agent_id ON read_cursors(agent_id);
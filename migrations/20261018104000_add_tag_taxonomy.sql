@@ -0,0 +1,6 @@
+-- Migration: add_tag_taxonomy
+-- Generated: 2026-10-18T10:40:00+05:45
+
+ALTER TABLE tags ADD COLUMN color TEXT(7);
+ALTER TABLE tags ADD COLUMN description TEXT;
+ALTER TABLE tags ADD COLUMN updated_at DATETIME;
@@ -0,0 +1,23 @@
+-- Migration: add_custom_attributes
+-- Generated: 2026-10-18T10:50:00+05:45
+
+ALTER TABLE conversations ADD COLUMN custom_attributes TEXT;
+ALTER TABLE external_users ADD COLUMN custom_attributes TEXT;
+
+-- Table: custom_attribute_definitions
+CREATE TABLE IF NOT EXISTS custom_attribute_definitions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    entity TEXT(20) NOT NULL,
+    key TEXT(50) NOT NULL,
+    label TEXT(100) NOT NULL,
+    type TEXT(20) NOT NULL,
+    options TEXT,
+    description TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_attributes_org_entity_key ON custom_attribute_definitions(organization_id, entity, key);
+
+-- Per-attribute json_extract indexes are created when an attribute is
+-- defined and dropped with the last definition using its key
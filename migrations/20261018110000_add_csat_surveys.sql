@@ -0,0 +1,36 @@
+-- Migration: add_csat_surveys
+-- Generated: 2026-10-18T11:00:00+05:45
+
+-- Table: csat_policies
+CREATE TABLE IF NOT EXISTS csat_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel_id INTEGER NOT NULL,
+    enabled NUMERIC DEFAULT false,
+    question TEXT NOT NULL,
+    comment_prompt TEXT,
+    response_window_seconds INTEGER DEFAULT 86400,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_csat_policies_channel_id ON csat_policies(channel_id);
+
+-- Table: csat_surveys
+CREATE TABLE IF NOT EXISTS csat_surveys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL,
+    external_user_id INTEGER NOT NULL,
+    agent_id TEXT,
+    team_id INTEGER,
+    message_id INTEGER,
+    status TEXT NOT NULL DEFAULT 'pending',
+    rating INTEGER,
+    comment TEXT,
+    sent_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    rated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_csat_surveys_conversation_id ON csat_surveys(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_csat_surveys_channel_user ON csat_surveys(channel_id, external_user_id);
+CREATE INDEX IF NOT EXISTS idx_csat_surveys_agent_id ON csat_surveys(agent_id);
+CREATE INDEX IF NOT EXISTS idx_csat_surveys_sent_at ON csat_surveys(sent_at);
@@ -0,0 +1,8 @@
+-- Migration: add_business_holidays_and_away_replies
+-- Generated: 2026-10-18T11:10:00+05:45
+
+ALTER TABLE business_hours ADD COLUMN holidays TEXT;
+ALTER TABLE business_hours ADD COLUMN away_message TEXT;
+ALTER TABLE business_hours ADD COLUMN away_cooldown_seconds INTEGER DEFAULT 0;
+
+ALTER TABLE conversations ADD COLUMN away_replied_at DATETIME;
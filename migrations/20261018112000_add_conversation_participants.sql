@@ -0,0 +1,21 @@
+-- Migration: add_conversation_participants
+-- Generated: 2026-10-18T11:20:00+05:45
+
+ALTER TABLE conversations ADD COLUMN platform_chat_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_conversations_channel_chat ON conversations(channel_id, platform_chat_id);
+
+-- Table: conversation_participants
+CREATE TABLE IF NOT EXISTS conversation_participants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id INTEGER NOT NULL,
+    external_user_id INTEGER NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    joined_at DATETIME NOT NULL,
+    left_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participants_conv_user ON conversation_participants(conversation_id, external_user_id);
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_external_user_id ON conversation_participants(external_user_id);
+
+-- Every existing conversation has its customer as requester
+INSERT OR IGNORE INTO conversation_participants (conversation_id, external_user_id, role, joined_at)
+SELECT id, external_user_id, 'requester', created_at FROM conversations;
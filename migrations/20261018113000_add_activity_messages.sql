@@ -0,0 +1,4 @@
+-- Migration: add_activity_messages
+-- Generated: 2026-10-18T11:30:00+05:45
+
+ALTER TABLE chat_channels ADD COLUMN activity_messages BOOLEAN DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Migration: add_notes_and_message_edits
+-- Generated: 2026-10-18T11:40:00+05:45
+
+ALTER TABLE messages ADD COLUMN author_id TEXT;
+ALTER TABLE messages ADD COLUMN edited_at DATETIME;
+ALTER TABLE messages ADD COLUMN deleted_at DATETIME;
+
+-- Table: message_edits
+CREATE TABLE IF NOT EXISTS message_edits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    edited_by TEXT,
+    edited_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_message_edits_message_id ON message_edits(message_id);
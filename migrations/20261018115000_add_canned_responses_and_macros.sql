@@ -0,0 +1,43 @@
+-- Migration: add_canned_responses_and_macros
+-- Generated: 2026-10-18T11:50:00+05:45
+
+-- Table: canned_responses
+CREATE TABLE IF NOT EXISTS canned_responses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    team_id INTEGER,
+    shortcut TEXT(50) NOT NULL,
+    title TEXT(100) NOT NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_canned_responses_org_shortcut ON canned_responses(organization_id, shortcut);
+CREATE INDEX IF NOT EXISTS idx_canned_responses_team_id ON canned_responses(team_id);
+
+-- Table: macros
+CREATE TABLE IF NOT EXISTS macros (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id INTEGER NOT NULL,
+    team_id INTEGER,
+    name TEXT(100) NOT NULL,
+    description TEXT,
+    actions TEXT,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_macros_org_name ON macros(organization_id, name);
+CREATE INDEX IF NOT EXISTS idx_macros_team_id ON macros(team_id);
+
+-- Table: macro_runs
+CREATE TABLE IF NOT EXISTS macro_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    macro_id INTEGER NOT NULL,
+    conversation_id INTEGER NOT NULL,
+    actor_id TEXT,
+    actions TEXT,
+    error TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_macro_runs_macro_id ON macro_runs(macro_id);
+CREATE INDEX IF NOT EXISTS idx_macro_runs_conversation_id ON macro_runs(conversation_id);
@@ -0,0 +1,4 @@
+-- Migration: add_deleted_message_retention
+-- Generated: 2026-10-18T12:00:00+05:45
+
+ALTER TABLE chat_channels ADD COLUMN retain_deleted_days INTEGER DEFAULT 0;
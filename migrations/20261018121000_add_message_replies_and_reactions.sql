@@ -0,0 +1,16 @@
+-- Migration: add_message_replies_and_reactions
+-- Generated: 2026-10-18T12:10:00+05:45
+
+ALTER TABLE messages ADD COLUMN reply_to_message_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_messages_reply_to_message_id ON messages(reply_to_message_id);
+
+-- Table: message_reactions
+CREATE TABLE IF NOT EXISTS message_reactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id INTEGER NOT NULL,
+    sender_type TEXT NOT NULL,
+    sender_id TEXT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_sender_emoji ON message_reactions(message_id, sender_type, sender_id, emoji);
@@ -0,0 +1,5 @@
+-- Migration: add_command_claim_time
+-- Generated: 2026-10-18T12:20:00+05:45
+
+ALTER TABLE processed_commands ADD COLUMN claimed_at DATETIME;
+UPDATE processed_commands SET claimed_at = created_at WHERE claimed_at IS NULL;
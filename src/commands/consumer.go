@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CommandField is the stream entry field holding the JSON-encoded command
+const CommandField = "command"
+
+// Consumer reads commands from a Redis stream with a consumer group, so
+// several service instances can share the load. Entries left
+// unacknowledged for claimTimeout, because they failed or their consumer
+// died, are claimed again and retried.
+type Consumer struct {
+	client       *redis.Client
+	stream       string
+	group        string
+	consumer     string
+	claimTimeout time.Duration
+	service      services.CommandService
+}
+
+// NewRedisConsumer connects to Redis and prepares a stream consumer
+func NewRedisConsumer(redisURL, stream, group, consumer string, claimTimeout time.Duration, service services.CommandService) (*Consumer, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &Consumer{
+		client:       client,
+		stream:       stream,
+		group:        group,
+		consumer:     consumer,
+		claimTimeout: claimTimeout,
+		service:      service,
+	}, nil
+}
+
+// Run consumes commands until ctx is cancelled. Entries left pending by a
+// previous run of this consumer are processed once first; after that,
+// pending entries are only retried once they have been idle for
+// claimTimeout, which also spaces out retries of a failing command.
+func (c *Consumer) Run(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.group, "0").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	c.replayPending(ctx)
+
+	for ctx.Err() == nil {
+		c.reclaim(ctx)
+
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.consumer,
+			Streams:  []string{c.stream, ">"},
+			Count:    10,
+			Block:    5 * time.Second,
+		}).Result()
+
+		if ctx.Err() != nil {
+			break
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Command consumer: read failed: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				c.handle(ctx, msg)
+			}
+		}
+	}
+	return nil
+}
+
+// replayPending processes the entries pending for this consumer in a
+// single pass, reading past each batch so an entry that keeps failing is
+// not read again
+func (c *Consumer) replayPending(ctx context.Context) {
+	cursor := "0"
+	for ctx.Err() == nil {
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.consumer,
+			Streams:  []string{c.stream, cursor},
+			Count:    10,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				log.Printf("Command consumer: failed to read pending entries: %v", err)
+			}
+			return
+		}
+
+		processed := 0
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				c.handle(ctx, msg)
+				cursor = msg.ID
+				processed++
+			}
+		}
+		if processed == 0 {
+			return
+		}
+	}
+}
+
+// reclaim claims the group's entries that have been pending for longer
+// than claimTimeout, from any consumer, and retries them
+func (c *Consumer) reclaim(ctx context.Context) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		msgs, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.stream,
+			Group:    c.group,
+			Consumer: c.consumer,
+			MinIdle:  c.claimTimeout,
+			Start:    start,
+			Count:    10,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Command consumer: failed to claim stale entries: %v", err)
+			}
+			return
+		}
+
+		for _, msg := range msgs {
+			c.handle(ctx, msg)
+		}
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// handle executes one stream entry and acknowledges it unless execution
+// failed for infrastructure reasons, in which case it stays pending
+func (c *Consumer) handle(ctx context.Context, msg redis.XMessage) {
+	raw, _ := msg.Values[CommandField].(string)
+
+	var cmd models.Command
+	if err := json.Unmarshal([]byte(raw), &cmd); err != nil {
+		log.Printf("Command consumer: dropping malformed entry %s: %v", msg.ID, err)
+		c.ack(ctx, msg.ID)
+		return
+	}
+
+	if _, err := c.service.Execute(ctx, &cmd); err != nil {
+		log.Printf("Command consumer: command %s failed: %v", cmd.ID, err)
+		return
+	}
+
+	c.ack(ctx, msg.ID)
+}
+
+func (c *Consumer) ack(ctx context.Context, id string) {
+	if err := c.client.XAck(ctx, c.stream, c.group, id).Err(); err != nil {
+		log.Printf("Command consumer: failed to ack %s: %v", id, err)
+	}
+}
+
+// Close closes the Redis connection
+func (c *Consumer) Close() error {
+	return c.client.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,7 +13,11 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
+	NATS     NATSConfig
 	JWT      JWTConfig
+	Events   EventsConfig
+	Commands CommandsConfig
+	Jobs     JobsConfig
 }
 
 type ServerConfig struct {
@@ -30,12 +35,47 @@ type RedisConfig struct {
 	Password string
 	DB       int
 	Enabled  bool
+	// OrgScoped publishes to org:{id}:{eventType} channels when the
+	// organization is known
+	OrgScoped bool
+}
+
+// NATSConfig configures the JetStream event sink
+type NATSConfig struct {
+	URL           string
+	Enabled       bool
+	Stream        string
+	SubjectPrefix string
+	// AutoProvision creates or updates Stream to capture SubjectPrefix.>
+	AutoProvision bool
 }
 
 type JWTConfig struct {
 	Secret string
 }
 
+type EventsConfig struct {
+	// RoutesFile is a JSON list of event routes; empty uses the defaults
+	RoutesFile   string
+	AuditLogPath string
+}
+
+// CommandsConfig configures the Redis stream command consumer.
+// ClaimTimeout is how long a command may stay unacknowledged before it is
+// retried, by this or another consumer.
+type CommandsConfig struct {
+	Enabled      bool
+	Stream       string
+	Group        string
+	Consumer     string
+	ClaimTimeout time.Duration
+}
+
+// JobsConfig configures background jobs such as routing reassignment
+type JobsConfig struct {
+	Interval time.Duration
+}
+
 func Load() (*Config, error) {
 
 	_ = godotenv.Load()
@@ -49,15 +89,37 @@ func Load() (*Config, error) {
 			Path: getEnv("DATABASE_PATH", "./data/chat.db"),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvAsInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
-			Enabled:  getEnvAsBool("REDIS_ENABLED", false),
+			Host:      getEnv("REDIS_HOST", "localhost"),
+			Port:      getEnvAsInt("REDIS_PORT", 6379),
+			Password:  getEnv("REDIS_PASSWORD", ""),
+			DB:        getEnvAsInt("REDIS_DB", 0),
+			Enabled:   getEnvAsBool("REDIS_ENABLED", false),
+			OrgScoped: getEnvAsBool("REDIS_ORG_SCOPED_CHANNELS", false),
+		},
+		NATS: NATSConfig{
+			URL:           getEnv("NATS_URL", "nats://localhost:4222"),
+			Enabled:       getEnvAsBool("NATS_ENABLED", false),
+			Stream:        getEnv("NATS_STREAM", "CHAT_EVENTS"),
+			SubjectPrefix: getEnv("NATS_SUBJECT_PREFIX", "chat.events"),
+			AutoProvision: getEnvAsBool("NATS_AUTO_PROVISION_STREAM", false),
 		},
 		JWT: JWTConfig{
 			Secret: getEnv("JWT_SECRET", "change-me-in-production"),
 		},
+		Events: EventsConfig{
+			RoutesFile:   getEnv("EVENT_ROUTES_FILE", ""),
+			AuditLogPath: getEnv("EVENT_AUDIT_LOG_PATH", "./data/events.log"),
+		},
+		Commands: CommandsConfig{
+			Enabled:      getEnvAsBool("COMMANDS_ENABLED", false),
+			Stream:       getEnv("COMMANDS_STREAM", "chat:commands"),
+			Group:        getEnv("COMMANDS_GROUP", "go-chat-service"),
+			Consumer:     getEnv("COMMANDS_CONSUMER", hostname()),
+			ClaimTimeout: time.Duration(getEnvAsInt("COMMANDS_CLAIM_TIMEOUT_SECONDS", 60)) * time.Second,
+		},
+		Jobs: JobsConfig{
+			Interval: time.Duration(getEnvAsInt("JOBS_INTERVAL_SECONDS", 30)) * time.Second,
+		},
 	}
 
 	if config.JWT.Secret == "change-me-in-production" && config.Server.Env == "production" {
@@ -67,6 +129,13 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+func hostname() string {
+	if name, err := os.Hostname(); err == nil {
+		return name
+	}
+	return "go-chat-service"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
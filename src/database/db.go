@@ -35,20 +35,47 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 	return db, nil
 }
 
-// AutoMigrate runs GORM auto-migration for all models
-func AutoMigrate(db *gorm.DB) error {
-	if db == nil {
-		return fmt.Errorf("database not initialized")
-	}
-
-	err := db.AutoMigrate(
+// Models returns every GORM model managed by migrations
+func Models() []interface{} {
+	return []interface{}{
 		&models.Organization{},
 		&models.ChatChannel{},
 		&models.ExternalUser{},
 		&models.Conversation{},
+		&models.ConversationParticipant{},
 		&models.Message{},
+		&models.MessageEdit{},
+		&models.MessageReaction{},
 		&models.WebhookEvent{},
-	)
+		&models.ProcessedCommand{},
+		&models.Tag{},
+		&models.ConversationTag{},
+		&models.Agent{},
+		&models.RoutingPolicy{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.ConversationEvent{},
+		&models.SLAPolicy{},
+		&models.SLAAlert{},
+		&models.BusinessHours{},
+		&models.LifecyclePolicy{},
+		&models.ReadCursor{},
+		&models.CustomAttributeDefinition{},
+		&models.CSATPolicy{},
+		&models.CSATSurvey{},
+		&models.CannedResponse{},
+		&models.Macro{},
+		&models.MacroRun{},
+	}
+}
+
+// AutoMigrate runs GORM auto-migration for all models
+func AutoMigrate(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	err := db.AutoMigrate(Models()...)
 	if err != nil {
 		return fmt.Errorf("failed to run auto-migration: %w", err)
 	}
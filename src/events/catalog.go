@@ -0,0 +1,181 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaEntry describes one event type in the published catalog
+type SchemaEntry struct {
+	Type          string                 `json:"type"`
+	SchemaVersion int                    `json:"schema_version"`
+	Schema        map[string]interface{} `json:"schema"`
+}
+
+// Catalog returns the JSON Schema of every registered event, sorted by type
+func Catalog() []SchemaEntry {
+	entries := make([]SchemaEntry, 0, len(registry))
+	for _, p := range registry {
+		entries = append(entries, SchemaEntry{
+			Type:          p.EventType(),
+			SchemaVersion: p.SchemaVersion(),
+			Schema:        SchemaFor(p),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Type < entries[j].Type
+	})
+
+	return entries
+}
+
+// SchemaFor generates the JSON Schema for a payload, including the
+// schema_version field stamped on every emitted payload.
+func SchemaFor(p Payload) map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(p))
+	schema["$schema"] = jsonSchemaDraft
+	schema["$id"] = fmt.Sprintf("urn:go-chat-service:event:%s:v%d", p.EventType(), p.SchemaVersion())
+	schema["title"] = p.EventType()
+
+	props := schema["properties"].(map[string]interface{})
+	props["schema_version"] = map[string]interface{}{
+		"type":  "integer",
+		"const": p.SchemaVersion(),
+	}
+	schema["required"] = append(schema["required"].([]string), "schema_version")
+
+	return schema
+}
+
+// Publish emits a typed payload, stamping it with its schema version and
+// the correlation/causation IDs carried by ctx
+func Publish(ctx context.Context, e Emitter, p Payload) error {
+	return e.EmitWithMetadata(p.EventType(), ToMap(p), TraceFrom(ctx).Metadata())
+}
+
+// ToMap flattens a payload into the map form carried by Event.Payload.
+// Values keep their Go types; nil optional fields are dropped.
+func ToMap(p Payload) map[string]interface{} {
+	v := reflect.ValueOf(p)
+	t := v.Type()
+
+	out := make(map[string]interface{}, t.NumField()+1)
+	for i := 0; i < t.NumField(); i++ {
+		name, omitempty, ok := jsonField(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				if !omitempty {
+					out[name] = nil
+				}
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		out[name] = fv.Interface()
+	}
+	out["schema_version"] = p.SchemaVersion()
+
+	return out
+}
+
+func structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+
+		prop := typeSchema(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+		props[name] = prop
+
+		if !omitempty && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	sort.Strings(required)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return typeSchema(t.Elem())
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func jsonField(f reflect.StructField) (name string, omitempty bool, ok bool) {
+	if !f.IsExported() {
+		return "", false, false
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return name, omitempty, true
+}
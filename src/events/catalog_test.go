@@ -0,0 +1,115 @@
+package events
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/catalog.golden.json from the current payload types")
+
+const goldenPath = "testdata/catalog.golden.json"
+
+// TestCatalog_Compatibility fails when a payload's schema changes without a
+// schema_version bump. After bumping, regenerate the golden file with
+// `go test ./src/events -run TestCatalog_Compatibility -update`, which
+// still refuses a changed schema whose version was not bumped.
+func TestCatalog_Compatibility(t *testing.T) {
+	current := Catalog()
+	currentJSON, err := json.MarshalIndent(current, "", "  ")
+	require.NoError(t, err)
+
+	goldenByType := make(map[string]SchemaEntry)
+	data, err := os.ReadFile(goldenPath)
+	if err != nil && !(*update && os.IsNotExist(err)) {
+		require.NoError(t, err, "golden catalog missing; run with -update")
+	}
+	if err == nil {
+		var golden []SchemaEntry
+		require.NoError(t, json.Unmarshal(data, &golden))
+		for _, entry := range golden {
+			goldenByType[entry.Type] = entry
+		}
+	}
+
+	for _, entry := range current {
+		prev, ok := goldenByType[entry.Type]
+		if !ok {
+			if !*update {
+				t.Errorf("%s is not in the golden catalog; run with -update", entry.Type)
+			}
+			continue
+		}
+
+		// Round-trip through JSON so both sides compare as plain values
+		var schema map[string]interface{}
+		raw, _ := json.Marshal(entry.Schema)
+		require.NoError(t, json.Unmarshal(raw, &schema))
+
+		changed := !assert.ObjectsAreEqual(prev.Schema, schema)
+		switch {
+		case entry.SchemaVersion < prev.SchemaVersion:
+			t.Errorf("%s: schema_version went backwards (%d -> %d)", entry.Type, prev.SchemaVersion, entry.SchemaVersion)
+		case changed && entry.SchemaVersion == prev.SchemaVersion:
+			t.Errorf("%s: payload changed without a schema_version bump (still v%d)", entry.Type, entry.SchemaVersion)
+		case entry.SchemaVersion != prev.SchemaVersion && !*update:
+			t.Errorf("%s: schema_version bumped to v%d; run with -update to record it", entry.Type, entry.SchemaVersion)
+		}
+		delete(goldenByType, entry.Type)
+	}
+
+	if !*update {
+		for eventType := range goldenByType {
+			t.Errorf("%s was removed from the catalog; run with -update if intentional", eventType)
+		}
+		return
+	}
+
+	// Only a compatible catalog is recorded
+	if t.Failed() {
+		t.Fatal("golden catalog not updated")
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0755))
+	require.NoError(t, os.WriteFile(goldenPath, append(currentJSON, '\n'), 0644))
+}
+
+func TestCatalog_UniqueTypes(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, entry := range Catalog() {
+		assert.False(t, seen[entry.Type], "duplicate event type %s", entry.Type)
+		seen[entry.Type] = true
+	}
+}
+
+func TestToMap(t *testing.T) {
+	status := "resolved"
+	payload := ToMap(ConversationUpdatedPayload{
+		ConversationID: 7,
+		Status:         &status,
+	})
+
+	assert.Equal(t, int64(7), payload["conversation_id"])
+	assert.Equal(t, "resolved", payload["status"])
+	assert.Equal(t, 4, payload["schema_version"])
+	_, hasPriority := payload["priority"]
+	assert.False(t, hasPriority)
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor(MessageNewPayload{})
+
+	assert.Equal(t, EventNewMessage, schema["title"])
+	required := schema["required"].([]string)
+	assert.Contains(t, required, "message_id")
+	assert.Contains(t, required, "schema_version")
+	assert.NotContains(t, required, "external_user_id")
+
+	props := schema["properties"].(map[string]interface{})
+	timestamp := props["timestamp"].(map[string]interface{})
+	assert.Equal(t, "date-time", timestamp["format"])
+}
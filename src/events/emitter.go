@@ -6,21 +6,39 @@ import (
 	"fmt"
 	"time"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
 	"github.com/redis/go-redis/v9"
 )
 
-// Event types that will be emitted to NestJS
+// Event types that will be emitted to NestJS. Every type has a typed
+// payload registered in schemas.go.
 const (
-	EventNewChatRequest      = "chat.request.new"
-	EventChatAccepted        = "chat.request.accepted"
-	EventChatRejected        = "chat.request.rejected"
-	EventNewMessage          = "chat.message.new"
-	EventMessageDelivered    = "chat.message.delivered"
-	EventMessageRead         = "chat.message.read"
-	EventConversationCreated = "chat.conversation.created"
-	EventConversationUpdated = "chat.conversation.updated"
-	EventUserOnline          = "chat.user.online"
-	EventUserOffline         = "chat.user.offline"
+	EventNewMessage           = "chat.message.new"
+	EventMessageDelivered     = "chat.message.delivered"
+	EventMessageRead          = "chat.message.read"
+	EventMessageUpdated       = "chat.message.updated"
+	EventMessageDeleted       = "chat.message.deleted"
+	EventReactionAdded        = "chat.message.reaction_added"
+	EventReactionRemoved      = "chat.message.reaction_removed"
+	EventConversationUpdated  = "chat.conversation.updated"
+	EventConversationAssigned = "chat.conversation.assigned"
+	EventConversationMerged   = "chat.conversation.merged"
+	EventConversationSplit    = "chat.conversation.split"
+	EventConversationWoken    = "chat.conversation.woken"
+	EventConversationRead     = "chat.conversation.read"
+	EventConversationTagged   = "chat.conversation.tags_changed"
+	EventParticipantJoined    = "chat.participant.joined"
+	EventParticipantLeft      = "chat.participant.left"
+	EventNoteMention          = "chat.note.mention"
+	EventTranscriptEmail      = "chat.transcript.email_requested"
+	EventCSATSent             = "chat.csat.sent"
+	EventCSATResponded        = "chat.csat.responded"
+	EventUserBlocked          = "chat.user.blocked"
+	EventUserUnblocked        = "chat.user.unblocked"
+	EventCommandResult        = "chat.command.result"
+	EventSLAWarning           = "chat.sla.warning"
+	EventSLABreached          = "chat.sla.breached"
 
 	// Organization events
 	EventOrganizationCreated = "organization.created"
@@ -50,14 +68,37 @@ type Emitter interface {
 	Close() error
 }
 
+// Source identifies this service on every emitted event
+const Source = "go-chat-service"
+
+// newEvent builds the envelope shared by every sink. The ID is the one the
+// router assigned before fan-out, if any.
+func newEvent(source, eventType string, payload map[string]interface{}, metadata map[string]string) Event {
+	id := metadata[MetadataEventID]
+	if id == "" {
+		id = utils.NewULID()
+	}
+	return Event{
+		ID:        id,
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Source:    source,
+		Payload:   payload,
+		Metadata:  metadata,
+	}
+}
+
 type redisEmitter struct {
-	client  *redis.Client
-	enabled bool
-	source  string
+	client    *redis.Client
+	enabled   bool
+	orgScoped bool
+	source    string
 }
 
-// NewRedisEmitter creates a new Redis event emitter
-func NewRedisEmitter(redisURL string, enabled bool) (Emitter, error) {
+// NewRedisEmitter creates a new Redis event emitter. With orgScoped set,
+// events that carry an organization_id in their metadata are published to
+// org:{id}:{eventType} instead of the bare event type channel.
+func NewRedisEmitter(redisURL string, enabled, orgScoped bool) (Emitter, error) {
 	if !enabled {
 		return &noopEmitter{}, nil
 	}
@@ -78,9 +119,10 @@ func NewRedisEmitter(redisURL string, enabled bool) (Emitter, error) {
 	}
 
 	return &redisEmitter{
-		client:  client,
-		enabled: true,
-		source:  "go-chat-service",
+		client:    client,
+		enabled:   true,
+		orgScoped: orgScoped,
+		source:    Source,
 	}, nil
 }
 
@@ -93,14 +135,7 @@ func (e *redisEmitter) EmitWithMetadata(eventType string, payload map[string]int
 		return nil
 	}
 
-	event := Event{
-		ID:        fmt.Sprintf("%s-%d", eventType, time.Now().UnixNano()),
-		Type:      eventType,
-		Timestamp: time.Now(),
-		Source:    e.source,
-		Payload:   payload,
-		Metadata:  metadata,
-	}
+	event := newEvent(e.source, eventType, payload, metadata)
 
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -110,14 +145,23 @@ func (e *redisEmitter) EmitWithMetadata(eventType string, payload map[string]int
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	// Publish to event type channel
-	if err := e.client.Publish(ctx, eventType, data).Err(); err != nil {
+	if err := e.client.Publish(ctx, e.channelName(eventType, metadata), data).Err(); err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	return nil
 }
 
+// channelName returns the Redis channel an event is published to
+func (e *redisEmitter) channelName(eventType string, metadata map[string]string) string {
+	if e.orgScoped {
+		if orgID := metadata[MetadataOrganizationID]; orgID != "" {
+			return fmt.Sprintf("org:%s:%s", orgID, eventType)
+		}
+	}
+	return eventType
+}
+
 func (e *redisEmitter) Close() error {
 	if e.client != nil {
 		return e.client.Close()
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsDuplicateWindow is how long JetStream remembers Nats-Msg-Id values
+// on streams provisioned by this service
+const natsDuplicateWindow = 2 * time.Minute
+
+type natsEmitter struct {
+	conn          *nats.Conn
+	js            jetstream.JetStream
+	subjectPrefix string
+	source        string
+}
+
+// NewNATSEmitter creates an emitter that publishes events to JetStream and
+// waits for each publish to be acknowledged. Events are published to
+// {prefix}.org.{id}.{eventType} when the organization is known and to
+// {prefix}.global.{eventType} otherwise. The event ID is sent as
+// Nats-Msg-Id so the stream drops redelivered duplicates. With
+// provisionStream set, the stream is created (or updated) to capture
+// {prefix}.>; otherwise it must already exist.
+func NewNATSEmitter(url, stream, subjectPrefix string, enabled, provisionStream bool) (Emitter, error) {
+	if !enabled {
+		return &noopEmitter{}, nil
+	}
+
+	conn, err := nats.Connect(url, nats.Name(Source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if provisionStream {
+		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:       stream,
+			Subjects:   []string{subjectPrefix + ".>"},
+			Duplicates: natsDuplicateWindow,
+		})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to provision NATS stream %s: %w", stream, err)
+		}
+	} else if _, err := js.Stream(ctx, stream); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to find NATS stream %s: %w", stream, err)
+	}
+
+	return &natsEmitter{
+		conn:          conn,
+		js:            js,
+		subjectPrefix: subjectPrefix,
+		source:        Source,
+	}, nil
+}
+
+func (e *natsEmitter) Emit(eventType string, payload map[string]interface{}) error {
+	return e.EmitWithMetadata(eventType, payload, nil)
+}
+
+func (e *natsEmitter) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	event := newEvent(e.source, eventType, payload, metadata)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	msg := nats.NewMsg(e.subject(eventType, metadata))
+	msg.Data = data
+	// The event ID is assigned once per event, so JetStream drops a retried
+	// publish of the same event as a duplicate
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+
+	if _, err := e.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// subject returns the JetStream subject an event is published to
+func (e *natsEmitter) subject(eventType string, metadata map[string]string) string {
+	if orgID := metadata[MetadataOrganizationID]; orgID != "" {
+		return fmt.Sprintf("%s.org.%s.%s", e.subjectPrefix, orgID, eventType)
+	}
+	return fmt.Sprintf("%s.global.%s", e.subjectPrefix, eventType)
+}
+
+func (e *natsEmitter) Close() error {
+	if e.conn == nil {
+		return nil
+	}
+	return e.conn.Drain()
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startNATSServer runs an in-process JetStream-enabled NATS server
+func startNATSServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "nats server not ready")
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func openStream(t *testing.T, url, name string) jetstream.Stream {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	stream, err := js.Stream(context.Background(), name)
+	require.NoError(t, err)
+	return stream
+}
+
+func TestNATSEmitter_PublishesToScopedSubjects(t *testing.T) {
+	srv := startNATSServer(t)
+
+	emitter, err := NewNATSEmitter(srv.ClientURL(), "CHAT_EVENTS", "chat.events", true, true)
+	require.NoError(t, err)
+	defer emitter.Close()
+
+	require.NoError(t, emitter.EmitWithMetadata(EventNewMessage,
+		map[string]interface{}{"message_id": 1},
+		map[string]string{MetadataOrganizationID: "12"},
+	))
+	require.NoError(t, emitter.Emit(EventOrganizationCreated, map[string]interface{}{}))
+
+	stream := openStream(t, srv.ClientURL(), "CHAT_EVENTS")
+
+	first, err := stream.GetMsg(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "chat.events.org.12.chat.message.new", first.Subject)
+
+	var event Event
+	require.NoError(t, json.Unmarshal(first.Data, &event))
+	assert.Equal(t, EventNewMessage, event.Type)
+	assert.Equal(t, event.ID, first.Header.Get(nats.MsgIdHdr))
+
+	second, err := stream.GetMsg(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "chat.events.global.organization.created", second.Subject)
+}
+
+func TestNATSEmitter_DeduplicatesByMsgID(t *testing.T) {
+	srv := startNATSServer(t)
+
+	emitter, err := NewNATSEmitter(srv.ClientURL(), "CHAT_EVENTS", "chat.events", true, true)
+	require.NoError(t, err)
+	defer emitter.Close()
+
+	// A retried publish carries the same event, and so the same ID
+	metadata := map[string]string{MetadataEventID: "01HZZZZZZZZZZZZZZZZZZZZZZZ"}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, emitter.EmitWithMetadata(EventNewMessage, map[string]interface{}{"message_id": 1}, metadata))
+	}
+	require.NoError(t, emitter.Emit(EventNewMessage, map[string]interface{}{"message_id": 2}))
+
+	info, err := openStream(t, srv.ClientURL(), "CHAT_EVENTS").Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.State.Msgs)
+}
+
+func TestNATSEmitter_RequiresStreamWithoutProvisioning(t *testing.T) {
+	srv := startNATSServer(t)
+
+	_, err := NewNATSEmitter(srv.ClientURL(), "MISSING", "chat.events", true, false)
+	assert.Error(t, err)
+}
+
+func TestNATSEmitter_Disabled(t *testing.T) {
+	emitter, err := NewNATSEmitter("nats://127.0.0.1:1", "CHAT_EVENTS", "chat.events", false, false)
+	require.NoError(t, err)
+	assert.NoError(t, emitter.Emit(EventNewMessage, nil))
+}
@@ -0,0 +1,239 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// Metadata keys the router fills in so sinks can scope and identify events
+const (
+	MetadataOrganizationID = "organization_id"
+	MetadataPlatform       = "platform"
+	MetadataEventID        = "event_id"
+)
+
+// Scope is the organization and platform an event belongs to
+type Scope struct {
+	OrganizationID int64
+	Platform       string
+}
+
+// ScopeResolver works out the scope of a payload that does not carry it
+// directly, e.g. by looking up the channel behind a conversation_id.
+type ScopeResolver func(payload map[string]interface{}) (Scope, bool)
+
+// Rule sends matching events to a sink. Empty filters match everything.
+// Event types match exactly or by prefix when ending in "*" (e.g. "chat.*").
+type Rule struct {
+	Sink            Emitter
+	EventTypes      []string
+	OrganizationIDs []int64
+	Platforms       []string
+}
+
+func (r Rule) matches(eventType string, scope Scope) bool {
+	if len(r.EventTypes) > 0 && !matchesEventType(r.EventTypes, eventType) {
+		return false
+	}
+	if len(r.OrganizationIDs) > 0 && !slices.Contains(r.OrganizationIDs, scope.OrganizationID) {
+		return false
+	}
+	if len(r.Platforms) > 0 && !slices.Contains(r.Platforms, scope.Platform) {
+		return false
+	}
+	return true
+}
+
+type routerEmitter struct {
+	rules    []Rule
+	resolver ScopeResolver
+}
+
+// NewRouter creates an emitter that fans each event out to every sink whose
+// rule matches. The resolver may be nil.
+func NewRouter(resolver ScopeResolver, rules ...Rule) Emitter {
+	return &routerEmitter{
+		rules:    rules,
+		resolver: resolver,
+	}
+}
+
+func (e *routerEmitter) Emit(eventType string, payload map[string]interface{}) error {
+	return e.EmitWithMetadata(eventType, payload, nil)
+}
+
+func (e *routerEmitter) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	scope := e.scopeOf(payload, metadata)
+
+	enriched := make(map[string]string, len(metadata)+3)
+	for k, v := range metadata {
+		enriched[k] = v
+	}
+	// Every sink delivers the event under the same ID, so consumers of
+	// several sinks can deduplicate it
+	if enriched[MetadataEventID] == "" {
+		enriched[MetadataEventID] = utils.NewULID()
+	}
+	if scope.OrganizationID != 0 {
+		enriched[MetadataOrganizationID] = strconv.FormatInt(scope.OrganizationID, 10)
+	}
+	if scope.Platform != "" {
+		enriched[MetadataPlatform] = scope.Platform
+	}
+
+	var errs []error
+	for _, rule := range e.rules {
+		if !rule.matches(eventType, scope) {
+			continue
+		}
+		if err := rule.Sink.EmitWithMetadata(eventType, payload, enriched); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// scopeOf reads the scope from the payload or metadata, falling back to the resolver
+func (e *routerEmitter) scopeOf(payload map[string]interface{}, metadata map[string]string) Scope {
+	var scope Scope
+
+	if id, ok := toInt64(payload["organization_id"]); ok {
+		scope.OrganizationID = id
+	} else if id, err := strconv.ParseInt(metadata[MetadataOrganizationID], 10, 64); err == nil {
+		scope.OrganizationID = id
+	}
+
+	if platform, ok := payload["platform"].(string); ok {
+		scope.Platform = platform
+	} else {
+		scope.Platform = metadata[MetadataPlatform]
+	}
+
+	if (scope.OrganizationID == 0 || scope.Platform == "") && e.resolver != nil {
+		if resolved, ok := e.resolver(payload); ok {
+			if scope.OrganizationID == 0 {
+				scope.OrganizationID = resolved.OrganizationID
+			}
+			if scope.Platform == "" {
+				scope.Platform = resolved.Platform
+			}
+		}
+	}
+
+	return scope
+}
+
+func (e *routerEmitter) Close() error {
+	closed := make(map[Emitter]bool)
+	var errs []error
+	for _, rule := range e.rules {
+		if closed[rule.Sink] {
+			continue
+		}
+		closed[rule.Sink] = true
+		if err := rule.Sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RouteSpec is the JSON form of a Rule, loaded from EVENT_ROUTES_FILE.
+// Sink is "redis", "nats", "audit" or "http"; http routes also need a URL.
+type RouteSpec struct {
+	Sink            string   `json:"sink"`
+	URL             string   `json:"url,omitempty"`
+	Secret          string   `json:"secret,omitempty"`
+	EventTypes      []string `json:"event_types,omitempty"`
+	OrganizationIDs []int64  `json:"organization_ids,omitempty"`
+	Platforms       []string `json:"platforms,omitempty"`
+}
+
+// DefaultRoutes sends every event to Redis, NATS (when enabled) and the
+// audit log
+func DefaultRoutes() []RouteSpec {
+	return []RouteSpec{
+		{Sink: "redis"},
+		{Sink: "nats"},
+		{Sink: "audit"},
+	}
+}
+
+// LoadRoutes reads route specs from a JSON file
+func LoadRoutes(path string) ([]RouteSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event routes: %w", err)
+	}
+
+	var specs []RouteSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse event routes: %w", err)
+	}
+	return specs, nil
+}
+
+// NewRouterFromSpecs builds a router from route specs. Named sinks ("redis",
+// "nats", "audit") are shared across routes; each http route gets its own emitter.
+// Routes naming a sink that is not configured are skipped.
+func NewRouterFromSpecs(specs []RouteSpec, sinks map[string]Emitter, resolver ScopeResolver) (Emitter, error) {
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		var sink Emitter
+		switch spec.Sink {
+		case "http":
+			if spec.URL == "" {
+				return nil, fmt.Errorf("http event route requires a url")
+			}
+			sink = NewHTTPEmitter(spec.URL, spec.Secret)
+		default:
+			s, ok := sinks[spec.Sink]
+			if !ok {
+				continue
+			}
+			sink = s
+		}
+
+		rules = append(rules, Rule{
+			Sink:            sink,
+			EventTypes:      spec.EventTypes,
+			OrganizationIDs: spec.OrganizationIDs,
+			Platforms:       spec.Platforms,
+		})
+	}
+
+	return NewRouter(resolver, rules...), nil
+}
+
+func matchesEventType(patterns []string, eventType string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(eventType, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if p == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
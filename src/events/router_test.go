@@ -0,0 +1,167 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink captures events routed to it
+type recordingSink struct {
+	events   []string
+	metadata []map[string]string
+	err      error
+	closed   int
+}
+
+func (s *recordingSink) Emit(eventType string, payload map[string]interface{}) error {
+	return s.EmitWithMetadata(eventType, payload, nil)
+}
+
+func (s *recordingSink) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	s.events = append(s.events, eventType)
+	s.metadata = append(s.metadata, metadata)
+	return s.err
+}
+
+func (s *recordingSink) Close() error {
+	s.closed++
+	return nil
+}
+
+func TestRouter_FansOutByEventType(t *testing.T) {
+	chat := &recordingSink{}
+	all := &recordingSink{}
+	router := NewRouter(nil,
+		Rule{Sink: chat, EventTypes: []string{"chat.*"}},
+		Rule{Sink: all},
+	)
+
+	require.NoError(t, router.Emit(EventNewMessage, map[string]interface{}{}))
+	require.NoError(t, router.Emit(EventChannelCreated, map[string]interface{}{}))
+
+	assert.Equal(t, []string{EventNewMessage}, chat.events)
+	assert.Equal(t, []string{EventNewMessage, EventChannelCreated}, all.events)
+}
+
+func TestRouter_FiltersByOrganizationAndPlatform(t *testing.T) {
+	org1 := &recordingSink{}
+	whatsapp := &recordingSink{}
+	router := NewRouter(nil,
+		Rule{Sink: org1, OrganizationIDs: []int64{1}},
+		Rule{Sink: whatsapp, Platforms: []string{"whatsapp"}},
+	)
+
+	router.Emit(EventChannelCreated, map[string]interface{}{"organization_id": int64(1), "platform": "telegram"})
+	router.Emit(EventChannelCreated, map[string]interface{}{"organization_id": int64(2), "platform": "whatsapp"})
+
+	assert.Len(t, org1.events, 1)
+	assert.Equal(t, "1", org1.metadata[0][MetadataOrganizationID])
+	assert.Len(t, whatsapp.events, 1)
+	assert.Equal(t, "2", whatsapp.metadata[0][MetadataOrganizationID])
+}
+
+func TestRouter_UsesResolverForMissingScope(t *testing.T) {
+	sink := &recordingSink{}
+	resolver := func(payload map[string]interface{}) (Scope, bool) {
+		if payload["conversation_id"] == int64(5) {
+			return Scope{OrganizationID: 9, Platform: "sms"}, true
+		}
+		return Scope{}, false
+	}
+	router := NewRouter(resolver, Rule{Sink: sink, OrganizationIDs: []int64{9}})
+
+	router.EmitWithMetadata(EventConversationUpdated, map[string]interface{}{"conversation_id": int64(5)}, map[string]string{"request": "abc"})
+	router.Emit(EventConversationUpdated, map[string]interface{}{"conversation_id": int64(6)})
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, "9", sink.metadata[0][MetadataOrganizationID])
+	assert.Equal(t, "sms", sink.metadata[0][MetadataPlatform])
+	assert.Equal(t, "abc", sink.metadata[0]["request"])
+}
+
+func TestRouter_JoinsSinkErrors(t *testing.T) {
+	failing := &recordingSink{err: errors.New("boom")}
+	ok := &recordingSink{}
+	router := NewRouter(nil, Rule{Sink: failing}, Rule{Sink: ok})
+
+	err := router.Emit(EventNewMessage, map[string]interface{}{})
+	assert.ErrorContains(t, err, "boom")
+	assert.Len(t, ok.events, 1)
+}
+
+func TestRouter_AssignsOneEventIDPerEvent(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewLogEmitter(filepath.Join(dir, "first.log"))
+	require.NoError(t, err)
+	second, err := NewLogEmitter(filepath.Join(dir, "second.log"))
+	require.NoError(t, err)
+	router := NewRouter(nil, Rule{Sink: first}, Rule{Sink: second})
+
+	require.NoError(t, router.Emit(EventNewMessage, map[string]interface{}{}))
+	require.NoError(t, router.EmitWithMetadata(EventNewMessage, map[string]interface{}{}, map[string]string{MetadataEventID: "evt-1"}))
+	require.NoError(t, router.Close())
+
+	readIDs := func(name string) []string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		var ids []string
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var event Event
+			require.NoError(t, json.Unmarshal([]byte(line), &event))
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	ids := readIDs("first.log")
+	require.Len(t, ids, 2)
+	assert.NotEmpty(t, ids[0])
+	assert.Equal(t, "evt-1", ids[1])
+	assert.Equal(t, ids, readIDs("second.log"))
+}
+
+func TestRouter_ClosesSharedSinksOnce(t *testing.T) {
+	sink := &recordingSink{}
+	router := NewRouter(nil, Rule{Sink: sink}, Rule{Sink: sink, EventTypes: []string{"chat.*"}})
+
+	require.NoError(t, router.Close())
+	assert.Equal(t, 1, sink.closed)
+}
+
+func TestNewRouterFromSpecs(t *testing.T) {
+	redis := &recordingSink{}
+
+	t.Run("skips unconfigured sinks", func(t *testing.T) {
+		router, err := NewRouterFromSpecs(DefaultRoutes(), map[string]Emitter{"redis": redis}, nil)
+		require.NoError(t, err)
+
+		router.Emit(EventNewMessage, map[string]interface{}{})
+		assert.Len(t, redis.events, 1)
+	})
+
+	t.Run("http route requires url", func(t *testing.T) {
+		_, err := NewRouterFromSpecs([]RouteSpec{{Sink: "http"}}, nil, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadRoutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"sink": "redis", "event_types": ["chat.*"], "organization_ids": [3]},
+		{"sink": "http", "url": "http://localhost:9000/events", "platforms": ["whatsapp"]}
+	]`), 0644))
+
+	specs, err := LoadRoutes(path)
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, []int64{3}, specs[0].OrganizationIDs)
+	assert.Equal(t, "http://localhost:9000/events", specs[1].URL)
+}
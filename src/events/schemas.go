@@ -0,0 +1,475 @@
+package events
+
+import (
+	"time"
+)
+
+// Payload is implemented by every typed event payload. The schema version
+// must be bumped whenever the shape of the payload changes.
+type Payload interface {
+	EventType() string
+	SchemaVersion() int
+}
+
+// Organization events
+
+type OrganizationCreatedPayload struct {
+	OrganizationID int64  `json:"organization_id"`
+	Slug           string `json:"slug"`
+	Name           string `json:"name"`
+}
+
+func (OrganizationCreatedPayload) EventType() string  { return EventOrganizationCreated }
+func (OrganizationCreatedPayload) SchemaVersion() int { return 1 }
+
+type OrganizationUpdatedPayload struct {
+	OrganizationID int64 `json:"organization_id"`
+}
+
+func (OrganizationUpdatedPayload) EventType() string  { return EventOrganizationUpdated }
+func (OrganizationUpdatedPayload) SchemaVersion() int { return 1 }
+
+type OrganizationDeletedPayload struct {
+	OrganizationID int64 `json:"organization_id"`
+}
+
+func (OrganizationDeletedPayload) EventType() string  { return EventOrganizationDeleted }
+func (OrganizationDeletedPayload) SchemaVersion() int { return 1 }
+
+// Channel events
+
+type ChannelCreatedPayload struct {
+	ChannelID      int64  `json:"channel_id"`
+	OrganizationID int64  `json:"organization_id"`
+	Platform       string `json:"platform" enum:"whatsapp,telegram,instagram,facebook,sms,email,web"`
+	Name           string `json:"name"`
+}
+
+func (ChannelCreatedPayload) EventType() string  { return EventChannelCreated }
+func (ChannelCreatedPayload) SchemaVersion() int { return 1 }
+
+type ChannelUpdatedPayload struct {
+	ChannelID int64   `json:"channel_id"`
+	Status    *string `json:"status,omitempty" enum:"active,inactive,error,pending"`
+}
+
+func (ChannelUpdatedPayload) EventType() string  { return EventChannelUpdated }
+func (ChannelUpdatedPayload) SchemaVersion() int { return 1 }
+
+type ChannelDeletedPayload struct {
+	ChannelID int64 `json:"channel_id"`
+}
+
+func (ChannelDeletedPayload) EventType() string  { return EventChannelDeleted }
+func (ChannelDeletedPayload) SchemaVersion() int { return 1 }
+
+// Message events
+
+// MessageNewPayload reports a new message. Inbound messages carry their
+// Sender, and PlatformChatID is set for group chats. Outbound messages with
+// QuickReplies should offer them as buttons on platforms that support them.
+// ReplyTo is set for a reply to an earlier message.
+type MessageNewPayload struct {
+	MessageID      int64        `json:"message_id"`
+	ConversationID int64        `json:"conversation_id"`
+	ChannelID      int64        `json:"channel_id"`
+	ExternalUserID *int64       `json:"external_user_id,omitempty"`
+	PlatformChatID *string      `json:"platform_chat_id,omitempty"`
+	Sender         *Sender      `json:"sender,omitempty"`
+	Content        string       `json:"content"`
+	MessageType    string       `json:"message_type" enum:"text,image,video,audio,file,location,contact,sticker,system"`
+	Direction      string       `json:"direction" enum:"inbound,outbound"`
+	QuickReplies   []QuickReply `json:"quick_replies,omitempty"`
+	ReplyTo        *MessageRef  `json:"reply_to,omitempty"`
+	Timestamp      time.Time    `json:"timestamp"`
+}
+
+func (MessageNewPayload) EventType() string  { return EventNewMessage }
+func (MessageNewPayload) SchemaVersion() int { return 4 }
+
+// Sender is the external user who wrote an inbound message, which in a
+// group chat need not be the conversation's customer
+type Sender struct {
+	ExternalUserID int64   `json:"external_user_id"`
+	PlatformUserID string  `json:"platform_user_id"`
+	DisplayName    *string `json:"display_name,omitempty"`
+}
+
+// QuickReply is a reply button. Title is shown to the customer and Payload
+// comes back when it is pressed.
+type QuickReply struct {
+	Title   string `json:"title"`
+	Payload string `json:"payload"`
+}
+
+// MessageRef identifies a message a reply refers to. PlatformMessageID is
+// set when the platform knows the message, so the reply can quote it there.
+type MessageRef struct {
+	MessageID         int64   `json:"message_id"`
+	PlatformMessageID *string `json:"platform_message_id,omitempty"`
+}
+
+type MessageDeliveredPayload struct {
+	MessageID int64  `json:"message_id"`
+	Status    string `json:"status" enum:"delivered"`
+}
+
+func (MessageDeliveredPayload) EventType() string  { return EventMessageDelivered }
+func (MessageDeliveredPayload) SchemaVersion() int { return 1 }
+
+type MessageReadPayload struct {
+	MessageID int64  `json:"message_id"`
+	Status    string `json:"status" enum:"read"`
+}
+
+func (MessageReadPayload) EventType() string  { return EventMessageRead }
+func (MessageReadPayload) SchemaVersion() int { return 1 }
+
+// MessageUpdatedPayload reports an edited message. EditedBy is the agent
+// who edited it, and is unset for edits the customer made on the platform.
+// Propagate asks for the edit to be made on the platform too.
+type MessageUpdatedPayload struct {
+	MessageID         int64     `json:"message_id"`
+	ConversationID    int64     `json:"conversation_id"`
+	ChannelID         int64     `json:"channel_id"`
+	PlatformMessageID *string   `json:"platform_message_id,omitempty"`
+	Content           string    `json:"content"`
+	Direction         string    `json:"direction" enum:"inbound,outbound"`
+	EditedBy          *string   `json:"edited_by,omitempty"`
+	Propagate         bool      `json:"propagate"`
+	EditedAt          time.Time `json:"edited_at"`
+}
+
+func (MessageUpdatedPayload) EventType() string  { return EventMessageUpdated }
+func (MessageUpdatedPayload) SchemaVersion() int { return 1 }
+
+// MessageDeletedPayload reports a deleted message. DeletedBy is the agent
+// who deleted it, and is unset for messages the customer deleted on the
+// platform. Propagate asks for the message to be deleted on the platform
+// too.
+type MessageDeletedPayload struct {
+	MessageID         int64     `json:"message_id"`
+	ConversationID    int64     `json:"conversation_id"`
+	ChannelID         int64     `json:"channel_id"`
+	PlatformMessageID *string   `json:"platform_message_id,omitempty"`
+	Direction         string    `json:"direction" enum:"inbound,outbound"`
+	DeletedBy         *string   `json:"deleted_by,omitempty"`
+	Propagate         bool      `json:"propagate"`
+	DeletedAt         time.Time `json:"deleted_at"`
+}
+
+func (MessageDeletedPayload) EventType() string  { return EventMessageDeleted }
+func (MessageDeletedPayload) SchemaVersion() int { return 1 }
+
+// ReactionAddedPayload reports a reaction to a message. SenderID is the
+// platform user ID of a customer or the user ID of an agent. Propagate asks
+// for an agent's reaction to be made on the platform too.
+type ReactionAddedPayload struct {
+	MessageID         int64     `json:"message_id"`
+	ConversationID    int64     `json:"conversation_id"`
+	ChannelID         int64     `json:"channel_id"`
+	PlatformMessageID *string   `json:"platform_message_id,omitempty"`
+	SenderType        string    `json:"sender_type" enum:"external,internal"`
+	SenderID          string    `json:"sender_id"`
+	Emoji             string    `json:"emoji"`
+	Propagate         bool      `json:"propagate"`
+	ReactedAt         time.Time `json:"reacted_at"`
+}
+
+func (ReactionAddedPayload) EventType() string  { return EventReactionAdded }
+func (ReactionAddedPayload) SchemaVersion() int { return 1 }
+
+// ReactionRemovedPayload reports a reaction taken back, or replaced on a
+// platform that keeps one reaction per sender. Propagate asks for an
+// agent's reaction to be removed on the platform too; it is unset for a
+// replaced reaction, which the platform replaces itself.
+type ReactionRemovedPayload struct {
+	MessageID         int64     `json:"message_id"`
+	ConversationID    int64     `json:"conversation_id"`
+	ChannelID         int64     `json:"channel_id"`
+	PlatformMessageID *string   `json:"platform_message_id,omitempty"`
+	SenderType        string    `json:"sender_type" enum:"external,internal"`
+	SenderID          string    `json:"sender_id"`
+	Emoji             string    `json:"emoji"`
+	Propagate         bool      `json:"propagate"`
+	RemovedAt         time.Time `json:"removed_at"`
+}
+
+func (ReactionRemovedPayload) EventType() string  { return EventReactionRemoved }
+func (ReactionRemovedPayload) SchemaVersion() int { return 1 }
+
+// Conversation events
+
+// ConversationUpdatedPayload carries the fields that changed. PreviousStatus
+// is set with Status, and Reason is the agent's reason for a status change
+// or customer_reply, idle or snooze_expired for automatic ones. SnoozedUntil
+// is set when a conversation is snoozed until a given time.
+type ConversationUpdatedPayload struct {
+	ConversationID int64      `json:"conversation_id"`
+	Status         *string    `json:"status,omitempty" enum:"open,pending,snoozed,resolved,closed"`
+	PreviousStatus *string    `json:"previous_status,omitempty" enum:"open,pending,snoozed,resolved,closed"`
+	Reason         *string    `json:"reason,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+	Priority       *string    `json:"priority,omitempty" enum:"low,normal,high,urgent"`
+	Subject        *string    `json:"subject,omitempty"`
+}
+
+func (ConversationUpdatedPayload) EventType() string  { return EventConversationUpdated }
+func (ConversationUpdatedPayload) SchemaVersion() int { return 4 }
+
+// ConversationWokenPayload reports that a snoozed conversation was reopened,
+// either because its wake-up time passed or because the customer wrote
+type ConversationWokenPayload struct {
+	ConversationID int64      `json:"conversation_id"`
+	Reason         string     `json:"reason" enum:"snooze_expired,customer_reply"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+}
+
+func (ConversationWokenPayload) EventType() string  { return EventConversationWoken }
+func (ConversationWokenPayload) SchemaVersion() int { return 1 }
+
+// ConversationAssignedPayload reports a manual or routed assignment. Strategy
+// is set when the routing engine picked the assignee. AssigneeID is empty
+// when the conversation was queued to a team without an agent.
+type ConversationAssignedPayload struct {
+	ConversationID     int64   `json:"conversation_id"`
+	AssigneeID         string  `json:"assignee_id,omitempty"`
+	TeamID             *int64  `json:"team_id,omitempty"`
+	PreviousAssigneeID *string `json:"previous_assignee_id,omitempty"`
+	Reason             string  `json:"reason" enum:"manual,new_conversation,timeout,agent_offline"`
+	Strategy           *string `json:"strategy,omitempty" enum:"round_robin,least_open,capacity,sticky"`
+}
+
+func (ConversationAssignedPayload) EventType() string  { return EventConversationAssigned }
+func (ConversationAssignedPayload) SchemaVersion() int { return 3 }
+
+// ConversationMergedPayload reports that SourceConversationID was merged
+// into ConversationID
+type ConversationMergedPayload struct {
+	ConversationID       int64 `json:"conversation_id"`
+	SourceConversationID int64 `json:"source_conversation_id"`
+	MessagesMoved        int64 `json:"messages_moved"`
+}
+
+func (ConversationMergedPayload) EventType() string  { return EventConversationMerged }
+func (ConversationMergedPayload) SchemaVersion() int { return 1 }
+
+// ConversationSplitPayload reports that messages FromMessageID to
+// ToMessageID were moved out of SourceConversationID into the new
+// conversation ConversationID
+type ConversationSplitPayload struct {
+	ConversationID       int64 `json:"conversation_id"`
+	SourceConversationID int64 `json:"source_conversation_id"`
+	FromMessageID        int64 `json:"from_message_id"`
+	ToMessageID          int64 `json:"to_message_id"`
+	MessagesMoved        int64 `json:"messages_moved"`
+}
+
+func (ConversationSplitPayload) EventType() string  { return EventConversationSplit }
+func (ConversationSplitPayload) SchemaVersion() int { return 1 }
+
+// ConversationReadPayload reports that an agent's read cursor moved.
+// UnreadCount is what remains unread in the conversation for the agent and
+// TotalUnread across every open conversation assigned to them.
+type ConversationReadPayload struct {
+	ConversationID    int64  `json:"conversation_id"`
+	AgentID           string `json:"agent_id"`
+	LastReadMessageID int64  `json:"last_read_message_id"`
+	UnreadCount       int64  `json:"unread_count"`
+	TotalUnread       int64  `json:"total_unread"`
+}
+
+func (ConversationReadPayload) EventType() string  { return EventConversationRead }
+func (ConversationReadPayload) SchemaVersion() int { return 1 }
+
+// ConversationTagsChangedPayload lists the tag names added to and removed
+// from a conversation
+type ConversationTagsChangedPayload struct {
+	ConversationID int64    `json:"conversation_id"`
+	Added          []string `json:"added,omitempty"`
+	Removed        []string `json:"removed,omitempty"`
+}
+
+func (ConversationTagsChangedPayload) EventType() string  { return EventConversationTagged }
+func (ConversationTagsChangedPayload) SchemaVersion() int { return 1 }
+
+// Participant events
+
+// ParticipantJoinedPayload reports that an external user joined a
+// conversation. Reason is message when they joined by writing in it,
+// platform when the chat reported it and agent when an agent added them.
+type ParticipantJoinedPayload struct {
+	ConversationID int64  `json:"conversation_id"`
+	ChannelID      int64  `json:"channel_id"`
+	ExternalUserID int64  `json:"external_user_id"`
+	Role           string `json:"role" enum:"requester,member,cc"`
+	Reason         string `json:"reason" enum:"message,platform,agent"`
+}
+
+func (ParticipantJoinedPayload) EventType() string  { return EventParticipantJoined }
+func (ParticipantJoinedPayload) SchemaVersion() int { return 1 }
+
+// ParticipantLeftPayload reports that an external user left a
+// conversation, as reported by the chat or because an agent removed them
+type ParticipantLeftPayload struct {
+	ConversationID int64  `json:"conversation_id"`
+	ChannelID      int64  `json:"channel_id"`
+	ExternalUserID int64  `json:"external_user_id"`
+	Reason         string `json:"reason" enum:"platform,agent"`
+}
+
+func (ParticipantLeftPayload) EventType() string  { return EventParticipantLeft }
+func (ParticipantLeftPayload) SchemaVersion() int { return 1 }
+
+// Note events
+
+// NoteMentionPayload notifies an agent, by external ID, that they were
+// mentioned in an internal note. AuthorID is the note's author if known.
+type NoteMentionPayload struct {
+	NoteID         int64   `json:"note_id"`
+	ConversationID int64   `json:"conversation_id"`
+	ChannelID      int64   `json:"channel_id"`
+	AgentID        string  `json:"agent_id"`
+	AuthorID       *string `json:"author_id,omitempty"`
+	Content        string  `json:"content"`
+}
+
+func (NoteMentionPayload) EventType() string  { return EventNoteMention }
+func (NoteMentionPayload) SchemaVersion() int { return 1 }
+
+// TranscriptEmailPayload asks the email channel ChannelID to send a
+// rendered conversation transcript to To
+type TranscriptEmailPayload struct {
+	ConversationID int64  `json:"conversation_id"`
+	ChannelID      int64  `json:"channel_id"`
+	To             string `json:"to"`
+	Subject        string `json:"subject"`
+	ContentType    string `json:"content_type"`
+	Body           string `json:"body"`
+}
+
+func (TranscriptEmailPayload) EventType() string  { return EventTranscriptEmail }
+func (TranscriptEmailPayload) SchemaVersion() int { return 1 }
+
+// CSAT events
+
+// CSATSentPayload reports a satisfaction survey sent for a resolved
+// conversation. AgentID is the assignee it rates.
+type CSATSentPayload struct {
+	SurveyID       int64   `json:"survey_id"`
+	ConversationID int64   `json:"conversation_id"`
+	ChannelID      int64   `json:"channel_id"`
+	MessageID      int64   `json:"message_id"`
+	AgentID        *string `json:"agent_id,omitempty"`
+	TeamID         *int64  `json:"team_id,omitempty"`
+}
+
+func (CSATSentPayload) EventType() string  { return EventCSATSent }
+func (CSATSentPayload) SchemaVersion() int { return 1 }
+
+// CSATRespondedPayload is sent when the customer rates a survey and again
+// when they add a comment
+type CSATRespondedPayload struct {
+	SurveyID       int64   `json:"survey_id"`
+	ConversationID int64   `json:"conversation_id"`
+	AgentID        *string `json:"agent_id,omitempty"`
+	TeamID         *int64  `json:"team_id,omitempty"`
+	Status         string  `json:"status" enum:"awaiting_comment,completed"`
+	Rating         int     `json:"rating"`
+	Comment        *string `json:"comment,omitempty"`
+}
+
+func (CSATRespondedPayload) EventType() string  { return EventCSATResponded }
+func (CSATRespondedPayload) SchemaVersion() int { return 1 }
+
+// External user events
+
+type UserBlockedPayload struct {
+	ExternalUserID int64 `json:"external_user_id"`
+}
+
+func (UserBlockedPayload) EventType() string  { return EventUserBlocked }
+func (UserBlockedPayload) SchemaVersion() int { return 1 }
+
+type UserUnblockedPayload struct {
+	ExternalUserID int64 `json:"external_user_id"`
+}
+
+func (UserUnblockedPayload) EventType() string  { return EventUserUnblocked }
+func (UserUnblockedPayload) SchemaVersion() int { return 1 }
+
+// SLA events
+
+// SLAWarningPayload is sent once per target and due time when the due time
+// is within the policy's warning window
+type SLAWarningPayload struct {
+	ConversationID int64     `json:"conversation_id"`
+	PolicyID       int64     `json:"policy_id"`
+	Target         string    `json:"target" enum:"first_response,next_response,resolution"`
+	DueAt          time.Time `json:"due_at"`
+}
+
+func (SLAWarningPayload) EventType() string  { return EventSLAWarning }
+func (SLAWarningPayload) SchemaVersion() int { return 1 }
+
+// SLABreachedPayload is sent once per target and due time when the due
+// time passes without the target being met
+type SLABreachedPayload struct {
+	ConversationID int64     `json:"conversation_id"`
+	PolicyID       int64     `json:"policy_id"`
+	Target         string    `json:"target" enum:"first_response,next_response,resolution"`
+	DueAt          time.Time `json:"due_at"`
+}
+
+func (SLABreachedPayload) EventType() string  { return EventSLABreached }
+func (SLABreachedPayload) SchemaVersion() int { return 1 }
+
+// Command bus events
+
+// CommandResultPayload is the reply to a command, correlated by command_id
+type CommandResultPayload struct {
+	CommandID string                 `json:"command_id"`
+	Name      string                 `json:"name"`
+	Status    string                 `json:"status" enum:"succeeded,failed"`
+	Error     *string                `json:"error,omitempty"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+}
+
+func (CommandResultPayload) EventType() string  { return EventCommandResult }
+func (CommandResultPayload) SchemaVersion() int { return 1 }
+
+// registry lists every payload the service can emit. Catalog and the
+// compatibility test are both driven from it.
+var registry = []Payload{
+	OrganizationCreatedPayload{},
+	OrganizationUpdatedPayload{},
+	OrganizationDeletedPayload{},
+	ChannelCreatedPayload{},
+	ChannelUpdatedPayload{},
+	ChannelDeletedPayload{},
+	MessageNewPayload{},
+	MessageDeliveredPayload{},
+	MessageReadPayload{},
+	MessageUpdatedPayload{},
+	MessageDeletedPayload{},
+	ReactionAddedPayload{},
+	ReactionRemovedPayload{},
+	ConversationUpdatedPayload{},
+	ConversationAssignedPayload{},
+	ConversationMergedPayload{},
+	ConversationSplitPayload{},
+	ConversationWokenPayload{},
+	ConversationReadPayload{},
+	ConversationTagsChangedPayload{},
+	ParticipantJoinedPayload{},
+	ParticipantLeftPayload{},
+	NoteMentionPayload{},
+	TranscriptEmailPayload{},
+	CSATSentPayload{},
+	CSATRespondedPayload{},
+	UserBlockedPayload{},
+	UserUnblockedPayload{},
+	CommandResultPayload{},
+	SLAWarningPayload{},
+	SLABreachedPayload{},
+}
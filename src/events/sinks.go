@@ -0,0 +1,118 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// logEmitter appends every event as a JSON line to a local audit file
+type logEmitter struct {
+	mu     sync.Mutex
+	file   *os.File
+	source string
+}
+
+// NewLogEmitter creates an emitter that appends events to the file at path
+func NewLogEmitter(path string) (Emitter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &logEmitter{file: file, source: Source}, nil
+}
+
+func (e *logEmitter) Emit(eventType string, payload map[string]interface{}) error {
+	return e.EmitWithMetadata(eventType, payload, nil)
+}
+
+func (e *logEmitter) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	data, err := json.Marshal(newEvent(e.source, eventType, payload, metadata))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (e *logEmitter) Close() error {
+	return e.file.Close()
+}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when an
+// HTTP subscriber is configured with a secret
+const SignatureHeader = "X-Event-Signature"
+
+// httpEmitter POSTs each event as JSON to a subscriber URL
+type httpEmitter struct {
+	url    string
+	secret string
+	client *http.Client
+	source string
+}
+
+// NewHTTPEmitter creates an emitter that delivers events to an HTTP subscriber
+func NewHTTPEmitter(url, secret string) Emitter {
+	return &httpEmitter{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 5 * time.Second},
+		source: Source,
+	}
+}
+
+func (e *httpEmitter) Emit(eventType string, payload map[string]interface{}) error {
+	return e.EmitWithMetadata(eventType, payload, nil)
+}
+
+func (e *httpEmitter) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	data, err := json.Marshal(newEvent(e.source, eventType, payload, metadata))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build subscriber request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.secret != "" {
+		mac := hmac.New(sha256.New, []byte(e.secret))
+		mac.Write(data)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event to %s: %w", e.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("subscriber %s responded with %d", e.url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *httpEmitter) Close() error {
+	return nil
+}
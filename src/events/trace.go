@@ -0,0 +1,58 @@
+package events
+
+import "context"
+
+// Metadata keys linking an event to the request that caused it
+const (
+	MetadataCorrelationID = "correlation_id"
+	MetadataCausationID   = "causation_id"
+)
+
+// Trace links emitted events to their origin. CorrelationID is shared by
+// everything that stems from one inbound request, webhook or command;
+// CausationID is the ID of the immediate cause.
+type Trace struct {
+	CorrelationID string
+	CausationID   string
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context carrying the trace
+func WithTrace(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFrom returns the trace stored in ctx, if any
+func TraceFrom(ctx context.Context) Trace {
+	if trace, ok := ctx.Value(traceKey{}).(Trace); ok {
+		return trace
+	}
+	return Trace{}
+}
+
+// WithCause returns a context whose events keep the current correlation ID
+// but name causationID as their immediate cause
+func WithCause(ctx context.Context, causationID string) context.Context {
+	trace := TraceFrom(ctx)
+	if trace.CorrelationID == "" {
+		trace.CorrelationID = causationID
+	}
+	trace.CausationID = causationID
+	return WithTrace(ctx, trace)
+}
+
+// Metadata returns the trace as event metadata
+func (t Trace) Metadata() map[string]string {
+	if t.CorrelationID == "" && t.CausationID == "" {
+		return nil
+	}
+	metadata := make(map[string]string, 2)
+	if t.CorrelationID != "" {
+		metadata[MetadataCorrelationID] = t.CorrelationID
+	}
+	if t.CausationID != "" {
+		metadata[MetadataCausationID] = t.CausationID
+	}
+	return metadata
+}
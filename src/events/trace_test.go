@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace_WithCause(t *testing.T) {
+	ctx := WithTrace(context.Background(), Trace{CorrelationID: "req-1", CausationID: "req-1"})
+	ctx = WithCause(ctx, "webhook_event:7")
+
+	trace := TraceFrom(ctx)
+	assert.Equal(t, "req-1", trace.CorrelationID)
+	assert.Equal(t, "webhook_event:7", trace.CausationID)
+}
+
+func TestTrace_WithCauseStartsCorrelation(t *testing.T) {
+	trace := TraceFrom(WithCause(context.Background(), "cmd-1"))
+
+	assert.Equal(t, "cmd-1", trace.CorrelationID)
+	assert.Equal(t, "cmd-1", trace.CausationID)
+}
+
+func TestTrace_Metadata(t *testing.T) {
+	assert.Nil(t, TraceFrom(context.Background()).Metadata())
+
+	metadata := Trace{CorrelationID: "a", CausationID: "b"}.Metadata()
+	assert.Equal(t, map[string]string{
+		MetadataCorrelationID: "a",
+		MetadataCausationID:   "b",
+	}, metadata)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// AgentHandler handles agent HTTP requests
+type AgentHandler struct {
+	service   services.AgentService
+	validator *validator.Validate
+}
+
+func NewAgentHandler(service services.AgentService) *AgentHandler {
+	return &AgentHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/agents
+func (h *AgentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	agent, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, agent)
+}
+
+// GetByID handles GET /api/v1/agents/{id}
+func (h *AgentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid agent ID")
+		return
+	}
+
+	agent, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "agent not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, agent)
+}
+
+// ListByOrganization handles GET /api/v1/organizations/{orgId}/agents
+func (h *AgentHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	agents, err := h.service.ListByOrganization(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   agents,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/agents/{id}
+func (h *AgentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid agent ID")
+		return
+	}
+
+	var req models.UpdateAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "agent updated successfully",
+	})
+}
+
+// UpdateStatus handles PATCH /api/v1/agents/{id}/status
+func (h *AgentHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid agent ID")
+		return
+	}
+
+	var req models.UpdateAgentStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.UpdateStatus(r.Context(), id, req.Status); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "agent status updated successfully",
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// BusinessHoursHandler handles business hours HTTP requests
+type BusinessHoursHandler struct {
+	service   services.BusinessHoursService
+	validator *validator.Validate
+}
+
+func NewBusinessHoursHandler(service services.BusinessHoursService) *BusinessHoursHandler {
+	return &BusinessHoursHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetForOrganization handles GET /api/v1/organizations/{orgId}/business-hours
+func (h *BusinessHoursHandler) GetForOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	hours, err := h.service.GetForOrganization(r.Context(), orgID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "business hours not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, hours)
+}
+
+// SetForOrganization handles PUT /api/v1/organizations/{orgId}/business-hours
+func (h *BusinessHoursHandler) SetForOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	req, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	hours, err := h.service.SetForOrganization(r.Context(), orgID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, hours)
+}
+
+// GetForChannel handles GET /api/v1/channels/{id}/business-hours
+func (h *BusinessHoursHandler) GetForChannel(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	hours, err := h.service.GetForChannel(r.Context(), channelID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "business hours not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, hours)
+}
+
+// SetForChannel handles PUT /api/v1/channels/{id}/business-hours
+func (h *BusinessHoursHandler) SetForChannel(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	req, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	hours, err := h.service.SetForChannel(r.Context(), channelID, req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, hours)
+}
+
+// StatusForOrganization handles GET /api/v1/organizations/{orgId}/business-hours/status
+func (h *BusinessHoursHandler) StatusForOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	at, ok := statusTime(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.StatusForOrganization(r.Context(), orgID, at)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, status)
+}
+
+// StatusForChannel handles GET /api/v1/channels/{id}/business-hours/status
+func (h *BusinessHoursHandler) StatusForChannel(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	at, ok := statusTime(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.StatusForChannel(r.Context(), channelID, at)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "channel not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, status)
+}
+
+// statusTime reads the optional at query parameter, defaulting to now
+func statusTime(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	v := r.URL.Query().Get("at")
+	if v == "" {
+		return time.Now(), true
+	}
+	at, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid at")
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+func (h *BusinessHoursHandler) decode(w http.ResponseWriter, r *http.Request) (*models.SetBusinessHoursRequest, bool) {
+	var req models.SetBusinessHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return nil, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+
+	if err := models.ValidateBusinessHoursWindows(req.Windows); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
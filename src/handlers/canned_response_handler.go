@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// CannedResponseHandler handles canned response HTTP requests
+type CannedResponseHandler struct {
+	service   services.CannedResponseService
+	validator *validator.Validate
+}
+
+func NewCannedResponseHandler(service services.CannedResponseService) *CannedResponseHandler {
+	return &CannedResponseHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/canned-responses
+func (h *CannedResponseHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCannedResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	response, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, response)
+}
+
+// GetByID handles GET /api/v1/canned-responses/{id}
+func (h *CannedResponseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid canned response ID")
+		return
+	}
+
+	response, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "canned response not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// List handles GET /api/v1/organizations/{orgId}/canned-responses. With
+// team_id, only responses of the whole organization and of that team are
+// listed; q searches shortcuts, titles and content.
+func (h *CannedResponseHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := r.URL.Query()
+	teamID, err := parseTeamID(query)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid team_id")
+		return
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	responses, err := h.service.List(r.Context(), &models.CannedResponseQuery{
+		OrganizationID: orgID,
+		TeamID:         teamID,
+		Search:         query.Get("q"),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   responses,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/canned-responses/{id}
+func (h *CannedResponseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid canned response ID")
+		return
+	}
+
+	var req models.UpdateCannedResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "canned response updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/v1/canned-responses/{id}
+func (h *CannedResponseHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid canned response ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Render handles POST /api/v1/canned-responses/{id}/render, returning the
+// content with its placeholders filled in for a conversation
+func (h *CannedResponseHandler) Render(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid canned response ID")
+		return
+	}
+
+	var req models.RenderCannedResponseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	content, err := h.service.Render(r.Context(), id, req.ConversationID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"content": content,
+	})
+}
+
+func respondTeamError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrUnknownTeam) {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
+
+// parseTeamID reads the optional team_id of a list query
+func parseTeamID(query url.Values) (*int64, error) {
+	v := query.Get("team_id")
+	if v == "" {
+		return nil, nil
+	}
+	teamID, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &teamID, nil
+}
@@ -39,7 +39,7 @@ func (h *ChannelHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	channel, err := h.service.Create(&req)
+	channel, err := h.service.Create(r.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -56,7 +56,7 @@ func (h *ChannelHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	channel, err := h.service.GetByID(id)
+	channel, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "channel not found")
 		return
@@ -76,7 +76,7 @@ func (h *ChannelHandler) ListByOrganization(w http.ResponseWriter, r *http.Reque
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	channels, err := h.service.ListByOrganization(orgID, limit, offset)
+	channels, err := h.service.ListByOrganization(r.Context(), orgID, limit, offset)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -108,7 +108,7 @@ func (h *ChannelHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Update(id, &req); err != nil {
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -138,7 +138,7 @@ func (h *ChannelHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.UpdateStatus(id, req.Status); err != nil {
+	if err := h.service.UpdateStatus(r.Context(), id, req.Status); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -154,7 +154,7 @@ func (h *ChannelHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
+	if err := h.service.Delete(r.Context(), id); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
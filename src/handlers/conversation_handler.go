@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
@@ -34,7 +38,7 @@ func (h *ConversationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	conversation, err := h.service.GetByID(id)
+	conversation, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "conversation not found")
 		return
@@ -61,9 +65,23 @@ func (h *ConversationHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	conversations, err := h.service.ListByChannel(channelID, status, limit, offset)
+	tags := splitList(r.URL.Query().Get("tag"))
+
+	attrs, err := parseAttributeQuery(r.URL.Query(), true)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if attrs != nil {
+		if err := h.validator.Struct(attrs); err != nil {
+			utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+
+	conversations, err := h.service.ListByChannel(r.Context(), channelID, status, tags, attrs, limit, offset)
+	if err != nil {
+		respondAttributeError(w, err)
 		return
 	}
 
@@ -82,22 +100,19 @@ func (h *ConversationHandler) Assign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		AssigneeID string `json:"assignee_id" validate:"required"`
-	}
-
+	var req models.AssignConversationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if req.AssigneeID == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "assignee_id is required")
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
-	if err := h.service.Assign(id, req.AssigneeID); err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Assign(r.Context(), id, &req); err != nil {
+		respondTeamError(w, err)
 		return
 	}
 
@@ -114,21 +129,26 @@ func (h *ConversationHandler) UpdateStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var req struct {
-		Status models.ConversationStatus `json:"status" validate:"required"`
-	}
-
+	var req models.UpdateConversationStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if req.Status == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "status is required")
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
-	if err := h.service.UpdateStatus(id, req.Status); err != nil {
+	if err := h.service.UpdateStatus(r.Context(), id, &req); err != nil {
+		if errors.Is(err, models.ErrInvalidTransition) {
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrInvalidSnooze) {
+			utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -146,26 +166,262 @@ func (h *ConversationHandler) UpdatePriority(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var req struct {
-		Priority models.ConversationPriority `json:"priority" validate:"required"`
+	var req models.UpdateConversationPriorityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.UpdatePriority(r.Context(), id, req.Priority); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "conversation priority updated successfully",
+	})
+}
+
+// UpdateSubject handles PATCH /api/v1/conversations/{id}/subject
+func (h *ConversationHandler) UpdateSubject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
 	}
 
+	var req models.UpdateConversationSubjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if req.Priority == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "priority is required")
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
-	if err := h.service.UpdatePriority(id, req.Priority); err != nil {
+	if err := h.service.UpdateSubject(r.Context(), id, req.Subject); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	utils.JSONResponse(w, http.StatusOK, map[string]string{
-		"message": "conversation priority updated successfully",
+		"message": "conversation subject updated successfully",
+	})
+}
+
+// Timeline handles GET /api/v1/conversations/{id}/timeline
+func (h *ConversationHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	includeMessages, _ := strconv.ParseBool(r.URL.Query().Get("include_messages"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	timeline, err := h.service.Timeline(r.Context(), id, includeMessages, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   timeline,
+		"limit":  limit,
+		"offset": offset,
 	})
 }
+
+// Merge handles POST /api/v1/conversations/{id}/merge
+func (h *ConversationHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	var req models.MergeConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	conversation, err := h.service.Merge(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidMerge) {
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, conversation)
+}
+
+// Split handles POST /api/v1/conversations/{id}/split
+func (h *ConversationHandler) Split(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	var req models.SplitConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	conversation, err := h.service.Split(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidMerge) {
+			utils.ErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, conversation)
+}
+
+// Inbox handles GET /api/v1/inbox
+func (h *ConversationHandler) Inbox(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(middleware.GetOrganizationID(r), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusForbidden, "organization required")
+		return
+	}
+
+	query := r.URL.Query()
+	q := &models.InboxQuery{
+		OrganizationID: orgID,
+		Assignee:       query.Get("assignee"),
+		Tags:           splitList(query.Get("tag")),
+		Sort:           models.InboxSort(query.Get("sort")),
+		Cursor:         query.Get("cursor"),
+		Reader:         middleware.GetUserID(r),
+	}
+	for _, s := range splitList(query.Get("status")) {
+		q.Statuses = append(q.Statuses, models.ConversationStatus(s))
+	}
+	for _, p := range splitList(query.Get("priority")) {
+		q.Priorities = append(q.Priorities, models.ConversationPriority(p))
+	}
+	for _, p := range splitList(query.Get("platform")) {
+		q.Platforms = append(q.Platforms, models.Platform(p))
+	}
+	for _, s := range splitList(query.Get("sla_status")) {
+		q.SLAStatuses = append(q.SLAStatuses, models.SLAStatus(s))
+	}
+	q.Limit, _ = strconv.Atoi(query.Get("limit"))
+
+	if q.Assignee == "mine" {
+		q.Assignee = middleware.GetUserID(r)
+		if q.Assignee == "" {
+			utils.ErrorResponse(w, http.StatusBadRequest, "assignee=mine requires a user token")
+			return
+		}
+	}
+
+	switch team := query.Get("team"); team {
+	case "":
+	case models.InboxTeamNone:
+		q.NoTeam = true
+	case "mine":
+		q.TeamMember = middleware.GetUserID(r)
+		if q.TeamMember == "" {
+			utils.ErrorResponse(w, http.StatusBadRequest, "team=mine requires a user token")
+			return
+		}
+	default:
+		for _, v := range splitList(team) {
+			teamID, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "invalid team")
+				return
+			}
+			q.Teams = append(q.Teams, teamID)
+		}
+	}
+
+	for param, dst := range map[string]**time.Time{
+		"created_after":       &q.CreatedAfter,
+		"created_before":      &q.CreatedBefore,
+		"last_message_after":  &q.LastMessageAfter,
+		"last_message_before": &q.LastMessageBefore,
+	} {
+		if v := query.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "invalid "+param)
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	if attrs, _ := parseAttributeQuery(query, false); attrs != nil {
+		q.Attributes = attrs.Filters
+	}
+
+	if v := query.Get("has_unread"); v != "" {
+		hasUnread, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid has_unread")
+			return
+		}
+		q.HasUnread = &hasUnread
+	}
+
+	if err := h.validator.Struct(q); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	page, err := h.service.Inbox(r.Context(), q)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, page)
+}
+
+// splitList parses a comma-separated query parameter
+func splitList(v string) []string {
+	if v == "" {
+		return nil
+	}
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
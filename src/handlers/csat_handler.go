@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// CSATHandler handles satisfaction survey HTTP requests
+type CSATHandler struct {
+	service   services.CSATService
+	validator *validator.Validate
+}
+
+func NewCSATHandler(service services.CSATService) *CSATHandler {
+	return &CSATHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetPolicy handles GET /api/v1/channels/{id}/csat
+func (h *CSATHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), channelID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "CSAT policy not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, policy)
+}
+
+// SetPolicy handles PUT /api/v1/channels/{id}/csat
+func (h *CSATHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	var req models.UpsertCSATPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	policy, err := h.service.SetPolicy(r.Context(), channelID, &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, policy)
+}
+
+// ConversationSurveys handles GET /api/v1/conversations/{id}/csat
+func (h *CSATHandler) ConversationSurveys(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	surveys, err := h.service.ListByConversation(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": surveys,
+	})
+}
+
+// Report handles GET /api/v1/organizations/{orgId}/csat/report
+func (h *CSATHandler) Report(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := r.URL.Query()
+	q := &models.CSATReportQuery{OrganizationID: orgID}
+	if v := query.Get("channel_id"); v != "" {
+		channelID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel_id")
+			return
+		}
+		q.ChannelID = &channelID
+	}
+	if v := query.Get("agent_id"); v != "" {
+		q.AgentID = &v
+	}
+
+	for param, dst := range map[string]**time.Time{
+		"sent_after":  &q.SentAfter,
+		"sent_before": &q.SentBefore,
+	} {
+		if v := query.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "invalid "+param)
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	if err := h.validator.Struct(q); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), q)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, report)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// attributeParamPrefix prefixes custom attribute filters in list queries,
+// as in attr.plan=pro,enterprise or attr.seats.gte=10
+const attributeParamPrefix = "attr."
+
+// CustomAttributeHandler handles custom attribute definition and value
+// HTTP requests
+type CustomAttributeHandler struct {
+	service   services.CustomAttributeService
+	validator *validator.Validate
+}
+
+func NewCustomAttributeHandler(service services.CustomAttributeService) *CustomAttributeHandler {
+	return &CustomAttributeHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/custom-attributes
+func (h *CustomAttributeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCustomAttributeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	def, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, def)
+}
+
+// GetByID handles GET /api/v1/custom-attributes/{id}
+func (h *CustomAttributeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid custom attribute ID")
+		return
+	}
+
+	def, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "custom attribute not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, def)
+}
+
+// ListByOrganization handles GET /api/v1/organizations/{orgId}/custom-attributes
+func (h *CustomAttributeHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var entity *models.CustomAttributeEntity
+	if v := r.URL.Query().Get("entity"); v != "" {
+		e := models.CustomAttributeEntity(v)
+		entity = &e
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	defs, err := h.service.ListByOrganization(r.Context(), orgID, entity, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   defs,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/custom-attributes/{id}
+func (h *CustomAttributeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid custom attribute ID")
+		return
+	}
+
+	var req models.UpdateCustomAttributeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "custom attribute updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/v1/custom-attributes/{id}, removing the
+// attribute's values from every record
+func (h *CustomAttributeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid custom attribute ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetConversationAttributes handles PATCH /api/v1/conversations/{id}/attributes
+func (h *CustomAttributeHandler) SetConversationAttributes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	req, ok := h.decodeValues(w, r)
+	if !ok {
+		return
+	}
+
+	conversation, err := h.service.SetConversationAttributes(r.Context(), id, req.Attributes)
+	if err != nil {
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, conversation)
+}
+
+// SetContactAttributes handles PATCH /api/v1/external-users/{id}/attributes
+func (h *CustomAttributeHandler) SetContactAttributes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	req, ok := h.decodeValues(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.SetContactAttributes(r.Context(), id, req.Attributes)
+	if err != nil {
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, user)
+}
+
+func (h *CustomAttributeHandler) decodeValues(w http.ResponseWriter, r *http.Request) (*models.SetCustomAttributesRequest, bool) {
+	var req models.SetCustomAttributesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return nil, false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+	return &req, true
+}
+
+func respondAttributeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrInvalidAttribute) {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
+
+// parseAttributeQuery reads attr.<key>[.<op>] filters and, when sortable,
+// a sort=attr.<key> or sort=-attr.<key> order from a list query. It
+// returns nil if there are neither.
+func parseAttributeQuery(query url.Values, sortable bool) (*models.AttributeQuery, error) {
+	q := &models.AttributeQuery{}
+	for param, values := range query {
+		if !strings.HasPrefix(param, attributeParamPrefix) {
+			continue
+		}
+
+		key, op, _ := strings.Cut(strings.TrimPrefix(param, attributeParamPrefix), ".")
+		filter := models.AttributeFilter{Key: key, Op: models.AttributeOpEq}
+		if op != "" {
+			filter.Op = models.AttributeOp(op)
+		}
+		for _, v := range values {
+			filter.Values = append(filter.Values, splitList(v)...)
+		}
+		q.Filters = append(q.Filters, filter)
+	}
+
+	if sort := query.Get("sort"); sortable && sort != "" {
+		if strings.HasPrefix(sort, "-") {
+			q.SortDesc = true
+			sort = sort[1:]
+		}
+		if !strings.HasPrefix(sort, attributeParamPrefix) {
+			return nil, fmt.Errorf("invalid sort")
+		}
+		q.SortKey = strings.TrimPrefix(sort, attributeParamPrefix)
+	}
+
+	if len(q.Filters) == 0 && q.SortKey == "" {
+		return nil, nil
+	}
+	return q, nil
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// EventHandler exposes the published event catalog
+type EventHandler struct{}
+
+func NewEventHandler() *EventHandler {
+	return &EventHandler{}
+}
+
+// Catalog handles GET /api/v1/events/catalog
+func (h *EventHandler) Catalog(w http.ResponseWriter, r *http.Request) {
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": events.Catalog(),
+	})
+}
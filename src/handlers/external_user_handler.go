@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// ExternalUserHandler handles external user (contact) HTTP requests
+type ExternalUserHandler struct {
+	service   services.ExternalUserService
+	validator *validator.Validate
+}
+
+func NewExternalUserHandler(service services.ExternalUserService) *ExternalUserHandler {
+	return &ExternalUserHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetByID handles GET /api/v1/external-users/{id}
+func (h *ExternalUserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	user, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, user)
+}
+
+// List handles GET /api/v1/organizations/{orgId}/external-users, filtered
+// by channel_id and custom attributes
+func (h *ExternalUserHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	q := &models.ExternalUserQuery{OrganizationID: orgID, Limit: limit, Offset: offset}
+
+	if v := query.Get("channel_id"); v != "" {
+		channelID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+			return
+		}
+		q.ChannelID = &channelID
+	}
+
+	if q.Attributes, err = parseAttributeQuery(query, true); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(q); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	users, err := h.service.List(r.Context(), q)
+	if err != nil {
+		respondAttributeError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   users,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Block handles POST /api/v1/external-users/{id}/block
+func (h *ExternalUserHandler) Block(w http.ResponseWriter, r *http.Request) {
+	h.setBlocked(w, r, true)
+}
+
+// Unblock handles POST /api/v1/external-users/{id}/unblock
+func (h *ExternalUserHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	h.setBlocked(w, r, false)
+}
+
+func (h *ExternalUserHandler) setBlocked(w http.ResponseWriter, r *http.Request, blocked bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.service.SetBlocked(r.Context(), id, blocked); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]bool{"blocked": blocked})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// LifecycleHandler handles conversation lifecycle policy HTTP requests
+type LifecycleHandler struct {
+	service   services.LifecycleService
+	validator *validator.Validate
+}
+
+func NewLifecycleHandler(service services.LifecycleService) *LifecycleHandler {
+	return &LifecycleHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetPolicy handles GET /api/v1/channels/{id}/lifecycle
+func (h *LifecycleHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), channelID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "lifecycle policy not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, policy)
+}
+
+// SetPolicy handles PUT /api/v1/channels/{id}/lifecycle
+func (h *LifecycleHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	var req models.UpsertLifecyclePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	policy, err := h.service.SetPolicy(r.Context(), channelID, &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, policy)
+}
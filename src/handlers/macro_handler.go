@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// MacroHandler handles macro HTTP requests
+type MacroHandler struct {
+	service   services.MacroService
+	validator *validator.Validate
+}
+
+func NewMacroHandler(service services.MacroService) *MacroHandler {
+	return &MacroHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/macros
+func (h *MacroHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMacroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	macro, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		respondMacroError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, macro)
+}
+
+// GetByID handles GET /api/v1/macros/{id}
+func (h *MacroHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid macro ID")
+		return
+	}
+
+	macro, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "macro not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, macro)
+}
+
+// ListByOrganization handles GET /api/v1/organizations/{orgId}/macros.
+// With team_id, only macros of the whole organization and of that team
+// are listed.
+func (h *MacroHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := r.URL.Query()
+	teamID, err := parseTeamID(query)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid team_id")
+		return
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	macros, err := h.service.ListByOrganization(r.Context(), orgID, teamID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   macros,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/macros/{id}
+func (h *MacroHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid macro ID")
+		return
+	}
+
+	var req models.UpdateMacroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
+		respondMacroError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "macro updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/v1/macros/{id}
+func (h *MacroHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid macro ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Run handles POST /api/v1/conversations/{id}/macros/{macroId}/run
+func (h *MacroHandler) Run(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+	macroID, err := strconv.ParseInt(chi.URLParam(r, "macroId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid macro ID")
+		return
+	}
+
+	run, err := h.service.Run(r.Context(), conversationID, macroID)
+	if err != nil {
+		respondMacroError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, run)
+}
+
+// ListRuns handles GET /api/v1/macros/{id}/runs
+func (h *MacroHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid macro ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	runs, err := h.service.ListRuns(r.Context(), id, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   runs,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func respondMacroError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidTransition):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrInvalidMacro),
+		errors.Is(err, models.ErrUnknownTag),
+		errors.Is(err, models.ErrUnknownTeam):
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
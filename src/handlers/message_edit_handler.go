@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// MessageEditHandler handles message edit and delete HTTP requests
+type MessageEditHandler struct {
+	service   services.MessageEditService
+	validator *validator.Validate
+}
+
+func NewMessageEditHandler(service services.MessageEditService) *MessageEditHandler {
+	return &MessageEditHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Edit handles PATCH /api/v1/messages/{id}
+func (h *MessageEditHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	var req models.EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	msg, err := h.service.Edit(r.Context(), id, &req)
+	if err != nil {
+		respondMessageEditError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, msg)
+}
+
+// Delete handles DELETE /api/v1/messages/{id}
+func (h *MessageEditHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		respondMessageEditError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListEdits handles GET /api/v1/messages/{id}/edits
+func (h *MessageEditHandler) ListEdits(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	edits, err := h.service.ListEdits(r.Context(), id)
+	if err != nil {
+		respondMessageEditError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": edits,
+	})
+}
+
+func respondMessageEditError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrMessageNotEditable) {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -34,12 +35,7 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		Content     string             `json:"content" validate:"required"`
-		MessageType models.MessageType `json:"message_type"`
-		MediaURL    *string            `json:"media_url,omitempty"`
-		Metadata    *string            `json:"metadata,omitempty"`
-	}
+	var req models.SendMessageRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
@@ -51,19 +47,20 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.MessageType == "" {
-		req.MessageType = models.MessageTypeText
-	}
-
-	message, err := h.service.SendOutgoingMessage(&services.SendOutgoingMessageRequest{
+	message, err := h.service.SendOutgoingMessage(r.Context(), &services.SendOutgoingMessageRequest{
 		ConversationID: conversationID,
 		Content:        req.Content,
 		MessageType:    req.MessageType,
 		MediaURL:       req.MediaURL,
 		Metadata:       req.Metadata,
+		ReplyTo:        req.ReplyTo,
 	})
 
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidReply) {
+			utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -82,6 +79,7 @@ func (h *MessageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 	beforeStr := r.URL.Query().Get("before")
+	hideSystem, _ := strconv.ParseBool(r.URL.Query().Get("hide_system"))
 
 	var before *int64
 	if beforeStr != "" {
@@ -91,7 +89,13 @@ func (h *MessageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	messages, err := h.service.GetMessageHistory(conversationID, limit, offset, before)
+	messages, err := h.service.GetMessageHistory(r.Context(), &models.MessageListQuery{
+		ConversationID: conversationID,
+		Limit:          limit,
+		Offset:         offset,
+		Before:         before,
+		HideSystem:     hideSystem,
+	})
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -112,7 +116,7 @@ func (h *MessageHandler) MarkDelivered(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.MarkDelivered(messageID); err != nil {
+	if err := h.service.MarkDelivered(r.Context(), messageID); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -130,7 +134,7 @@ func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.MarkRead(messageID); err != nil {
+	if err := h.service.MarkRead(r.Context(), messageID); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
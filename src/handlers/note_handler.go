@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// NoteHandler handles internal note HTTP requests
+type NoteHandler struct {
+	service   services.NoteService
+	validator *validator.Validate
+}
+
+func NewNoteHandler(service services.NoteService) *NoteHandler {
+	return &NoteHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/conversations/{id}/notes
+func (h *NoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "user required")
+		return
+	}
+
+	var req models.CreateNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	note, err := h.service.Create(r.Context(), conversationID, &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, note)
+}
+
+// Update handles PATCH /api/v1/notes/{id}
+func (h *NoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid note ID")
+		return
+	}
+
+	var req models.UpdateNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	note, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrNotNoteAuthor) {
+			utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, note)
+}
+
+// Delete handles DELETE /api/v1/notes/{id}
+func (h *NoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid note ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrNotNoteAuthor) {
+			utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "note deleted successfully",
+	})
+}
+
+// ListEdits handles GET /api/v1/notes/{id}/edits
+func (h *NoteHandler) ListEdits(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid note ID")
+		return
+	}
+
+	edits, err := h.service.ListEdits(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "note not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": edits,
+	})
+}
@@ -39,7 +39,7 @@ func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	org, err := h.service.Create(&req)
+	org, err := h.service.Create(r.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -56,7 +56,7 @@ func (h *OrganizationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	org, err := h.service.GetByID(id)
+	org, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "organization not found")
 		return
@@ -73,7 +73,7 @@ func (h *OrganizationHandler) GetBySlug(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	org, err := h.service.GetBySlug(slug)
+	org, err := h.service.GetBySlug(r.Context(), slug)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "organization not found")
 		return
@@ -87,7 +87,7 @@ func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	orgs, err := h.service.List(limit, offset)
+	orgs, err := h.service.List(r.Context(), limit, offset)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -119,7 +119,7 @@ func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Update(id, &req); err != nil {
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -137,7 +137,7 @@ func (h *OrganizationHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
+	if err := h.service.Delete(r.Context(), id); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// ParticipantHandler handles conversation participant HTTP requests
+type ParticipantHandler struct {
+	service   services.ParticipantService
+	validator *validator.Validate
+}
+
+func NewParticipantHandler(service services.ParticipantService) *ParticipantHandler {
+	return &ParticipantHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// List handles GET /api/v1/conversations/{id}/participants
+func (h *ParticipantHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	participants, err := h.service.List(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": participants,
+	})
+}
+
+// Add handles POST /api/v1/conversations/{id}/participants
+func (h *ParticipantHandler) Add(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	var req models.AddParticipantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	participant, err := h.service.Add(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidParticipant) {
+			utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, participant)
+}
+
+// Remove handles DELETE /api/v1/conversations/{id}/participants/{userId}
+func (h *ParticipantHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.service.Remove(r.Context(), id, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "participant removed successfully",
+	})
+}
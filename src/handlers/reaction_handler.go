@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// ReactionHandler handles message reaction HTTP requests
+type ReactionHandler struct {
+	service   services.ReactionService
+	validator *validator.Validate
+}
+
+func NewReactionHandler(service services.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// React handles POST /api/v1/messages/{id}/reactions
+func (h *ReactionHandler) React(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "user required")
+		return
+	}
+
+	var req models.ReactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	reactions, err := h.service.React(r.Context(), id, &req)
+	if err != nil {
+		respondReactionError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": reactions,
+	})
+}
+
+// Unreact handles DELETE /api/v1/messages/{id}/reactions?emoji=
+func (h *ReactionHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "user required")
+		return
+	}
+
+	emoji := r.URL.Query().Get("emoji")
+	if emoji == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "emoji is required")
+		return
+	}
+
+	if err := h.service.Unreact(r.Context(), id, emoji); err != nil {
+		respondReactionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List handles GET /api/v1/messages/{id}/reactions
+func (h *ReactionHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid message ID")
+		return
+	}
+
+	reactions, err := h.service.List(r.Context(), id)
+	if err != nil {
+		respondReactionError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": reactions,
+	})
+}
+
+func respondReactionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrUserRequired) {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrCannotReact) {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// ReadHandler handles agent read cursor HTTP requests
+type ReadHandler struct {
+	service   services.ReadService
+	validator *validator.Validate
+}
+
+func NewReadHandler(service services.ReadService) *ReadHandler {
+	return &ReadHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// MarkRead handles POST /api/v1/conversations/{id}/read for the calling agent
+func (h *ReadHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	agentID := middleware.GetUserID(r)
+	if agentID == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "user required")
+		return
+	}
+
+	var req models.MarkConversationReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	cursor, err := h.service.MarkRead(r.Context(), id, agentID, req.MessageID)
+	if err != nil {
+		respondNotFoundError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, cursor)
+}
+
+// AgentUnread handles GET /api/v1/agents/{id}/unread
+func (h *ReadHandler) AgentUnread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid agent ID")
+		return
+	}
+
+	totals, err := h.service.AgentUnread(r.Context(), id)
+	if err != nil {
+		respondNotFoundError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, totals)
+}
+
+// respondNotFoundError responds 404 for missing records and 500 otherwise
+func respondNotFoundError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// SLAHandler handles SLA policy HTTP requests
+type SLAHandler struct {
+	service   services.SLAService
+	validator *validator.Validate
+}
+
+func NewSLAHandler(service services.SLAService) *SLAHandler {
+	return &SLAHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/sla-policies
+func (h *SLAHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateSLAPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(r.Context(), &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, policy)
+}
+
+// GetByID handles GET /api/v1/sla-policies/{id}
+func (h *SLAHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid SLA policy ID")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), id)
+	if err != nil || policy == nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "SLA policy not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, policy)
+}
+
+// ListByOrganization handles GET /api/v1/organizations/{orgId}/sla-policies
+func (h *SLAHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	policies, err := h.service.ListPolicies(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   policies,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/sla-policies/{id}
+func (h *SLAHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid SLA policy ID")
+		return
+	}
+
+	var req models.UpdateSLAPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.UpdatePolicy(r.Context(), id, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "SLA policy updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/v1/sla-policies/{id}
+func (h *SLAHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid SLA policy ID")
+		return
+	}
+
+	if err := h.service.DeletePolicy(r.Context(), id); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// TagHandler handles tag taxonomy and conversation tagging HTTP requests
+type TagHandler struct {
+	service   services.TagService
+	validator *validator.Validate
+}
+
+func NewTagHandler(service services.TagService) *TagHandler {
+	return &TagHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/tags
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	tag, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, tag)
+}
+
+// GetByID handles GET /api/v1/tags/{id}
+func (h *TagHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	tag, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "tag not found")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, tag)
+}
+
+// ListByOrganization handles GET /api/v1/organizations/{orgId}/tags
+func (h *TagHandler) ListByOrganization(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	tags, err := h.service.ListByOrganization(r.Context(), orgID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data":   tags,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Update handles PATCH /api/v1/tags/{id}
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.service.Update(r.Context(), id, &req); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "tag updated successfully",
+	})
+}
+
+// Delete handles DELETE /api/v1/tags/{id}, untagging every conversation
+// that carried it
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Counts handles GET /api/v1/organizations/{orgId}/tags/counts
+func (h *TagHandler) Counts(w http.ResponseWriter, r *http.Request) {
+	orgID, err := strconv.ParseInt(chi.URLParam(r, "orgId"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := r.URL.Query()
+	q := &models.TagCountQuery{OrganizationID: orgID}
+	for _, s := range splitList(query.Get("status")) {
+		q.Statuses = append(q.Statuses, models.ConversationStatus(s))
+	}
+
+	for param, dst := range map[string]**time.Time{
+		"created_after":  &q.CreatedAfter,
+		"created_before": &q.CreatedBefore,
+	} {
+		if v := query.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				utils.ErrorResponse(w, http.StatusBadRequest, "invalid "+param)
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	if err := h.validator.Struct(q); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	counts, err := h.service.Counts(r.Context(), q)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": counts,
+	})
+}
+
+// ConversationTags handles GET /api/v1/conversations/{id}/tags
+func (h *TagHandler) ConversationTags(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	tags, err := h.service.ConversationTags(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": tags,
+	})
+}
+
+// AddToConversation handles POST /api/v1/conversations/{id}/tags
+func (h *TagHandler) AddToConversation(w http.ResponseWriter, r *http.Request) {
+	h.changeConversation(w, r, h.service.AddTags)
+}
+
+// RemoveFromConversation handles DELETE /api/v1/conversations/{id}/tags
+func (h *TagHandler) RemoveFromConversation(w http.ResponseWriter, r *http.Request) {
+	h.changeConversation(w, r, h.service.RemoveTags)
+}
+
+// BulkAdd handles POST /api/v1/conversations/tags
+func (h *TagHandler) BulkAdd(w http.ResponseWriter, r *http.Request) {
+	h.changeBulk(w, r, h.service.AddTags)
+}
+
+// BulkRemove handles DELETE /api/v1/conversations/tags
+func (h *TagHandler) BulkRemove(w http.ResponseWriter, r *http.Request) {
+	h.changeBulk(w, r, h.service.RemoveTags)
+}
+
+type tagChange func(ctx context.Context, conversationIDs []int64, names []string) error
+
+// changeConversation applies change to one conversation and responds with
+// its tags afterwards
+func (h *TagHandler) changeConversation(w http.ResponseWriter, r *http.Request, change tagChange) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	var req models.ConversationTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := change(r.Context(), []int64{id}, req.Tags); err != nil {
+		respondTagError(w, err)
+		return
+	}
+
+	tags, err := h.service.ConversationTags(r.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"data": tags,
+	})
+}
+
+// changeBulk applies change to every conversation in the request
+func (h *TagHandler) changeBulk(w http.ResponseWriter, r *http.Request, change tagChange) {
+	var req models.BulkConversationTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := change(r.Context(), req.ConversationIDs, req.Tags); err != nil {
+		respondTagError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "conversation tags updated successfully",
+	})
+}
+
+func respondTagError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrUnknownTag) {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+}
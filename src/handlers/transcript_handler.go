@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// transcriptExtensions names downloaded transcript files by format
+var transcriptExtensions = map[models.TranscriptFormat]string{
+	models.TranscriptFormatJSON:     "json",
+	models.TranscriptFormatText:     "txt",
+	models.TranscriptFormatMarkdown: "md",
+	models.TranscriptFormatHTML:     "html",
+}
+
+// TranscriptHandler handles conversation transcript HTTP requests
+type TranscriptHandler struct {
+	service   services.TranscriptService
+	validator *validator.Validate
+}
+
+func NewTranscriptHandler(service services.TranscriptService) *TranscriptHandler {
+	return &TranscriptHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// Transcript handles GET /api/v1/conversations/{id}/transcript
+func (h *TranscriptHandler) Transcript(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := &models.TranscriptOptions{Format: models.TranscriptFormat(query.Get("format"))}
+	if opts.Format == "" {
+		opts.Format = models.TranscriptFormatJSON
+	}
+	if opts.Location, err = loadLocation(query.Get("tz")); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid tz")
+		return
+	}
+	if v := query.Get("redact_notes"); v != "" {
+		if opts.RedactNotes, err = strconv.ParseBool(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid redact_notes")
+			return
+		}
+	}
+	if v := query.Get("exclude_notes"); v != "" {
+		if opts.ExcludeNotes, err = strconv.ParseBool(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "invalid exclude_notes")
+			return
+		}
+	}
+
+	if err := h.validator.Struct(opts); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	transcript, err := h.service.Build(r.Context(), id, opts)
+	if err != nil {
+		respondNotFoundError(w, err)
+		return
+	}
+
+	body, contentType, err := services.RenderTranscript(transcript, opts.Format)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="conversation-%d.%s"`, id, transcriptExtensions[opts.Format]))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
+
+// Email handles POST /api/v1/conversations/{id}/transcript/email
+func (h *TranscriptHandler) Email(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid conversation ID")
+		return
+	}
+
+	var req models.EmailTranscriptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	loc, err := loadLocation(req.Timezone)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, "invalid timezone")
+		return
+	}
+
+	opts := &models.TranscriptOptions{Format: req.Format, Location: loc}
+	if err := h.service.Email(r.Context(), id, req.To, opts); err != nil {
+		if errors.Is(err, models.ErrTranscriptUndeliverable) {
+			utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondNotFoundError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusAccepted, map[string]string{
+		"message": "transcript queued for delivery",
+	})
+}
+
+// loadLocation loads an IANA time zone, UTC if name is empty
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
@@ -50,7 +50,7 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Process webhook
-	if err := h.service.ProcessWebhook(channelID, eventType, payload); err != nil {
+	if err := h.service.ProcessWebhook(r.Context(), channelID, eventType, payload); err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
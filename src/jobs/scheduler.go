@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// Job is a background task run on a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Start runs each job in its own goroutine until ctx is cancelled. Every
+// run gets its own correlation ID so the events it emits can be traced.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, job)
+	}
+}
+
+func run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			runCtx := events.WithCause(ctx, "job:"+job.Name+":"+utils.NewULID())
+			if err := job.Run(runCtx, now); err != nil {
+				log.Printf("Job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
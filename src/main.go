@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // business hours timezones on hosts without zoneinfo
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/commands"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/config"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/database"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/handlers"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/jobs"
 	custommiddleware "github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/services"
@@ -39,16 +44,6 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
-	// Initialize Redis event emitter
-	emitter, err := events.NewRedisEmitter(
-		fmt.Sprintf("redis://%s:%d/%d", cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.DB),
-		cfg.Redis.Enabled,
-	)
-	if err != nil {
-		log.Fatalf("Failed to initialize event emitter: %v", err)
-	}
-	defer emitter.Close()
-
 	// Initialize repositories
 	orgRepo := repositories.NewOrganizationRepository(db)
 	channelRepo := repositories.NewChannelRepository(db)
@@ -56,25 +51,193 @@ func main() {
 	conversationRepo := repositories.NewConversationRepository(db)
 	messageRepo := repositories.NewMessageRepository(db)
 	webhookEventRepo := repositories.NewWebhookEventRepository(db)
+	commandRepo := repositories.NewCommandRepository(db)
+	agentRepo := repositories.NewAgentRepository(db)
+	routingPolicyRepo := repositories.NewRoutingPolicyRepository(db)
+	teamRepo := repositories.NewTeamRepository(db)
+	conversationEventRepo := repositories.NewConversationEventRepository(db)
+	slaRepo := repositories.NewSLARepository(db)
+	businessHoursRepo := repositories.NewBusinessHoursRepository(db)
+	lifecyclePolicyRepo := repositories.NewLifecyclePolicyRepository(db)
+	readCursorRepo := repositories.NewReadCursorRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	customAttributeRepo := repositories.NewCustomAttributeRepository(db)
+	csatRepo := repositories.NewCSATRepository(db)
+	participantRepo := repositories.NewParticipantRepository(db)
+	cannedResponseRepo := repositories.NewCannedResponseRepository(db)
+	macroRepo := repositories.NewMacroRepository(db)
+	reactionRepo := repositories.NewReactionRepository(db)
+
+	// Initialize event sinks and the router that fans events out to them
+	redisEmitter, err := events.NewRedisEmitter(
+		fmt.Sprintf("redis://%s:%d/%d", cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.DB),
+		cfg.Redis.Enabled,
+		cfg.Redis.OrgScoped,
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize event emitter: %v", err)
+	}
+
+	sinks := map[string]events.Emitter{"redis": redisEmitter}
+	if cfg.NATS.Enabled {
+		natsEmitter, err := events.NewNATSEmitter(
+			cfg.NATS.URL,
+			cfg.NATS.Stream,
+			cfg.NATS.SubjectPrefix,
+			cfg.NATS.Enabled,
+			cfg.NATS.AutoProvision,
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize NATS emitter: %v", err)
+		}
+		sinks["nats"] = natsEmitter
+	}
+	if cfg.Events.AuditLogPath != "" {
+		auditEmitter, err := events.NewLogEmitter(cfg.Events.AuditLogPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize audit log: %v", err)
+		}
+		sinks["audit"] = auditEmitter
+	}
+
+	routes := events.DefaultRoutes()
+	if cfg.Events.RoutesFile != "" {
+		if routes, err = events.LoadRoutes(cfg.Events.RoutesFile); err != nil {
+			log.Fatalf("Failed to load event routes: %v", err)
+		}
+	}
+
+	emitter, err := events.NewRouterFromSpecs(
+		routes,
+		sinks,
+		services.NewEventScopeResolver(channelRepo, conversationRepo, messageRepo),
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize event router: %v", err)
+	}
+	defer emitter.Close()
 
 	// Initialize services
 	orgService := services.NewOrganizationService(orgRepo, emitter)
 	channelService := services.NewChannelService(channelRepo, emitter)
-	messageService := services.NewMessageService(messageRepo, conversationRepo, externalUserRepo, emitter)
-	conversationService := services.NewConversationService(conversationRepo, emitter)
-	webhookService := services.NewWebhookService(webhookEventRepo, messageService)
+	routingService := services.NewRoutingService(routingPolicyRepo, agentRepo, teamRepo, conversationRepo, conversationEventRepo, channelRepo, emitter)
+	agentService := services.NewAgentService(agentRepo, routingService)
+	teamService := services.NewTeamService(teamRepo)
+	slaService := services.NewSLAService(slaRepo, conversationRepo, channelRepo, businessHoursRepo, conversationEventRepo, emitter)
+	businessHoursService := services.NewBusinessHoursService(businessHoursRepo, channelRepo, conversationRepo, messageRepo, emitter)
+	csatService := services.NewCSATService(csatRepo, channelRepo, messageRepo, emitter)
+	lifecycleService := services.NewLifecycleService(lifecyclePolicyRepo, conversationRepo, messageRepo, conversationEventRepo, channelRepo, csatService, emitter)
+	participantService := services.NewParticipantService(participantRepo, conversationRepo, externalUserRepo, emitter)
+	messageService := services.NewMessageService(messageRepo, conversationRepo, externalUserRepo, emitter, routingService, slaService, lifecycleService, csatService, businessHoursService, participantService)
+	conversationService := services.NewConversationService(conversationRepo, channelRepo, teamRepo, conversationEventRepo, slaService, csatService, emitter)
+	tagService := services.NewTagService(tagRepo, conversationRepo, channelRepo, conversationEventRepo, emitter)
+	customAttributeService := services.NewCustomAttributeService(customAttributeRepo, conversationRepo, externalUserRepo, channelRepo)
+	transcriptService := services.NewTranscriptService(conversationRepo, conversationEventRepo, channelRepo, externalUserRepo, emitter)
+	messageEditService := services.NewMessageEditService(messageRepo, conversationRepo, channelRepo, emitter)
+	reactionService := services.NewReactionService(reactionRepo, messageRepo, conversationRepo, channelRepo, emitter)
+	noteService := services.NewNoteService(messageRepo, conversationRepo, channelRepo, agentRepo, emitter)
+	cannedResponseService := services.NewCannedResponseService(cannedResponseRepo, teamRepo, conversationRepo, channelRepo, externalUserRepo, agentRepo)
+	macroService := services.NewMacroService(macroRepo, teamRepo, tagRepo, conversationRepo, channelRepo, cannedResponseService, slaService, csatService, emitter)
+	readService := services.NewReadService(readCursorRepo, messageRepo, conversationRepo, channelRepo, agentRepo, emitter)
+	webhookService := services.NewWebhookService(webhookEventRepo, messageService, participantService, messageEditService, reactionService)
+	externalUserService := services.NewExternalUserService(externalUserRepo, emitter)
+	commandService := services.NewCommandService(commandRepo, messageService, conversationService, externalUserService, emitter, cfg.Commands.ClaimTimeout)
+
+	// Background workers stop when ctx is cancelled on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Periodic jobs
+	jobs.Start(ctx, jobs.Job{
+		Name:     "routing.reassign_unanswered",
+		Interval: cfg.Jobs.Interval,
+		Run: func(ctx context.Context, now time.Time) error {
+			_, err := routingService.ReassignUnanswered(ctx, now)
+			return err
+		},
+	}, jobs.Job{
+		Name:     "sla.check",
+		Interval: cfg.Jobs.Interval,
+		Run: func(ctx context.Context, now time.Time) error {
+			_, err := slaService.Check(ctx, now)
+			return err
+		},
+	}, jobs.Job{
+		Name:     "lifecycle.close_idle",
+		Interval: cfg.Jobs.Interval,
+		Run: func(ctx context.Context, now time.Time) error {
+			_, err := lifecycleService.CloseIdle(ctx, now)
+			return err
+		},
+	}, jobs.Job{
+		Name:     "lifecycle.wake_snoozed",
+		Interval: cfg.Jobs.Interval,
+		Run: func(ctx context.Context, now time.Time) error {
+			_, err := lifecycleService.WakeSnoozed(ctx, now)
+			return err
+		},
+	}, jobs.Job{
+		Name:     "messages.purge_deleted",
+		Interval: cfg.Jobs.Interval,
+		Run: func(ctx context.Context, now time.Time) error {
+			_, err := messageEditService.PurgeDeleted(ctx, now)
+			return err
+		},
+	})
+
+	// Consume commands from NestJS over a Redis stream
+	if cfg.Commands.Enabled {
+		consumer, err := commands.NewRedisConsumer(
+			fmt.Sprintf("redis://%s:%d/%d", cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.DB),
+			cfg.Commands.Stream,
+			cfg.Commands.Group,
+			cfg.Commands.Consumer,
+			cfg.Commands.ClaimTimeout,
+			commandService,
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize command consumer: %v", err)
+		}
+		defer consumer.Close()
+
+		go func() {
+			if err := consumer.Run(ctx); err != nil {
+				log.Printf("Command consumer stopped: %v", err)
+			}
+		}()
+	}
 
 	// Initialize handlers
 	orgHandler := handlers.NewOrganizationHandler(orgService)
 	channelHandler := handlers.NewChannelHandler(channelService)
 	messageHandler := handlers.NewMessageHandler(messageService)
+	messageEditHandler := handlers.NewMessageEditHandler(messageEditService)
+	reactionHandler := handlers.NewReactionHandler(reactionService)
 	conversationHandler := handlers.NewConversationHandler(conversationService)
 	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	eventHandler := handlers.NewEventHandler()
+	externalUserHandler := handlers.NewExternalUserHandler(externalUserService)
+	agentHandler := handlers.NewAgentHandler(agentService)
+	teamHandler := handlers.NewTeamHandler(teamService)
+	routingHandler := handlers.NewRoutingHandler(routingService)
+	lifecycleHandler := handlers.NewLifecycleHandler(lifecycleService)
+	readHandler := handlers.NewReadHandler(readService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	customAttributeHandler := handlers.NewCustomAttributeHandler(customAttributeService)
+	transcriptHandler := handlers.NewTranscriptHandler(transcriptService)
+	csatHandler := handlers.NewCSATHandler(csatService)
+	participantHandler := handlers.NewParticipantHandler(participantService)
+	noteHandler := handlers.NewNoteHandler(noteService)
+	cannedResponseHandler := handlers.NewCannedResponseHandler(cannedResponseService)
+	macroHandler := handlers.NewMacroHandler(macroService)
+	slaHandler := handlers.NewSLAHandler(slaService)
+	businessHoursHandler := handlers.NewBusinessHoursHandler(businessHoursService)
 
 	// Setup Chi router
 	r := chi.NewRouter()
 
 	// Global middleware
+	r.Use(custommiddleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(custommiddleware.SetupCORS([]string{"http://localhost:3000", "http://localhost:5173"}))
@@ -86,6 +249,9 @@ func main() {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Event catalog (public so consumers can generate types from it)
+	r.Get("/api/v1/events/catalog", eventHandler.Catalog)
+
 	// Webhook routes (platform-specific authentication would be added per platform)
 	r.Route("/api/v1/webhooks", func(r chi.Router) {
 		r.Post("/{channelId}/{platform}", webhookHandler.HandleWebhook)
@@ -104,6 +270,10 @@ func main() {
 		r.Get("/organizations/slug/{slug}", orgHandler.GetBySlug)
 		r.Patch("/organizations/{id}", orgHandler.Update)
 		r.Delete("/organizations/{id}", orgHandler.Delete)
+		r.Get("/organizations/{orgId}/business-hours", businessHoursHandler.GetForOrganization)
+		r.Put("/organizations/{orgId}/business-hours", businessHoursHandler.SetForOrganization)
+		r.Get("/organizations/{orgId}/business-hours/status", businessHoursHandler.StatusForOrganization)
+		r.Get("/organizations/{orgId}/csat/report", csatHandler.Report)
 
 		// Channel routes
 		r.Post("/channels", channelHandler.Create)
@@ -112,19 +282,120 @@ func main() {
 		r.Patch("/channels/{id}", channelHandler.Update)
 		r.Patch("/channels/{id}/status", channelHandler.UpdateStatus)
 		r.Delete("/channels/{id}", channelHandler.Delete)
+		r.Get("/channels/{id}/routing", routingHandler.GetPolicy)
+		r.Put("/channels/{id}/routing", routingHandler.SetPolicy)
+		r.Get("/channels/{id}/lifecycle", lifecycleHandler.GetPolicy)
+		r.Put("/channels/{id}/lifecycle", lifecycleHandler.SetPolicy)
+		r.Get("/channels/{id}/csat", csatHandler.GetPolicy)
+		r.Put("/channels/{id}/csat", csatHandler.SetPolicy)
+		r.Get("/channels/{id}/business-hours", businessHoursHandler.GetForChannel)
+		r.Put("/channels/{id}/business-hours", businessHoursHandler.SetForChannel)
+		r.Get("/channels/{id}/business-hours/status", businessHoursHandler.StatusForChannel)
+
+		// Agent routes
+		r.Post("/agents", agentHandler.Create)
+		r.Get("/agents/{id}", agentHandler.GetByID)
+		r.Get("/organizations/{orgId}/agents", agentHandler.ListByOrganization)
+		r.Patch("/agents/{id}", agentHandler.Update)
+		r.Patch("/agents/{id}/status", agentHandler.UpdateStatus)
+		r.Get("/agents/{id}/unread", readHandler.AgentUnread)
+
+		// Team routes
+		r.Post("/teams", teamHandler.Create)
+		r.Get("/teams/{id}", teamHandler.GetByID)
+		r.Get("/organizations/{orgId}/teams", teamHandler.ListByOrganization)
+		r.Patch("/teams/{id}", teamHandler.Update)
+		r.Delete("/teams/{id}", teamHandler.Delete)
+		r.Put("/teams/{id}/members", teamHandler.SetMembers)
+
+		// SLA policy routes
+		r.Post("/sla-policies", slaHandler.Create)
+		r.Get("/sla-policies/{id}", slaHandler.GetByID)
+		r.Get("/organizations/{orgId}/sla-policies", slaHandler.ListByOrganization)
+		r.Patch("/sla-policies/{id}", slaHandler.Update)
+		r.Delete("/sla-policies/{id}", slaHandler.Delete)
+
+		// Tag routes
+		r.Post("/tags", tagHandler.Create)
+		r.Get("/tags/{id}", tagHandler.GetByID)
+		r.Get("/organizations/{orgId}/tags", tagHandler.ListByOrganization)
+		r.Get("/organizations/{orgId}/tags/counts", tagHandler.Counts)
+		r.Patch("/tags/{id}", tagHandler.Update)
+		r.Delete("/tags/{id}", tagHandler.Delete)
+
+		// Custom attribute routes
+		r.Post("/custom-attributes", customAttributeHandler.Create)
+		r.Get("/custom-attributes/{id}", customAttributeHandler.GetByID)
+		r.Get("/organizations/{orgId}/custom-attributes", customAttributeHandler.ListByOrganization)
+		r.Patch("/custom-attributes/{id}", customAttributeHandler.Update)
+		r.Delete("/custom-attributes/{id}", customAttributeHandler.Delete)
 
 		// Conversation routes
+		r.Get("/inbox", conversationHandler.Inbox)
 		r.Get("/conversations/{id}", conversationHandler.GetByID)
 		r.Get("/channels/{channelId}/conversations", conversationHandler.List)
 		r.Post("/conversations/{id}/assign", conversationHandler.Assign)
 		r.Patch("/conversations/{id}/status", conversationHandler.UpdateStatus)
 		r.Patch("/conversations/{id}/priority", conversationHandler.UpdatePriority)
+		r.Patch("/conversations/{id}/subject", conversationHandler.UpdateSubject)
+		r.Get("/conversations/{id}/timeline", conversationHandler.Timeline)
+		r.Get("/conversations/{id}/transcript", transcriptHandler.Transcript)
+		r.Post("/conversations/{id}/transcript/email", transcriptHandler.Email)
+		r.Get("/conversations/{id}/csat", csatHandler.ConversationSurveys)
+		r.Get("/conversations/{id}/participants", participantHandler.List)
+		r.Post("/conversations/{id}/participants", participantHandler.Add)
+		r.Delete("/conversations/{id}/participants/{userId}", participantHandler.Remove)
+		r.Post("/conversations/{id}/merge", conversationHandler.Merge)
+		r.Post("/conversations/{id}/split", conversationHandler.Split)
+		r.Post("/conversations/{id}/read", readHandler.MarkRead)
+		r.Get("/conversations/{id}/tags", tagHandler.ConversationTags)
+		r.Post("/conversations/{id}/tags", tagHandler.AddToConversation)
+		r.Delete("/conversations/{id}/tags", tagHandler.RemoveFromConversation)
+		r.Post("/conversations/tags", tagHandler.BulkAdd)
+		r.Delete("/conversations/tags", tagHandler.BulkRemove)
+		r.Patch("/conversations/{id}/attributes", customAttributeHandler.SetConversationAttributes)
+
+		// External user routes
+		r.Get("/organizations/{orgId}/external-users", externalUserHandler.List)
+		r.Get("/external-users/{id}", externalUserHandler.GetByID)
+		r.Patch("/external-users/{id}/attributes", customAttributeHandler.SetContactAttributes)
+		r.Post("/external-users/{id}/block", externalUserHandler.Block)
+		r.Post("/external-users/{id}/unblock", externalUserHandler.Unblock)
 
 		// Message routes
 		r.Post("/conversations/{id}/messages", messageHandler.SendMessage)
 		r.Get("/conversations/{id}/messages", messageHandler.GetHistory)
 		r.Post("/messages/{id}/delivered", messageHandler.MarkDelivered)
 		r.Post("/messages/{id}/read", messageHandler.MarkRead)
+		r.Patch("/messages/{id}", messageEditHandler.Edit)
+		r.Delete("/messages/{id}", messageEditHandler.Delete)
+		r.Get("/messages/{id}/edits", messageEditHandler.ListEdits)
+		r.Post("/messages/{id}/reactions", reactionHandler.React)
+		r.Delete("/messages/{id}/reactions", reactionHandler.Unreact)
+		r.Get("/messages/{id}/reactions", reactionHandler.List)
+
+		// Note routes
+		r.Post("/conversations/{id}/notes", noteHandler.Create)
+		r.Patch("/notes/{id}", noteHandler.Update)
+		r.Delete("/notes/{id}", noteHandler.Delete)
+		r.Get("/notes/{id}/edits", noteHandler.ListEdits)
+
+		// Canned response routes
+		r.Post("/canned-responses", cannedResponseHandler.Create)
+		r.Get("/canned-responses/{id}", cannedResponseHandler.GetByID)
+		r.Get("/organizations/{orgId}/canned-responses", cannedResponseHandler.List)
+		r.Patch("/canned-responses/{id}", cannedResponseHandler.Update)
+		r.Delete("/canned-responses/{id}", cannedResponseHandler.Delete)
+		r.Post("/canned-responses/{id}/render", cannedResponseHandler.Render)
+
+		// Macro routes
+		r.Post("/macros", macroHandler.Create)
+		r.Get("/macros/{id}", macroHandler.GetByID)
+		r.Get("/organizations/{orgId}/macros", macroHandler.ListByOrganization)
+		r.Patch("/macros/{id}", macroHandler.Update)
+		r.Delete("/macros/{id}", macroHandler.Delete)
+		r.Get("/macros/{id}/runs", macroHandler.ListRuns)
+		r.Post("/conversations/{id}/macros/{macroId}/run", macroHandler.Run)
 	})
 
 	// Start server
@@ -15,8 +15,8 @@ func SetupCORS(allowedOrigins []string) func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Organization-ID"},
-		ExposedHeaders:   []string{"Content-Length"},
+		AllowedHeaders:   []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Organization-ID", RequestIDHeader},
+		ExposedHeaders:   []string{"Content-Length", RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
@@ -90,7 +90,13 @@ func SetupJWT(config JWTConfig) func(http.Handler) http.Handler {
 
 // GetUserID retrieves user ID from context
 func GetUserID(r *http.Request) string {
-	if v := r.Context().Value(UserIDKey); v != nil {
+	return UserIDFromContext(r.Context())
+}
+
+// UserIDFromContext retrieves the user ID stored by the JWT middleware, so
+// services can attribute changes to the caller
+func UserIDFromContext(ctx context.Context) string {
+	if v := ctx.Value(UserIDKey); v != nil {
 		return v.(string)
 	}
 	return ""
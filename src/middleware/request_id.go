@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// RequestIDHeader is read from incoming requests and echoed on responses
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID accepts a caller-supplied X-Request-ID or generates a ULID, and
+// stores it in the request context as the correlation and causation ID of
+// every event emitted while handling the request
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = utils.NewULID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := events.WithTrace(r.Context(), events.Trace{
+			CorrelationID: requestID,
+			CausationID:   requestID,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRequestID retrieves the request ID from context
+func GetRequestID(r *http.Request) string {
+	return events.TraceFrom(r.Context()).CorrelationID
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type AgentStatus string
+
+const (
+	AgentStatusOnline  AgentStatus = "online"
+	AgentStatusAway    AgentStatus = "away"
+	AgentStatusOffline AgentStatus = "offline"
+)
+
+// Agent is a support agent known to the routing engine. ExternalID is the
+// ID used as a conversation assignee (e.g. the NestJS user ID).
+type Agent struct {
+	ID             int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64       `json:"organization_id" gorm:"not null;uniqueIndex:idx_agents_org_external"`
+	ExternalID     string      `json:"external_id" gorm:"not null;uniqueIndex:idx_agents_org_external"`
+	Name           string      `json:"name" gorm:"not null;size:100"`
+	Status         AgentStatus `json:"status" gorm:"default:offline;index"`
+	// MaxConcurrent caps open conversations assigned by routing; 0 means no limit
+	MaxConcurrent  int        `json:"max_concurrent" gorm:"default:0"`
+	LastAssignedAt *time.Time `json:"last_assigned_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AgentLoad is an agent together with its open conversation count
+type AgentLoad struct {
+	Agent
+	OpenConversations int `json:"open_conversations"`
+}
+
+// HasCapacity reports whether routing may assign another conversation
+func (a *AgentLoad) HasCapacity() bool {
+	return a.MaxConcurrent <= 0 || a.OpenConversations < a.MaxConcurrent
+}
+
+type CreateAgentRequest struct {
+	OrganizationID int64       `json:"organization_id" validate:"required,gt=0"`
+	ExternalID     string      `json:"external_id" validate:"required,max=255"`
+	Name           string      `json:"name" validate:"required,min=1,max=100"`
+	Status         AgentStatus `json:"status" validate:"omitempty,oneof=online away offline"`
+	MaxConcurrent  int         `json:"max_concurrent" validate:"min=0"`
+}
+
+type UpdateAgentRequest struct {
+	Name          *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	MaxConcurrent *int    `json:"max_concurrent,omitempty" validate:"omitempty,min=0"`
+}
+
+type UpdateAgentStatusRequest struct {
+	Status AgentStatus `json:"status" validate:"required,oneof=online away offline"`
+}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BusinessHoursWindow is an opening period on one weekday. Times are
+// "HH:MM" in the schedule's time zone; End may be "24:00". Windows do not
+// span midnight.
+type BusinessHoursWindow struct {
+	Day   string `json:"day" validate:"required,oneof=sun mon tue wed thu fri sat"`
+	Start string `json:"start" validate:"required,len=5"`
+	End   string `json:"end" validate:"required,len=5"`
+}
+
+// BusinessHoliday closes a schedule for a whole day, "YYYY-MM-DD" in the
+// schedule's time zone. A recurring holiday falls on the same month and day
+// every year.
+type BusinessHoliday struct {
+	Date      string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name      string `json:"name,omitempty" validate:"max=100"`
+	Recurring bool   `json:"recurring,omitempty"`
+}
+
+// DefaultAwayCooldown is how long a conversation goes without another away
+// reply when the schedule does not say
+const DefaultAwayCooldown = time.Hour
+
+// BusinessHours is the weekly schedule of an organization or, when
+// ChannelID is set, of one channel. A channel schedule overrides the
+// organization's.
+type BusinessHours struct {
+	ID             int64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64                 `json:"organization_id" gorm:"not null;uniqueIndex:idx_business_hours_scope"`
+	ChannelID      int64                 `json:"channel_id,omitempty" gorm:"not null;default:0;uniqueIndex:idx_business_hours_scope"`
+	Timezone       string                `json:"timezone" gorm:"not null"`
+	Windows        []BusinessHoursWindow `json:"windows" gorm:"type:text;serializer:json"`
+	Holidays       []BusinessHoliday     `json:"holidays" gorm:"type:text;serializer:json"`
+	// AwayMessage, if set, is sent when a customer writes outside business
+	// hours, at most once per conversation every AwayCooldownSeconds
+	AwayMessage         *string   `json:"away_message,omitempty" gorm:"type:text"`
+	AwayCooldownSeconds int       `json:"away_cooldown_seconds" gorm:"default:0"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type SetBusinessHoursRequest struct {
+	Timezone            string                `json:"timezone" validate:"required,timezone"`
+	Windows             []BusinessHoursWindow `json:"windows" validate:"required,min=1,dive"`
+	Holidays            []BusinessHoliday     `json:"holidays,omitempty" validate:"max=366,dive"`
+	AwayMessage         *string               `json:"away_message,omitempty" validate:"omitempty,max=4096"`
+	AwayCooldownSeconds int                   `json:"away_cooldown_seconds" validate:"min=0"`
+}
+
+// BusinessHoursStatus tells whether a schedule is open at a given time.
+// ClosesAt is the end of the current opening period; OpensAt the start of
+// the next one when closed. Holiday is set when the day is a holiday.
+type BusinessHoursStatus struct {
+	Open     bool             `json:"open"`
+	Timezone string           `json:"timezone,omitempty"`
+	Holiday  *BusinessHoliday `json:"holiday,omitempty"`
+	ClosesAt *time.Time       `json:"closes_at,omitempty"`
+	OpensAt  *time.Time       `json:"opens_at,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ValidateBusinessHoursWindows checks that every window is a valid,
+// non-empty period within one day
+func ValidateBusinessHoursWindows(windows []BusinessHoursWindow) error {
+	for _, w := range windows {
+		start, end, err := w.minutes()
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf("window %s %s-%s must end after it starts", w.Day, w.Start, w.End)
+		}
+	}
+	return nil
+}
+
+func (w BusinessHoursWindow) minutes() (start, end int, err error) {
+	if start, err = parseClock(w.Start); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%02d:%02d", &h, &m); err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// Location returns the schedule's time zone, falling back to UTC
+func (b *BusinessHours) Location() *time.Location {
+	if loc, err := time.LoadLocation(b.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+type openPeriod struct{ start, end time.Time }
+
+// AwayCooldown returns how long to wait between away replies in one
+// conversation
+func (b *BusinessHours) AwayCooldown() time.Duration {
+	if b.AwayCooldownSeconds <= 0 {
+		return DefaultAwayCooldown
+	}
+	return time.Duration(b.AwayCooldownSeconds) * time.Second
+}
+
+// holiday returns the holiday falling on the calendar day containing day,
+// or nil
+func (b *BusinessHours) holiday(day time.Time) *BusinessHoliday {
+	date := day.In(b.Location()).Format("2006-01-02")
+	for i, h := range b.Holidays {
+		if h.Date == date || (h.Recurring && len(h.Date) == len(date) && h.Date[4:] == date[4:]) {
+			return &b.Holidays[i]
+		}
+	}
+	return nil
+}
+
+// periods returns the opening periods of the calendar day containing day,
+// none on holidays
+func (b *BusinessHours) periods(day time.Time) []openPeriod {
+	loc := b.Location()
+	y, m, d := day.In(loc).Date()
+	weekday := time.Date(y, m, d, 0, 0, 0, 0, loc).Weekday()
+	if b.holiday(day) != nil {
+		return nil
+	}
+
+	var out []openPeriod
+	for _, w := range b.Windows {
+		if weekdays[w.Day] != weekday {
+			continue
+		}
+		start, end, err := w.minutes()
+		if err != nil || end <= start {
+			continue
+		}
+		out = append(out, openPeriod{
+			start: time.Date(y, m, d, start/60, start%60, 0, 0, loc),
+			end:   time.Date(y, m, d, end/60, end%60, 0, 0, loc),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].start.Before(out[j].start) })
+	return out
+}
+
+// IsOpen reports whether t falls within the schedule. A nil schedule is
+// always open.
+func (b *BusinessHours) IsOpen(t time.Time) bool {
+	if b == nil || len(b.Windows) == 0 {
+		return true
+	}
+	for _, p := range b.periods(t) {
+		if !t.Before(p.start) && t.Before(p.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status reports whether the schedule is open at t and when that changes.
+// A nil schedule is always open.
+func (b *BusinessHours) Status(t time.Time) *BusinessHoursStatus {
+	if b == nil || len(b.Windows) == 0 {
+		return &BusinessHoursStatus{Open: true}
+	}
+
+	loc := b.Location()
+	status := &BusinessHoursStatus{Timezone: loc.String(), Holiday: b.holiday(t)}
+	t = t.In(loc)
+	for _, p := range b.periods(t) {
+		if !t.Before(p.start) && t.Before(p.end) {
+			status.Open = true
+			status.ClosesAt = &p.end
+			return status
+		}
+	}
+
+	day := t
+	// The bound only matters for schedules whose windows are all invalid
+	for i := 0; i < 366*8; i++ {
+		for _, p := range b.periods(day) {
+			if p.start.After(t) {
+				status.OpensAt = &p.start
+				return status
+			}
+		}
+		y, m, d := day.Date()
+		day = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+	return status
+}
+
+// Add returns the time at which d of business time has elapsed after start.
+// A nil schedule counts every hour.
+func (b *BusinessHours) Add(start time.Time, d time.Duration) time.Time {
+	if b == nil || len(b.Windows) == 0 {
+		return start.Add(d)
+	}
+
+	loc := b.Location()
+	t := start.In(loc)
+	// The bound only matters for schedules whose windows are all invalid
+	for i := 0; i < 366*8; i++ {
+		for _, p := range b.periods(t) {
+			if !p.end.After(t) {
+				continue
+			}
+			from := p.start
+			if t.After(from) {
+				from = t
+			}
+			open := p.end.Sub(from)
+			if d <= open {
+				return from.Add(d)
+			}
+			d -= open
+			t = p.end
+		}
+		y, m, day := t.Date()
+		t = time.Date(y, m, day+1, 0, 0, 0, 0, loc)
+	}
+	return start.Add(d)
+}
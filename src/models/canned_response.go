@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownTeam is returned when a team is not one of the organization's
+var ErrUnknownTeam = errors.New("unknown team")
+
+// CannedResponse is a saved reply agents insert by its shortcut. Responses
+// without a team are available to the whole organization. Content may hold
+// placeholders such as {{contact.name}} or {{conversation.order_id|unknown}},
+// filled in from the contact and conversation when it is rendered.
+type CannedResponse struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64     `json:"organization_id" gorm:"not null;uniqueIndex:idx_canned_responses_org_shortcut"`
+	TeamID         *int64    `json:"team_id,omitempty" gorm:"index"`
+	Shortcut       string    `json:"shortcut" gorm:"not null;size:50;uniqueIndex:idx_canned_responses_org_shortcut"`
+	Title          string    `json:"title" gorm:"not null;size:100"`
+	Content        string    `json:"content" gorm:"not null;type:text"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CannedResponseQuery lists an organization's canned responses. With a
+// TeamID, only those of the whole organization and of that team are listed.
+// Search matches the shortcut, title or content.
+type CannedResponseQuery struct {
+	OrganizationID int64
+	TeamID         *int64
+	Search         string
+	Limit          int
+	Offset         int
+}
+
+type CreateCannedResponseRequest struct {
+	OrganizationID int64  `json:"organization_id" validate:"required,gt=0"`
+	TeamID         *int64 `json:"team_id,omitempty" validate:"omitempty,gt=0"`
+	Shortcut       string `json:"shortcut" validate:"required,min=1,max=50,excludesall= "`
+	Title          string `json:"title" validate:"required,min=1,max=100"`
+	Content        string `json:"content" validate:"required,min=1"`
+}
+
+// UpdateCannedResponseRequest changes a canned response. A zero TeamID
+// makes it available to the whole organization.
+type UpdateCannedResponseRequest struct {
+	TeamID   *int64  `json:"team_id,omitempty" validate:"omitempty,min=0"`
+	Shortcut *string `json:"shortcut,omitempty" validate:"omitempty,min=1,max=50,excludesall= "`
+	Title    *string `json:"title,omitempty" validate:"omitempty,min=1,max=100"`
+	Content  *string `json:"content,omitempty" validate:"omitempty,min=1"`
+}
+
+// RenderCannedResponseRequest fills in a canned response's placeholders
+// for a conversation
+type RenderCannedResponseRequest struct {
+	ConversationID int64 `json:"conversation_id" validate:"required,gt=0"`
+}
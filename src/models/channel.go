@@ -14,6 +14,46 @@ const (
 	PlatformWeb       Platform = "web"
 )
 
+// unlimited is a change window without a time limit
+const unlimited = time.Duration(1<<63 - 1)
+
+// messageChangeWindows is how long after sending each platform lets a
+// message be edited or deleted for everyone; zero means it does not.
+// Platforms not listed support neither.
+var messageChangeWindows = map[Platform]struct{ edit, delete time.Duration }{
+	PlatformTelegram: {edit: 48 * time.Hour, delete: 48 * time.Hour},
+	PlatformWhatsApp: {edit: 15 * time.Minute, delete: 48 * time.Hour},
+	PlatformWeb:      {edit: unlimited, delete: unlimited},
+}
+
+// CanEdit reports whether the platform still lets a message sent at sentAt
+// be edited at now
+func (p Platform) CanEdit(sentAt, now time.Time) bool {
+	window := messageChangeWindows[p].edit
+	return window > 0 && now.Sub(sentAt) <= window
+}
+
+// CanDelete reports whether the platform still lets a message sent at
+// sentAt be deleted for everyone at now
+func (p Platform) CanDelete(sentAt, now time.Time) bool {
+	window := messageChangeWindows[p].delete
+	return window > 0 && now.Sub(sentAt) <= window
+}
+
+// singleReactionPlatforms keep one reaction per sender on a message, a new
+// one replacing the last
+var singleReactionPlatforms = map[Platform]bool{
+	PlatformWhatsApp:  true,
+	PlatformInstagram: true,
+	PlatformFacebook:  true,
+}
+
+// SingleReaction reports whether a sender's new reaction to a message
+// replaces their previous one on the platform
+func (p Platform) SingleReaction() bool {
+	return singleReactionPlatforms[p]
+}
+
 type ChannelStatus string
 
 const (
@@ -37,6 +77,9 @@ type ChatChannel struct {
 	UpdatedAt         time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 	LastMessageAt     *time.Time    `json:"last_message_at,omitempty"`
 	IsActive          bool          `json:"is_active" gorm:"default:true"`
+	DefaultTeamID     *int64        `json:"default_team_id,omitempty"`
+	ActivityMessages  bool          `json:"activity_messages" gorm:"default:false"`
+	RetainDeletedDays int           `json:"retain_deleted_days" gorm:"default:0"`
 }
 
 type CreateChannelRequest struct {
@@ -47,13 +90,23 @@ type CreateChannelRequest struct {
 	WebhookSecret     *string  `json:"webhook_secret,omitempty"`
 	AccessToken       *string  `json:"access_token,omitempty"`
 	Config            *string  `json:"config,omitempty"`
+	DefaultTeamID     *int64   `json:"default_team_id,omitempty" validate:"omitempty,gt=0"`
+	ActivityMessages  bool     `json:"activity_messages,omitempty"`
+	RetainDeletedDays int      `json:"retain_deleted_days,omitempty" validate:"omitempty,min=0,max=3650"`
 }
 
+// UpdateChannelRequest changes a channel. A zero DefaultTeamID removes the
+// default team. ActivityMessages turns on recording conversation changes as
+// system messages. RetainDeletedDays keeps the content of deleted messages
+// for that many days; with 0 it is dropped on deletion.
 type UpdateChannelRequest struct {
-	Name          *string        `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Status        *ChannelStatus `json:"status,omitempty" validate:"omitempty,oneof=active inactive error pending"`
-	WebhookSecret *string        `json:"webhook_secret,omitempty"`
-	AccessToken   *string        `json:"access_token,omitempty"`
-	Config        *string        `json:"config,omitempty"`
-	IsActive      *bool          `json:"is_active,omitempty"`
+	Name              *string        `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Status            *ChannelStatus `json:"status,omitempty" validate:"omitempty,oneof=active inactive error pending"`
+	WebhookSecret     *string        `json:"webhook_secret,omitempty"`
+	AccessToken       *string        `json:"access_token,omitempty"`
+	Config            *string        `json:"config,omitempty"`
+	IsActive          *bool          `json:"is_active,omitempty"`
+	DefaultTeamID     *int64         `json:"default_team_id,omitempty" validate:"omitempty,min=0"`
+	ActivityMessages  *bool          `json:"activity_messages,omitempty"`
+	RetainDeletedDays *int           `json:"retain_deleted_days,omitempty" validate:"omitempty,min=0,max=3650"`
 }
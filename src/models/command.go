@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CommandName string
+
+const (
+	CommandSendMessage        CommandName = "send_message"
+	CommandAssignConversation CommandName = "assign_conversation"
+	CommandUpdateStatus       CommandName = "update_status"
+	CommandBlockUser          CommandName = "block_user"
+	CommandUnblockUser        CommandName = "unblock_user"
+)
+
+type CommandStatus string
+
+const (
+	CommandStatusPending   CommandStatus = "pending"
+	CommandStatusSucceeded CommandStatus = "succeeded"
+	CommandStatusFailed    CommandStatus = "failed"
+)
+
+// Command is a request from NestJS delivered over the command stream.
+// CorrelationID defaults to the command ID when not supplied.
+type Command struct {
+	ID            string          `json:"command_id" validate:"required,max=100"`
+	Name          CommandName     `json:"name" validate:"required,oneof=send_message assign_conversation update_status block_user unblock_user"`
+	Payload       json.RawMessage `json:"payload" validate:"required"`
+	CorrelationID string          `json:"correlation_id,omitempty" validate:"max=128"`
+}
+
+// ProcessedCommand records a command so redeliveries are not executed twice.
+// ClaimedAt is when the pending command was last taken up.
+type ProcessedCommand struct {
+	CommandID   string        `json:"command_id" gorm:"primaryKey"`
+	Name        CommandName   `json:"name" gorm:"not null"`
+	Status      CommandStatus `json:"status" gorm:"not null;default:pending"`
+	Result      *string       `json:"result,omitempty" gorm:"type:text"`
+	Error       *string       `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	ClaimedAt   time.Time     `json:"claimed_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
+type SendMessageCommand struct {
+	ConversationID int64 `json:"conversation_id" validate:"required,gt=0"`
+	SendMessageRequest
+}
+
+type AssignConversationCommand struct {
+	ConversationID int64 `json:"conversation_id" validate:"required,gt=0"`
+	AssignConversationRequest
+}
+
+type UpdateStatusCommand struct {
+	ConversationID int64 `json:"conversation_id" validate:"required,gt=0"`
+	UpdateConversationStatusRequest
+}
+
+// BlockUserCommand is the payload of both block_user and unblock_user
+type BlockUserCommand struct {
+	ExternalUserID int64 `json:"external_user_id" validate:"required,gt=0"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type ConversationStatus string
 
@@ -9,8 +12,42 @@ const (
 	ConversationStatusPending  ConversationStatus = "pending"
 	ConversationStatusResolved ConversationStatus = "resolved"
 	ConversationStatusClosed   ConversationStatus = "closed"
+	ConversationStatusSnoozed  ConversationStatus = "snoozed"
 )
 
+// ErrInvalidTransition is returned for a status change the conversation
+// state machine does not allow
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// ErrInvalidMerge is returned for a merge or split the conversations
+// involved do not allow
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// ErrInvalidSnooze is returned when snoozing with a wake-up time that is
+// not in the future, or setting a wake-up time without snoozing
+var ErrInvalidSnooze = errors.New("invalid snooze")
+
+// conversationTransitions lists the statuses each status may move to.
+// Resolved conversations are reopened by moving them back to open; closed
+// is final. Only active conversations can be snoozed.
+var conversationTransitions = map[ConversationStatus][]ConversationStatus{
+	ConversationStatusOpen:     {ConversationStatusPending, ConversationStatusSnoozed, ConversationStatusResolved, ConversationStatusClosed},
+	ConversationStatusPending:  {ConversationStatusOpen, ConversationStatusSnoozed, ConversationStatusResolved, ConversationStatusClosed},
+	ConversationStatusSnoozed:  {ConversationStatusOpen, ConversationStatusPending, ConversationStatusResolved, ConversationStatusClosed},
+	ConversationStatusResolved: {ConversationStatusOpen, ConversationStatusClosed},
+	ConversationStatusClosed:   {},
+}
+
+// CanTransitionTo reports whether a conversation may move from s to status
+func (s ConversationStatus) CanTransitionTo(status ConversationStatus) bool {
+	for _, next := range conversationTransitions[s] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
 type ConversationPriority string
 
 const (
@@ -20,32 +57,95 @@ const (
 	PriorityUrgent ConversationPriority = "urgent"
 )
 
+// Conversation is a thread with a customer. Group chats and email threads
+// set PlatformChatID and map to one conversation whoever writes in them;
+// ExternalUserID is then the participant who started it.
 type Conversation struct {
 	ID                   int64                `json:"id" gorm:"primaryKey;autoIncrement"`
-	ChannelID            int64                `json:"channel_id" gorm:"not null;index"`
+	ChannelID            int64                `json:"channel_id" gorm:"not null;index;index:idx_conversations_channel_last_message,priority:1;index:idx_conversations_channel_created,priority:1;index:idx_conversations_channel_chat,priority:1"`
 	ExternalUserID       int64                `json:"external_user_id" gorm:"not null;index"`
+	PlatformChatID       *string              `json:"platform_chat_id,omitempty" gorm:"index:idx_conversations_channel_chat,priority:2"`
 	AssignedToExternalID *string              `json:"assigned_to_external_id,omitempty" gorm:"index"`
+	AssignedAt           *time.Time           `json:"assigned_at,omitempty"`
+	TeamID               *int64               `json:"team_id,omitempty" gorm:"index"`
 	Status               ConversationStatus   `json:"status" gorm:"default:open;index"`
 	Priority             ConversationPriority `json:"priority" gorm:"default:normal"`
 	Subject              *string              `json:"subject,omitempty"`
 	FirstMessageAt       *time.Time           `json:"first_message_at,omitempty"`
-	LastMessageAt        *time.Time           `json:"last_message_at,omitempty"`
+	LastMessageAt        *time.Time           `json:"last_message_at,omitempty" gorm:"index:idx_conversations_channel_last_message,priority:2"`
 	ResolvedAt           *time.Time           `json:"resolved_at,omitempty"`
-	CreatedAt            time.Time            `json:"created_at" gorm:"autoCreateTime"`
+	SnoozedUntil         *time.Time           `json:"snoozed_until,omitempty" gorm:"index"`
+	AwayRepliedAt        *time.Time           `json:"away_replied_at,omitempty"`
+	MergedIntoID         *int64               `json:"merged_into_id,omitempty" gorm:"index"`
+	SLAPolicyID          *int64               `json:"sla_policy_id,omitempty"`
+	SLAStatus            SLAStatus            `json:"sla_status,omitempty" gorm:"index"`
+	FirstResponseAt      *time.Time           `json:"first_response_at,omitempty"`
+	FirstResponseDueAt   *time.Time           `json:"first_response_due_at,omitempty"`
+	NextResponseDueAt    *time.Time           `json:"next_response_due_at,omitempty"`
+	ResolutionDueAt      *time.Time           `json:"resolution_due_at,omitempty"`
+	CreatedAt            time.Time            `json:"created_at" gorm:"autoCreateTime;index:idx_conversations_channel_created,priority:2"`
 	UpdatedAt            time.Time            `json:"updated_at" gorm:"autoUpdateTime;index"`
 	Metadata             *string              `json:"metadata,omitempty" gorm:"type:text"`
+	CustomAttributes     CustomAttributes     `json:"custom_attributes,omitempty" gorm:"type:text;serializer:json"`
+	// UnreadCount is the number of inbound messages the requesting agent
+	// has not read, set on inbox listings only
+	UnreadCount *int64 `json:"unread_count,omitempty" gorm:"-"`
 }
 
 type CreateConversationRequest struct {
 	ChannelID      int64                `validate:"required,gt=0"`
 	ExternalUserID int64                `validate:"required,gt=0"`
+	PlatformChatID *string              `validate:"omitempty,max=255"`
 	Subject        *string              `validate:"omitempty,max=200"`
 	Priority       ConversationPriority `validate:"omitempty,oneof=low normal high urgent"`
 }
 
+// AssignConversationRequest assigns a conversation to an agent, a team, or
+// an agent within a team. With only a team, the conversation waits in the
+// team's queue unassigned.
+type AssignConversationRequest struct {
+	AssigneeID string `json:"assignee_id" validate:"required_without=TeamID,max=255"`
+	TeamID     *int64 `json:"team_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+// UpdateConversationStatusRequest changes a conversation's status. A
+// snoozed conversation wakes up at SnoozedUntil, or when the customer
+// writes if it is not set.
+type UpdateConversationStatusRequest struct {
+	Status       ConversationStatus `json:"status" validate:"required,oneof=open pending snoozed resolved closed"`
+	Reason       *string            `json:"reason,omitempty" validate:"omitempty,max=255"`
+	SnoozedUntil *time.Time         `json:"snoozed_until,omitempty"`
+}
+
+type UpdateConversationPriorityRequest struct {
+	Priority ConversationPriority `json:"priority" validate:"required,oneof=low normal high urgent"`
+}
+
+type UpdateConversationSubjectRequest struct {
+	Subject string `json:"subject" validate:"max=200"`
+}
+
+// MergeConversationRequest merges a conversation into TargetID
+type MergeConversationRequest struct {
+	TargetID int64 `json:"target_id" validate:"required,gt=0"`
+}
+
+// SplitConversationRequest moves the messages with IDs from FromMessageID
+// to ToMessageID, inclusive, into a new conversation
+type SplitConversationRequest struct {
+	FromMessageID int64 `json:"from_message_id" validate:"required,gt=0"`
+	ToMessageID   int64 `json:"to_message_id" validate:"required,gtefield=FromMessageID"`
+}
+
+// UpdateConversationRequest changes a conversation. An empty
+// AssignedToExternalID unassigns it and a zero TeamID removes its team.
+// SnoozedUntil is written with Status and cleared for any status but
+// snoozed.
 type UpdateConversationRequest struct {
 	AssignedToExternalID *string               `json:"assigned_to_external_id,omitempty"`
-	Status               *ConversationStatus   `json:"status,omitempty" validate:"omitempty,oneof=open pending resolved closed"`
+	TeamID               *int64                `json:"team_id,omitempty"`
+	Status               *ConversationStatus   `json:"status,omitempty" validate:"omitempty,oneof=open pending snoozed resolved closed"`
+	SnoozedUntil         *time.Time            `json:"snoozed_until,omitempty"`
 	Priority             *ConversationPriority `json:"priority,omitempty" validate:"omitempty,oneof=low normal high urgent"`
 	Subject              *string               `json:"subject,omitempty" validate:"omitempty,max=200"`
 	Metadata             *string               `json:"metadata,omitempty"`
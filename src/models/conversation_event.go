@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ConversationEventType string
+
+const (
+	ConversationEventStatusChanged   ConversationEventType = "status_changed"
+	ConversationEventPriorityChanged ConversationEventType = "priority_changed"
+	ConversationEventAssigneeChanged ConversationEventType = "assignee_changed"
+	ConversationEventTeamChanged     ConversationEventType = "team_changed"
+	ConversationEventSubjectChanged  ConversationEventType = "subject_changed"
+	ConversationEventMerged          ConversationEventType = "merged"
+	ConversationEventSplit           ConversationEventType = "split"
+	ConversationEventTagAdded        ConversationEventType = "tag_added"
+	ConversationEventTagRemoved      ConversationEventType = "tag_removed"
+	ConversationEventSLAWarning      ConversationEventType = "sla_warning"
+	ConversationEventSLABreached     ConversationEventType = "sla_breached"
+)
+
+// ConversationEvent is an entry in a conversation's change history. Values
+// are stored as text; ActorID is nil for changes made by the system.
+type ConversationEvent struct {
+	ID             int64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID int64                 `json:"conversation_id" gorm:"not null;index"`
+	Type           ConversationEventType `json:"type" gorm:"not null"`
+	FromValue      *string               `json:"from_value,omitempty"`
+	ToValue        *string               `json:"to_value,omitempty"`
+	ActorID        *string               `json:"actor_id,omitempty"`
+	Reason         *string               `json:"reason,omitempty"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ActivityMetadata is stored as the metadata of the system message that
+// records a conversation change when the channel has activity messages on
+type ActivityMetadata struct {
+	EventID   int64                 `json:"event_id"`
+	EventType ConversationEventType `json:"event_type"`
+	From      *string               `json:"from,omitempty"`
+	To        *string               `json:"to,omitempty"`
+	ActorID   *string               `json:"actor_id,omitempty"`
+	Reason    *string               `json:"reason,omitempty"`
+}
+
+type TimelineEntryKind string
+
+const (
+	TimelineEntryEvent   TimelineEntryKind = "event"
+	TimelineEntryMessage TimelineEntryKind = "message"
+)
+
+// TimelineEntry is a history event or, when messages are included, a
+// message in a conversation's timeline. Exactly one of Event and Message
+// is set.
+type TimelineEntry struct {
+	Kind    TimelineEntryKind  `json:"kind"`
+	At      time.Time          `json:"at"`
+	Event   *ConversationEvent `json:"event,omitempty"`
+	Message *Message           `json:"message,omitempty"`
+}
+
+// Describe summarizes the change in a sentence for transcripts and
+// activity messages
+func (e *ConversationEvent) Describe() string {
+	value := func(v *string) string {
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+	from, to := value(e.FromValue), value(e.ToValue)
+
+	var text string
+	switch e.Type {
+	case ConversationEventStatusChanged:
+		text = fmt.Sprintf("Status changed from %s to %s", from, to)
+	case ConversationEventPriorityChanged:
+		text = fmt.Sprintf("Priority changed from %s to %s", from, to)
+	case ConversationEventAssigneeChanged:
+		text = "Unassigned"
+		if to != "" {
+			text = "Assigned to " + to
+		}
+	case ConversationEventTeamChanged:
+		text = "Removed from team"
+		if to != "" {
+			text = "Moved to team " + to
+		}
+	case ConversationEventSubjectChanged:
+		text = fmt.Sprintf("Subject changed to %q", to)
+	case ConversationEventMerged:
+		text = fmt.Sprintf("Conversation %s merged into conversation %s", from, to)
+	case ConversationEventSplit:
+		text = fmt.Sprintf("Messages split from conversation %s into conversation %s", from, to)
+	case ConversationEventTagAdded:
+		text = "Tagged " + to
+	case ConversationEventTagRemoved:
+		text = "Untagged " + from
+	case ConversationEventSLAWarning:
+		text = fmt.Sprintf("SLA %s target due soon", strings.ReplaceAll(to, "_", " "))
+	case ConversationEventSLABreached:
+		text = fmt.Sprintf("SLA %s target breached", strings.ReplaceAll(to, "_", " "))
+	default:
+		text = fmt.Sprintf("%s changed from %s to %s", e.Type, from, to)
+	}
+
+	if e.Reason != nil && *e.Reason != "" {
+		text += " (" + *e.Reason + ")"
+	}
+	return text
+}
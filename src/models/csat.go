@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSAT ratings run from CSATMinRating (very poor) to CSATMaxRating
+// (excellent)
+const (
+	CSATMinRating = 1
+	CSATMaxRating = 5
+)
+
+// DefaultCSATResponseWindow is how long a survey accepts replies when the
+// channel's policy does not say
+const DefaultCSATResponseWindow = 24 * time.Hour
+
+var csatRatingLabels = map[int]string{
+	1: "Very poor",
+	2: "Poor",
+	3: "Okay",
+	4: "Good",
+	5: "Excellent",
+}
+
+// CSATNumericPrompt is appended to the survey question on platforms
+// without quick replies
+var CSATNumericPrompt = fmt.Sprintf("Reply with a number from %d (very poor) to %d (excellent).", CSATMinRating, CSATMaxRating)
+
+type CSATStatus string
+
+// A survey is pending until the customer rates it. If the channel asks for
+// a comment it then awaits one; otherwise it is completed.
+const (
+	CSATStatusPending         CSATStatus = "pending"
+	CSATStatusAwaitingComment CSATStatus = "awaiting_comment"
+	CSATStatusCompleted       CSATStatus = "completed"
+)
+
+// CSATPolicy controls the satisfaction survey sent to the customer when one
+// of a channel's conversations is resolved
+type CSATPolicy struct {
+	ID        int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChannelID int64  `json:"channel_id" gorm:"not null;uniqueIndex"`
+	Enabled   bool   `json:"enabled" gorm:"default:false"`
+	Question  string `json:"question" gorm:"not null;type:text"`
+	// CommentPrompt, if set, is sent after the rating and the customer's
+	// next message is kept as their comment
+	CommentPrompt *string `json:"comment_prompt,omitempty" gorm:"type:text"`
+	// ResponseWindowSeconds is how long after sending replies are taken as
+	// survey answers; later ones start or reopen a conversation as usual
+	ResponseWindowSeconds int       `json:"response_window_seconds" gorm:"default:86400"`
+	CreatedAt             time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt             time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CSATSurvey is a survey sent for a resolved conversation and the
+// customer's answer. AgentID and TeamID are the conversation's assignee
+// when it was resolved.
+type CSATSurvey struct {
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID int64      `json:"conversation_id" gorm:"not null;index"`
+	ChannelID      int64      `json:"channel_id" gorm:"not null;index:idx_csat_surveys_channel_user,priority:1"`
+	ExternalUserID int64      `json:"external_user_id" gorm:"not null;index:idx_csat_surveys_channel_user,priority:2"`
+	AgentID        *string    `json:"agent_id,omitempty" gorm:"index"`
+	TeamID         *int64     `json:"team_id,omitempty"`
+	MessageID      *int64     `json:"message_id,omitempty"`
+	Status         CSATStatus `json:"status" gorm:"not null;default:pending"`
+	Rating         *int       `json:"rating,omitempty"`
+	Comment        *string    `json:"comment,omitempty" gorm:"type:text"`
+	SentAt         time.Time  `json:"sent_at" gorm:"not null;index"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	RatedAt        *time.Time `json:"rated_at,omitempty"`
+}
+
+type UpsertCSATPolicyRequest struct {
+	Enabled               bool    `json:"enabled"`
+	Question              string  `json:"question" validate:"required,min=1,max=1024"`
+	CommentPrompt         *string `json:"comment_prompt,omitempty" validate:"omitempty,max=1024"`
+	ResponseWindowSeconds int     `json:"response_window_seconds" validate:"min=0"`
+}
+
+// CSATReportQuery restricts a CSAT report to surveys of an organization
+// sent within the given range, optionally on one channel or for one agent
+type CSATReportQuery struct {
+	OrganizationID int64 `validate:"required,gt=0"`
+	ChannelID      *int64
+	AgentID        *string `validate:"omitempty,max=255"`
+	SentAfter      *time.Time
+	SentBefore     *time.Time
+}
+
+// CSATSummary aggregates survey answers. ResponseRate is the percentage of
+// surveys answered and Score the percentage of answers rated 4 or 5;
+// Ratings counts answers per rating.
+type CSATSummary struct {
+	Sent          int64         `json:"sent"`
+	Responses     int64         `json:"responses"`
+	ResponseRate  float64       `json:"response_rate"`
+	AverageRating float64       `json:"average_rating"`
+	Score         float64       `json:"score"`
+	Ratings       map[int]int64 `json:"ratings"`
+}
+
+type CSATAgentSummary struct {
+	AgentID string `json:"agent_id"`
+	CSATSummary
+}
+
+type CSATReport struct {
+	CSATSummary
+	Agents []*CSATAgentSummary `json:"agents"`
+}
+
+// CSATQuickReplyTitle is the button title offered for a rating
+func CSATQuickReplyTitle(rating int) string {
+	return fmt.Sprintf("%d - %s", rating, csatRatingLabels[rating])
+}
+
+// CSATQuickReplyPayload is the button payload sent back for a rating
+func CSATQuickReplyPayload(rating int) string {
+	return fmt.Sprintf("csat:%d", rating)
+}
+
+// ParseCSATReply reads a rating from a survey reply that is nothing but
+// the rating: a number in range, optionally out of the maximum ("4/5"), or
+// a quick-reply title or payload
+func ParseCSATReply(content string) (int, bool) {
+	text := strings.TrimSpace(content)
+	for rating := CSATMinRating; rating <= CSATMaxRating; rating++ {
+		if strings.EqualFold(text, CSATQuickReplyTitle(rating)) || text == CSATQuickReplyPayload(rating) {
+			return rating, true
+		}
+	}
+
+	text = strings.TrimSuffix(text, "/"+strconv.Itoa(CSATMaxRating))
+	if text == "" || strings.Trim(text, "0123456789") != "" {
+		return 0, false
+	}
+	rating, err := strconv.Atoi(text)
+	if err != nil || rating < CSATMinRating || rating > CSATMaxRating {
+		return 0, false
+	}
+	return rating, true
+}
+
+// SupportsQuickReplies reports whether surveys on the platform can offer
+// rating buttons instead of asking for a numeric reply
+func (p Platform) SupportsQuickReplies() bool {
+	switch p {
+	case PlatformSMS, PlatformEmail:
+		return false
+	}
+	return true
+}
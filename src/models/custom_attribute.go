@@ -0,0 +1,170 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidAttribute is returned for a custom attribute key that is not
+// defined for the organization, or a value that does not fit its type
+var ErrInvalidAttribute = errors.New("invalid custom attribute")
+
+// CustomAttributeEntity is the kind of record a custom attribute belongs to
+type CustomAttributeEntity string
+
+const (
+	AttributeEntityConversation CustomAttributeEntity = "conversation"
+	AttributeEntityContact      CustomAttributeEntity = "contact"
+)
+
+type CustomAttributeType string
+
+const (
+	AttributeTypeString  CustomAttributeType = "string"
+	AttributeTypeNumber  CustomAttributeType = "number"
+	AttributeTypeBoolean CustomAttributeType = "boolean"
+	AttributeTypeDate    CustomAttributeType = "date"
+	AttributeTypeEnum    CustomAttributeType = "enum"
+)
+
+// attributeKeyPattern keeps keys safe to embed in JSON paths and index names
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidAttributeKey reports whether key may name a custom attribute
+func ValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// CustomAttributes holds custom attribute values by key. It is stored as a
+// JSON object so that values can be queried with json_extract.
+type CustomAttributes map[string]interface{}
+
+// CustomAttributeDefinition is an organization-defined, typed attribute of
+// conversations or contacts. Enum attributes take one of Options.
+type CustomAttributeDefinition struct {
+	ID             int64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64                 `json:"organization_id" gorm:"not null;uniqueIndex:idx_custom_attributes_org_entity_key"`
+	Entity         CustomAttributeEntity `json:"entity" gorm:"not null;size:20;uniqueIndex:idx_custom_attributes_org_entity_key"`
+	Key            string                `json:"key" gorm:"not null;size:50;uniqueIndex:idx_custom_attributes_org_entity_key"`
+	Label          string                `json:"label" gorm:"not null;size:100"`
+	Type           CustomAttributeType   `json:"type" gorm:"not null;size:20"`
+	Options        []string              `json:"options,omitempty" gorm:"type:text;serializer:json"`
+	Description    *string               `json:"description,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Coerce checks a JSON-decoded value against the attribute's type and
+// returns it in stored form. Dates are stored as UTC RFC 3339 strings so
+// that they sort as text.
+func (d *CustomAttributeDefinition) Coerce(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case AttributeTypeNumber:
+		if n, ok := value.(float64); ok {
+			return n, nil
+		}
+	case AttributeTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case AttributeTypeDate:
+		if s, ok := value.(string); ok {
+			return parseAttributeDate(s)
+		}
+	case AttributeTypeEnum:
+		if s, ok := value.(string); ok && slices.Contains(d.Options, s) {
+			return s, nil
+		}
+	default:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s must be a valid %s", ErrInvalidAttribute, d.Key, d.Type)
+}
+
+// Parse converts a query string value to the attribute's stored form
+func (d *CustomAttributeDefinition) Parse(s string) (interface{}, error) {
+	switch d.Type {
+	case AttributeTypeNumber:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a valid number", ErrInvalidAttribute, d.Key)
+		}
+		return n, nil
+	case AttributeTypeBoolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a valid boolean", ErrInvalidAttribute, d.Key)
+		}
+		return b, nil
+	}
+	return d.Coerce(s)
+}
+
+// parseAttributeDate accepts RFC 3339 timestamps and plain dates
+func parseAttributeDate(s string) (string, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not an RFC 3339 date", ErrInvalidAttribute, s)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+type AttributeOp string
+
+const (
+	AttributeOpEq  AttributeOp = "eq"
+	AttributeOpNe  AttributeOp = "ne"
+	AttributeOpGt  AttributeOp = "gt"
+	AttributeOpGte AttributeOp = "gte"
+	AttributeOpLt  AttributeOp = "lt"
+	AttributeOpLte AttributeOp = "lte"
+)
+
+// AttributeFilter matches records whose attribute Key compares to Values
+// with Op. With eq, any of Values matches; other operators take one value.
+type AttributeFilter struct {
+	Key    string      `validate:"required,max=50"`
+	Op     AttributeOp `validate:"oneof=eq ne gt gte lt lte"`
+	Values []string    `validate:"required,min=1,dive,max=255"`
+}
+
+// AttributeQuery filters and optionally orders a list by custom attributes.
+// Records without the SortKey attribute sort last.
+type AttributeQuery struct {
+	Filters  []AttributeFilter `validate:"dive"`
+	SortKey  string            `validate:"max=50"`
+	SortDesc bool
+}
+
+type CreateCustomAttributeRequest struct {
+	OrganizationID int64                 `json:"organization_id" validate:"required,gt=0"`
+	Entity         CustomAttributeEntity `json:"entity" validate:"required,oneof=conversation contact"`
+	Key            string                `json:"key" validate:"required,max=50"`
+	Label          string                `json:"label" validate:"required,max=100"`
+	Type           CustomAttributeType   `json:"type" validate:"required,oneof=string number boolean date enum"`
+	Options        []string              `json:"options,omitempty" validate:"required_if=Type enum,max=100,dive,required,max=100"`
+	Description    *string               `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// UpdateCustomAttributeRequest changes an attribute's presentation. The key
+// and type are fixed once values have been written.
+type UpdateCustomAttributeRequest struct {
+	Label       *string  `json:"label,omitempty" validate:"omitempty,min=1,max=100"`
+	Options     []string `json:"options,omitempty" validate:"omitempty,max=100,dive,required,max=100"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// SetCustomAttributesRequest merges values into a record's custom
+// attributes; a null value removes the attribute
+type SetCustomAttributesRequest struct {
+	Attributes map[string]interface{} `json:"attributes" validate:"required,min=1,max=50"`
+}
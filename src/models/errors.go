@@ -0,0 +1,11 @@
+package models
+
+import "errors"
+
+// ErrNotFound is wrapped by the errors reporting that a record does not
+// exist, such as "conversation not found"
+var ErrNotFound = errors.New("not found")
+
+// ErrUserRequired is returned for an action that must be taken by an
+// authenticated agent when there is none
+var ErrUserRequired = errors.New("user required")
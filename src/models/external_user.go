@@ -3,18 +3,19 @@ package models
 import "time"
 
 type ExternalUser struct {
-	ID               int64      `json:"id" gorm:"primaryKey;autoIncrement"`
-	ChannelID        int64      `json:"channel_id" gorm:"not null;index"`
-	PlatformUserID   string     `json:"platform_user_id" gorm:"not null;index"`
-	PlatformUsername *string    `json:"platform_username,omitempty"`
-	DisplayName      *string    `json:"display_name,omitempty"`
-	PhoneNumber      *string    `json:"phone_number,omitempty" gorm:"index"`
-	Email            *string    `json:"email,omitempty"`
-	AvatarURL        *string    `json:"avatar_url,omitempty" gorm:"type:text"`
-	Metadata         *string    `json:"metadata,omitempty" gorm:"type:text"`
-	FirstSeenAt      time.Time  `json:"first_seen_at" gorm:"autoCreateTime"`
-	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
-	IsBlocked        bool       `json:"is_blocked" gorm:"default:false"`
+	ID               int64            `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChannelID        int64            `json:"channel_id" gorm:"not null;index"`
+	PlatformUserID   string           `json:"platform_user_id" gorm:"not null;index"`
+	PlatformUsername *string          `json:"platform_username,omitempty"`
+	DisplayName      *string          `json:"display_name,omitempty"`
+	PhoneNumber      *string          `json:"phone_number,omitempty" gorm:"index"`
+	Email            *string          `json:"email,omitempty"`
+	AvatarURL        *string          `json:"avatar_url,omitempty" gorm:"type:text"`
+	Metadata         *string          `json:"metadata,omitempty" gorm:"type:text"`
+	CustomAttributes CustomAttributes `json:"custom_attributes,omitempty" gorm:"type:text;serializer:json"`
+	FirstSeenAt      time.Time        `json:"first_seen_at" gorm:"autoCreateTime"`
+	LastSeenAt       *time.Time       `json:"last_seen_at,omitempty"`
+	IsBlocked        bool             `json:"is_blocked" gorm:"default:false"`
 }
 
 type CreateExternalUserRequest struct {
@@ -36,3 +37,13 @@ type UpdateExternalUserRequest struct {
 	Metadata    *string `json:"metadata,omitempty"`
 	IsBlocked   *bool   `json:"is_blocked,omitempty"`
 }
+
+// ExternalUserQuery lists an organization's contacts, optionally on one
+// channel and matching a custom attribute query
+type ExternalUserQuery struct {
+	OrganizationID int64 `validate:"required,gt=0"`
+	ChannelID      *int64
+	Attributes     *AttributeQuery
+	Limit          int
+	Offset         int
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type InboxSort string
+
+const (
+	InboxSortLastMessage InboxSort = "last_message"
+	InboxSortCreated     InboxSort = "created"
+	InboxSortPriority    InboxSort = "priority"
+)
+
+// InboxAssigneeUnassigned matches conversations without an assignee
+const InboxAssigneeUnassigned = "unassigned"
+
+// InboxTeamNone matches conversations without a team
+const InboxTeamNone = "none"
+
+// InboxQuery filters conversations across every channel of an organization.
+// Empty filters match everything, except that snoozed conversations are
+// only listed when asked for by status; list filters match any of their
+// values.
+// TeamMember matches the conversations of every team the agent belongs to.
+// With Reader set, each conversation carries the agent's unread count, and
+// HasUnread matches on it.
+type InboxQuery struct {
+	OrganizationID    int64                  `validate:"required,gt=0"`
+	Statuses          []ConversationStatus   `validate:"dive,oneof=open pending snoozed resolved closed"`
+	Priorities        []ConversationPriority `validate:"dive,oneof=low normal high urgent"`
+	Assignee          string                 `validate:"max=255"`
+	Teams             []int64                `validate:"dive,gt=0"`
+	TeamMember        string                 `validate:"max=255"`
+	Platforms         []Platform             `validate:"dive,oneof=whatsapp telegram instagram facebook sms email web"`
+	Tags              []string               `validate:"dive,min=1,max=50"`
+	SLAStatuses       []SLAStatus            `validate:"dive,oneof=ok warning breached"`
+	NoTeam            bool
+	CreatedAfter      *time.Time
+	CreatedBefore     *time.Time
+	LastMessageAfter  *time.Time
+	LastMessageBefore *time.Time
+	HasUnread         *bool
+	Attributes        []AttributeFilter `validate:"dive"`
+	Reader            string
+	Sort              InboxSort `validate:"omitempty,oneof=last_message created priority"`
+	Cursor            string
+	Limit             int
+}
+
+// InboxPage is one page of inbox results. NextCursor is nil on the last page.
+type InboxPage struct {
+	Data       []*Conversation `json:"data"`
+	NextCursor *string         `json:"next_cursor"`
+	Total      int64           `json:"total"`
+	Limit      int             `json:"limit"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Status change reasons recorded by the lifecycle policy and when snoozed
+// conversations wake up
+const (
+	StatusReasonCustomerReply = "customer_reply"
+	StatusReasonIdle          = "idle"
+	StatusReasonSnoozeExpired = "snooze_expired"
+)
+
+// LifecyclePolicy controls when a channel's resolved conversations are
+// reopened and when idle ones are resolved or closed
+type LifecyclePolicy struct {
+	ID        int64 `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChannelID int64 `json:"channel_id" gorm:"not null;uniqueIndex"`
+	// ReopenWithinSeconds reopens the customer's last conversation when they
+	// write within this many seconds of its resolution; 0 always starts a
+	// new conversation
+	ReopenWithinSeconds int `json:"reopen_within_seconds" gorm:"default:0"`
+	// IdleAfterSeconds moves open and pending conversations without
+	// messages for this many seconds to IdleStatus; 0 disables it
+	IdleAfterSeconds int                `json:"idle_after_seconds" gorm:"default:0"`
+	IdleStatus       ConversationStatus `json:"idle_status" gorm:"not null;default:resolved"`
+	// ClosingMessage is sent to the customer when a conversation is closed
+	// for inactivity
+	ClosingMessage *string   `json:"closing_message,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type UpsertLifecyclePolicyRequest struct {
+	ReopenWithinSeconds int                `json:"reopen_within_seconds" validate:"min=0"`
+	IdleAfterSeconds    int                `json:"idle_after_seconds" validate:"min=0"`
+	IdleStatus          ConversationStatus `json:"idle_status,omitempty" validate:"omitempty,oneof=resolved closed"`
+	ClosingMessage      *string            `json:"closing_message,omitempty" validate:"omitempty,max=4096"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidMacro is returned for a macro action that is missing what it
+// needs, or one that cannot be applied to the conversation
+var ErrInvalidMacro = errors.New("invalid macro")
+
+type MacroActionType string
+
+const (
+	MacroActionSendReply   MacroActionType = "send_reply"
+	MacroActionAddTags     MacroActionType = "add_tags"
+	MacroActionRemoveTags  MacroActionType = "remove_tags"
+	MacroActionSetPriority MacroActionType = "set_priority"
+	MacroActionSetStatus   MacroActionType = "set_status"
+)
+
+// MacroAction is one step of a macro. A reply is sent with Content, or
+// with a canned response; either may hold placeholders.
+type MacroAction struct {
+	Type             MacroActionType       `json:"type" validate:"required,oneof=send_reply add_tags remove_tags set_priority set_status"`
+	Content          *string               `json:"content,omitempty" validate:"omitempty,min=1"`
+	CannedResponseID *int64                `json:"canned_response_id,omitempty" validate:"omitempty,gt=0"`
+	Tags             []string              `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	Priority         *ConversationPriority `json:"priority,omitempty" validate:"omitempty,oneof=low normal high urgent"`
+	Status           *ConversationStatus   `json:"status,omitempty" validate:"omitempty,oneof=open pending resolved closed"`
+}
+
+// Check reports a missing argument of the action as ErrInvalidMacro
+func (a *MacroAction) Check() error {
+	var missing string
+	switch a.Type {
+	case MacroActionSendReply:
+		if a.Content == nil && a.CannedResponseID == nil {
+			missing = "content or canned_response_id"
+		}
+	case MacroActionAddTags, MacroActionRemoveTags:
+		if len(a.Tags) == 0 {
+			missing = "tags"
+		}
+	case MacroActionSetPriority:
+		if a.Priority == nil {
+			missing = "priority"
+		}
+	case MacroActionSetStatus:
+		if a.Status == nil {
+			missing = "status"
+		}
+	}
+	if missing != "" {
+		return fmt.Errorf("%w: %s requires %s", ErrInvalidMacro, a.Type, missing)
+	}
+	return nil
+}
+
+// Macro is a named sequence of actions agents run against a conversation
+// in one step. Macros without a team are available to the whole
+// organization.
+type Macro struct {
+	ID             int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64         `json:"organization_id" gorm:"not null;uniqueIndex:idx_macros_org_name"`
+	TeamID         *int64        `json:"team_id,omitempty" gorm:"index"`
+	Name           string        `json:"name" gorm:"not null;size:100;uniqueIndex:idx_macros_org_name"`
+	Description    *string       `json:"description,omitempty" gorm:"type:text"`
+	Actions        []MacroAction `json:"actions" gorm:"type:text;serializer:json"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// MacroRun records who ran a macro on which conversation, with the actions
+// as they were at the time. Error is set when the run was rejected or
+// failed.
+type MacroRun struct {
+	ID             int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	MacroID        int64         `json:"macro_id" gorm:"not null;index"`
+	ConversationID int64         `json:"conversation_id" gorm:"not null;index"`
+	ActorID        *string       `json:"actor_id,omitempty"`
+	Actions        []MacroAction `json:"actions" gorm:"type:text;serializer:json"`
+	Error          *string       `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// MacroStep is a macro action checked against the conversation it runs
+// on, with the reply it sends rendered and the tags it changes resolved.
+// Applying the step sets what it changed: the reply Message, the names of
+// the Changed tags, or the Previous priority or status. A step that
+// changed nothing is left as it was.
+type MacroStep struct {
+	Action   MacroAction
+	Reply    string
+	Tags     []*Tag
+	Message  *Message
+	Changed  []string
+	Previous *string
+}
+
+type CreateMacroRequest struct {
+	OrganizationID int64         `json:"organization_id" validate:"required,gt=0"`
+	TeamID         *int64        `json:"team_id,omitempty" validate:"omitempty,gt=0"`
+	Name           string        `json:"name" validate:"required,min=1,max=100"`
+	Description    *string       `json:"description,omitempty" validate:"omitempty,max=500"`
+	Actions        []MacroAction `json:"actions" validate:"required,min=1,max=20,dive"`
+}
+
+// UpdateMacroRequest changes a macro; Actions, when set, replace its
+// actions. A zero TeamID makes it available to the whole organization.
+type UpdateMacroRequest struct {
+	TeamID      *int64        `json:"team_id,omitempty" validate:"omitempty,min=0"`
+	Name        *string       `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string       `json:"description,omitempty" validate:"omitempty,max=500"`
+	Actions     []MacroAction `json:"actions,omitempty" validate:"omitempty,min=1,max=20,dive"`
+}
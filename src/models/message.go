@@ -1,6 +1,17 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrMessageNotEditable is returned for a message agents may not edit or
+// delete, or one its platform no longer lets be edited
+var ErrMessageNotEditable = errors.New("message cannot be changed")
+
+// ErrInvalidReply is returned for a reply to a message that is not one of
+// the conversation's, or that cannot be replied to
+var ErrInvalidReply = errors.New("invalid reply")
 
 type MessageSenderType string
 
@@ -22,6 +33,9 @@ const (
 	MessageTypeContact  MessageType = "contact"
 	MessageTypeSticker  MessageType = "sticker"
 	MessageTypeSystem   MessageType = "system"
+	// MessageTypeNote is an internal note between agents. Notes are never
+	// delivered to the customer.
+	MessageTypeNote MessageType = "note"
 )
 
 type MessageDirection string
@@ -43,19 +57,33 @@ const (
 
 type Message struct {
 	ID                int64             `json:"id" gorm:"primaryKey;autoIncrement"`
-	ConversationID    int64             `json:"conversation_id" gorm:"not null;index:idx_conv_created"`
+	ConversationID    int64             `json:"conversation_id" gorm:"not null;index:idx_conv_created;index:idx_messages_conv_unread,priority:1"`
 	PlatformMessageID *string           `json:"platform_message_id,omitempty" gorm:"index"`
+	ReplyToMessageID  *int64            `json:"reply_to_message_id,omitempty" gorm:"index"`
 	SenderType        MessageSenderType `json:"sender_type" gorm:"not null"`
 	SenderID          *int64            `json:"sender_id,omitempty"`
 	Content           string            `json:"content" gorm:"not null;type:text"`
 	MessageType       MessageType       `json:"message_type" gorm:"default:text"`
 	MediaURL          *string           `json:"media_url,omitempty" gorm:"type:text"`
-	Direction         MessageDirection  `json:"direction" gorm:"not null"`
-	Status            MessageStatus     `json:"status" gorm:"default:received"`
+	Direction         MessageDirection  `json:"direction" gorm:"not null;index:idx_messages_conv_unread,priority:2"`
+	Status            MessageStatus     `json:"status" gorm:"default:received;index:idx_messages_conv_unread,priority:3"`
 	CreatedAt         time.Time         `json:"created_at" gorm:"autoCreateTime;index:idx_created,idx_conv_created"`
 	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
 	ReadAt            *time.Time        `json:"read_at,omitempty"`
 	Metadata          *string           `json:"metadata,omitempty" gorm:"type:text"`
+	// AuthorID is the agent who wrote a note, as the JWT user ID
+	AuthorID  *string    `json:"author_id,omitempty"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// MessageEdit is the content of a message before one of its edits
+type MessageEdit struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID int64     `json:"message_id" gorm:"not null;index"`
+	Content   string    `json:"content" gorm:"not null;type:text"`
+	EditedBy  *string   `json:"edited_by,omitempty"`
+	EditedAt  time.Time `json:"edited_at" gorm:"not null"`
 }
 
 type CreateMessageRequest struct {
@@ -67,6 +95,22 @@ type CreateMessageRequest struct {
 	Metadata       *string     `json:"metadata,omitempty"`
 }
 
+// SendMessageRequest is the body of an agent reply, shared by the HTTP
+// handler and the command bus
+type SendMessageRequest struct {
+	Content     string      `json:"content" validate:"required"`
+	MessageType MessageType `json:"message_type" validate:"omitempty,oneof=text image video audio file location contact sticker"`
+	MediaURL    *string     `json:"media_url,omitempty"`
+	Metadata    *string     `json:"metadata,omitempty"`
+	// ReplyTo is the message of the conversation this one replies to
+	ReplyTo *int64 `json:"reply_to_message_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+// EditMessageRequest replaces the content of an agent's message
+type EditMessageRequest struct {
+	Content string `json:"content" validate:"required,min=1"`
+}
+
 type InboundMessageRequest struct {
 	ChannelID         int64       `validate:"required,gt=0"`
 	PlatformMessageID *string     `validate:"required"`
@@ -77,9 +121,13 @@ type InboundMessageRequest struct {
 	Metadata          *string
 }
 
+// MessageListQuery pages through a conversation's messages, newest first.
+// HideSystem leaves out the system messages that record conversation
+// activity.
 type MessageListQuery struct {
 	ConversationID int64  `query:"conversation_id" validate:"required,gt=0"`
 	Limit          int    `query:"limit" validate:"omitempty,min=1,max=100"`
 	Offset         int    `query:"offset" validate:"omitempty,min=0"`
 	Before         *int64 `query:"before"`
+	HideSystem     bool   `query:"hide_system"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "errors"
+
+// ErrNotNoteAuthor is returned when someone other than its author edits or
+// deletes a note
+var ErrNotNoteAuthor = errors.New("only the author may change a note")
+
+// CreateNoteRequest leaves an internal note on a conversation. Agents
+// mentioned as @external_id are notified.
+type CreateNoteRequest struct {
+	Content string `json:"content" validate:"required,min=1"`
+}
+
+type UpdateNoteRequest struct {
+	Content string `json:"content" validate:"required,min=1"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidParticipant is returned when adding a user from another channel
+// to a conversation
+var ErrInvalidParticipant = errors.New("invalid participant")
+
+type ParticipantRole string
+
+// The requester started the conversation. Members write in the same group
+// chat, and CCs were copied on an email thread.
+const (
+	ParticipantRoleRequester ParticipantRole = "requester"
+	ParticipantRoleMember    ParticipantRole = "member"
+	ParticipantRoleCC        ParticipantRole = "cc"
+)
+
+// Reasons a participant joined or left a conversation: they wrote in it,
+// the platform reported it, or an agent added or removed them
+const (
+	ParticipantReasonMessage  = "message"
+	ParticipantReasonPlatform = "platform"
+	ParticipantReasonAgent    = "agent"
+)
+
+// ConversationParticipant is an external user taking part in a
+// conversation. LeftAt is set while they are out of it; rejoining clears it
+// and moves JoinedAt.
+type ConversationParticipant struct {
+	ID             int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID int64           `json:"conversation_id" gorm:"not null;uniqueIndex:idx_participants_conv_user"`
+	ExternalUserID int64           `json:"external_user_id" gorm:"not null;uniqueIndex:idx_participants_conv_user;index"`
+	Role           ParticipantRole `json:"role" gorm:"not null;default:member"`
+	JoinedAt       time.Time       `json:"joined_at" gorm:"not null"`
+	LeftAt         *time.Time      `json:"left_at,omitempty"`
+}
+
+type AddParticipantRequest struct {
+	ExternalUserID int64           `json:"external_user_id" validate:"required,gt=0"`
+	Role           ParticipantRole `json:"role" validate:"omitempty,oneof=member cc"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCannotReact is returned for a reaction to a note, an activity message
+// or a deleted message
+var ErrCannotReact = errors.New("message cannot be reacted to")
+
+// MessageReaction is an emoji reaction to a message. A sender reacts with
+// each emoji at most once. SenderID is the platform user ID of a customer,
+// or the JWT user ID of an agent.
+type MessageReaction struct {
+	ID         int64             `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID  int64             `json:"message_id" gorm:"not null;uniqueIndex:idx_reactions_sender_emoji"`
+	SenderType MessageSenderType `json:"sender_type" gorm:"not null;uniqueIndex:idx_reactions_sender_emoji"`
+	SenderID   string            `json:"sender_id" gorm:"not null;uniqueIndex:idx_reactions_sender_emoji"`
+	Emoji      string            `json:"emoji" gorm:"not null;uniqueIndex:idx_reactions_sender_emoji"`
+	CreatedAt  time.Time         `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ReactRequest is an agent's reaction to a message
+type ReactRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=32"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ReadCursor records the last message an agent has seen in a conversation.
+// Inbound messages after it are unread for that agent.
+type ReadCursor struct {
+	ID                int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID    int64     `json:"conversation_id" gorm:"not null;uniqueIndex:idx_read_cursors_conv_agent"`
+	AgentID           string    `json:"agent_id" gorm:"not null;uniqueIndex:idx_read_cursors_conv_agent;index"`
+	LastReadMessageID int64     `json:"last_read_message_id" gorm:"not null"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type MarkConversationReadRequest struct {
+	MessageID int64 `json:"message_id" validate:"required,gt=0"`
+}
+
+// UnreadTotals sums an agent's unread inbound messages over the open and
+// pending conversations assigned to them
+type UnreadTotals struct {
+	AgentID       string `json:"agent_id"`
+	Messages      int64  `json:"unread_messages"`
+	Conversations int64  `json:"unread_conversations"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type RoutingStrategy string
+
+const (
+	// RoutingRoundRobin picks the agent who was assigned least recently
+	RoutingRoundRobin RoutingStrategy = "round_robin"
+	// RoutingLeastOpen picks the agent with the fewest open conversations
+	RoutingLeastOpen RoutingStrategy = "least_open"
+	// RoutingCapacity picks the agent with the most free capacity relative
+	// to their max concurrent conversations
+	RoutingCapacity RoutingStrategy = "capacity"
+	// RoutingSticky prefers the agent who handled the customer last,
+	// falling back to least_open
+	RoutingSticky RoutingStrategy = "sticky"
+)
+
+// Assignment reasons reported on chat.conversation.assigned
+const (
+	AssignReasonManual       = "manual"
+	AssignReasonNew          = "new_conversation"
+	AssignReasonTimeout      = "timeout"
+	AssignReasonAgentOffline = "agent_offline"
+)
+
+// RoutingPolicy configures automatic assignment for a channel's conversations
+type RoutingPolicy struct {
+	ID        int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChannelID int64           `json:"channel_id" gorm:"not null;uniqueIndex"`
+	Strategy  RoutingStrategy `json:"strategy" gorm:"not null"`
+	// ReassignAfterSeconds reassigns a conversation whose agent has not
+	// replied within this many seconds of assignment; 0 disables it
+	ReassignAfterSeconds int       `json:"reassign_after_seconds" gorm:"default:0"`
+	Enabled              bool      `json:"enabled"`
+	CreatedAt            time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type UpsertRoutingPolicyRequest struct {
+	Strategy             RoutingStrategy `json:"strategy" validate:"required,oneof=round_robin least_open capacity sticky"`
+	ReassignAfterSeconds int             `json:"reassign_after_seconds" validate:"min=0"`
+	Enabled              *bool           `json:"enabled,omitempty"`
+}
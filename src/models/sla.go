@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type SLAStatus string
+
+const (
+	SLAStatusOK       SLAStatus = "ok"
+	SLAStatusWarning  SLAStatus = "warning"
+	SLAStatusBreached SLAStatus = "breached"
+)
+
+// SLA targets tracked per conversation
+const (
+	SLATargetFirstResponse = "first_response"
+	SLATargetNextResponse  = "next_response"
+	SLATargetResolution    = "resolution"
+)
+
+// SLA alert kinds
+const (
+	SLAAlertWarning  = "warning"
+	SLAAlertBreached = "breached"
+)
+
+// SLAPolicy sets response and resolution targets for an organization's
+// conversations. A policy with ChannelID or Priority set only applies to
+// matching conversations; the most specific enabled policy wins. Targets of
+// 0 are not tracked.
+type SLAPolicy struct {
+	ID                   int64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID       int64                 `json:"organization_id" gorm:"not null;index"`
+	Name                 string                `json:"name" gorm:"not null;size:100"`
+	ChannelID            *int64                `json:"channel_id,omitempty"`
+	Priority             *ConversationPriority `json:"priority,omitempty"`
+	FirstResponseSeconds int                   `json:"first_response_seconds" gorm:"default:0"`
+	NextResponseSeconds  int                   `json:"next_response_seconds" gorm:"default:0"`
+	ResolutionSeconds    int                   `json:"resolution_seconds" gorm:"default:0"`
+	// WarnBeforeSeconds is how long before a due time the warning is sent
+	WarnBeforeSeconds int `json:"warn_before_seconds" gorm:"default:0"`
+	// BusinessHoursOnly counts only time within the channel's or
+	// organization's business hours
+	BusinessHoursOnly bool      `json:"business_hours_only"`
+	Enabled           bool      `json:"enabled"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SLAAlert records a warning or breach that has been emitted, so each is
+// sent once per due time
+type SLAAlert struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID int64     `json:"conversation_id" gorm:"not null;uniqueIndex:idx_sla_alerts_unique"`
+	Target         string    `json:"target" gorm:"not null;uniqueIndex:idx_sla_alerts_unique"`
+	Kind           string    `json:"kind" gorm:"not null;uniqueIndex:idx_sla_alerts_unique"`
+	DueAt          time.Time `json:"due_at" gorm:"not null;uniqueIndex:idx_sla_alerts_unique"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateSLAPolicyRequest struct {
+	OrganizationID       int64                 `json:"organization_id" validate:"required,gt=0"`
+	Name                 string                `json:"name" validate:"required,min=1,max=100"`
+	ChannelID            *int64                `json:"channel_id,omitempty" validate:"omitempty,gt=0"`
+	Priority             *ConversationPriority `json:"priority,omitempty" validate:"omitempty,oneof=low normal high urgent"`
+	FirstResponseSeconds int                   `json:"first_response_seconds" validate:"min=0"`
+	NextResponseSeconds  int                   `json:"next_response_seconds" validate:"min=0"`
+	ResolutionSeconds    int                   `json:"resolution_seconds" validate:"min=0"`
+	WarnBeforeSeconds    int                   `json:"warn_before_seconds" validate:"min=0"`
+	BusinessHoursOnly    bool                  `json:"business_hours_only"`
+	Enabled              *bool                 `json:"enabled,omitempty"`
+}
+
+type UpdateSLAPolicyRequest struct {
+	Name                 *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	FirstResponseSeconds *int    `json:"first_response_seconds,omitempty" validate:"omitempty,min=0"`
+	NextResponseSeconds  *int    `json:"next_response_seconds,omitempty" validate:"omitempty,min=0"`
+	ResolutionSeconds    *int    `json:"resolution_seconds,omitempty" validate:"omitempty,min=0"`
+	WarnBeforeSeconds    *int    `json:"warn_before_seconds,omitempty" validate:"omitempty,min=0"`
+	BusinessHoursOnly    *bool   `json:"business_hours_only,omitempty"`
+	Enabled              *bool   `json:"enabled,omitempty"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrUnknownTag is returned when a tag name is not in the organization's
+// taxonomy
+var ErrUnknownTag = errors.New("unknown tag")
+
+// Tag is an organization-defined label for conversations. Names are stored
+// normalized by NormalizeTagName so that "Billing " and "billing" are the
+// same tag.
+type Tag struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64     `json:"organization_id" gorm:"not null;uniqueIndex:idx_tags_org_name"`
+	Name           string    `json:"name" gorm:"not null;size:50;uniqueIndex:idx_tags_org_name"`
+	Color          *string   `json:"color,omitempty" gorm:"size:7"`
+	Description    *string   `json:"description,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ConversationTag links a tag to a conversation
+type ConversationTag struct {
+	ConversationID int64     `json:"conversation_id" gorm:"primaryKey"`
+	TagID          int64     `json:"tag_id" gorm:"primaryKey;index"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TagCount is the number of conversations carrying a tag
+type TagCount struct {
+	TagID         int64   `json:"tag_id"`
+	Name          string  `json:"name"`
+	Color         *string `json:"color,omitempty"`
+	Conversations int64   `json:"conversations"`
+}
+
+// TagCountQuery restricts tag counts to conversations with one of Statuses,
+// created within the given range
+type TagCountQuery struct {
+	OrganizationID int64                `validate:"required,gt=0"`
+	Statuses       []ConversationStatus `validate:"dive,oneof=open pending snoozed resolved closed"`
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+}
+
+type CreateTagRequest struct {
+	OrganizationID int64   `json:"organization_id" validate:"required,gt=0"`
+	Name           string  `json:"name" validate:"required,min=1,max=50"`
+	Color          *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Description    *string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+type UpdateTagRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=50"`
+	Color       *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// ConversationTagsRequest adds or removes tags on one conversation
+type ConversationTagsRequest struct {
+	Tags []string `json:"tags" validate:"required,min=1,max=20,dive,required,max=50"`
+}
+
+// BulkConversationTagsRequest adds or removes tags on several conversations
+type BulkConversationTagsRequest struct {
+	ConversationIDs []int64  `json:"conversation_ids" validate:"required,min=1,max=100,dive,gt=0"`
+	Tags            []string `json:"tags" validate:"required,min=1,max=20,dive,required,max=50"`
+}
+
+// NormalizeTagName trims and lowercases a tag name
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
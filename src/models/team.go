@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Team is a named group of agents, e.g. "billing" or "tier 2", that
+// conversations can be assigned to as a queue
+type Team struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int64     `json:"organization_id" gorm:"not null;uniqueIndex:idx_teams_org_name"`
+	Name           string    `json:"name" gorm:"not null;size:100;uniqueIndex:idx_teams_org_name"`
+	Description    *string   `json:"description,omitempty" gorm:"type:text"`
+	MemberIDs      []string  `json:"member_ids" gorm:"-"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TeamMember links an agent, by the external ID used as assignee, to a team
+type TeamMember struct {
+	TeamID    int64     `json:"team_id" gorm:"primaryKey"`
+	AgentID   string    `json:"agent_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type CreateTeamRequest struct {
+	OrganizationID int64    `json:"organization_id" validate:"required,gt=0"`
+	Name           string   `json:"name" validate:"required,min=1,max=100"`
+	Description    *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	MemberIDs      []string `json:"member_ids" validate:"dive,required,max=255"`
+}
+
+type UpdateTeamRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+// SetTeamMembersRequest replaces a team's members
+type SetTeamMembersRequest struct {
+	MemberIDs []string `json:"member_ids" validate:"dive,required,max=255"`
+}
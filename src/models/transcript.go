@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTranscriptUndeliverable is returned when a transcript cannot be
+// emailed because the organization has no active email channel or the
+// customer has no email address
+var ErrTranscriptUndeliverable = errors.New("transcript undeliverable")
+
+type TranscriptFormat string
+
+const (
+	TranscriptFormatJSON     TranscriptFormat = "json"
+	TranscriptFormatText     TranscriptFormat = "text"
+	TranscriptFormatMarkdown TranscriptFormat = "markdown"
+	TranscriptFormatHTML     TranscriptFormat = "html"
+)
+
+// TranscriptOptions controls how a transcript is rendered. Timestamps are
+// shown in Location, UTC if nil. With RedactNotes, internal notes are kept
+// in place but their content is withheld; with ExcludeNotes they are left
+// out altogether.
+type TranscriptOptions struct {
+	Format       TranscriptFormat `validate:"omitempty,oneof=json text markdown html"`
+	Location     *time.Location
+	RedactNotes  bool
+	ExcludeNotes bool
+}
+
+// Transcript is the full record of a conversation: every message with its
+// attachment link and every change to the conversation, oldest first
+type Transcript struct {
+	ConversationID int64              `json:"conversation_id"`
+	Subject        *string            `json:"subject,omitempty"`
+	Channel        string             `json:"channel"`
+	Platform       Platform           `json:"platform"`
+	Customer       string             `json:"customer"`
+	Status         ConversationStatus `json:"status"`
+	Timezone       string             `json:"timezone"`
+	GeneratedAt    time.Time          `json:"generated_at"`
+	Entries        []*TranscriptEntry `json:"entries"`
+}
+
+// TranscriptEntry is a message or a change in a transcript. Text is the
+// message content, or a description of the change.
+type TranscriptEntry struct {
+	Kind          TimelineEntryKind      `json:"kind"`
+	At            time.Time              `json:"at"`
+	Author        string                 `json:"author"`
+	MessageID     *int64                 `json:"message_id,omitempty"`
+	Direction     *MessageDirection      `json:"direction,omitempty"`
+	MessageType   *MessageType           `json:"message_type,omitempty"`
+	EventType     *ConversationEventType `json:"event_type,omitempty"`
+	Text          string                 `json:"text"`
+	AttachmentURL *string                `json:"attachment_url,omitempty"`
+	Redacted      bool                   `json:"redacted,omitempty"`
+}
+
+// EmailTranscriptRequest emails a transcript to To, or to the customer's
+// address if it is not set. Internal notes are always left out.
+type EmailTranscriptRequest struct {
+	To       *string          `json:"to,omitempty" validate:"omitempty,email"`
+	Format   TranscriptFormat `json:"format,omitempty" validate:"omitempty,oneof=text markdown html"`
+	Timezone string           `json:"timezone,omitempty" validate:"max=64"`
+}
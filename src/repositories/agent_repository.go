@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type AgentRepository interface {
+	Create(req *models.CreateAgentRequest) (*models.Agent, error)
+	GetByID(id int64) (*models.Agent, error)
+	GetByExternalID(orgID int64, externalID string) (*models.Agent, error)
+	ListByOrganization(orgID int64, limit, offset int) ([]*models.Agent, error)
+	Update(id int64, req *models.UpdateAgentRequest) error
+	UpdateStatus(id int64, status models.AgentStatus) error
+	// ListAvailable returns the organization's online agents with their
+	// open conversation counts
+	ListAvailable(orgID int64) ([]*models.AgentLoad, error)
+	MarkAssigned(id int64) error
+}
+
+type agentRepository struct {
+	db *gorm.DB
+}
+
+func NewAgentRepository(db *gorm.DB) AgentRepository {
+	return &agentRepository{db: db}
+}
+
+func (r *agentRepository) Create(req *models.CreateAgentRequest) (*models.Agent, error) {
+	status := req.Status
+	if status == "" {
+		status = models.AgentStatusOffline
+	}
+
+	agent := &models.Agent{
+		OrganizationID: req.OrganizationID,
+		ExternalID:     req.ExternalID,
+		Name:           req.Name,
+		Status:         status,
+		MaxConcurrent:  req.MaxConcurrent,
+	}
+
+	if err := r.db.Create(agent).Error; err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	return agent, nil
+}
+
+func (r *agentRepository) GetByID(id int64) (*models.Agent, error) {
+	var agent models.Agent
+	if err := r.db.First(&agent, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("agent %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *agentRepository) GetByExternalID(orgID int64, externalID string) (*models.Agent, error) {
+	var agent models.Agent
+	if err := r.db.Where("organization_id = ? AND external_id = ?", orgID, externalID).First(&agent).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("agent %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *agentRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Agent, error) {
+	var agents []*models.Agent
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&agents).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+
+	return agents, nil
+}
+
+func (r *agentRepository) Update(id int64, req *models.UpdateAgentRequest) error {
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.MaxConcurrent != nil {
+		updates["max_concurrent"] = *req.MaxConcurrent
+	}
+
+	result := r.db.Model(&models.Agent{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update agent: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("agent %w", models.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *agentRepository) UpdateStatus(id int64, status models.AgentStatus) error {
+	result := r.db.Model(&models.Agent{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update agent status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("agent %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *agentRepository) ListAvailable(orgID int64) ([]*models.AgentLoad, error) {
+	var loads []*models.AgentLoad
+	err := r.db.Model(&models.Agent{}).
+		Select(`agents.*, (
+			SELECT COUNT(*) FROM conversations
+			JOIN chat_channels ON chat_channels.id = conversations.channel_id
+			WHERE chat_channels.organization_id = agents.organization_id
+				AND conversations.assigned_to_external_id = agents.external_id
+				AND conversations.status IN ?
+		) AS open_conversations`,
+			[]models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+		Where("agents.organization_id = ? AND agents.status = ?", orgID, models.AgentStatusOnline).
+		Order("agents.id").
+		Scan(&loads).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available agents: %w", err)
+	}
+
+	return loads, nil
+}
+
+func (r *agentRepository) MarkAssigned(id int64) error {
+	return r.db.Model(&models.Agent{}).Where("id = ?", id).
+		Update("last_assigned_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
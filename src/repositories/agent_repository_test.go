@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentRepository_Create(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewAgentRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+
+	agent, err := repo.Create(&models.CreateAgentRequest{OrganizationID: org.ID, ExternalID: "agent-1", Name: "Alice"})
+	require.NoError(t, err)
+	assert.NotZero(t, agent.ID)
+	assert.Equal(t, models.AgentStatusOffline, agent.Status)
+
+	t.Run("external id is unique per organization", func(t *testing.T) {
+		_, err := repo.Create(&models.CreateAgentRequest{OrganizationID: org.ID, ExternalID: "agent-1", Name: "Again"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAgentRepository_ListAvailable(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewAgentRepository(db)
+	convRepo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	online, err := repo.Create(&models.CreateAgentRequest{OrganizationID: org.ID, ExternalID: "online", Name: "On", Status: models.AgentStatusOnline})
+	require.NoError(t, err)
+	_, err = repo.Create(&models.CreateAgentRequest{OrganizationID: org.ID, ExternalID: "offline", Name: "Off"})
+	require.NoError(t, err)
+
+	assignee := "online"
+	resolved := models.ConversationStatusResolved
+	for i := 0; i < 3; i++ {
+		conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+		require.NoError(t, convRepo.Update(conv.ID, &models.UpdateConversationRequest{AssignedToExternalID: &assignee}))
+		if i == 0 {
+			require.NoError(t, convRepo.Update(conv.ID, &models.UpdateConversationRequest{Status: &resolved}))
+		}
+	}
+
+	loads, err := repo.ListAvailable(org.ID)
+	require.NoError(t, err)
+	require.Len(t, loads, 1)
+	assert.Equal(t, online.ID, loads[0].ID)
+	assert.Equal(t, 2, loads[0].OpenConversations)
+
+	require.NoError(t, repo.MarkAssigned(online.ID))
+	got, err := repo.GetByID(online.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.LastAssignedAt)
+}
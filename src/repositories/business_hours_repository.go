@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BusinessHoursRepository interface {
+	// Find returns the schedule defined for exactly this scope, where
+	// channelID 0 is the organization's own schedule, or nil if none is
+	Find(orgID, channelID int64) (*models.BusinessHours, error)
+	// Resolve returns the schedule in effect for a channel: its own, else
+	// its organization's, else nil
+	Resolve(orgID, channelID int64) (*models.BusinessHours, error)
+	Upsert(orgID, channelID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error)
+}
+
+type businessHoursRepository struct {
+	db *gorm.DB
+}
+
+func NewBusinessHoursRepository(db *gorm.DB) BusinessHoursRepository {
+	return &businessHoursRepository{db: db}
+}
+
+func (r *businessHoursRepository) Find(orgID, channelID int64) (*models.BusinessHours, error) {
+	var hours models.BusinessHours
+	err := r.db.Where("organization_id = ? AND channel_id = ?", orgID, channelID).First(&hours).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business hours: %w", err)
+	}
+	return &hours, nil
+}
+
+func (r *businessHoursRepository) Resolve(orgID, channelID int64) (*models.BusinessHours, error) {
+	var hours models.BusinessHours
+	err := r.db.Where("organization_id = ? AND channel_id IN ?", orgID, []int64{channelID, 0}).
+		Order("channel_id DESC").
+		First(&hours).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business hours: %w", err)
+	}
+	return &hours, nil
+}
+
+func (r *businessHoursRepository) Upsert(orgID, channelID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error) {
+	holidays := req.Holidays
+	if holidays == nil {
+		holidays = []models.BusinessHoliday{}
+	}
+
+	hours := &models.BusinessHours{
+		OrganizationID:      orgID,
+		ChannelID:           channelID,
+		Timezone:            req.Timezone,
+		Windows:             req.Windows,
+		Holidays:            holidays,
+		AwayMessage:         req.AwayMessage,
+		AwayCooldownSeconds: req.AwayCooldownSeconds,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "channel_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "windows", "holidays", "away_message", "away_cooldown_seconds", "updated_at"}),
+	}).Create(hours).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save business hours: %w", err)
+	}
+
+	return r.Find(orgID, channelID)
+}
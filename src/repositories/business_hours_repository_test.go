@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessHoursRepository_UpsertAndResolve(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewBusinessHoursRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	wa := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	tg := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	sms := testutils.CreateTestChannel(t, db, org.ID, models.PlatformSMS, "SMS")
+
+	hours, err := repo.Resolve(org.ID, wa.ID)
+	require.NoError(t, err)
+	assert.Nil(t, hours)
+
+	orgHours, err := repo.Upsert(org.ID, 0, &models.SetBusinessHoursRequest{
+		Timezone: "UTC",
+		Windows:  []models.BusinessHoursWindow{{Day: "mon", Start: "09:00", End: "17:00"}},
+	})
+	require.NoError(t, err)
+
+	updated, err := repo.Upsert(org.ID, 0, &models.SetBusinessHoursRequest{
+		Timezone: "Asia/Kathmandu",
+		Windows: []models.BusinessHoursWindow{
+			{Day: "mon", Start: "10:00", End: "18:00"},
+			{Day: "tue", Start: "10:00", End: "18:00"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, orgHours.ID, updated.ID)
+
+	_, err = repo.Upsert(org.ID, wa.ID, &models.SetBusinessHoursRequest{
+		Timezone: "UTC",
+		Windows:  []models.BusinessHoursWindow{{Day: "sat", Start: "00:00", End: "24:00"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("channel schedule", func(t *testing.T) {
+		hours, err := repo.Resolve(org.ID, wa.ID)
+		require.NoError(t, err)
+		require.NotNil(t, hours)
+		assert.Equal(t, wa.ID, hours.ChannelID)
+		assert.Equal(t, "sat", hours.Windows[0].Day)
+	})
+
+	t.Run("organization fallback", func(t *testing.T) {
+		hours, err := repo.Resolve(org.ID, tg.ID)
+		require.NoError(t, err)
+		require.NotNil(t, hours)
+		assert.Equal(t, int64(0), hours.ChannelID)
+		assert.Equal(t, "Asia/Kathmandu", hours.Timezone)
+		assert.Len(t, hours.Windows, 2)
+	})
+
+	t.Run("find is exact", func(t *testing.T) {
+		hours, err := repo.Find(org.ID, tg.ID)
+		require.NoError(t, err)
+		assert.Nil(t, hours)
+	})
+
+	t.Run("holidays and away message", func(t *testing.T) {
+		away := "We're closed"
+		_, err := repo.Upsert(org.ID, sms.ID, &models.SetBusinessHoursRequest{
+			Timezone:            "UTC",
+			Windows:             []models.BusinessHoursWindow{{Day: "mon", Start: "09:00", End: "17:00"}},
+			Holidays:            []models.BusinessHoliday{{Date: "2026-12-25", Name: "Christmas", Recurring: true}},
+			AwayMessage:         &away,
+			AwayCooldownSeconds: 600,
+		})
+		require.NoError(t, err)
+
+		hours, err := repo.Find(org.ID, sms.ID)
+		require.NoError(t, err)
+		require.NotNil(t, hours)
+		require.Len(t, hours.Holidays, 1)
+		assert.Equal(t, "Christmas", hours.Holidays[0].Name)
+		assert.True(t, hours.Holidays[0].Recurring)
+		assert.Equal(t, away, *hours.AwayMessage)
+		assert.Equal(t, 600, hours.AwayCooldownSeconds)
+
+		// Clearing them on update
+		_, err = repo.Upsert(org.ID, sms.ID, &models.SetBusinessHoursRequest{
+			Timezone: "UTC",
+			Windows:  []models.BusinessHoursWindow{{Day: "mon", Start: "09:00", End: "17:00"}},
+		})
+		require.NoError(t, err)
+		hours, err = repo.Find(org.ID, sms.ID)
+		require.NoError(t, err)
+		assert.Empty(t, hours.Holidays)
+		assert.Nil(t, hours.AwayMessage)
+	})
+}
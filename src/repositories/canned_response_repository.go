@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type CannedResponseRepository interface {
+	Create(req *models.CreateCannedResponseRequest) (*models.CannedResponse, error)
+	GetByID(id int64) (*models.CannedResponse, error)
+	// List returns the matching canned responses ordered by shortcut
+	List(q *models.CannedResponseQuery) ([]*models.CannedResponse, error)
+	Update(id int64, req *models.UpdateCannedResponseRequest) error
+	Delete(id int64) error
+}
+
+type cannedResponseRepository struct {
+	db *gorm.DB
+}
+
+func NewCannedResponseRepository(db *gorm.DB) CannedResponseRepository {
+	return &cannedResponseRepository{db: db}
+}
+
+func (r *cannedResponseRepository) Create(req *models.CreateCannedResponseRequest) (*models.CannedResponse, error) {
+	response := &models.CannedResponse{
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		Shortcut:       req.Shortcut,
+		Title:          req.Title,
+		Content:        req.Content,
+	}
+
+	if err := r.db.Create(response).Error; err != nil {
+		return nil, fmt.Errorf("failed to create canned response: %w", err)
+	}
+	return response, nil
+}
+
+func (r *cannedResponseRepository) GetByID(id int64) (*models.CannedResponse, error) {
+	var response models.CannedResponse
+	if err := r.db.First(&response, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("canned response not found")
+		}
+		return nil, fmt.Errorf("failed to get canned response: %w", err)
+	}
+	return &response, nil
+}
+
+func (r *cannedResponseRepository) List(q *models.CannedResponseQuery) ([]*models.CannedResponse, error) {
+	query := r.db.Where("organization_id = ?", q.OrganizationID)
+	if q.TeamID != nil {
+		query = query.Where("team_id IS NULL OR team_id = ?", *q.TeamID)
+	}
+	if q.Search != "" {
+		pattern := likePattern(q.Search)
+		query = query.Where(`shortcut LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\' OR content LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+	}
+
+	var responses []*models.CannedResponse
+	err := query.Order("shortcut").
+		Limit(q.Limit).
+		Offset(q.Offset).
+		Find(&responses).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+	return responses, nil
+}
+
+func (r *cannedResponseRepository) Update(id int64, req *models.UpdateCannedResponseRequest) error {
+	updates := make(map[string]interface{})
+
+	if req.TeamID != nil {
+		if *req.TeamID == 0 {
+			updates["team_id"] = nil
+		} else {
+			updates["team_id"] = *req.TeamID
+		}
+	}
+	if req.Shortcut != nil {
+		updates["shortcut"] = *req.Shortcut
+	}
+	if req.Title != nil {
+		updates["title"] = *req.Title
+	}
+	if req.Content != nil {
+		updates["content"] = *req.Content
+	}
+
+	result := r.db.Model(&models.CannedResponse{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update canned response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("canned response not found")
+	}
+	return nil
+}
+
+func (r *cannedResponseRepository) Delete(id int64) error {
+	result := r.db.Delete(&models.CannedResponse{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete canned response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("canned response not found")
+	}
+	return nil
+}
+
+// likePattern matches text containing s, escaping LIKE wildcards with a
+// backslash
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCannedResponseRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCannedResponseRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	otherOrg := testutils.CreateTestOrganization(t, db, "Other Org", "otherorg")
+	support, sales := int64(1), int64(2)
+
+	create := func(orgID int64, teamID *int64, shortcut, content string) *models.CannedResponse {
+		response, err := repo.Create(&models.CreateCannedResponseRequest{
+			OrganizationID: orgID,
+			TeamID:         teamID,
+			Shortcut:       shortcut,
+			Title:          shortcut,
+			Content:        content,
+		})
+		require.NoError(t, err)
+		return response
+	}
+	thanks := create(org.ID, nil, "thanks", "Thank you, {{contact.first_name}}!")
+	refund := create(org.ID, &support, "refund", "Your refund is on its way")
+	create(org.ID, &sales, "pricing", "Our plans start at 10% off")
+	create(otherOrg.ID, nil, "thanks", "Cheers")
+
+	shortcuts := func(q *models.CannedResponseQuery) []string {
+		q.Limit = 50
+		responses, err := repo.List(q)
+		require.NoError(t, err)
+		var result []string
+		for _, response := range responses {
+			result = append(result, response.Shortcut)
+		}
+		return result
+	}
+
+	t.Run("shortcuts are unique per organization", func(t *testing.T) {
+		_, err := repo.Create(&models.CreateCannedResponseRequest{OrganizationID: org.ID, Shortcut: "thanks", Title: "Again", Content: "Again"})
+		assert.Error(t, err)
+	})
+
+	t.Run("list by team", func(t *testing.T) {
+		assert.Equal(t, []string{"pricing", "refund", "thanks"}, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID}))
+		assert.Equal(t, []string{"refund", "thanks"}, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID, TeamID: &support}))
+	})
+
+	t.Run("search", func(t *testing.T) {
+		assert.Equal(t, []string{"refund"}, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID, Search: "REFUND"}))
+		assert.Equal(t, []string{"thanks"}, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID, Search: "first_name"}))
+		// LIKE wildcards match literally
+		assert.Equal(t, []string{"pricing"}, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID, Search: "10%"}))
+		assert.Empty(t, shortcuts(&models.CannedResponseQuery{OrganizationID: org.ID, Search: "r_fund"}))
+	})
+
+	t.Run("update and clear team", func(t *testing.T) {
+		content, zero := "Refund issued", int64(0)
+		require.NoError(t, repo.Update(refund.ID, &models.UpdateCannedResponseRequest{Content: &content, TeamID: &zero}))
+
+		got, err := repo.GetByID(refund.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Refund issued", got.Content)
+		assert.Nil(t, got.TeamID)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(thanks.ID))
+		_, err := repo.GetByID(thanks.ID)
+		assert.Error(t, err)
+		assert.Error(t, repo.Delete(thanks.ID))
+	})
+}
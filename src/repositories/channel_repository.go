@@ -36,6 +36,15 @@ func (r *channelRepository) Create(req *models.CreateChannelRequest) (*models.Ch
 		AccessToken:       req.AccessToken,
 		Config:            req.Config,
 		IsActive:          true,
+		DefaultTeamID:     req.DefaultTeamID,
+		ActivityMessages:  req.ActivityMessages,
+		RetainDeletedDays: req.RetainDeletedDays,
+	}
+
+	if req.DefaultTeamID != nil {
+		if err := r.checkTeam(req.OrganizationID, *req.DefaultTeamID); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := r.db.Create(channel).Error; err != nil {
@@ -49,7 +58,7 @@ func (r *channelRepository) GetByID(id int64) (*models.ChatChannel, error) {
 	var channel models.ChatChannel
 	if err := r.db.First(&channel, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("channel not found")
+			return nil, fmt.Errorf("channel %w", models.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get channel: %w", err)
 	}
@@ -97,13 +106,33 @@ func (r *channelRepository) Update(id int64, req *models.UpdateChannelRequest) e
 	if req.IsActive != nil {
 		updates["is_active"] = *req.IsActive
 	}
+	if req.ActivityMessages != nil {
+		updates["activity_messages"] = *req.ActivityMessages
+	}
+	if req.RetainDeletedDays != nil {
+		updates["retain_deleted_days"] = *req.RetainDeletedDays
+	}
+	if req.DefaultTeamID != nil {
+		if *req.DefaultTeamID == 0 {
+			updates["default_team_id"] = nil
+		} else {
+			var orgID int64
+			if err := r.db.Model(&models.ChatChannel{}).Where("id = ?", id).Pluck("organization_id", &orgID).Error; err != nil {
+				return fmt.Errorf("failed to get channel: %w", err)
+			}
+			if err := r.checkTeam(orgID, *req.DefaultTeamID); err != nil {
+				return err
+			}
+			updates["default_team_id"] = *req.DefaultTeamID
+		}
+	}
 
 	result := r.db.Model(&models.ChatChannel{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
 		return fmt.Errorf("failed to update channel: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("channel not found")
+		return fmt.Errorf("channel %w", models.ErrNotFound)
 	}
 
 	return nil
@@ -115,7 +144,7 @@ func (r *channelRepository) UpdateStatus(id int64, status models.ChannelStatus)
 		return fmt.Errorf("failed to update channel status: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("channel not found")
+		return fmt.Errorf("channel %w", models.ErrNotFound)
 	}
 	return nil
 }
@@ -126,7 +155,22 @@ func (r *channelRepository) Delete(id int64) error {
 		return fmt.Errorf("failed to delete channel: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("channel not found")
+		return fmt.Errorf("channel %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// checkTeam verifies that the team exists and belongs to the organization
+func (r *channelRepository) checkTeam(orgID, teamID int64) error {
+	var count int64
+	err := r.db.Model(&models.Team{}).
+		Where("id = ? AND organization_id = ?", teamID, orgID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("team %w", models.ErrNotFound)
 	}
 	return nil
 }
@@ -50,7 +50,7 @@ func TestChannelRepository_GetByID(t *testing.T) {
 
 	t.Run("return error for non-existent channel", func(t *testing.T) {
 		found, err := repo.GetByID(99999)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, models.ErrNotFound)
 		assert.Nil(t, found)
 	})
 }
@@ -114,3 +114,38 @@ func TestChannelRepository_Delete(t *testing.T) {
 		assert.False(t, found.IsActive)
 	})
 }
+
+func TestChannelRepository_DefaultTeam(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewChannelRepository(db)
+	teamRepo := NewTeamRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	other := testutils.CreateTestOrganization(t, db, "Other Org", "otherorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+
+	team, err := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: org.ID, Name: "billing"})
+	require.NoError(t, err)
+	foreign, err := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: other.ID, Name: "billing"})
+	require.NoError(t, err)
+
+	t.Run("rejects another organization's team", func(t *testing.T) {
+		err := repo.Update(channel.ID, &models.UpdateChannelRequest{DefaultTeamID: &foreign.ID})
+		assert.Error(t, err)
+	})
+
+	t.Run("sets and clears the default team", func(t *testing.T) {
+		require.NoError(t, repo.Update(channel.ID, &models.UpdateChannelRequest{DefaultTeamID: &team.ID}))
+		got, err := repo.GetByID(channel.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.DefaultTeamID)
+		assert.Equal(t, team.ID, *got.DefaultTeamID)
+
+		none := int64(0)
+		require.NoError(t, repo.Update(channel.ID, &models.UpdateChannelRequest{DefaultTeamID: &none}))
+		got, err = repo.GetByID(channel.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.DefaultTeamID)
+	})
+}
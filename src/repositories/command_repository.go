@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommandRepository tracks processed commands for idempotency
+type CommandRepository interface {
+	// Claim records the command as pending. It returns false when the
+	// command ID has been seen before, unless its claim is still pending
+	// and was made before staleBefore: the claim of a consumer that died
+	// while executing the command is then taken over.
+	Claim(commandID string, name models.CommandName, now, staleBefore time.Time) (bool, error)
+	GetByID(commandID string) (*models.ProcessedCommand, error)
+	Complete(commandID string, status models.CommandStatus, result *string, errorMsg *string) error
+}
+
+type commandRepository struct {
+	db *gorm.DB
+}
+
+func NewCommandRepository(db *gorm.DB) CommandRepository {
+	return &commandRepository{db: db}
+}
+
+func (r *commandRepository) Claim(commandID string, name models.CommandName, now, staleBefore time.Time) (bool, error) {
+	cmd := &models.ProcessedCommand{
+		CommandID: commandID,
+		Name:      name,
+		Status:    models.CommandStatusPending,
+		ClaimedAt: now,
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(cmd)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim command: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = r.db.Model(&models.ProcessedCommand{}).
+		Where("command_id = ? AND status = ?", commandID, models.CommandStatusPending).
+		Where("datetime(claimed_at) <= datetime(?)", sqliteTime(staleBefore)).
+		Update("claimed_at", now)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to take over command claim: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *commandRepository) GetByID(commandID string) (*models.ProcessedCommand, error) {
+	var cmd models.ProcessedCommand
+	if err := r.db.Where("command_id = ?", commandID).First(&cmd).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("command not found")
+		}
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	return &cmd, nil
+}
+
+func (r *commandRepository) Complete(commandID string, status models.CommandStatus, result *string, errorMsg *string) error {
+	res := r.db.Model(&models.ProcessedCommand{}).Where("command_id = ?", commandID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"result":       result,
+			"error":        errorMsg,
+			"completed_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("failed to complete command: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("command not found")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRepository_Claim(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCommandRepository(db)
+	now := time.Now()
+	staleBefore := now.Add(-time.Minute)
+
+	t.Run("first claim succeeds", func(t *testing.T) {
+		claimed, err := repo.Claim("cmd-1", models.CommandSendMessage, now, staleBefore)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		found, err := repo.GetByID("cmd-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.CommandStatusPending, found.Status)
+	})
+
+	t.Run("duplicate claim is rejected", func(t *testing.T) {
+		claimed, err := repo.Claim("cmd-1", models.CommandSendMessage, now, staleBefore)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("stale pending claim is taken over", func(t *testing.T) {
+		later := now.Add(2 * time.Minute)
+		claimed, err := repo.Claim("cmd-1", models.CommandSendMessage, later, later.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		// The new claim is fresh again
+		claimed, err = repo.Claim("cmd-1", models.CommandSendMessage, later, later.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("completed command is never claimed again", func(t *testing.T) {
+		require.NoError(t, repo.Complete("cmd-1", models.CommandStatusSucceeded, nil, nil))
+
+		later := now.Add(time.Hour)
+		claimed, err := repo.Claim("cmd-1", models.CommandSendMessage, later, later.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestCommandRepository_Complete(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCommandRepository(db)
+	_, err := repo.Claim("cmd-2", models.CommandAssignConversation, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	t.Run("store result", func(t *testing.T) {
+		result := `{"message_id":1}`
+		require.NoError(t, repo.Complete("cmd-2", models.CommandStatusSucceeded, &result, nil))
+
+		found, err := repo.GetByID("cmd-2")
+		require.NoError(t, err)
+		assert.Equal(t, models.CommandStatusSucceeded, found.Status)
+		assert.Equal(t, result, *found.Result)
+		assert.NotNil(t, found.CompletedAt)
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		err := repo.Complete("missing", models.CommandStatusFailed, nil, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type ConversationEventRepository interface {
+	// Create records the change, and also adds it to the message stream as
+	// a system message when the conversation's channel has activity
+	// messages on
+	Create(event *models.ConversationEvent) error
+	ListByConversation(conversationID int64, limit, offset int) ([]*models.ConversationEvent, error)
+	// ListTimeline lists a conversation's history oldest first, interleaved
+	// with its messages when includeMessages is set
+	ListTimeline(conversationID int64, includeMessages bool, limit, offset int) ([]*models.TimelineEntry, error)
+}
+
+type conversationEventRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationEventRepository(db *gorm.DB) ConversationEventRepository {
+	return &conversationEventRepository{db: db}
+}
+
+func (r *conversationEventRepository) Create(event *models.ConversationEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createConversationEvent(tx, event)
+	})
+}
+
+// createConversationEvent records the change within tx, adding the activity
+// message for it when the conversation's channel has them on
+func createConversationEvent(tx *gorm.DB, event *models.ConversationEvent) error {
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create conversation event: %w", err)
+	}
+
+	var enabled []bool
+	err := tx.Table("conversations").
+		Joins("JOIN chat_channels ON chat_channels.id = conversations.channel_id").
+		Where("conversations.id = ?", event.ConversationID).
+		Pluck("chat_channels.activity_messages", &enabled).Error
+	if err != nil {
+		return fmt.Errorf("failed to get channel: %w", err)
+	}
+	if len(enabled) == 0 || !enabled[0] {
+		return nil
+	}
+
+	metadata, err := json.Marshal(models.ActivityMetadata{
+		EventID:   event.ID,
+		EventType: event.Type,
+		From:      event.FromValue,
+		To:        event.ToValue,
+		ActorID:   event.ActorID,
+		Reason:    event.Reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode activity metadata: %w", err)
+	}
+	meta := string(metadata)
+	err = tx.Create(&models.Message{
+		ConversationID: event.ConversationID,
+		SenderType:     models.SenderSystem,
+		Content:        event.Describe(),
+		MessageType:    models.MessageTypeSystem,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+		CreatedAt:      event.CreatedAt,
+		Metadata:       &meta,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to create activity message: %w", err)
+	}
+	return nil
+}
+
+func (r *conversationEventRepository) ListByConversation(conversationID int64, limit, offset int) ([]*models.ConversationEvent, error) {
+	var history []*models.ConversationEvent
+	err := r.db.Where("conversation_id = ?", conversationID).
+		Order("created_at ASC").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&history).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversation events: %w", err)
+	}
+
+	return history, nil
+}
+
+func (r *conversationEventRepository) ListTimeline(conversationID int64, includeMessages bool, limit, offset int) ([]*models.TimelineEntry, error) {
+	if !includeMessages {
+		history, err := r.ListByConversation(conversationID, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		timeline := make([]*models.TimelineEntry, 0, len(history))
+		for _, event := range history {
+			timeline = append(timeline, &models.TimelineEntry{Kind: models.TimelineEntryEvent, At: event.CreatedAt, Event: event})
+		}
+		return timeline, nil
+	}
+
+	// Page over both tables first, then load the rows of the page. A message
+	// sorts before a change made in the same instant. Activity messages are
+	// left out since they repeat the history, and so are deleted messages.
+	var keys []struct {
+		Kind models.TimelineEntryKind
+		ID   int64
+	}
+	err := r.db.Raw(`SELECT 'event' AS kind, id, created_at FROM conversation_events WHERE conversation_id = ?
+		UNION ALL
+		SELECT 'message' AS kind, id, created_at FROM messages WHERE conversation_id = ? AND message_type IS NOT 'system' AND deleted_at IS NULL
+		ORDER BY created_at ASC, kind DESC, id ASC
+		LIMIT ? OFFSET ?`, conversationID, conversationID, limit, offset).
+		Scan(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversation timeline: %w", err)
+	}
+
+	var eventIDs, messageIDs []int64
+	for _, key := range keys {
+		if key.Kind == models.TimelineEntryMessage {
+			messageIDs = append(messageIDs, key.ID)
+		} else {
+			eventIDs = append(eventIDs, key.ID)
+		}
+	}
+
+	var history []*models.ConversationEvent
+	if len(eventIDs) > 0 {
+		if err := r.db.Where("id IN ?", eventIDs).Find(&history).Error; err != nil {
+			return nil, fmt.Errorf("failed to list conversation timeline: %w", err)
+		}
+	}
+	var messages []*models.Message
+	if len(messageIDs) > 0 {
+		if err := r.db.Where("id IN ?", messageIDs).Find(&messages).Error; err != nil {
+			return nil, fmt.Errorf("failed to list conversation timeline: %w", err)
+		}
+	}
+
+	eventsByID := make(map[int64]*models.ConversationEvent, len(history))
+	for _, event := range history {
+		eventsByID[event.ID] = event
+	}
+	messagesByID := make(map[int64]*models.Message, len(messages))
+	for _, msg := range messages {
+		messagesByID[msg.ID] = msg
+	}
+
+	timeline := make([]*models.TimelineEntry, 0, len(keys))
+	for _, key := range keys {
+		if key.Kind == models.TimelineEntryMessage {
+			if msg, ok := messagesByID[key.ID]; ok {
+				timeline = append(timeline, &models.TimelineEntry{Kind: key.Kind, At: msg.CreatedAt, Message: msg})
+			}
+		} else if event, ok := eventsByID[key.ID]; ok {
+			timeline = append(timeline, &models.TimelineEntry{Kind: key.Kind, At: event.CreatedAt, Event: event})
+		}
+	}
+
+	return timeline, nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationEventRepository_ListTimeline(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationEventRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	other := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	base := time.Now().Add(-time.Hour)
+	message := func(convID int64, at time.Time, content string) {
+		require.NoError(t, db.Create(&models.Message{
+			ConversationID: convID,
+			SenderType:     models.SenderExternal,
+			Content:        content,
+			Direction:      models.DirectionInbound,
+			Status:         models.MessageStatusDelivered,
+			CreatedAt:      at,
+		}).Error)
+	}
+	change := func(convID int64, at time.Time, to string) {
+		require.NoError(t, repo.Create(&models.ConversationEvent{
+			ConversationID: convID,
+			Type:           models.ConversationEventStatusChanged,
+			ToValue:        &to,
+			CreatedAt:      at,
+		}))
+	}
+
+	message(conv.ID, base, "hello")
+	change(conv.ID, base.Add(time.Minute), "pending")
+	message(conv.ID, base.Add(2*time.Minute), "any news?")
+	change(conv.ID, base.Add(2*time.Minute), "open")
+	message(other.ID, base, "elsewhere")
+
+	t.Run("history only", func(t *testing.T) {
+		timeline, err := repo.ListTimeline(conv.ID, false, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, timeline, 2)
+		for _, entry := range timeline {
+			assert.Equal(t, models.TimelineEntryEvent, entry.Kind)
+			assert.NotNil(t, entry.Event)
+		}
+	})
+
+	t.Run("merged with messages", func(t *testing.T) {
+		timeline, err := repo.ListTimeline(conv.ID, true, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, timeline, 4)
+
+		kinds := make([]models.TimelineEntryKind, 0, len(timeline))
+		for _, entry := range timeline {
+			kinds = append(kinds, entry.Kind)
+		}
+		assert.Equal(t, []models.TimelineEntryKind{"message", "event", "message", "event"}, kinds)
+		assert.Equal(t, "hello", timeline[0].Message.Content)
+		assert.Equal(t, "open", *timeline[3].Event.ToValue)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		timeline, err := repo.ListTimeline(conv.ID, true, 2, 1)
+		require.NoError(t, err)
+		require.Len(t, timeline, 2)
+		assert.Equal(t, "pending", *timeline[0].Event.ToValue)
+		assert.Equal(t, "any news?", timeline[1].Message.Content)
+	})
+}
+
+func TestConversationEventRepository_ActivityMessages(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationEventRepository(db)
+	messages := NewMessageRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	quiet := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	require.NoError(t, db.Model(channel).Update("activity_messages", true).Error)
+
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	other := testutils.CreateTestConversation(t, db, quiet.ID, user.ID)
+
+	to, actor, reason := "agent-1", "agent-2", "manual"
+	for _, convID := range []int64{conv.ID, other.ID} {
+		require.NoError(t, repo.Create(&models.ConversationEvent{
+			ConversationID: convID,
+			Type:           models.ConversationEventAssigneeChanged,
+			ToValue:        &to,
+			ActorID:        &actor,
+			Reason:         &reason,
+		}))
+	}
+	require.NoError(t, db.Create(&models.Message{
+		ConversationID: conv.ID,
+		SenderType:     models.SenderExternal,
+		Content:        "hello",
+		MessageType:    models.MessageTypeText,
+		Direction:      models.DirectionInbound,
+	}).Error)
+
+	t.Run("recorded only when the channel has it on", func(t *testing.T) {
+		stream, err := messages.ListByConversation(conv.ID, 10, 0, nil)
+		require.NoError(t, err)
+		require.Len(t, stream, 2)
+
+		var activity *models.Message
+		for _, msg := range stream {
+			if msg.MessageType == models.MessageTypeSystem {
+				activity = msg
+			}
+		}
+		require.NotNil(t, activity)
+		assert.Equal(t, models.SenderSystem, activity.SenderType)
+		assert.Equal(t, "Assigned to agent-1 (manual)", activity.Content)
+
+		require.NotNil(t, activity.Metadata)
+		var metadata models.ActivityMetadata
+		require.NoError(t, json.Unmarshal([]byte(*activity.Metadata), &metadata))
+		assert.Equal(t, models.ConversationEventAssigneeChanged, metadata.EventType)
+		assert.Equal(t, &to, metadata.To)
+		assert.Equal(t, &actor, metadata.ActorID)
+		assert.NotZero(t, metadata.EventID)
+
+		stream, err = messages.ListByConversation(other.ID, 10, 0, nil)
+		require.NoError(t, err)
+		assert.Empty(t, stream)
+	})
+
+	t.Run("hidden by the history filter", func(t *testing.T) {
+		stream, err := messages.ListHistory(&models.MessageListQuery{ConversationID: conv.ID, Limit: 10, HideSystem: true})
+		require.NoError(t, err)
+		require.Len(t, stream, 1)
+		assert.Equal(t, "hello", stream[0].Content)
+	})
+
+	t.Run("not repeated in the timeline", func(t *testing.T) {
+		timeline, err := repo.ListTimeline(conv.ID, true, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, timeline, 2)
+		assert.Equal(t, models.TimelineEntryEvent, timeline[0].Kind)
+		assert.Equal(t, models.TimelineEntryMessage, timeline[1].Kind)
+	})
+}
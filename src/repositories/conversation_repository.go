@@ -1,9 +1,13 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
 
 	"gorm.io/gorm"
 )
@@ -12,10 +16,54 @@ import (
 type ConversationRepository interface {
 	Create(req *models.CreateConversationRequest) (*models.Conversation, error)
 	GetByID(id int64) (*models.Conversation, error)
-	GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, error)
-	List(channelID int64, status *models.ConversationStatus, limit, offset int) ([]*models.Conversation, error)
+	// GetOrCreateByUser and LatestByUser find the user's one-to-one
+	// conversations on the channel; GetOrCreateByChat and LatestByChat find
+	// those of a group chat, which the user creates if it has none open
+	GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, bool, error)
+	GetOrCreateByChat(channelID int64, platformChatID string, externalUserID int64) (*models.Conversation, bool, error)
+	// LatestByUser returns the user's most recent conversation on the
+	// channel, or nil if they have none
+	LatestByUser(channelID, externalUserID int64) (*models.Conversation, error)
+	LatestByChat(channelID int64, platformChatID string) (*models.Conversation, error)
+	// List lists a channel's conversations, optionally with the given status,
+	// carrying any of the named tags and matching the custom attribute query
+	List(channelID int64, status *models.ConversationStatus, tags []string, attrs *models.AttributeQuery, limit, offset int) ([]*models.Conversation, error)
 	Update(id int64, req *models.UpdateConversationRequest) error
+	// SetAttributes merges values into the conversation's custom
+	// attributes, removing those set to nil
+	SetAttributes(id int64, values models.CustomAttributes) error
 	UpdateLastMessage(id int64) error
+	// ClaimAwayReply records that an away reply is sent at the given time,
+	// reporting false if one was already sent after since
+	ClaimAwayReply(id int64, at, since time.Time) (bool, error)
+	ListInbox(q *models.InboxQuery) (*models.InboxPage, error)
+	LastAssigneeForUser(externalUserID, excludeID int64) (*string, error)
+	ListOpenByAssignee(orgID int64, assigneeID string) ([]*models.Conversation, error)
+	ListUnanswered(now time.Time) ([]*models.Conversation, error)
+	// ListIdle lists open and pending conversations without messages for
+	// longer than the idle period of their channel's lifecycle policy
+	ListIdle(now time.Time) ([]*models.Conversation, error)
+	// ListSnoozedDue lists snoozed conversations whose wake-up time is at
+	// or before now
+	ListSnoozedDue(now time.Time) ([]*models.Conversation, error)
+	// SaveSLA writes the conversation's SLA policy, status and timers
+	SaveSLA(conv *models.Conversation) error
+	// ListSLADue lists open conversations with an SLA target that is within
+	// its policy's warning window of now, or past due
+	ListSLADue(now time.Time) ([]*models.Conversation, error)
+	// Merge moves the source conversation's messages, tags, participants
+	// and history into the target, closes the source with a pointer to the
+	// target and records the history entries given, in one transaction. It
+	// returns the number of messages moved.
+	Merge(sourceID, targetID int64, history []*models.ConversationEvent) (int64, error)
+	// Split moves the source conversation's messages with IDs from
+	// fromMessageID to toMessageID into a new conversation for the same
+	// user or group chat, with the same participants. The new conversation
+	// is resolved while the source is still active, and open otherwise. It
+	// returns the new conversation and the number of messages moved, or
+	// models.ErrInvalidMerge if it would be opened while the user or group
+	// chat has another active conversation.
+	Split(sourceID, fromMessageID, toMessageID int64) (*models.Conversation, int64, error)
 }
 
 type conversationRepository struct {
@@ -35,6 +83,7 @@ func (r *conversationRepository) Create(req *models.CreateConversationRequest) (
 	conv := &models.Conversation{
 		ChannelID:      req.ChannelID,
 		ExternalUserID: req.ExternalUserID,
+		PlatformChatID: req.PlatformChatID,
 		Status:         models.ConversationStatusOpen,
 		Priority:       priority,
 		Subject:        req.Subject,
@@ -51,45 +100,105 @@ func (r *conversationRepository) GetByID(id int64) (*models.Conversation, error)
 	var conv models.Conversation
 	if err := r.db.First(&conv, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("conversation not found")
+			return nil, fmt.Errorf("conversation %w", models.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 	return &conv, nil
 }
 
-func (r *conversationRepository) GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, error) {
-	// Check for existing open conversation
+// GetOrCreateByUser returns the user's open conversation on the channel,
+// creating one if none exists. The flag reports whether it was created.
+func (r *conversationRepository) GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, bool, error) {
+	return r.getOrCreate(
+		r.db.Where("channel_id = ? AND external_user_id = ? AND platform_chat_id IS NULL", channelID, externalUserID),
+		&models.CreateConversationRequest{ChannelID: channelID, ExternalUserID: externalUserID},
+	)
+}
+
+func (r *conversationRepository) GetOrCreateByChat(channelID int64, platformChatID string, externalUserID int64) (*models.Conversation, bool, error) {
+	return r.getOrCreate(
+		r.db.Where("channel_id = ? AND platform_chat_id = ?", channelID, platformChatID),
+		&models.CreateConversationRequest{ChannelID: channelID, ExternalUserID: externalUserID, PlatformChatID: &platformChatID},
+	)
+}
+
+func (r *conversationRepository) getOrCreate(query *gorm.DB, req *models.CreateConversationRequest) (*models.Conversation, bool, error) {
+	// Check for existing active conversation, which may be snoozed
 	var conv models.Conversation
-	err := r.db.Where("channel_id = ? AND external_user_id = ? AND status IN ?",
-		channelID, externalUserID, []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+	err := query.Where("status IN ?", []models.ConversationStatus{
+		models.ConversationStatusOpen, models.ConversationStatusPending, models.ConversationStatusSnoozed,
+	}).
 		Order("created_at DESC").
 		First(&conv).Error
 
 	if err == nil {
-		return &conv, nil
+		return &conv, false, nil
 	}
 	if err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to get conversation: %w", err)
+		return nil, false, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
 	// No open conversation, create new
-	return r.Create(&models.CreateConversationRequest{
-		ChannelID:      channelID,
-		ExternalUserID: externalUserID,
-	})
+	created, err := r.Create(req)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
+
+func (r *conversationRepository) LatestByUser(channelID, externalUserID int64) (*models.Conversation, error) {
+	return r.latest(r.db.Where("channel_id = ? AND external_user_id = ? AND platform_chat_id IS NULL", channelID, externalUserID))
+}
+
+func (r *conversationRepository) LatestByChat(channelID int64, platformChatID string) (*models.Conversation, error) {
+	return r.latest(r.db.Where("channel_id = ? AND platform_chat_id = ?", channelID, platformChatID))
+}
+
+func (r *conversationRepository) latest(query *gorm.DB) (*models.Conversation, error) {
+	var conv models.Conversation
+	err := query.Where("merged_into_id IS NULL").
+		Order("created_at DESC").
+		Order("id DESC").
+		First(&conv).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return &conv, nil
 }
 
-func (r *conversationRepository) List(channelID int64, status *models.ConversationStatus, limit, offset int) ([]*models.Conversation, error) {
+func (r *conversationRepository) List(channelID int64, status *models.ConversationStatus, tags []string, attrs *models.AttributeQuery, limit, offset int) ([]*models.Conversation, error) {
 	var conversations []*models.Conversation
 
-	query := r.db.Where("channel_id = ?", channelID)
+	var orgID int64
+	if attrs != nil {
+		if err := r.db.Model(&models.ChatChannel{}).Select("organization_id").
+			Where("id = ?", channelID).Scan(&orgID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get channel: %w", err)
+		}
+	}
+	scope, err := attributeScope(r.db, orgID, models.AttributeEntityConversation, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	query := scope(r.db.Where("channel_id = ?", channelID))
 
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
+	if len(tags) > 0 {
+		query = query.Where(`id IN (
+			SELECT conversation_tags.conversation_id FROM conversation_tags
+			JOIN tags ON tags.id = conversation_tags.tag_id
+			WHERE tags.name IN ?)`, normalizeTagNames(tags))
+	}
 
-	err := query.Order("updated_at DESC").
+	err = query.Order("updated_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&conversations).Error
@@ -105,12 +214,36 @@ func (r *conversationRepository) Update(id int64, req *models.UpdateConversation
 	updates := make(map[string]interface{})
 
 	if req.AssignedToExternalID != nil {
-		updates["assigned_to_external_id"] = *req.AssignedToExternalID
+		if *req.AssignedToExternalID == "" {
+			updates["assigned_to_external_id"] = nil
+			updates["assigned_at"] = nil
+		} else {
+			updates["assigned_to_external_id"] = *req.AssignedToExternalID
+			updates["assigned_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+		}
+	}
+	if req.TeamID != nil {
+		if *req.TeamID == 0 {
+			updates["team_id"] = nil
+		} else {
+			updates["team_id"] = *req.TeamID
+		}
 	}
 	if req.Status != nil {
 		updates["status"] = *req.Status
-		if *req.Status == models.ConversationStatusResolved || *req.Status == models.ConversationStatusClosed {
+		if *req.Status == models.ConversationStatusSnoozed && req.SnoozedUntil != nil {
+			updates["snoozed_until"] = sqliteTime(*req.SnoozedUntil)
+		} else {
+			updates["snoozed_until"] = nil
+		}
+		switch *req.Status {
+		case models.ConversationStatusResolved:
 			updates["resolved_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+		case models.ConversationStatusClosed:
+			// Closing a resolved conversation keeps its resolution time
+			updates["resolved_at"] = gorm.Expr("COALESCE(resolved_at, CURRENT_TIMESTAMP)")
+		default:
+			updates["resolved_at"] = nil
 		}
 	}
 	if req.Priority != nil {
@@ -128,9 +261,27 @@ func (r *conversationRepository) Update(id int64, req *models.UpdateConversation
 		return fmt.Errorf("failed to update conversation: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("conversation not found")
+		return fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *conversationRepository) SetAttributes(id int64, values models.CustomAttributes) error {
+	patch, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode custom attributes: %w", err)
 	}
 
+	// json_patch drops keys whose patch value is null
+	result := r.db.Model(&models.Conversation{}).Where("id = ?", id).
+		Update("custom_attributes", gorm.Expr("json_patch(COALESCE(custom_attributes, '{}'), ?)", string(patch)))
+	if result.Error != nil {
+		return fmt.Errorf("failed to update custom attributes: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
 	return nil
 }
 
@@ -141,3 +292,480 @@ func (r *conversationRepository) UpdateLastMessage(id int64) error {
 			"first_message_at": gorm.Expr("COALESCE(first_message_at, CURRENT_TIMESTAMP)"),
 		}).Error
 }
+
+func (r *conversationRepository) ClaimAwayReply(id int64, at, since time.Time) (bool, error) {
+	result := r.db.Model(&models.Conversation{}).
+		Where("id = ?", id).
+		Where("away_replied_at IS NULL OR datetime(away_replied_at) <= datetime(?)", sqliteTime(since)).
+		Update("away_replied_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record away reply: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *conversationRepository) Merge(sourceID, targetID int64, history []*models.ConversationEvent) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Message{}).Where("conversation_id = ?", sourceID).
+			Update("conversation_id", targetID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to move messages: %w", result.Error)
+		}
+		moved = result.RowsAffected
+
+		if err := tx.Exec(`INSERT OR IGNORE INTO conversation_tags (conversation_id, tag_id, created_at)
+			SELECT ?, tag_id, created_at FROM conversation_tags WHERE conversation_id = ?`, targetID, sourceID).Error; err != nil {
+			return fmt.Errorf("failed to move tags: %w", err)
+		}
+		if err := tx.Where("conversation_id = ?", sourceID).Delete(&models.ConversationTag{}).Error; err != nil {
+			return fmt.Errorf("failed to move tags: %w", err)
+		}
+
+		if err := tx.Exec(`INSERT OR IGNORE INTO conversation_participants (conversation_id, external_user_id, role, joined_at, left_at)
+			SELECT ?, external_user_id, role, joined_at, left_at FROM conversation_participants WHERE conversation_id = ?`, targetID, sourceID).Error; err != nil {
+			return fmt.Errorf("failed to move participants: %w", err)
+		}
+		if err := tx.Where("conversation_id = ?", sourceID).Delete(&models.ConversationParticipant{}).Error; err != nil {
+			return fmt.Errorf("failed to move participants: %w", err)
+		}
+
+		if err := tx.Model(&models.ConversationEvent{}).Where("conversation_id = ?", sourceID).
+			Update("conversation_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move conversation history: %w", err)
+		}
+
+		if err := tx.Model(&models.Conversation{}).Where("id = ?", sourceID).
+			Updates(map[string]interface{}{
+				"merged_into_id": targetID,
+				"status":         models.ConversationStatusClosed,
+				"resolved_at":    gorm.Expr("COALESCE(resolved_at, CURRENT_TIMESTAMP)"),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update conversation: %w", err)
+		}
+
+		for _, event := range history {
+			if err := createConversationEvent(tx, event); err != nil {
+				return err
+			}
+		}
+
+		return refreshMessageTimes(tx, sourceID, targetID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func (r *conversationRepository) Split(sourceID, fromMessageID, toMessageID int64) (*models.Conversation, int64, error) {
+	var conv *models.Conversation
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var source models.Conversation
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("conversation %w", models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to get conversation: %w", err)
+		}
+
+		// Messages from the user or group chat go to their one active
+		// conversation. While that is the source, the split-off
+		// conversation is created resolved; otherwise it is opened, so
+		// there must be no other.
+		active := []models.ConversationStatus{
+			models.ConversationStatusOpen, models.ConversationStatusPending, models.ConversationStatusSnoozed,
+		}
+		conv = &models.Conversation{
+			ChannelID:      source.ChannelID,
+			ExternalUserID: source.ExternalUserID,
+			PlatformChatID: source.PlatformChatID,
+			Status:         models.ConversationStatusOpen,
+			Priority:       source.Priority,
+			TeamID:         source.TeamID,
+		}
+		if slices.Contains(active, source.Status) {
+			now := time.Now()
+			conv.Status = models.ConversationStatusResolved
+			conv.ResolvedAt = &now
+		} else {
+			scope := tx.Where("channel_id = ? AND id <> ?", source.ChannelID, source.ID)
+			if source.PlatformChatID != nil {
+				scope = scope.Where("platform_chat_id = ?", *source.PlatformChatID)
+			} else {
+				scope = scope.Where("external_user_id = ? AND platform_chat_id IS NULL", source.ExternalUserID)
+			}
+			var other models.Conversation
+			err := scope.Where("status IN ?", active).First(&other).Error
+			if err == nil {
+				return fmt.Errorf("%w: conversation %d is still open", models.ErrInvalidMerge, other.ID)
+			}
+			if err != gorm.ErrRecordNotFound {
+				return fmt.Errorf("failed to get conversation: %w", err)
+			}
+		}
+
+		if err := tx.Create(conv).Error; err != nil {
+			return fmt.Errorf("failed to create conversation: %w", err)
+		}
+
+		result := tx.Model(&models.Message{}).
+			Where("conversation_id = ? AND id BETWEEN ? AND ?", sourceID, fromMessageID, toMessageID).
+			Update("conversation_id", conv.ID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to move messages: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: no messages in range", models.ErrInvalidMerge)
+		}
+		moved = result.RowsAffected
+
+		if err := tx.Exec(`INSERT INTO conversation_participants (conversation_id, external_user_id, role, joined_at, left_at)
+			SELECT ?, external_user_id, role, joined_at, left_at FROM conversation_participants WHERE conversation_id = ?`, conv.ID, sourceID).Error; err != nil {
+			return fmt.Errorf("failed to copy participants: %w", err)
+		}
+
+		if err := refreshMessageTimes(tx, sourceID, conv.ID); err != nil {
+			return err
+		}
+		return tx.First(conv, conv.ID).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return conv, moved, nil
+}
+
+// refreshMessageTimes recomputes the first and last message times of
+// conversations whose messages were moved
+func refreshMessageTimes(tx *gorm.DB, ids ...int64) error {
+	err := tx.Model(&models.Conversation{}).Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"first_message_at": gorm.Expr("(SELECT MIN(datetime(created_at)) FROM messages WHERE conversation_id = conversations.id)"),
+			"last_message_at":  gorm.Expr("(SELECT MAX(datetime(created_at)) FROM messages WHERE conversation_id = conversations.id)"),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update conversation message times: %w", err)
+	}
+	return nil
+}
+
+// LastAssigneeForUser returns the assignee of the user's most recently
+// active other conversation, or nil if none was assigned
+func (r *conversationRepository) LastAssigneeForUser(externalUserID, excludeID int64) (*string, error) {
+	var conv models.Conversation
+	err := r.db.Where("external_user_id = ? AND id <> ? AND assigned_to_external_id IS NOT NULL AND assigned_to_external_id <> ''",
+		externalUserID, excludeID).
+		Order("COALESCE(last_message_at, created_at) DESC").
+		First(&conv).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous assignee: %w", err)
+	}
+	return conv.AssignedToExternalID, nil
+}
+
+// ListOpenByAssignee lists the open and pending conversations assigned to
+// an agent within an organization
+func (r *conversationRepository) ListOpenByAssignee(orgID int64, assigneeID string) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	err := r.db.Joins("JOIN chat_channels ON chat_channels.id = conversations.channel_id").
+		Where("chat_channels.organization_id = ? AND conversations.assigned_to_external_id = ? AND conversations.status IN ?",
+			orgID, assigneeID, []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+		Order("conversations.id").
+		Find(&conversations).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assigned conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// ListUnanswered lists open conversations whose assignee has not replied
+// within the reassign window of the channel's routing policy. Activity
+// messages and notes do not count as replies.
+func (r *conversationRepository) ListUnanswered(now time.Time) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	err := r.db.Joins("JOIN routing_policies ON routing_policies.channel_id = conversations.channel_id").
+		Where("routing_policies.enabled = ? AND routing_policies.reassign_after_seconds > 0", true).
+		Where("conversations.status IN ?", []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+		Where("conversations.assigned_to_external_id IS NOT NULL AND conversations.assigned_to_external_id <> ''").
+		Where("conversations.assigned_at <= datetime(?, '-' || routing_policies.reassign_after_seconds || ' seconds')", sqliteTime(now)).
+		Where(`NOT EXISTS (SELECT 1 FROM messages WHERE messages.conversation_id = conversations.id
+			AND messages.direction = 'outbound' AND messages.message_type NOT IN ('system', 'note')
+			AND datetime(messages.created_at) >= datetime(conversations.assigned_at))`).
+		Order("conversations.assigned_at").
+		Find(&conversations).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unanswered conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) ListIdle(now time.Time) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	err := r.db.Joins("JOIN lifecycle_policies ON lifecycle_policies.channel_id = conversations.channel_id").
+		Where("lifecycle_policies.idle_after_seconds > 0").
+		Where("conversations.status IN ?", []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+		Where("datetime(COALESCE(conversations.last_message_at, conversations.created_at)) <= datetime(?, '-' || lifecycle_policies.idle_after_seconds || ' seconds')", sqliteTime(now)).
+		Order("conversations.id").
+		Find(&conversations).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list idle conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) ListSnoozedDue(now time.Time) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	err := r.db.Where("status = ? AND snoozed_until IS NOT NULL AND datetime(snoozed_until) <= datetime(?)",
+		models.ConversationStatusSnoozed, sqliteTime(now)).
+		Order("snoozed_until").
+		Find(&conversations).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snoozed conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) SaveSLA(conv *models.Conversation) error {
+	result := r.db.Model(&models.Conversation{}).Where("id = ?", conv.ID).
+		Select("sla_policy_id", "sla_status", "first_response_at", "first_response_due_at",
+			"next_response_due_at", "resolution_due_at").
+		Updates(conv)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update conversation SLA: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *conversationRepository) ListSLADue(now time.Time) ([]*models.Conversation, error) {
+	const horizon = "datetime(?, '+' || sla_policies.warn_before_seconds || ' seconds')"
+	at := sqliteTime(now)
+
+	var conversations []*models.Conversation
+	err := r.db.Joins("JOIN sla_policies ON sla_policies.id = conversations.sla_policy_id").
+		Where("conversations.status IN ?", []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending}).
+		Where("((conversations.first_response_at IS NULL AND conversations.first_response_due_at <= "+horizon+
+			") OR conversations.next_response_due_at <= "+horizon+
+			" OR conversations.resolution_due_at <= "+horizon+")", at, at, at).
+		Order("conversations.id").
+		Find(&conversations).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLA due conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// Inbox sort keys. Conversations without messages sort by creation time.
+// Timestamps are normalized with datetime() since they are stored with the
+// offset of the zone they were written in.
+const (
+	inboxActivityExpr = "datetime(COALESCE(conversations.last_message_at, conversations.created_at))"
+	inboxCreatedExpr  = "datetime(conversations.created_at)"
+	inboxPriorityExpr = "CASE conversations.priority WHEN 'urgent' THEN 3 WHEN 'high' THEN 2 WHEN 'normal' THEN 1 ELSE 0 END"
+)
+
+// inboxCursor is the keyset position after the last row of a page
+type inboxCursor struct {
+	Sort models.InboxSort `json:"s"`
+	Rank int              `json:"r,omitempty"`
+	At   string           `json:"a"`
+	ID   int64            `json:"i"`
+}
+
+// inboxRow is a conversation plus the raw sort keys used to build cursors
+// and the reader's unread count
+type inboxRow struct {
+	models.Conversation `gorm:"embedded"`
+	SortRank            int
+	SortAt              string
+	ReaderUnread        *int64
+}
+
+// ListInbox pages through an organization's conversations using keyset
+// pagination, newest first. Total counts every match, ignoring the cursor.
+func (r *conversationRepository) ListInbox(q *models.InboxQuery) (*models.InboxPage, error) {
+	sort := q.Sort
+	if sort == "" {
+		sort = models.InboxSortLastMessage
+	}
+
+	attrs, err := attributeScope(r.db, q.OrganizationID, models.AttributeEntityConversation,
+		&models.AttributeQuery{Filters: q.Attributes})
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := attrs(r.inboxFilter(q)).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count inbox: %w", err)
+	}
+
+	atExpr := inboxActivityExpr
+	if sort == models.InboxSortCreated {
+		atExpr = inboxCreatedExpr
+	}
+	rankExpr := "0"
+	if sort == models.InboxSortPriority {
+		rankExpr = inboxPriorityExpr
+	}
+
+	query := attrs(r.inboxFilter(q))
+	if q.Reader != "" {
+		query = query.Select(fmt.Sprintf("conversations.*, %s AS sort_rank, CAST(%s AS TEXT) AS sort_at, %s AS reader_unread",
+			rankExpr, atExpr, unreadCountExpr), q.Reader)
+	} else {
+		query = query.Select(fmt.Sprintf("conversations.*, %s AS sort_rank, CAST(%s AS TEXT) AS sort_at", rankExpr, atExpr))
+	}
+
+	if q.Cursor != "" {
+		var cursor inboxCursor
+		if err := utils.DecodeCursor(q.Cursor, &cursor); err != nil || cursor.Sort != sort {
+			return nil, utils.ErrInvalidCursor
+		}
+		if sort == models.InboxSortPriority {
+			query = query.Where(fmt.Sprintf("(%s, %s, conversations.id) < (?, datetime(?), ?)", rankExpr, atExpr),
+				cursor.Rank, cursor.At, cursor.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, conversations.id) < (datetime(?), ?)", atExpr), cursor.At, cursor.ID)
+		}
+	}
+
+	if sort == models.InboxSortPriority {
+		query = query.Order("sort_rank DESC")
+	}
+
+	var rows []*inboxRow
+	err = query.Order("sort_at DESC").
+		Order("conversations.id DESC").
+		Limit(q.Limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox: %w", err)
+	}
+
+	page := &models.InboxPage{
+		Data:  make([]*models.Conversation, 0, len(rows)),
+		Total: total,
+		Limit: q.Limit,
+	}
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		next := utils.EncodeCursor(inboxCursor{
+			Sort: sort,
+			Rank: last.SortRank,
+			At:   last.SortAt,
+			ID:   last.ID,
+		})
+		page.NextCursor = &next
+	}
+	for _, row := range rows {
+		conv := row.Conversation
+		conv.UnreadCount = row.ReaderUnread
+		page.Data = append(page.Data, &conv)
+	}
+
+	return page, nil
+}
+
+// inboxFilter applies every InboxQuery filter except the cursor and the
+// custom attribute filters
+func (r *conversationRepository) inboxFilter(q *models.InboxQuery) *gorm.DB {
+	query := r.db.Model(&models.Conversation{}).
+		Joins("JOIN chat_channels ON chat_channels.id = conversations.channel_id").
+		Where("chat_channels.organization_id = ?", q.OrganizationID)
+
+	if len(q.Statuses) > 0 {
+		query = query.Where("conversations.status IN ?", q.Statuses)
+	} else {
+		query = query.Where("conversations.status <> ?", models.ConversationStatusSnoozed)
+	}
+	if len(q.Priorities) > 0 {
+		query = query.Where("conversations.priority IN ?", q.Priorities)
+	}
+	switch q.Assignee {
+	case "":
+	case models.InboxAssigneeUnassigned:
+		query = query.Where("(conversations.assigned_to_external_id IS NULL OR conversations.assigned_to_external_id = '')")
+	default:
+		query = query.Where("conversations.assigned_to_external_id = ?", q.Assignee)
+	}
+	if len(q.Teams) > 0 {
+		query = query.Where("conversations.team_id IN ?", q.Teams)
+	}
+	if q.TeamMember != "" {
+		query = query.Where("conversations.team_id IN (SELECT team_id FROM team_members WHERE agent_id = ?)", q.TeamMember)
+	}
+	if q.NoTeam {
+		query = query.Where("conversations.team_id IS NULL")
+	}
+	if len(q.Platforms) > 0 {
+		query = query.Where("chat_channels.platform IN ?", q.Platforms)
+	}
+	if len(q.SLAStatuses) > 0 {
+		query = query.Where("conversations.sla_status IN ?", q.SLAStatuses)
+	}
+	if len(q.Tags) > 0 {
+		query = query.Where(`conversations.id IN (
+			SELECT conversation_tags.conversation_id FROM conversation_tags
+			JOIN tags ON tags.id = conversation_tags.tag_id
+			WHERE tags.organization_id = ? AND tags.name IN ?)`, q.OrganizationID, normalizeTagNames(q.Tags))
+	}
+	if q.CreatedAfter != nil {
+		query = query.Where("datetime(conversations.created_at) >= datetime(?)", sqliteTime(*q.CreatedAfter))
+	}
+	if q.CreatedBefore != nil {
+		query = query.Where("datetime(conversations.created_at) < datetime(?)", sqliteTime(*q.CreatedBefore))
+	}
+	if q.LastMessageAfter != nil {
+		query = query.Where("datetime(conversations.last_message_at) >= datetime(?)", sqliteTime(*q.LastMessageAfter))
+	}
+	if q.LastMessageBefore != nil {
+		query = query.Where("datetime(conversations.last_message_at) < datetime(?)", sqliteTime(*q.LastMessageBefore))
+	}
+	if q.HasUnread != nil && q.Reader != "" {
+		// Unread by the reader, as their unread count reports
+		if *q.HasUnread {
+			query = query.Where(unreadCountExpr+" > 0", q.Reader)
+		} else {
+			query = query.Where(unreadCountExpr+" = 0", q.Reader)
+		}
+	} else if q.HasUnread != nil {
+		unread := `EXISTS (SELECT 1 FROM messages WHERE messages.conversation_id = conversations.id
+			AND messages.direction = 'inbound' AND messages.status = 'received')`
+		if *q.HasUnread {
+			query = query.Where(unread)
+		} else {
+			query = query.Where("NOT " + unread)
+		}
+	}
+
+	return query
+}
+
+// sqliteTime formats t like CURRENT_TIMESTAMP so text comparisons against
+// stored timestamps order correctly
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func normalizeTagNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, models.NormalizeTagName(name))
+	}
+	return normalized
+}
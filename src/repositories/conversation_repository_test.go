@@ -1,10 +1,13 @@
 package repositories
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,16 +47,18 @@ func TestConversationRepository_GetOrCreateByUser(t *testing.T) {
 	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-456", "Jane Doe")
 
 	t.Run("create new conversation if not exists", func(t *testing.T) {
-		conv, err := repo.GetOrCreateByUser(channel.ID, user.ID)
+		conv, created, err := repo.GetOrCreateByUser(channel.ID, user.ID)
 		require.NoError(t, err)
 		assert.NotZero(t, conv.ID)
+		assert.True(t, created)
 	})
 
 	t.Run("return existing conversation", func(t *testing.T) {
-		conv1, _ := repo.GetOrCreateByUser(channel.ID, user.ID)
-		conv2, err := repo.GetOrCreateByUser(channel.ID, user.ID)
+		conv1, _, _ := repo.GetOrCreateByUser(channel.ID, user.ID)
+		conv2, created, err := repo.GetOrCreateByUser(channel.ID, user.ID)
 		require.NoError(t, err)
 		assert.Equal(t, conv1.ID, conv2.ID)
+		assert.False(t, created)
 	})
 }
 
@@ -72,14 +77,14 @@ func TestConversationRepository_List(t *testing.T) {
 	}
 
 	t.Run("list all conversations", func(t *testing.T) {
-		conversations, err := repo.List(channel.ID, nil, 10, 0)
+		conversations, err := repo.List(channel.ID, nil, nil, nil, 10, 0)
 		require.NoError(t, err)
 		assert.Len(t, conversations, 5)
 	})
 
 	t.Run("filter by status", func(t *testing.T) {
 		status := models.ConversationStatusOpen
-		conversations, err := repo.List(channel.ID, &status, 10, 0)
+		conversations, err := repo.List(channel.ID, &status, nil, nil, 10, 0)
 		require.NoError(t, err)
 		assert.Len(t, conversations, 5)
 	})
@@ -113,5 +118,750 @@ func TestConversationRepository_Update(t *testing.T) {
 		assert.Equal(t, models.ConversationStatusResolved, found.Status)
 		assert.Equal(t, models.PriorityHigh, found.Priority)
 		assert.Equal(t, "agent-001", *found.AssignedToExternalID)
+		assert.NotNil(t, found.ResolvedAt)
+	})
+
+	t.Run("closing keeps the resolution time", func(t *testing.T) {
+		before, _ := repo.GetByID(conv.ID)
+		closed := models.ConversationStatusClosed
+		require.NoError(t, repo.Update(conv.ID, &models.UpdateConversationRequest{Status: &closed}))
+
+		found, _ := repo.GetByID(conv.ID)
+		require.NotNil(t, found.ResolvedAt)
+		assert.True(t, before.ResolvedAt.Equal(*found.ResolvedAt))
+	})
+
+	t.Run("reopening clears the resolution time", func(t *testing.T) {
+		open := models.ConversationStatusOpen
+		require.NoError(t, repo.Update(conv.ID, &models.UpdateConversationRequest{Status: &open}))
+
+		found, _ := repo.GetByID(conv.ID)
+		assert.Nil(t, found.ResolvedAt)
+	})
+}
+
+func TestConversationRepository_ListInbox(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	other := testutils.CreateTestOrganization(t, db, "Other Org", "otherorg")
+	whatsapp := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	telegram := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	foreign := testutils.CreateTestChannel(t, db, other.ID, models.PlatformWhatsApp, "Other")
+
+	// Six conversations in the org with increasing activity, one elsewhere
+	var convs []*models.Conversation
+	for i := 0; i < 6; i++ {
+		channel := whatsapp
+		if i%2 == 1 {
+			channel = telegram
+		}
+		user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-"+string(rune('a'+i)), "User")
+		conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+		db.Model(conv).Update("last_message_at", time.Date(2026, 1, 1, i, 0, 0, 0, time.UTC))
+		convs = append(convs, conv)
+	}
+	foreignUser := testutils.CreateTestExternalUser(t, db, foreign.ID, "user-z", "User")
+	testutils.CreateTestConversation(t, db, foreign.ID, foreignUser.ID)
+
+	assignee := "agent-1"
+	urgent := models.PriorityUrgent
+	require.NoError(t, repo.Update(convs[1].ID, &models.UpdateConversationRequest{AssignedToExternalID: &assignee, Priority: &urgent}))
+	resolved := models.ConversationStatusResolved
+	require.NoError(t, repo.Update(convs[2].ID, &models.UpdateConversationRequest{Status: &resolved}))
+
+	require.NoError(t, db.Create(&models.Message{
+		ConversationID: convs[3].ID,
+		SenderType:     models.SenderExternal,
+		Content:        "hi",
+		Direction:      models.DirectionInbound,
+		Status:         models.MessageStatusReceived,
+	}).Error)
+
+	tag := &models.Tag{OrganizationID: org.ID, Name: "billing"}
+	require.NoError(t, db.Create(tag).Error)
+	require.NoError(t, db.Create(&models.ConversationTag{ConversationID: convs[4].ID, TagID: tag.ID}).Error)
+
+	ids := func(page *models.InboxPage) []int64 {
+		var out []int64
+		for _, c := range page.Data {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	t.Run("scoped to organization, newest activity first", func(t *testing.T) {
+		page, err := repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), page.Total)
+		assert.Equal(t, []int64{convs[5].ID, convs[4].ID, convs[3].ID, convs[2].ID, convs[1].ID, convs[0].ID}, ids(page))
+		assert.Nil(t, page.NextCursor)
+	})
+
+	t.Run("keyset pagination", func(t *testing.T) {
+		q := &models.InboxQuery{OrganizationID: org.ID, Limit: 4}
+		first, err := repo.ListInbox(q)
+		require.NoError(t, err)
+		require.NotNil(t, first.NextCursor)
+		assert.Len(t, first.Data, 4)
+
+		q.Cursor = *first.NextCursor
+		second, err := repo.ListInbox(q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{convs[1].ID, convs[0].ID}, ids(second))
+		assert.Equal(t, int64(6), second.Total)
+		assert.Nil(t, second.NextCursor)
+	})
+
+	t.Run("priority sort pages through every conversation", func(t *testing.T) {
+		q := &models.InboxQuery{OrganizationID: org.ID, Sort: models.InboxSortPriority, Limit: 2}
+		var seen []int64
+		for {
+			page, err := repo.ListInbox(q)
+			require.NoError(t, err)
+			seen = append(seen, ids(page)...)
+			if page.NextCursor == nil {
+				break
+			}
+			q.Cursor = *page.NextCursor
+		}
+		assert.Len(t, seen, 6)
+		assert.Equal(t, convs[1].ID, seen[0])
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		yes := true
+		after := time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC)
+
+		cases := []struct {
+			name string
+			q    models.InboxQuery
+			want []int64
+		}{
+			{"status", models.InboxQuery{Statuses: []models.ConversationStatus{models.ConversationStatusResolved}}, []int64{convs[2].ID}},
+			{"priority", models.InboxQuery{Priorities: []models.ConversationPriority{models.PriorityUrgent}}, []int64{convs[1].ID}},
+			{"assignee", models.InboxQuery{Assignee: "agent-1"}, []int64{convs[1].ID}},
+			{"platform", models.InboxQuery{Platforms: []models.Platform{models.PlatformTelegram}}, []int64{convs[5].ID, convs[3].ID, convs[1].ID}},
+			{"tag", models.InboxQuery{Tags: []string{"billing"}}, []int64{convs[4].ID}},
+			{"has unread", models.InboxQuery{HasUnread: &yes}, []int64{convs[3].ID}},
+			{"last message after", models.InboxQuery{LastMessageAfter: &after}, []int64{convs[5].ID, convs[4].ID}},
+		}
+		for _, tc := range cases {
+			tc.q.OrganizationID = org.ID
+			tc.q.Limit = 10
+			page, err := repo.ListInbox(&tc.q)
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, tc.want, ids(page), tc.name)
+			assert.Equal(t, int64(len(tc.want)), page.Total, tc.name)
+		}
+
+		page, err := repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Assignee: models.InboxAssigneeUnassigned, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+	})
+
+	t.Run("rejects invalid cursor", func(t *testing.T) {
+		_, err := repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Cursor: "not-a-cursor", Limit: 10})
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+}
+
+func TestConversationRepository_ListInbox_LocalTime(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("+0545", 5*60*60+45*60)
+	defer func() { time.Local = local }()
+
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	// Stored with different offsets: the local 10:00 is 04:15 UTC, before
+	// the 05:00 UTC one even though it sorts after it as text
+	earlier := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	later := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	require.NoError(t, db.Model(earlier).Update("last_message_at", time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)).Error)
+	require.NoError(t, db.Model(later).Update("last_message_at", time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)).Error)
+
+	t.Run("sorts and pages by instant", func(t *testing.T) {
+		q := &models.InboxQuery{OrganizationID: org.ID, Limit: 1}
+		first, err := repo.ListInbox(q)
+		require.NoError(t, err)
+		require.Len(t, first.Data, 1)
+		assert.Equal(t, later.ID, first.Data[0].ID)
+		require.NotNil(t, first.NextCursor)
+
+		q.Cursor = *first.NextCursor
+		second, err := repo.ListInbox(q)
+		require.NoError(t, err)
+		require.Len(t, second.Data, 1)
+		assert.Equal(t, earlier.ID, second.Data[0].ID)
+	})
+
+	t.Run("filters by instant", func(t *testing.T) {
+		after := time.Date(2026, 1, 1, 4, 30, 0, 0, time.UTC)
+		page, err := repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, LastMessageAfter: &after, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, later.ID, page.Data[0].ID)
+
+		page, err = repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, LastMessageBefore: &after, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, earlier.ID, page.Data[0].ID)
+	})
+}
+
+func TestConversationRepository_ListUnanswered(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	policyRepo := NewRoutingPolicyRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	_, err := policyRepo.Upsert(channel.ID, &models.UpsertRoutingPolicyRequest{Strategy: models.RoutingLeastOpen, ReassignAfterSeconds: 60})
+	require.NoError(t, err)
+
+	agent := "agent-1"
+	waiting := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	answered := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	unassigned := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	for _, conv := range []*models.Conversation{waiting, answered} {
+		require.NoError(t, repo.Update(conv.ID, &models.UpdateConversationRequest{AssignedToExternalID: &agent}))
+	}
+	require.NoError(t, db.Create(&models.Message{
+		ConversationID: answered.ID,
+		SenderType:     models.SenderInternal,
+		Content:        "hello",
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+	}).Error)
+	require.NoError(t, db.Create(&models.Message{
+		ConversationID: waiting.ID,
+		SenderType:     models.SenderSystem,
+		Content:        "Assigned to agent-1",
+		MessageType:    models.MessageTypeSystem,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+	}).Error)
+
+	t.Run("within the window", func(t *testing.T) {
+		convs, err := repo.ListUnanswered(time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, convs)
+	})
+
+	t.Run("after the window", func(t *testing.T) {
+		convs, err := repo.ListUnanswered(time.Now().Add(2 * time.Minute))
+		require.NoError(t, err)
+		require.Len(t, convs, 1)
+		assert.Equal(t, waiting.ID, convs[0].ID)
+	})
+
+	t.Run("previous assignee", func(t *testing.T) {
+		previous, err := repo.LastAssigneeForUser(user.ID, unassigned.ID)
+		require.NoError(t, err)
+		require.NotNil(t, previous)
+		assert.Equal(t, agent, *previous)
+	})
+}
+
+func TestConversationRepository_ListInbox_Teams(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	teamRepo := NewTeamRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	billing, err := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: org.ID, Name: "billing", MemberIDs: []string{"agent-1"}})
+	require.NoError(t, err)
+	tier2, err := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: org.ID, Name: "tier 2", MemberIDs: []string{"agent-2"}})
+	require.NoError(t, err)
+
+	inBilling := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	inTier2 := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	noTeam := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	require.NoError(t, repo.Update(inBilling.ID, &models.UpdateConversationRequest{TeamID: &billing.ID}))
+	require.NoError(t, repo.Update(inTier2.ID, &models.UpdateConversationRequest{TeamID: &tier2.ID}))
+
+	cases := []struct {
+		name string
+		q    models.InboxQuery
+		want []int64
+	}{
+		{"team", models.InboxQuery{Teams: []int64{tier2.ID}}, []int64{inTier2.ID}},
+		{"teams", models.InboxQuery{Teams: []int64{billing.ID, tier2.ID}}, []int64{inTier2.ID, inBilling.ID}},
+		{"team member", models.InboxQuery{TeamMember: "agent-1"}, []int64{inBilling.ID}},
+		{"no team", models.InboxQuery{NoTeam: true}, []int64{noTeam.ID}},
+	}
+	for _, tc := range cases {
+		tc.q.OrganizationID = org.ID
+		tc.q.Limit = 10
+		tc.q.Sort = models.InboxSortCreated
+		page, err := repo.ListInbox(&tc.q)
+		require.NoError(t, err, tc.name)
+
+		var got []int64
+		for _, conv := range page.Data {
+			got = append(got, conv.ID)
+		}
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+func TestConversationRepository_ListSLADue(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	slaRepo := NewSLARepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	policy, err := slaRepo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "Default", FirstResponseSeconds: 600, WarnBeforeSeconds: 120})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	due := now.Add(10 * time.Minute)
+	replied := now
+
+	pending := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	answered := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	resolved := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	testutils.CreateTestConversation(t, db, channel.ID, user.ID) // no policy
+	for _, conv := range []*models.Conversation{pending, answered, resolved} {
+		conv.SLAPolicyID = &policy.ID
+		conv.SLAStatus = models.SLAStatusOK
+		conv.FirstResponseDueAt = &due
+		if conv == answered {
+			conv.FirstResponseAt = &replied
+		}
+		require.NoError(t, repo.SaveSLA(conv))
+	}
+	status := models.ConversationStatusResolved
+	require.NoError(t, repo.Update(resolved.ID, &models.UpdateConversationRequest{Status: &status}))
+
+	t.Run("before the warning window", func(t *testing.T) {
+		convs, err := repo.ListSLADue(now)
+		require.NoError(t, err)
+		assert.Empty(t, convs)
+	})
+
+	t.Run("within the warning window", func(t *testing.T) {
+		convs, err := repo.ListSLADue(now.Add(9 * time.Minute))
+		require.NoError(t, err)
+		require.Len(t, convs, 1)
+		assert.Equal(t, pending.ID, convs[0].ID)
+	})
+
+	t.Run("inbox filter", func(t *testing.T) {
+		pending.SLAStatus = models.SLAStatusBreached
+		require.NoError(t, repo.SaveSLA(pending))
+
+		page, err := repo.ListInbox(&models.InboxQuery{
+			OrganizationID: org.ID,
+			SLAStatuses:    []models.SLAStatus{models.SLAStatusBreached},
+			Limit:          20,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, pending.ID, page.Data[0].ID)
+	})
+}
+
+func TestConversationRepository_LatestByUser(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	latest, err := repo.LatestByUser(channel.ID, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	second := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	resolved := models.ConversationStatusResolved
+	require.NoError(t, repo.Update(second.ID, &models.UpdateConversationRequest{Status: &resolved}))
+
+	latest, err = repo.LatestByUser(channel.ID, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, second.ID, latest.ID)
+	assert.Equal(t, models.ConversationStatusResolved, latest.Status)
+	assert.NotNil(t, latest.ResolvedAt)
+}
+
+func TestConversationRepository_ListIdle(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	policyRepo := NewLifecyclePolicyRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	other := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+
+	_, err := policyRepo.Upsert(channel.ID, &models.UpsertLifecyclePolicyRequest{IdleAfterSeconds: 3600})
+	require.NoError(t, err)
+
+	quiet := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	resolved := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	testutils.CreateTestConversation(t, db, other.ID, user.ID) // no policy
+	status := models.ConversationStatusResolved
+	require.NoError(t, repo.Update(resolved.ID, &models.UpdateConversationRequest{Status: &status}))
+
+	t.Run("within the idle period", func(t *testing.T) {
+		convs, err := repo.ListIdle(time.Now().Add(30 * time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, convs)
+	})
+
+	t.Run("after the idle period", func(t *testing.T) {
+		convs, err := repo.ListIdle(time.Now().Add(2 * time.Hour))
+		require.NoError(t, err)
+		require.Len(t, convs, 1)
+		assert.Equal(t, quiet.ID, convs[0].ID)
+	})
+
+	t.Run("recent messages keep it open", func(t *testing.T) {
+		require.NoError(t, repo.UpdateLastMessage(quiet.ID))
+		convs, err := repo.ListIdle(time.Now().Add(30 * time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, convs)
+	})
+}
+
+func TestConversationRepository_MergeAndSplit(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	target := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	source := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	base := time.Now().Add(-time.Hour)
+	addMessage := func(convID int64, at time.Time) *models.Message {
+		msg := &models.Message{
+			ConversationID: convID,
+			SenderType:     models.SenderExternal,
+			Content:        "hi",
+			Direction:      models.DirectionInbound,
+			Status:         models.MessageStatusReceived,
+			CreatedAt:      at,
+		}
+		require.NoError(t, db.Create(msg).Error)
+		return msg
+	}
+	addMessage(target.ID, base)
+	addMessage(source.ID, base.Add(10*time.Minute))
+	last := addMessage(source.ID, base.Add(20*time.Minute))
+
+	shared := &models.Tag{OrganizationID: org.ID, Name: "billing"}
+	other := &models.Tag{OrganizationID: org.ID, Name: "vip"}
+	require.NoError(t, db.Create(shared).Error)
+	require.NoError(t, db.Create(other).Error)
+	require.NoError(t, db.Create(&models.ConversationTag{ConversationID: target.ID, TagID: shared.ID}).Error)
+	require.NoError(t, db.Create(&models.ConversationTag{ConversationID: source.ID, TagID: shared.ID}).Error)
+	require.NoError(t, db.Create(&models.ConversationTag{ConversationID: source.ID, TagID: other.ID}).Error)
+	require.NoError(t, db.Create(&models.ConversationEvent{ConversationID: source.ID, Type: models.ConversationEventPriorityChanged}).Error)
+
+	t.Run("merge", func(t *testing.T) {
+		moved, err := repo.Merge(source.ID, target.ID, []*models.ConversationEvent{
+			{ConversationID: target.ID, Type: models.ConversationEventMerged},
+			{ConversationID: source.ID, Type: models.ConversationEventMerged},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), moved)
+
+		var count int64
+		db.Model(&models.Message{}).Where("conversation_id = ?", target.ID).Count(&count)
+		assert.Equal(t, int64(3), count)
+		db.Model(&models.ConversationTag{}).Where("conversation_id = ?", target.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+		db.Model(&models.ConversationTag{}).Where("conversation_id = ?", source.ID).Count(&count)
+		assert.Zero(t, count)
+		db.Model(&models.ConversationEvent{}).Where("conversation_id = ?", target.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+		db.Model(&models.ConversationEvent{}).Where("conversation_id = ? AND type = ?", source.ID, models.ConversationEventMerged).Count(&count)
+		assert.Equal(t, int64(1), count)
+
+		merged, err := repo.GetByID(source.ID)
+		require.NoError(t, err)
+		require.NotNil(t, merged.MergedIntoID)
+		assert.Equal(t, target.ID, *merged.MergedIntoID)
+		assert.Equal(t, models.ConversationStatusClosed, merged.Status)
+		assert.Nil(t, merged.LastMessageAt)
+
+		updated, err := repo.GetByID(target.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updated.LastMessageAt)
+		assert.WithinDuration(t, last.CreatedAt, *updated.LastMessageAt, time.Second)
+
+		latest, err := repo.LatestByUser(channel.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, target.ID, latest.ID)
+	})
+
+	var split *models.Conversation
+	t.Run("split an open conversation", func(t *testing.T) {
+		conv, moved, err := repo.Split(target.ID, last.ID, last.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+		assert.NotEqual(t, target.ID, conv.ID)
+		assert.Equal(t, user.ID, conv.ExternalUserID)
+		require.NotNil(t, conv.FirstMessageAt)
+		assert.WithinDuration(t, last.CreatedAt, *conv.FirstMessageAt, time.Second)
+		split = conv
+
+		// The source stays the conversation new messages go to
+		assert.Equal(t, models.ConversationStatusResolved, conv.Status)
+		assert.NotNil(t, conv.ResolvedAt)
+		active, created, err := repo.GetOrCreateByUser(channel.ID, user.ID)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, target.ID, active.ID)
+
+		var msg models.Message
+		require.NoError(t, db.First(&msg, last.ID).Error)
+		assert.Equal(t, conv.ID, msg.ConversationID)
+
+		updated, err := repo.GetByID(target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ConversationStatusOpen, updated.Status)
+		assert.WithinDuration(t, base.Add(10*time.Minute), *updated.LastMessageAt, time.Second)
+	})
+
+	t.Run("split rejected while the user has another open conversation", func(t *testing.T) {
+		_, _, err := repo.Split(split.ID, last.ID, last.ID)
+		assert.ErrorIs(t, err, models.ErrInvalidMerge)
+
+		var msg models.Message
+		require.NoError(t, db.First(&msg, last.ID).Error)
+		assert.Equal(t, split.ID, msg.ConversationID)
+	})
+
+	t.Run("split a resolved conversation", func(t *testing.T) {
+		resolved := models.ConversationStatusResolved
+		require.NoError(t, repo.Update(target.ID, &models.UpdateConversationRequest{Status: &resolved}))
+
+		conv, moved, err := repo.Split(split.ID, last.ID, last.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+		assert.Equal(t, models.ConversationStatusOpen, conv.Status)
+		assert.Nil(t, conv.ResolvedAt)
+
+		var msg models.Message
+		require.NoError(t, db.First(&msg, last.ID).Error)
+		assert.Equal(t, conv.ID, msg.ConversationID)
+	})
+
+	t.Run("split without messages in range rolls back", func(t *testing.T) {
+		require.NoError(t, db.Model(&models.Conversation{}).Where("status = ?", models.ConversationStatusOpen).
+			Update("status", models.ConversationStatusResolved).Error)
+
+		var before int64
+		db.Model(&models.Conversation{}).Count(&before)
+
+		_, _, err := repo.Split(target.ID, last.ID+100, last.ID+200)
+		assert.ErrorIs(t, err, models.ErrInvalidMerge)
+
+		var after int64
+		db.Model(&models.Conversation{}).Count(&after)
+		assert.Equal(t, before, after)
+	})
+}
+
+func TestConversationRepository_MergeActivityMessages(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	require.NoError(t, db.Model(channel).Update("activity_messages", true).Error)
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	target := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	source := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	from, to := strconv.FormatInt(source.ID, 10), strconv.FormatInt(target.ID, 10)
+	_, err := repo.Merge(source.ID, target.ID, []*models.ConversationEvent{
+		{ConversationID: target.ID, Type: models.ConversationEventMerged, FromValue: &from, ToValue: &to},
+	})
+	require.NoError(t, err)
+
+	var activity []*models.Message
+	require.NoError(t, db.Where("conversation_id = ? AND message_type = ?", target.ID, models.MessageTypeSystem).Find(&activity).Error)
+	require.Len(t, activity, 1)
+	assert.Equal(t, models.SenderSystem, activity[0].SenderType)
+	assert.Equal(t, "Conversation "+from+" merged into conversation "+to, activity[0].Content)
+}
+
+func TestConversationRepository_Snooze(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	active := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	other := testutils.CreateTestExternalUser(t, db, channel.ID, "user-456", "Jane Doe")
+	testutils.CreateTestConversation(t, db, channel.ID, other.ID)
+
+	snoozed := models.ConversationStatusSnoozed
+	until := time.Now().Add(time.Hour)
+	require.NoError(t, repo.Update(active.ID, &models.UpdateConversationRequest{Status: &snoozed, SnoozedUntil: &until}))
+
+	conv, err := repo.GetByID(active.ID)
+	require.NoError(t, err)
+	require.NotNil(t, conv.SnoozedUntil)
+	assert.WithinDuration(t, until, *conv.SnoozedUntil, time.Second)
+
+	t.Run("still the customer's active conversation", func(t *testing.T) {
+		conv, created, err := repo.GetOrCreateByUser(channel.ID, user.ID)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, active.ID, conv.ID)
+	})
+
+	t.Run("hidden from the default inbox", func(t *testing.T) {
+		page, err := repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Limit: 20})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.NotEqual(t, active.ID, page.Data[0].ID)
+
+		page, err = repo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Statuses: []models.ConversationStatus{snoozed}, Limit: 20})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, active.ID, page.Data[0].ID)
+	})
+
+	t.Run("due at the wake-up time", func(t *testing.T) {
+		due, err := repo.ListSnoozedDue(time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, due)
+
+		due, err = repo.ListSnoozedDue(until.Add(time.Second))
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, active.ID, due[0].ID)
+	})
+
+	t.Run("other statuses clear the wake-up time", func(t *testing.T) {
+		open := models.ConversationStatusOpen
+		require.NoError(t, repo.Update(active.ID, &models.UpdateConversationRequest{Status: &open}))
+		conv, err := repo.GetByID(active.ID)
+		require.NoError(t, err)
+		assert.Nil(t, conv.SnoozedUntil)
+	})
+}
+
+func TestConversationRepository_ClaimAwayReply(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	at := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	claimed, err := repo.ClaimAwayReply(conv.ID, at, at.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Within the cooldown
+	later := at.Add(30 * time.Minute)
+	claimed, err = repo.ClaimAwayReply(conv.ID, later, later.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	later = at.Add(time.Hour)
+	claimed, err = repo.ClaimAwayReply(conv.ID, later, later.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	found, err := repo.GetByID(conv.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.AwayRepliedAt)
+	assert.True(t, later.Equal(*found.AwayRepliedAt))
+}
+
+func TestConversationRepository_GetOrCreateByChat(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewConversationRepository(db)
+	participants := NewParticipantRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	alice := testutils.CreateTestExternalUser(t, db, channel.ID, "alice", "Alice")
+	bob := testutils.CreateTestExternalUser(t, db, channel.ID, "bob", "Bob")
+
+	group, created, err := repo.GetOrCreateByChat(channel.ID, "group-1", alice.ID)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, alice.ID, group.ExternalUserID)
+	require.NotNil(t, group.PlatformChatID)
+	assert.Equal(t, "group-1", *group.PlatformChatID)
+
+	// Anyone writing in the chat gets the same conversation
+	again, created, err := repo.GetOrCreateByChat(channel.ID, "group-1", bob.ID)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, group.ID, again.ID)
+
+	// The requester's one-to-one chat is separate
+	direct, created, err := repo.GetOrCreateByUser(channel.ID, alice.ID)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEqual(t, group.ID, direct.ID)
+
+	latest, err := repo.LatestByUser(channel.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, direct.ID, latest.ID)
+	latest, err = repo.LatestByChat(channel.ID, "group-1")
+	require.NoError(t, err)
+	assert.Equal(t, group.ID, latest.ID)
+
+	t.Run("merge moves participants", func(t *testing.T) {
+		now := time.Now().UTC()
+		_, _, err := participants.Join(group.ID, alice.ID, models.ParticipantRoleRequester, now)
+		require.NoError(t, err)
+		_, _, err = participants.Join(group.ID, bob.ID, models.ParticipantRoleMember, now)
+		require.NoError(t, err)
+		_, _, err = participants.Join(direct.ID, alice.ID, models.ParticipantRoleRequester, now)
+		require.NoError(t, err)
+
+		_, err = repo.Merge(group.ID, direct.ID, nil)
+		require.NoError(t, err)
+
+		moved, err := participants.ListByConversation(direct.ID)
+		require.NoError(t, err)
+		assert.Len(t, moved, 2)
+		left, err := participants.ListByConversation(group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, left)
 	})
 }
@@ -0,0 +1,229 @@
+package repositories
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CSATRepository interface {
+	// FindPolicy returns nil when the channel has no CSAT policy
+	FindPolicy(channelID int64) (*models.CSATPolicy, error)
+	UpsertPolicy(channelID int64, req *models.UpsertCSATPolicyRequest) (*models.CSATPolicy, error)
+	CreateSurvey(survey *models.CSATSurvey) (*models.CSATSurvey, error)
+	// FindOpen returns the customer's latest survey on the channel that is
+	// still waiting for a rating or comment at the given time, or nil
+	FindOpen(channelID, externalUserID int64, at time.Time) (*models.CSATSurvey, error)
+	Rate(id int64, rating int, comment *string, status models.CSATStatus, at time.Time) error
+	Comment(id int64, comment string) error
+	ListByConversation(conversationID int64) ([]*models.CSATSurvey, error)
+	Report(q *models.CSATReportQuery) (*models.CSATReport, error)
+}
+
+type csatRepository struct {
+	db *gorm.DB
+}
+
+func NewCSATRepository(db *gorm.DB) CSATRepository {
+	return &csatRepository{db: db}
+}
+
+func (r *csatRepository) FindPolicy(channelID int64) (*models.CSATPolicy, error) {
+	var policy models.CSATPolicy
+	if err := r.db.Where("channel_id = ?", channelID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get CSAT policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *csatRepository) UpsertPolicy(channelID int64, req *models.UpsertCSATPolicyRequest) (*models.CSATPolicy, error) {
+	window := req.ResponseWindowSeconds
+	if window == 0 {
+		window = int(models.DefaultCSATResponseWindow / time.Second)
+	}
+
+	policy := &models.CSATPolicy{
+		ChannelID:             channelID,
+		Enabled:               req.Enabled,
+		Question:              req.Question,
+		CommentPrompt:         req.CommentPrompt,
+		ResponseWindowSeconds: window,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "question", "comment_prompt", "response_window_seconds", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save CSAT policy: %w", err)
+	}
+
+	return r.FindPolicy(channelID)
+}
+
+func (r *csatRepository) CreateSurvey(survey *models.CSATSurvey) (*models.CSATSurvey, error) {
+	if err := r.db.Create(survey).Error; err != nil {
+		return nil, fmt.Errorf("failed to create CSAT survey: %w", err)
+	}
+	return survey, nil
+}
+
+func (r *csatRepository) FindOpen(channelID, externalUserID int64, at time.Time) (*models.CSATSurvey, error) {
+	var survey models.CSATSurvey
+	err := r.db.
+		Where("channel_id = ? AND external_user_id = ?", channelID, externalUserID).
+		Where("status IN ?", []models.CSATStatus{models.CSATStatusPending, models.CSATStatusAwaitingComment}).
+		Where("datetime(expires_at) > datetime(?)", sqliteTime(at)).
+		Order("sent_at DESC").
+		Order("id DESC").
+		First(&survey).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get CSAT survey: %w", err)
+	}
+	return &survey, nil
+}
+
+func (r *csatRepository) Rate(id int64, rating int, comment *string, status models.CSATStatus, at time.Time) error {
+	err := r.db.Model(&models.CSATSurvey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"rating":   rating,
+		"comment":  comment,
+		"status":   status,
+		"rated_at": at,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to rate CSAT survey: %w", err)
+	}
+	return nil
+}
+
+func (r *csatRepository) Comment(id int64, comment string) error {
+	err := r.db.Model(&models.CSATSurvey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"comment": comment,
+		"status":  models.CSATStatusCompleted,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save CSAT comment: %w", err)
+	}
+	return nil
+}
+
+func (r *csatRepository) ListByConversation(conversationID int64) ([]*models.CSATSurvey, error) {
+	surveys := make([]*models.CSATSurvey, 0)
+	err := r.db.Where("conversation_id = ?", conversationID).
+		Order("sent_at").
+		Order("id").
+		Find(&surveys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSAT surveys: %w", err)
+	}
+	return surveys, nil
+}
+
+// csatRatingRow counts the surveys of one agent with one rating, NULL when
+// unanswered
+type csatRatingRow struct {
+	AgentID *string
+	Rating  *int
+	Count   int64
+}
+
+func (r *csatRepository) Report(q *models.CSATReportQuery) (*models.CSATReport, error) {
+	query := r.db.Model(&models.CSATSurvey{}).
+		Select("csat_surveys.agent_id, csat_surveys.rating, COUNT(*) AS count").
+		Joins("JOIN chat_channels ON chat_channels.id = csat_surveys.channel_id").
+		Where("chat_channels.organization_id = ?", q.OrganizationID)
+	if q.ChannelID != nil {
+		query = query.Where("csat_surveys.channel_id = ?", *q.ChannelID)
+	}
+	if q.AgentID != nil {
+		query = query.Where("csat_surveys.agent_id = ?", *q.AgentID)
+	}
+	if q.SentAfter != nil {
+		query = query.Where("datetime(csat_surveys.sent_at) >= datetime(?)", sqliteTime(*q.SentAfter))
+	}
+	if q.SentBefore != nil {
+		query = query.Where("datetime(csat_surveys.sent_at) < datetime(?)", sqliteTime(*q.SentBefore))
+	}
+
+	var rows []csatRatingRow
+	if err := query.Group("csat_surveys.agent_id, csat_surveys.rating").Order("csat_surveys.agent_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to report CSAT: %w", err)
+	}
+
+	report := &models.CSATReport{
+		CSATSummary: newCSATSummary(),
+		Agents:      make([]*models.CSATAgentSummary, 0),
+	}
+	agents := make(map[string]*models.CSATAgentSummary)
+	for _, row := range rows {
+		addCSATRating(&report.CSATSummary, row)
+		if row.AgentID == nil {
+			continue
+		}
+		agent, ok := agents[*row.AgentID]
+		if !ok {
+			agent = &models.CSATAgentSummary{AgentID: *row.AgentID, CSATSummary: newCSATSummary()}
+			agents[*row.AgentID] = agent
+			report.Agents = append(report.Agents, agent)
+		}
+		addCSATRating(&agent.CSATSummary, row)
+	}
+
+	finishCSATSummary(&report.CSATSummary)
+	for _, agent := range report.Agents {
+		finishCSATSummary(&agent.CSATSummary)
+	}
+	return report, nil
+}
+
+func newCSATSummary() models.CSATSummary {
+	ratings := make(map[int]int64, models.CSATMaxRating)
+	for rating := models.CSATMinRating; rating <= models.CSATMaxRating; rating++ {
+		ratings[rating] = 0
+	}
+	return models.CSATSummary{Ratings: ratings}
+}
+
+func addCSATRating(summary *models.CSATSummary, row csatRatingRow) {
+	summary.Sent += row.Count
+	if row.Rating != nil {
+		summary.Responses += row.Count
+		summary.Ratings[*row.Rating] += row.Count
+	}
+}
+
+// finishCSATSummary derives the rates and average from the counts,
+// rounded to two decimals
+func finishCSATSummary(summary *models.CSATSummary) {
+	if summary.Sent > 0 {
+		summary.ResponseRate = round2(float64(summary.Responses) * 100 / float64(summary.Sent))
+	}
+	if summary.Responses == 0 {
+		return
+	}
+
+	var total, satisfied int64
+	for rating, count := range summary.Ratings {
+		total += int64(rating) * count
+		if rating >= 4 {
+			satisfied += count
+		}
+	}
+	summary.AverageRating = round2(float64(total) / float64(summary.Responses))
+	summary.Score = round2(float64(satisfied) * 100 / float64(summary.Responses))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
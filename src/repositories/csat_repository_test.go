@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSATRepository_UpsertPolicy(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCSATRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+
+	policy, err := repo.FindPolicy(channel.ID)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	prompt := "Anything we could do better?"
+	policy, err = repo.UpsertPolicy(channel.ID, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?", CommentPrompt: &prompt})
+	require.NoError(t, err)
+	assert.True(t, policy.Enabled)
+	assert.Equal(t, 86400, policy.ResponseWindowSeconds)
+	assert.Equal(t, prompt, *policy.CommentPrompt)
+
+	updated, err := repo.UpsertPolicy(channel.ID, &models.UpsertCSATPolicyRequest{Question: "Rate us", ResponseWindowSeconds: 3600})
+	require.NoError(t, err)
+	assert.Equal(t, policy.ID, updated.ID)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, 3600, updated.ResponseWindowSeconds)
+	assert.Nil(t, updated.CommentPrompt)
+}
+
+func TestCSATRepository_FindOpen(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCSATRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-1", "John")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	now := time.Now()
+	older, err := repo.CreateSurvey(&models.CSATSurvey{ConversationID: conv.ID, ChannelID: channel.ID, ExternalUserID: user.ID,
+		Status: models.CSATStatusPending, SentAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	latest, err := repo.CreateSurvey(&models.CSATSurvey{ConversationID: conv.ID, ChannelID: channel.ID, ExternalUserID: user.ID,
+		Status: models.CSATStatusPending, SentAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	open, err := repo.FindOpen(channel.ID, user.ID, now)
+	require.NoError(t, err)
+	require.NotNil(t, open)
+	assert.Equal(t, latest.ID, open.ID)
+
+	t.Run("skips expired surveys", func(t *testing.T) {
+		open, err := repo.FindOpen(channel.ID, user.ID, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, open)
+	})
+
+	t.Run("skips completed surveys", func(t *testing.T) {
+		require.NoError(t, repo.Rate(latest.ID, 4, nil, models.CSATStatusCompleted, now))
+		open, err := repo.FindOpen(channel.ID, user.ID, now)
+		require.NoError(t, err)
+		require.NotNil(t, open)
+		assert.Equal(t, older.ID, open.ID)
+	})
+}
+
+func TestCSATRepository_Report(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCSATRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	other := testutils.CreateTestOrganization(t, db, "Other Org", "otherorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	otherChannel := testutils.CreateTestChannel(t, db, other.ID, models.PlatformWhatsApp, "Other")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-1", "John")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	now := time.Now()
+	alice, bob := "alice", "bob"
+	survey := func(channelID int64, agentID *string, rating int, sentAt time.Time) {
+		s, err := repo.CreateSurvey(&models.CSATSurvey{ConversationID: conv.ID, ChannelID: channelID, ExternalUserID: user.ID,
+			AgentID: agentID, Status: models.CSATStatusPending, SentAt: sentAt, ExpiresAt: sentAt.Add(time.Hour)})
+		require.NoError(t, err)
+		if rating > 0 {
+			require.NoError(t, repo.Rate(s.ID, rating, nil, models.CSATStatusCompleted, sentAt))
+		}
+	}
+	survey(channel.ID, &alice, 5, now)
+	survey(channel.ID, &alice, 4, now)
+	survey(channel.ID, &alice, 0, now)
+	survey(channel.ID, &bob, 2, now)
+	survey(channel.ID, nil, 5, now)
+	survey(channel.ID, &bob, 1, now.Add(-48*time.Hour))
+	survey(otherChannel.ID, &alice, 1, now)
+
+	report, err := repo.Report(&models.CSATReportQuery{OrganizationID: org.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), report.Sent)
+	assert.Equal(t, int64(5), report.Responses)
+	assert.Equal(t, 83.33, report.ResponseRate)
+	assert.Equal(t, 3.4, report.AverageRating)
+	assert.Equal(t, 60.0, report.Score)
+	assert.Equal(t, int64(2), report.Ratings[5])
+	assert.Equal(t, int64(0), report.Ratings[3])
+
+	require.Len(t, report.Agents, 2)
+	assert.Equal(t, "alice", report.Agents[0].AgentID)
+	assert.Equal(t, int64(3), report.Agents[0].Sent)
+	assert.Equal(t, 4.5, report.Agents[0].AverageRating)
+	assert.Equal(t, 100.0, report.Agents[0].Score)
+	assert.Equal(t, "bob", report.Agents[1].AgentID)
+	assert.Equal(t, 1.5, report.Agents[1].AverageRating)
+
+	t.Run("filters by agent and time", func(t *testing.T) {
+		since := now.Add(-time.Hour)
+		report, err := repo.Report(&models.CSATReportQuery{OrganizationID: org.ID, AgentID: &bob, SentAfter: &since})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), report.Sent)
+		assert.Equal(t, 2.0, report.AverageRating)
+		assert.Equal(t, 0.0, report.Score)
+	})
+}
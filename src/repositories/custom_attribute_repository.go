@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+// attributeTables maps each custom attribute entity to the table holding
+// its values in a custom_attributes JSON column
+var attributeTables = map[models.CustomAttributeEntity]string{
+	models.AttributeEntityConversation: "conversations",
+	models.AttributeEntityContact:      "external_users",
+}
+
+var attributeOperators = map[models.AttributeOp]string{
+	models.AttributeOpGt:  ">",
+	models.AttributeOpGte: ">=",
+	models.AttributeOpLt:  "<",
+	models.AttributeOpLte: "<=",
+}
+
+type CustomAttributeRepository interface {
+	// Create stores the definition and indexes the attribute's values
+	Create(req *models.CreateCustomAttributeRequest) (*models.CustomAttributeDefinition, error)
+	GetByID(id int64) (*models.CustomAttributeDefinition, error)
+	ListByOrganization(orgID int64, entity *models.CustomAttributeEntity, limit, offset int) ([]*models.CustomAttributeDefinition, error)
+	// Definitions returns the organization's definitions for entity by key
+	Definitions(orgID int64, entity models.CustomAttributeEntity) (map[string]*models.CustomAttributeDefinition, error)
+	Update(id int64, req *models.UpdateCustomAttributeRequest) error
+	// Delete removes the definition and the attribute's values from every
+	// record of the organization
+	Delete(id int64) error
+}
+
+type customAttributeRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomAttributeRepository(db *gorm.DB) CustomAttributeRepository {
+	return &customAttributeRepository{db: db}
+}
+
+func (r *customAttributeRepository) Create(req *models.CreateCustomAttributeRequest) (*models.CustomAttributeDefinition, error) {
+	def := &models.CustomAttributeDefinition{
+		OrganizationID: req.OrganizationID,
+		Entity:         req.Entity,
+		Key:            req.Key,
+		Label:          req.Label,
+		Type:           req.Type,
+		Options:        req.Options,
+		Description:    req.Description,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(def).Error; err != nil {
+			return fmt.Errorf("failed to create custom attribute: %w", err)
+		}
+
+		// Indexes are shared by every organization using the same key
+		table := attributeTables[def.Entity]
+		if err := tx.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (json_extract(custom_attributes, '$.%s'))",
+			attributeIndexName(table, def.Key), table, def.Key)).Error; err != nil {
+			return fmt.Errorf("failed to index custom attribute: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (r *customAttributeRepository) GetByID(id int64) (*models.CustomAttributeDefinition, error) {
+	var def models.CustomAttributeDefinition
+	if err := r.db.First(&def, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("custom attribute not found")
+		}
+		return nil, fmt.Errorf("failed to get custom attribute: %w", err)
+	}
+	return &def, nil
+}
+
+func (r *customAttributeRepository) ListByOrganization(orgID int64, entity *models.CustomAttributeEntity, limit, offset int) ([]*models.CustomAttributeDefinition, error) {
+	query := r.db.Where("organization_id = ?", orgID)
+	if entity != nil {
+		query = query.Where("entity = ?", *entity)
+	}
+
+	var defs []*models.CustomAttributeDefinition
+	err := query.Order("entity").
+		Order("key").
+		Limit(limit).
+		Offset(offset).
+		Find(&defs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom attributes: %w", err)
+	}
+	return defs, nil
+}
+
+func (r *customAttributeRepository) Definitions(orgID int64, entity models.CustomAttributeEntity) (map[string]*models.CustomAttributeDefinition, error) {
+	return attributeDefinitions(r.db, orgID, entity)
+}
+
+func (r *customAttributeRepository) Update(id int64, req *models.UpdateCustomAttributeRequest) error {
+	def, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if req.Label != nil {
+		def.Label = *req.Label
+	}
+	if req.Options != nil {
+		def.Options = req.Options
+	}
+	if req.Description != nil {
+		def.Description = req.Description
+	}
+
+	if err := r.db.Save(def).Error; err != nil {
+		return fmt.Errorf("failed to update custom attribute: %w", err)
+	}
+	return nil
+}
+
+func (r *customAttributeRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var def models.CustomAttributeDefinition
+		if err := tx.First(&def, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("custom attribute not found")
+			}
+			return fmt.Errorf("failed to get custom attribute: %w", err)
+		}
+		if err := tx.Delete(&def).Error; err != nil {
+			return fmt.Errorf("failed to delete custom attribute: %w", err)
+		}
+
+		table := attributeTables[def.Entity]
+		path := "$." + def.Key
+		err := tx.Table(table).
+			Where("channel_id IN (SELECT id FROM chat_channels WHERE organization_id = ?)", def.OrganizationID).
+			Where("json_extract(custom_attributes, ?) IS NOT NULL", path).
+			Update("custom_attributes", gorm.Expr("json_remove(custom_attributes, ?)", path)).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove custom attribute values: %w", err)
+		}
+
+		var users int64
+		if err := tx.Model(&models.CustomAttributeDefinition{}).
+			Where("entity = ? AND key = ?", def.Entity, def.Key).
+			Count(&users).Error; err != nil {
+			return fmt.Errorf("failed to count custom attributes: %w", err)
+		}
+		if users == 0 {
+			if err := tx.Exec("DROP INDEX IF EXISTS " + attributeIndexName(table, def.Key)).Error; err != nil {
+				return fmt.Errorf("failed to drop custom attribute index: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func attributeIndexName(table, key string) string {
+	return fmt.Sprintf("idx_%s_attr_%s", table, key)
+}
+
+// attributeExpr extracts an attribute in the form its index is built on.
+// Keys are checked by models.ValidAttributeKey before they are defined, so
+// they are safe to embed.
+func attributeExpr(table, key string) string {
+	return fmt.Sprintf("json_extract(%s.custom_attributes, '$.%s')", table, key)
+}
+
+func attributeDefinitions(db *gorm.DB, orgID int64, entity models.CustomAttributeEntity) (map[string]*models.CustomAttributeDefinition, error) {
+	var defs []*models.CustomAttributeDefinition
+	if err := db.Where("organization_id = ? AND entity = ?", orgID, entity).Find(&defs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load custom attributes: %w", err)
+	}
+
+	byKey := make(map[string]*models.CustomAttributeDefinition, len(defs))
+	for _, def := range defs {
+		byKey[def.Key] = def
+	}
+	return byKey, nil
+}
+
+// attributeScope builds a scope filtering and ordering records of entity
+// by the organization's custom attributes. Unknown keys and values that do
+// not fit an attribute's type are rejected with models.ErrInvalidAttribute.
+func attributeScope(db *gorm.DB, orgID int64, entity models.CustomAttributeEntity, q *models.AttributeQuery) (func(*gorm.DB) *gorm.DB, error) {
+	if q == nil || (len(q.Filters) == 0 && q.SortKey == "") {
+		return func(query *gorm.DB) *gorm.DB { return query }, nil
+	}
+
+	defs, err := attributeDefinitions(db, orgID, entity)
+	if err != nil {
+		return nil, err
+	}
+	lookup := func(key string) (*models.CustomAttributeDefinition, error) {
+		def, ok := defs[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not defined", models.ErrInvalidAttribute, key)
+		}
+		return def, nil
+	}
+
+	table := attributeTables[entity]
+	var conditions []func(*gorm.DB) *gorm.DB
+	for _, filter := range q.Filters {
+		def, err := lookup(filter.Key)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, 0, len(filter.Values))
+		for _, v := range filter.Values {
+			value, err := def.Parse(v)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+
+		expr := attributeExpr(table, def.Key)
+		switch filter.Op {
+		case "", models.AttributeOpEq:
+			conditions = append(conditions, func(query *gorm.DB) *gorm.DB {
+				return query.Where(expr+" IN ?", values)
+			})
+		case models.AttributeOpNe:
+			conditions = append(conditions, func(query *gorm.DB) *gorm.DB {
+				return query.Where(fmt.Sprintf("(%s IS NULL OR %s NOT IN ?)", expr, expr), values)
+			})
+		default:
+			operator, ok := attributeOperators[filter.Op]
+			if !ok || len(values) != 1 {
+				return nil, fmt.Errorf("%w: %s takes one value", models.ErrInvalidAttribute, filter.Op)
+			}
+			conditions = append(conditions, func(query *gorm.DB) *gorm.DB {
+				return query.Where(fmt.Sprintf("%s %s ?", expr, operator), values[0])
+			})
+		}
+	}
+
+	if q.SortKey != "" {
+		def, err := lookup(q.SortKey)
+		if err != nil {
+			return nil, err
+		}
+		expr := attributeExpr(table, def.Key)
+		direction := "ASC"
+		if q.SortDesc {
+			direction = "DESC"
+		}
+		conditions = append(conditions, func(query *gorm.DB) *gorm.DB {
+			return query.Order(expr + " IS NULL").Order(expr + " " + direction)
+		})
+	}
+
+	return func(query *gorm.DB) *gorm.DB {
+		for _, condition := range conditions {
+			query = condition(query)
+		}
+		return query
+	}, nil
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomAttributeRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewCustomAttributeRepository(db)
+	convRepo := NewConversationRepository(db)
+	userRepo := NewExternalUserRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	alice := testutils.CreateTestExternalUser(t, db, channel.ID, "user-1", "Alice")
+	bob := testutils.CreateTestExternalUser(t, db, channel.ID, "user-2", "Bob")
+	first := testutils.CreateTestConversation(t, db, channel.ID, alice.ID)
+	second := testutils.CreateTestConversation(t, db, channel.ID, bob.ID)
+	third := testutils.CreateTestConversation(t, db, channel.ID, bob.ID)
+
+	plan, err := repo.Create(&models.CreateCustomAttributeRequest{
+		OrganizationID: org.ID,
+		Entity:         models.AttributeEntityConversation,
+		Key:            "plan",
+		Label:          "Plan",
+		Type:           models.AttributeTypeEnum,
+		Options:        []string{"free", "pro"},
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(&models.CreateCustomAttributeRequest{
+		OrganizationID: org.ID,
+		Entity:         models.AttributeEntityConversation,
+		Key:            "seats",
+		Label:          "Seats",
+		Type:           models.AttributeTypeNumber,
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(&models.CreateCustomAttributeRequest{
+		OrganizationID: org.ID,
+		Entity:         models.AttributeEntityContact,
+		Key:            "vip",
+		Label:          "VIP",
+		Type:           models.AttributeTypeBoolean,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, convRepo.SetAttributes(first.ID, models.CustomAttributes{"plan": "pro", "seats": 10.0}))
+	require.NoError(t, convRepo.SetAttributes(second.ID, models.CustomAttributes{"plan": "free", "seats": 2.0}))
+	require.NoError(t, userRepo.SetAttributes(alice.ID, models.CustomAttributes{"vip": true}))
+
+	ids := func(conversations []*models.Conversation) []int64 {
+		var out []int64
+		for _, conv := range conversations {
+			out = append(out, conv.ID)
+		}
+		return out
+	}
+
+	t.Run("set merges and removes values", func(t *testing.T) {
+		require.NoError(t, convRepo.SetAttributes(third.ID, models.CustomAttributes{"plan": "pro", "seats": 5.0}))
+		require.NoError(t, convRepo.SetAttributes(third.ID, models.CustomAttributes{"plan": nil}))
+
+		conv, err := convRepo.GetByID(third.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CustomAttributes{"seats": 5.0}, conv.CustomAttributes)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		conversations, err := convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{
+			Filters: []models.AttributeFilter{{Key: "plan", Op: models.AttributeOpEq, Values: []string{"pro"}}},
+		}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{first.ID}, ids(conversations))
+
+		conversations, err = convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{
+			Filters: []models.AttributeFilter{{Key: "seats", Op: models.AttributeOpGte, Values: []string{"5"}}},
+		}, 10, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{first.ID, third.ID}, ids(conversations))
+
+		conversations, err = convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{
+			Filters: []models.AttributeFilter{{Key: "plan", Op: models.AttributeOpNe, Values: []string{"pro"}}},
+		}, 10, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{second.ID, third.ID}, ids(conversations))
+	})
+
+	t.Run("sort puts missing values last", func(t *testing.T) {
+		conversations, err := convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{SortKey: "plan", SortDesc: true}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{first.ID, second.ID, third.ID}, ids(conversations))
+	})
+
+	t.Run("rejects unknown keys and mistyped values", func(t *testing.T) {
+		_, err := convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{SortKey: "vip"}, 10, 0)
+		assert.ErrorIs(t, err, models.ErrInvalidAttribute)
+
+		_, err = convRepo.List(channel.ID, nil, nil, &models.AttributeQuery{
+			Filters: []models.AttributeFilter{{Key: "seats", Op: models.AttributeOpEq, Values: []string{"many"}}},
+		}, 10, 0)
+		assert.ErrorIs(t, err, models.ErrInvalidAttribute)
+	})
+
+	t.Run("inbox", func(t *testing.T) {
+		page, err := convRepo.ListInbox(&models.InboxQuery{
+			OrganizationID: org.ID,
+			Attributes:     []models.AttributeFilter{{Key: "plan", Op: models.AttributeOpEq, Values: []string{"free", "pro"}}},
+			Limit:          10,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), page.Total)
+	})
+
+	t.Run("contacts", func(t *testing.T) {
+		users, err := userRepo.List(&models.ExternalUserQuery{
+			OrganizationID: org.ID,
+			Attributes: &models.AttributeQuery{
+				Filters: []models.AttributeFilter{{Key: "vip", Op: models.AttributeOpEq, Values: []string{"true"}}},
+			},
+			Limit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, alice.ID, users[0].ID)
+
+		users, err = userRepo.List(&models.ExternalUserQuery{OrganizationID: org.ID, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("filters use the attribute index", func(t *testing.T) {
+		var rows []struct{ Detail string }
+		require.NoError(t, db.Raw("EXPLAIN QUERY PLAN SELECT id FROM conversations WHERE "+
+			attributeExpr("conversations", "seats")+" > 1").Scan(&rows).Error)
+
+		var details []string
+		for _, row := range rows {
+			details = append(details, row.Detail)
+		}
+		assert.Contains(t, strings.Join(details, "\n"), "idx_conversations_attr_seats")
+	})
+
+	t.Run("delete removes values and the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(plan.ID))
+
+		conv, err := convRepo.GetByID(first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CustomAttributes{"seats": 10.0}, conv.CustomAttributes)
+
+		var indexes int64
+		require.NoError(t, db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?",
+			"idx_conversations_attr_plan").Scan(&indexes).Error)
+		assert.Zero(t, indexes)
+	})
+}
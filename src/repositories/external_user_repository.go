@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
@@ -14,7 +15,13 @@ type ExternalUserRepository interface {
 	GetByID(id int64) (*models.ExternalUser, error)
 	GetByPlatformUser(channelID int64, platformUserID string) (*models.ExternalUser, error)
 	FindOrCreate(req *models.CreateExternalUserRequest) (*models.ExternalUser, error)
+	// List lists contacts most recently seen first, unless the query
+	// orders them by a custom attribute
+	List(q *models.ExternalUserQuery) ([]*models.ExternalUser, error)
 	Update(id int64, req *models.UpdateExternalUserRequest) error
+	// SetAttributes merges values into the user's custom attributes,
+	// removing those set to nil
+	SetAttributes(id int64, values models.CustomAttributes) error
 	UpdateLastSeen(id int64) error
 }
 
@@ -121,6 +128,49 @@ func (r *externalUserRepository) Update(id int64, req *models.UpdateExternalUser
 	return nil
 }
 
+func (r *externalUserRepository) List(q *models.ExternalUserQuery) ([]*models.ExternalUser, error) {
+	scope, err := attributeScope(r.db, q.OrganizationID, models.AttributeEntityContact, q.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.Where("channel_id IN (SELECT id FROM chat_channels WHERE organization_id = ?)", q.OrganizationID)
+	if q.ChannelID != nil {
+		query = query.Where("channel_id = ?", *q.ChannelID)
+	}
+
+	var users []*models.ExternalUser
+	err = scope(query).
+		Order("COALESCE(last_seen_at, first_seen_at) DESC").
+		Order("id DESC").
+		Limit(q.Limit).
+		Offset(q.Offset).
+		Find(&users).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *externalUserRepository) SetAttributes(id int64, values models.CustomAttributes) error {
+	patch, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode custom attributes: %w", err)
+	}
+
+	// json_patch drops keys whose patch value is null
+	result := r.db.Model(&models.ExternalUser{}).Where("id = ?", id).
+		Update("custom_attributes", gorm.Expr("json_patch(COALESCE(custom_attributes, '{}'), ?)", string(patch)))
+	if result.Error != nil {
+		return fmt.Errorf("failed to update custom attributes: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
 func (r *externalUserRepository) UpdateLastSeen(id int64) error {
 	return r.db.Model(&models.ExternalUser{}).Where("id = ?", id).
 		Update("last_seen_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
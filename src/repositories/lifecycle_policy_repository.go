@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LifecyclePolicyRepository interface {
+	// FindByChannel returns nil when the channel has no lifecycle policy
+	FindByChannel(channelID int64) (*models.LifecyclePolicy, error)
+	Upsert(channelID int64, req *models.UpsertLifecyclePolicyRequest) (*models.LifecyclePolicy, error)
+}
+
+type lifecyclePolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewLifecyclePolicyRepository(db *gorm.DB) LifecyclePolicyRepository {
+	return &lifecyclePolicyRepository{db: db}
+}
+
+func (r *lifecyclePolicyRepository) FindByChannel(channelID int64) (*models.LifecyclePolicy, error) {
+	var policy models.LifecyclePolicy
+	if err := r.db.Where("channel_id = ?", channelID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lifecycle policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *lifecyclePolicyRepository) Upsert(channelID int64, req *models.UpsertLifecyclePolicyRequest) (*models.LifecyclePolicy, error) {
+	idleStatus := req.IdleStatus
+	if idleStatus == "" {
+		idleStatus = models.ConversationStatusResolved
+	}
+
+	policy := &models.LifecyclePolicy{
+		ChannelID:           channelID,
+		ReopenWithinSeconds: req.ReopenWithinSeconds,
+		IdleAfterSeconds:    req.IdleAfterSeconds,
+		IdleStatus:          idleStatus,
+		ClosingMessage:      req.ClosingMessage,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reopen_within_seconds", "idle_after_seconds", "idle_status", "closing_message", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save lifecycle policy: %w", err)
+	}
+
+	return r.FindByChannel(channelID)
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecyclePolicyRepository_Upsert(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewLifecyclePolicyRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+
+	policy, err := repo.FindByChannel(channel.ID)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	closing := "Closing this chat for now."
+	policy, err = repo.Upsert(channel.ID, &models.UpsertLifecyclePolicyRequest{ReopenWithinSeconds: 7200, IdleAfterSeconds: 86400, ClosingMessage: &closing})
+	require.NoError(t, err)
+	assert.Equal(t, 7200, policy.ReopenWithinSeconds)
+	assert.Equal(t, models.ConversationStatusResolved, policy.IdleStatus)
+	assert.Equal(t, closing, *policy.ClosingMessage)
+
+	updated, err := repo.Upsert(channel.ID, &models.UpsertLifecyclePolicyRequest{IdleAfterSeconds: 3600, IdleStatus: models.ConversationStatusClosed})
+	require.NoError(t, err)
+	assert.Equal(t, policy.ID, updated.ID)
+	assert.Equal(t, 0, updated.ReopenWithinSeconds)
+	assert.Equal(t, models.ConversationStatusClosed, updated.IdleStatus)
+	assert.Nil(t, updated.ClosingMessage)
+}
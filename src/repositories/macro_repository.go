@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type MacroRepository interface {
+	Create(req *models.CreateMacroRequest) (*models.Macro, error)
+	GetByID(id int64) (*models.Macro, error)
+	// ListByOrganization lists the organization's macros by name. With a
+	// team, only those of the whole organization and of that team are
+	// listed.
+	ListByOrganization(orgID int64, teamID *int64, limit, offset int) ([]*models.Macro, error)
+	Update(id int64, req *models.UpdateMacroRequest) error
+	Delete(id int64) error
+	// Apply applies the steps of a macro run to the conversation in one
+	// transaction, recording each change in its history with the actor
+	// and, for status changes, the reason. Nothing is applied if a step
+	// fails.
+	Apply(conversationID int64, steps []*models.MacroStep, actorID *string, reason string) error
+	RecordRun(run *models.MacroRun) error
+	// ListRuns lists a macro's runs, newest first
+	ListRuns(macroID int64, limit, offset int) ([]*models.MacroRun, error)
+}
+
+type macroRepository struct {
+	db *gorm.DB
+}
+
+func NewMacroRepository(db *gorm.DB) MacroRepository {
+	return &macroRepository{db: db}
+}
+
+func (r *macroRepository) Create(req *models.CreateMacroRequest) (*models.Macro, error) {
+	macro := &models.Macro{
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Actions:        req.Actions,
+	}
+
+	if err := r.db.Create(macro).Error; err != nil {
+		return nil, fmt.Errorf("failed to create macro: %w", err)
+	}
+	return macro, nil
+}
+
+func (r *macroRepository) GetByID(id int64) (*models.Macro, error) {
+	var macro models.Macro
+	if err := r.db.First(&macro, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("macro not found")
+		}
+		return nil, fmt.Errorf("failed to get macro: %w", err)
+	}
+	return &macro, nil
+}
+
+func (r *macroRepository) ListByOrganization(orgID int64, teamID *int64, limit, offset int) ([]*models.Macro, error) {
+	query := r.db.Where("organization_id = ?", orgID)
+	if teamID != nil {
+		query = query.Where("team_id IS NULL OR team_id = ?", *teamID)
+	}
+
+	var macros []*models.Macro
+	err := query.Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&macros).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list macros: %w", err)
+	}
+	return macros, nil
+}
+
+func (r *macroRepository) Update(id int64, req *models.UpdateMacroRequest) error {
+	macro, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if req.TeamID != nil {
+		macro.TeamID = req.TeamID
+		if *req.TeamID == 0 {
+			macro.TeamID = nil
+		}
+	}
+	if req.Name != nil {
+		macro.Name = *req.Name
+	}
+	if req.Description != nil {
+		macro.Description = req.Description
+	}
+	if req.Actions != nil {
+		macro.Actions = req.Actions
+	}
+
+	if err := r.db.Save(macro).Error; err != nil {
+		return fmt.Errorf("failed to update macro: %w", err)
+	}
+	return nil
+}
+
+func (r *macroRepository) Delete(id int64) error {
+	result := r.db.Delete(&models.Macro{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete macro: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("macro not found")
+	}
+	return nil
+}
+
+func (r *macroRepository) Apply(conversationID int64, steps []*models.MacroStep, actorID *string, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		conversations := NewConversationRepository(tx)
+		conv, err := conversations.GetByID(conversationID)
+		if err != nil {
+			return err
+		}
+
+		apply := &macroApply{
+			conv:          conv,
+			conversations: conversations,
+			messages:      NewMessageRepository(tx),
+			tags:          NewTagRepository(tx),
+			history:       NewConversationEventRepository(tx),
+			actorID:       actorID,
+			reason:        reason,
+		}
+		for i, step := range steps {
+			if err := apply.step(step); err != nil {
+				return fmt.Errorf("action %d (%s) failed: %w", i+1, step.Action.Type, err)
+			}
+		}
+		return nil
+	})
+}
+
+// macroApply applies the steps of a macro run through repositories bound
+// to its transaction, keeping track of the conversation as it changes
+type macroApply struct {
+	conv          *models.Conversation
+	conversations ConversationRepository
+	messages      MessageRepository
+	tags          TagRepository
+	history       ConversationEventRepository
+	actorID       *string
+	reason        string
+}
+
+func (a *macroApply) step(step *models.MacroStep) error {
+	action := step.Action
+	switch action.Type {
+	case models.MacroActionSendReply:
+		msg, err := a.messages.Create(&models.Message{
+			ConversationID: a.conv.ID,
+			SenderType:     models.SenderInternal,
+			Content:        step.Reply,
+			MessageType:    models.MessageTypeText,
+			Direction:      models.DirectionOutbound,
+			Status:         models.MessageStatusSent,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := a.conversations.UpdateLastMessage(a.conv.ID); err != nil {
+			return fmt.Errorf("failed to update conversation: %w", err)
+		}
+		step.Message = msg
+
+	case models.MacroActionAddTags, models.MacroActionRemoveTags:
+		add := action.Type == models.MacroActionAddTags
+		tagIDs := make([]int64, 0, len(step.Tags))
+		names := make(map[int64]string, len(step.Tags))
+		for _, tag := range step.Tags {
+			tagIDs = append(tagIDs, tag.ID)
+			names[tag.ID] = tag.Name
+		}
+
+		var changed []int64
+		var err error
+		if add {
+			changed, err = a.tags.AddToConversation(a.conv.ID, tagIDs)
+		} else {
+			changed, err = a.tags.RemoveFromConversation(a.conv.ID, tagIDs)
+		}
+		if err != nil {
+			return err
+		}
+		for _, tagID := range changed {
+			name := names[tagID]
+			event := &models.ConversationEvent{Type: models.ConversationEventTagAdded, ToValue: &name}
+			if !add {
+				event = &models.ConversationEvent{Type: models.ConversationEventTagRemoved, FromValue: &name}
+			}
+			if err := a.record(event, false); err != nil {
+				return err
+			}
+			step.Changed = append(step.Changed, name)
+		}
+
+	case models.MacroActionSetPriority:
+		previous := string(a.conv.Priority)
+		if a.conv.Priority == *action.Priority {
+			return nil
+		}
+		if err := a.conversations.Update(a.conv.ID, &models.UpdateConversationRequest{Priority: action.Priority}); err != nil {
+			return err
+		}
+		a.conv.Priority = *action.Priority
+		to := string(*action.Priority)
+		if err := a.record(&models.ConversationEvent{Type: models.ConversationEventPriorityChanged, FromValue: &previous, ToValue: &to}, false); err != nil {
+			return err
+		}
+		step.Previous = &previous
+
+	case models.MacroActionSetStatus:
+		previous := string(a.conv.Status)
+		if a.conv.Status == *action.Status {
+			return nil
+		}
+		if err := a.conversations.Update(a.conv.ID, &models.UpdateConversationRequest{Status: action.Status}); err != nil {
+			return err
+		}
+		a.conv.Status = *action.Status
+		to := string(*action.Status)
+		if err := a.record(&models.ConversationEvent{Type: models.ConversationEventStatusChanged, FromValue: &previous, ToValue: &to}, true); err != nil {
+			return err
+		}
+		step.Previous = &previous
+	}
+	return nil
+}
+
+// record adds a change to the conversation's history, with the run's
+// reason if asked to
+func (a *macroApply) record(event *models.ConversationEvent, withReason bool) error {
+	event.ConversationID = a.conv.ID
+	event.ActorID = a.actorID
+	if withReason && a.reason != "" {
+		event.Reason = &a.reason
+	}
+	return a.history.Create(event)
+}
+
+func (r *macroRepository) RecordRun(run *models.MacroRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to record macro run: %w", err)
+	}
+	return nil
+}
+
+func (r *macroRepository) ListRuns(macroID int64, limit, offset int) ([]*models.MacroRun, error) {
+	runs := make([]*models.MacroRun, 0)
+	err := r.db.Where("macro_id = ?", macroID).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list macro runs: %w", err)
+	}
+	return runs, nil
+}
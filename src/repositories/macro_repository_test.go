@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewMacroRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+
+	reply := "We are on it"
+	macro, err := repo.Create(&models.CreateMacroRequest{
+		OrganizationID: org.ID,
+		Name:           "Escalate",
+		Actions: []models.MacroAction{
+			{Type: models.MacroActionSendReply, Content: &reply},
+			{Type: models.MacroActionAddTags, Tags: []string{"urgent"}},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("names are unique per organization", func(t *testing.T) {
+		_, err := repo.Create(&models.CreateMacroRequest{OrganizationID: org.ID, Name: "Escalate", Actions: macro.Actions})
+		assert.Error(t, err)
+	})
+
+	t.Run("update replaces actions", func(t *testing.T) {
+		high := models.PriorityHigh
+		name := "Escalate now"
+		require.NoError(t, repo.Update(macro.ID, &models.UpdateMacroRequest{
+			Name:    &name,
+			Actions: []models.MacroAction{{Type: models.MacroActionSetPriority, Priority: &high}},
+		}))
+
+		got, err := repo.GetByID(macro.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Escalate now", got.Name)
+		require.Len(t, got.Actions, 1)
+		assert.Equal(t, models.PriorityHigh, *got.Actions[0].Priority)
+
+		macros, err := repo.ListByOrganization(org.ID, nil, 50, 0)
+		require.NoError(t, err)
+		assert.Len(t, macros, 1)
+	})
+
+	t.Run("runs newest first", func(t *testing.T) {
+		actor, failed := "agent-1", "invalid macro"
+		require.NoError(t, repo.RecordRun(&models.MacroRun{MacroID: macro.ID, ConversationID: 1, ActorID: &actor, Actions: macro.Actions}))
+		require.NoError(t, repo.RecordRun(&models.MacroRun{MacroID: macro.ID, ConversationID: 2, Actions: macro.Actions, Error: &failed}))
+
+		runs, err := repo.ListRuns(macro.ID, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, int64(2), runs[0].ConversationID)
+		assert.Equal(t, "invalid macro", *runs[0].Error)
+		assert.Equal(t, "agent-1", *runs[1].ActorID)
+		assert.Len(t, runs[1].Actions, 2)
+	})
+}
+
+func TestMacroRepository_Apply(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewMacroRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-1", "Jane")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	billing, err := NewTagRepository(db).Create(&models.CreateTagRequest{OrganizationID: org.ID, Name: "billing"})
+	require.NoError(t, err)
+
+	actor, reply := "agent-1", "All sorted"
+	high, resolved := models.PriorityHigh, models.ConversationStatusResolved
+	steps := func() []*models.MacroStep {
+		return []*models.MacroStep{
+			{Action: models.MacroAction{Type: models.MacroActionSendReply, Content: &reply}, Reply: reply},
+			{Action: models.MacroAction{Type: models.MacroActionSetPriority, Priority: &high}},
+			{Action: models.MacroAction{Type: models.MacroActionSetStatus, Status: &resolved}},
+			{Action: models.MacroAction{Type: models.MacroActionAddTags, Tags: []string{"billing"}}, Tags: []*models.Tag{billing}},
+		}
+	}
+
+	t.Run("nothing is applied when the last step fails", func(t *testing.T) {
+		require.NoError(t, db.Migrator().RenameTable(&models.ConversationTag{}, "conversation_tags_away"))
+
+		err := repo.Apply(conv.ID, steps(), &actor, "macro Billing resolved")
+		require.NoError(t, db.Migrator().RenameTable("conversation_tags_away", &models.ConversationTag{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "action 4 (add_tags) failed")
+
+		var messages, history int64
+		require.NoError(t, db.Model(&models.Message{}).Where("conversation_id = ?", conv.ID).Count(&messages).Error)
+		require.NoError(t, db.Model(&models.ConversationEvent{}).Where("conversation_id = ?", conv.ID).Count(&history).Error)
+		assert.Zero(t, messages)
+		assert.Zero(t, history)
+
+		got, err := NewConversationRepository(db).GetByID(conv.ID)
+		require.NoError(t, err)
+		assert.Equal(t, conv.Priority, got.Priority)
+		assert.Equal(t, models.ConversationStatusOpen, got.Status)
+		assert.Nil(t, got.LastMessageAt)
+	})
+
+	t.Run("applies and records every step", func(t *testing.T) {
+		applied := steps()
+		require.NoError(t, repo.Apply(conv.ID, applied, &actor, "macro Billing resolved"))
+
+		require.NotNil(t, applied[0].Message)
+		assert.Equal(t, models.DirectionOutbound, applied[0].Message.Direction)
+		assert.Equal(t, string(conv.Priority), *applied[1].Previous)
+		assert.Equal(t, "open", *applied[2].Previous)
+		assert.Equal(t, []string{"billing"}, applied[3].Changed)
+
+		got, err := NewConversationRepository(db).GetByID(conv.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.PriorityHigh, got.Priority)
+		assert.Equal(t, models.ConversationStatusResolved, got.Status)
+		assert.NotNil(t, got.LastMessageAt)
+
+		history, err := NewConversationEventRepository(db).ListByConversation(conv.ID, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		for _, event := range history {
+			assert.Equal(t, "agent-1", *event.ActorID)
+			if event.Type == models.ConversationEventStatusChanged {
+				assert.Equal(t, "macro Billing resolved", *event.Reason)
+			} else {
+				assert.Nil(t, event.Reason)
+			}
+		}
+	})
+}
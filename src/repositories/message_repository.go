@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"fmt"
+	"time"
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 
@@ -13,7 +14,26 @@ type MessageRepository interface {
 	Create(msg *models.Message) (*models.Message, error)
 	GetByID(id int64) (*models.Message, error)
 	ListByConversation(conversationID int64, limit, offset int, before *int64) ([]*models.Message, error)
+	// ListHistory lists a conversation's messages like ListByConversation,
+	// optionally leaving out activity messages
+	ListHistory(query *models.MessageListQuery) ([]*models.Message, error)
 	UpdateStatus(id int64, status models.MessageStatus) error
+	// Edit replaces the content of a message, keeping its previous content
+	// in the edit history
+	Edit(id int64, content string, editedBy *string, at time.Time) (*models.Message, error)
+	// Delete marks a message deleted and clears its content. With retain,
+	// the content is kept in the edit history until PurgeDeleted drops it;
+	// otherwise the edit history is dropped too.
+	Delete(id int64, deletedBy *string, at time.Time, retain bool) error
+	// ListEdits lists the earlier versions of a message, oldest first
+	ListEdits(messageID int64) ([]*models.MessageEdit, error)
+	// FindByPlatformID finds a message of the channel by its platform
+	// message ID, returning nil if there is none
+	FindByPlatformID(channelID int64, platformMessageID string) (*models.Message, error)
+	// PurgeDeleted drops the edit history of messages deleted longer ago
+	// than their channel keeps deleted messages for, and returns the number
+	// of versions dropped
+	PurgeDeleted(now time.Time) (int64, error)
 }
 
 type messageRepository struct {
@@ -35,7 +55,7 @@ func (r *messageRepository) GetByID(id int64) (*models.Message, error) {
 	var msg models.Message
 	if err := r.db.First(&msg, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("message not found")
+			return nil, fmt.Errorf("message %w", models.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
@@ -43,17 +63,29 @@ func (r *messageRepository) GetByID(id int64) (*models.Message, error) {
 }
 
 func (r *messageRepository) ListByConversation(conversationID int64, limit, offset int, before *int64) ([]*models.Message, error) {
+	return r.ListHistory(&models.MessageListQuery{
+		ConversationID: conversationID,
+		Limit:          limit,
+		Offset:         offset,
+		Before:         before,
+	})
+}
+
+func (r *messageRepository) ListHistory(q *models.MessageListQuery) ([]*models.Message, error) {
 	var messages []*models.Message
 
-	query := r.db.Where("conversation_id = ?", conversationID)
+	query := r.db.Where("conversation_id = ?", q.ConversationID)
 
-	if before != nil {
-		query = query.Where("id < ?", *before)
+	if q.Before != nil {
+		query = query.Where("id < ?", *q.Before)
+	}
+	if q.HideSystem {
+		query = query.Where("message_type IS NOT ?", models.MessageTypeSystem)
 	}
 
 	err := query.Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
+		Limit(q.Limit).
+		Offset(q.Offset).
 		Find(&messages).Error
 
 	if err != nil {
@@ -79,8 +111,126 @@ func (r *messageRepository) UpdateStatus(id int64, status models.MessageStatus)
 		return fmt.Errorf("failed to update message status: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("message not found")
+		return fmt.Errorf("message %w", models.ErrNotFound)
 	}
 
 	return nil
 }
+
+func (r *messageRepository) Edit(id int64, content string, editedBy *string, at time.Time) (*models.Message, error) {
+	var msg models.Message
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deleted_at IS NULL").First(&msg, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("message %w", models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to get message: %w", err)
+		}
+
+		edit := &models.MessageEdit{
+			MessageID: msg.ID,
+			Content:   msg.Content,
+			EditedBy:  editedBy,
+			EditedAt:  at,
+		}
+		if err := tx.Create(edit).Error; err != nil {
+			return fmt.Errorf("failed to record message edit: %w", err)
+		}
+
+		err := tx.Model(&msg).Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": at,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to edit message: %w", err)
+		}
+		msg.Content = content
+		msg.EditedAt = &at
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (r *messageRepository) Delete(id int64, deletedBy *string, at time.Time, retain bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var msg models.Message
+		if err := tx.Where("deleted_at IS NULL").First(&msg, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("message %w", models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to get message: %w", err)
+		}
+		content := msg.Content
+
+		err := tx.Model(&msg).Updates(map[string]interface{}{
+			"content":    "",
+			"media_url":  nil,
+			"deleted_at": at,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+
+		if !retain {
+			if err := tx.Where("message_id = ?", id).Delete(&models.MessageEdit{}).Error; err != nil {
+				return fmt.Errorf("failed to delete message edits: %w", err)
+			}
+			return nil
+		}
+
+		edit := &models.MessageEdit{
+			MessageID: msg.ID,
+			Content:   content,
+			EditedBy:  deletedBy,
+			EditedAt:  at,
+		}
+		if err := tx.Create(edit).Error; err != nil {
+			return fmt.Errorf("failed to record message edit: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *messageRepository) ListEdits(messageID int64) ([]*models.MessageEdit, error) {
+	edits := make([]*models.MessageEdit, 0)
+	err := r.db.Where("message_id = ?", messageID).
+		Order("edited_at").
+		Order("id").
+		Find(&edits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message edits: %w", err)
+	}
+	return edits, nil
+}
+
+func (r *messageRepository) FindByPlatformID(channelID int64, platformMessageID string) (*models.Message, error) {
+	var msg models.Message
+	err := r.db.Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Where("conversations.channel_id = ? AND messages.platform_message_id = ?", channelID, platformMessageID).
+		First(&msg).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return &msg, nil
+}
+
+func (r *messageRepository) PurgeDeleted(now time.Time) (int64, error) {
+	expired := r.db.Model(&models.Message{}).
+		Select("messages.id").
+		Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Joins("JOIN chat_channels ON chat_channels.id = conversations.channel_id").
+		Where("messages.deleted_at IS NOT NULL").
+		Where("datetime(messages.deleted_at) <= datetime(?, '-' || chat_channels.retain_deleted_days || ' days')", sqliteTime(now))
+
+	result := r.db.Where("message_id IN (?)", expired).Delete(&models.MessageEdit{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted messages: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
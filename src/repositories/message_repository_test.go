@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"testing"
+	"time"
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
@@ -66,7 +67,7 @@ func TestMessageRepository_GetByID(t *testing.T) {
 
 	t.Run("return error for non-existent message", func(t *testing.T) {
 		found, err := repo.GetByID(99999)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, models.ErrNotFound)
 		assert.Nil(t, found)
 	})
 }
@@ -157,3 +158,135 @@ func TestMessageRepository_UpdateStatus(t *testing.T) {
 		assert.Equal(t, models.MessageStatusRead, found.Status)
 	})
 }
+
+func TestMessageRepository_EditAndDelete(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewMessageRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	note, err := repo.Create(&models.Message{
+		ConversationID: conv.ID,
+		SenderType:     models.SenderInternal,
+		Content:        "first",
+		MessageType:    models.MessageTypeNote,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+	})
+	require.NoError(t, err)
+
+	author := "agent-1"
+	start := time.Now().Add(-time.Hour)
+
+	t.Run("edit keeps the previous content", func(t *testing.T) {
+		edited, err := repo.Edit(note.ID, "second", &author, start)
+		require.NoError(t, err)
+		assert.Equal(t, "second", edited.Content)
+		require.NotNil(t, edited.EditedAt)
+
+		_, err = repo.Edit(note.ID, "third", &author, start.Add(time.Minute))
+		require.NoError(t, err)
+
+		found, err := repo.GetByID(note.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "third", found.Content)
+
+		edits, err := repo.ListEdits(note.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 2)
+		assert.Equal(t, "first", edits[0].Content)
+		assert.Equal(t, "second", edits[1].Content)
+		assert.Equal(t, &author, edits[1].EditedBy)
+	})
+
+	t.Run("delete clears the content and history", func(t *testing.T) {
+		require.NoError(t, repo.Delete(note.ID, &author, start.Add(2*time.Minute), false))
+
+		found, err := repo.GetByID(note.ID)
+		require.NoError(t, err)
+		assert.Empty(t, found.Content)
+		assert.NotNil(t, found.DeletedAt)
+
+		edits, err := repo.ListEdits(note.ID)
+		require.NoError(t, err)
+		assert.Empty(t, edits)
+	})
+
+	t.Run("deleted messages cannot be changed", func(t *testing.T) {
+		_, err := repo.Edit(note.ID, "again", &author, time.Now())
+		assert.Error(t, err)
+		assert.Error(t, repo.Delete(note.ID, &author, time.Now(), false))
+	})
+}
+
+func TestMessageRepository_RetainDeleted(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewMessageRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	other := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	require.NoError(t, db.Model(channel).Update("retain_deleted_days", 7).Error)
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	create := func(platformID, content string) *models.Message {
+		msg, err := repo.Create(&models.Message{
+			ConversationID:    conv.ID,
+			PlatformMessageID: &platformID,
+			SenderType:        models.SenderExternal,
+			Content:           content,
+			Direction:         models.DirectionInbound,
+			Status:            models.MessageStatusReceived,
+		})
+		require.NoError(t, err)
+		return msg
+	}
+	old := create("tg-1", "my card is 4111")
+	recent := create("tg-2", "call me")
+
+	t.Run("find by platform ID", func(t *testing.T) {
+		found, err := repo.FindByPlatformID(channel.ID, "tg-2")
+		require.NoError(t, err)
+		assert.Equal(t, recent.ID, found.ID)
+
+		found, err = repo.FindByPlatformID(other.ID, "tg-2")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	now := time.Now()
+	agent := "agent-1"
+	require.NoError(t, repo.Delete(old.ID, &agent, now.Add(-8*24*time.Hour), true))
+	require.NoError(t, repo.Delete(recent.ID, nil, now.Add(-24*time.Hour), true))
+
+	t.Run("delete keeps the content in the history", func(t *testing.T) {
+		found, err := repo.GetByID(old.ID)
+		require.NoError(t, err)
+		assert.Empty(t, found.Content)
+
+		edits, err := repo.ListEdits(old.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, "my card is 4111", edits[0].Content)
+		assert.Equal(t, &agent, edits[0].EditedBy)
+	})
+
+	t.Run("purge drops content past retention", func(t *testing.T) {
+		purged, err := repo.PurgeDeleted(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		edits, err := repo.ListEdits(old.ID)
+		require.NoError(t, err)
+		assert.Empty(t, edits)
+		edits, err = repo.ListEdits(recent.ID)
+		require.NoError(t, err)
+		assert.Len(t, edits, 1)
+	})
+}
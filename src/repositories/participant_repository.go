@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type ParticipantRepository interface {
+	// Join adds the user to the conversation with the given role, or brings
+	// them back with their old role if they had left. The flag reports
+	// whether they were not already taking part.
+	Join(conversationID, externalUserID int64, role models.ParticipantRole, at time.Time) (*models.ConversationParticipant, bool, error)
+	// Leave marks the user as having left the conversation and reports
+	// whether they were taking part
+	Leave(conversationID, externalUserID int64, at time.Time) (bool, error)
+	// ListByConversation lists everyone who has taken part in the
+	// conversation, including those who left, in the order they joined
+	ListByConversation(conversationID int64) ([]*models.ConversationParticipant, error)
+}
+
+type participantRepository struct {
+	db *gorm.DB
+}
+
+func NewParticipantRepository(db *gorm.DB) ParticipantRepository {
+	return &participantRepository{db: db}
+}
+
+func (r *participantRepository) Join(conversationID, externalUserID int64, role models.ParticipantRole, at time.Time) (*models.ConversationParticipant, bool, error) {
+	var participant models.ConversationParticipant
+	err := r.db.Where("conversation_id = ? AND external_user_id = ?", conversationID, externalUserID).
+		First(&participant).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, false, fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		participant = models.ConversationParticipant{
+			ConversationID: conversationID,
+			ExternalUserID: externalUserID,
+			Role:           role,
+			JoinedAt:       at,
+		}
+		result := r.db.Where("conversation_id = ? AND external_user_id = ?", conversationID, externalUserID).
+			FirstOrCreate(&participant)
+		if result.Error != nil {
+			return nil, false, fmt.Errorf("failed to add participant: %w", result.Error)
+		}
+		// Another message from the same user may have added them first
+		return &participant, result.RowsAffected > 0, nil
+	}
+
+	if participant.LeftAt == nil {
+		return &participant, false, nil
+	}
+
+	result := r.db.Model(&models.ConversationParticipant{}).
+		Where("id = ? AND left_at IS NOT NULL", participant.ID).
+		Updates(map[string]interface{}{
+			"joined_at": at,
+			"left_at":   nil,
+		})
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to rejoin participant: %w", result.Error)
+	}
+	participant.JoinedAt = at
+	participant.LeftAt = nil
+	return &participant, result.RowsAffected > 0, nil
+}
+
+func (r *participantRepository) Leave(conversationID, externalUserID int64, at time.Time) (bool, error) {
+	result := r.db.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND external_user_id = ? AND left_at IS NULL", conversationID, externalUserID).
+		Update("left_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove participant: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *participantRepository) ListByConversation(conversationID int64) ([]*models.ConversationParticipant, error) {
+	participants := make([]*models.ConversationParticipant, 0)
+	err := r.db.Where("conversation_id = ?", conversationID).
+		Order("joined_at").
+		Order("id").
+		Find(&participants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+	return participants, nil
+}
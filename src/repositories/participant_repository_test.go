@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipantRepository_JoinAndLeave(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewParticipantRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	alice := testutils.CreateTestExternalUser(t, db, channel.ID, "alice", "Alice")
+	bob := testutils.CreateTestExternalUser(t, db, channel.ID, "bob", "Bob")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, alice.ID)
+
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	participant, joined, err := repo.Join(conv.ID, alice.ID, models.ParticipantRoleRequester, start)
+	require.NoError(t, err)
+	assert.True(t, joined)
+	assert.Equal(t, models.ParticipantRoleRequester, participant.Role)
+
+	_, joined, err = repo.Join(conv.ID, alice.ID, models.ParticipantRoleMember, start.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, joined)
+
+	_, joined, err = repo.Join(conv.ID, bob.ID, models.ParticipantRoleMember, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, joined)
+
+	t.Run("leave", func(t *testing.T) {
+		left, err := repo.Leave(conv.ID, bob.ID, start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, left)
+
+		left, err = repo.Leave(conv.ID, bob.ID, start.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.False(t, left)
+
+		participants, err := repo.ListByConversation(conv.ID)
+		require.NoError(t, err)
+		require.Len(t, participants, 2)
+		assert.Equal(t, alice.ID, participants[0].ExternalUserID)
+		assert.Nil(t, participants[0].LeftAt)
+		require.NotNil(t, participants[1].LeftAt)
+		assert.True(t, start.Add(time.Hour).Equal(*participants[1].LeftAt))
+	})
+
+	t.Run("rejoin", func(t *testing.T) {
+		rejoinedAt := start.Add(3 * time.Hour)
+		participant, joined, err := repo.Join(conv.ID, bob.ID, models.ParticipantRoleCC, rejoinedAt)
+		require.NoError(t, err)
+		assert.True(t, joined)
+		assert.Nil(t, participant.LeftAt)
+		assert.Equal(t, models.ParticipantRoleMember, participant.Role)
+
+		participants, err := repo.ListByConversation(conv.ID)
+		require.NoError(t, err)
+		require.Len(t, participants, 2)
+		assert.Nil(t, participants[1].LeftAt)
+		assert.True(t, rejoinedAt.Equal(participants[1].JoinedAt))
+	})
+}
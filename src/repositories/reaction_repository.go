@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository interface {
+	// Add records a reaction and reports whether it is new. A sender's
+	// reaction with an emoji they already reacted with is left as it is.
+	Add(reaction *models.MessageReaction) (bool, error)
+	// Remove removes a sender's reaction with an emoji and reports whether
+	// there was one
+	Remove(messageID int64, senderType models.MessageSenderType, senderID, emoji string) (bool, error)
+	// ListByMessage lists the reactions to a message, oldest first
+	ListByMessage(messageID int64) ([]*models.MessageReaction, error)
+}
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Add(reaction *models.MessageReaction) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *reactionRepository) Remove(messageID int64, senderType models.MessageSenderType, senderID, emoji string) (bool, error) {
+	result := r.db.Where("message_id = ? AND sender_type = ? AND sender_id = ? AND emoji = ?", messageID, senderType, senderID, emoji).
+		Delete(&models.MessageReaction{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *reactionRepository) ListByMessage(messageID int64) ([]*models.MessageReaction, error) {
+	reactions := make([]*models.MessageReaction, 0)
+	err := r.db.Where("message_id = ?", messageID).
+		Order("created_at").
+		Order("id").
+		Find(&reactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reactions: %w", err)
+	}
+	return reactions, nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactionRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewReactionRepository(db)
+	msgRepo := NewMessageRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	platformID := "tg-1"
+	msg, err := msgRepo.Create(&models.Message{
+		ConversationID:    conv.ID,
+		PlatformMessageID: &platformID,
+		SenderType:        models.SenderExternal,
+		Content:           "Thanks!",
+		Direction:         models.DirectionInbound,
+	})
+	require.NoError(t, err)
+
+	reaction := func(senderType models.MessageSenderType, senderID, emoji string) *models.MessageReaction {
+		return &models.MessageReaction{MessageID: msg.ID, SenderType: senderType, SenderID: senderID, Emoji: emoji}
+	}
+
+	t.Run("one per sender and emoji", func(t *testing.T) {
+		added, err := repo.Add(reaction(models.SenderExternal, "user-123", "👍"))
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		added, err = repo.Add(reaction(models.SenderExternal, "user-123", "👍"))
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		for _, r := range []*models.MessageReaction{
+			reaction(models.SenderExternal, "user-123", "🔥"),
+			reaction(models.SenderInternal, "user-123", "👍"),
+		} {
+			added, err = repo.Add(r)
+			require.NoError(t, err)
+			assert.True(t, added)
+		}
+
+		reactions, err := repo.ListByMessage(msg.ID)
+		require.NoError(t, err)
+		require.Len(t, reactions, 3)
+		assert.Equal(t, "👍", reactions[0].Emoji)
+		assert.Equal(t, models.SenderInternal, reactions[2].SenderType)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		removed, err := repo.Remove(msg.ID, models.SenderExternal, "user-123", "👍")
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		removed, err = repo.Remove(msg.ID, models.SenderExternal, "user-123", "👍")
+		require.NoError(t, err)
+		assert.False(t, removed)
+
+		reactions, err := repo.ListByMessage(msg.ID)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 2)
+	})
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// unreadCountExpr counts a conversation's inbound messages after an agent's
+// read cursor, leaving out deleted ones. Its only parameter is the agent ID.
+const unreadCountExpr = `(SELECT COUNT(*) FROM messages WHERE messages.conversation_id = conversations.id
+	AND messages.direction = 'inbound' AND messages.deleted_at IS NULL AND messages.id > COALESCE((SELECT read_cursors.last_read_message_id
+	FROM read_cursors WHERE read_cursors.conversation_id = conversations.id AND read_cursors.agent_id = ?), 0))`
+
+type ReadCursorRepository interface {
+	// Advance moves the agent's cursor forward to messageID and returns it.
+	// A cursor already past messageID is left where it is.
+	Advance(conversationID int64, agentID string, messageID int64) (*models.ReadCursor, error)
+	// UnreadCount counts the conversation's inbound messages after the
+	// agent's cursor that have not been deleted
+	UnreadCount(conversationID int64, agentID string) (int64, error)
+	// Totals sums the agent's unread messages over the organization's open
+	// and pending conversations assigned to them
+	Totals(orgID int64, agentID string) (*models.UnreadTotals, error)
+}
+
+type readCursorRepository struct {
+	db *gorm.DB
+}
+
+func NewReadCursorRepository(db *gorm.DB) ReadCursorRepository {
+	return &readCursorRepository{db: db}
+}
+
+func (r *readCursorRepository) Advance(conversationID int64, agentID string, messageID int64) (*models.ReadCursor, error) {
+	cursor := &models.ReadCursor{
+		ConversationID:    conversationID,
+		AgentID:           agentID,
+		LastReadMessageID: messageID,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "conversation_id"}, {Name: "agent_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_read_message_id": gorm.Expr("MAX(read_cursors.last_read_message_id, excluded.last_read_message_id)"),
+			"updated_at":           gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(cursor).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save read cursor: %w", err)
+	}
+
+	var saved models.ReadCursor
+	if err := r.db.Where("conversation_id = ? AND agent_id = ?", conversationID, agentID).First(&saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to get read cursor: %w", err)
+	}
+	return &saved, nil
+}
+
+func (r *readCursorRepository) UnreadCount(conversationID int64, agentID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Conversation{}).
+		Select(unreadCountExpr, agentID).
+		Where("id = ?", conversationID).
+		Scan(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+func (r *readCursorRepository) Totals(orgID int64, agentID string) (*models.UnreadTotals, error) {
+	unread := r.db.Model(&models.Conversation{}).
+		Select(unreadCountExpr+" AS unread", agentID).
+		Joins("JOIN chat_channels ON chat_channels.id = conversations.channel_id").
+		Where("chat_channels.organization_id = ? AND conversations.assigned_to_external_id = ? AND conversations.status IN ?",
+			orgID, agentID, []models.ConversationStatus{models.ConversationStatusOpen, models.ConversationStatusPending})
+
+	totals := &models.UnreadTotals{AgentID: agentID}
+	err := r.db.Table("(?) AS assigned", unread).
+		Select("COALESCE(SUM(unread), 0) AS messages, COALESCE(SUM(unread > 0), 0) AS conversations").
+		Scan(totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return totals, nil
+}
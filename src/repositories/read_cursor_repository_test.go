@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCursorRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewReadCursorRepository(db)
+	convRepo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	other := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	agent := "agent-1"
+	for _, c := range []*models.Conversation{conv, other} {
+		require.NoError(t, convRepo.Update(c.ID, &models.UpdateConversationRequest{AssignedToExternalID: &agent}))
+	}
+
+	addMessage := func(convID int64, direction models.MessageDirection) *models.Message {
+		msg := &models.Message{
+			ConversationID: convID,
+			SenderType:     models.SenderExternal,
+			Content:        "hi",
+			Direction:      direction,
+			Status:         models.MessageStatusReceived,
+		}
+		require.NoError(t, db.Create(msg).Error)
+		return msg
+	}
+	first := addMessage(conv.ID, models.DirectionInbound)
+	addMessage(conv.ID, models.DirectionOutbound)
+	third := addMessage(conv.ID, models.DirectionInbound)
+	addMessage(other.ID, models.DirectionInbound)
+
+	t.Run("everything is unread without a cursor", func(t *testing.T) {
+		count, err := repo.UnreadCount(conv.ID, agent)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		totals, err := repo.Totals(org.ID, agent)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), totals.Messages)
+		assert.Equal(t, int64(2), totals.Conversations)
+	})
+
+	t.Run("advance", func(t *testing.T) {
+		cursor, err := repo.Advance(conv.ID, agent, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, cursor.LastReadMessageID)
+
+		count, err := repo.UnreadCount(conv.ID, agent)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repo.UnreadCount(conv.ID, "agent-2")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("deleted messages are not unread", func(t *testing.T) {
+		deleted := addMessage(conv.ID, models.DirectionInbound)
+		require.NoError(t, db.Model(deleted).Update("deleted_at", time.Now()).Error)
+
+		count, err := repo.UnreadCount(conv.ID, agent)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("never moves backwards", func(t *testing.T) {
+		_, err := repo.Advance(conv.ID, agent, third.ID)
+		require.NoError(t, err)
+		cursor, err := repo.Advance(conv.ID, agent, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, third.ID, cursor.LastReadMessageID)
+
+		totals, err := repo.Totals(org.ID, agent)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), totals.Messages)
+		assert.Equal(t, int64(1), totals.Conversations)
+	})
+
+	t.Run("inbox unread counts", func(t *testing.T) {
+		page, err := convRepo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Reader: agent, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, page.Data, 1)
+		require.NotNil(t, page.NextCursor)
+
+		next, err := convRepo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Reader: agent, Limit: 1, Cursor: *page.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, next.Data, 1)
+
+		counts := map[int64]int64{}
+		for _, c := range append(page.Data, next.Data...) {
+			require.NotNil(t, c.UnreadCount)
+			counts[c.ID] = *c.UnreadCount
+		}
+		assert.Equal(t, map[int64]int64{conv.ID: 0, other.ID: 1}, counts)
+
+		page, err = convRepo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Limit: 20})
+		require.NoError(t, err)
+		assert.Nil(t, page.Data[0].UnreadCount)
+	})
+
+	t.Run("inbox has_unread follows the reader's cursor", func(t *testing.T) {
+		ids := func(hasUnread bool) []int64 {
+			page, err := convRepo.ListInbox(&models.InboxQuery{OrganizationID: org.ID, Reader: agent, HasUnread: &hasUnread, Limit: 20})
+			require.NoError(t, err)
+			var result []int64
+			for _, c := range page.Data {
+				result = append(result, c.ID)
+			}
+			return result
+		}
+
+		// conv has been read, though its messages are still received
+		assert.Equal(t, []int64{other.ID}, ids(true))
+		assert.Equal(t, []int64{conv.ID}, ids(false))
+	})
+}
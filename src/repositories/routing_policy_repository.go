@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoutingPolicyRepository interface {
+	// FindByChannel returns nil when the channel has no routing policy
+	FindByChannel(channelID int64) (*models.RoutingPolicy, error)
+	Upsert(channelID int64, req *models.UpsertRoutingPolicyRequest) (*models.RoutingPolicy, error)
+}
+
+type routingPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingPolicyRepository(db *gorm.DB) RoutingPolicyRepository {
+	return &routingPolicyRepository{db: db}
+}
+
+func (r *routingPolicyRepository) FindByChannel(channelID int64) (*models.RoutingPolicy, error) {
+	var policy models.RoutingPolicy
+	if err := r.db.Where("channel_id = ?", channelID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get routing policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *routingPolicyRepository) Upsert(channelID int64, req *models.UpsertRoutingPolicyRequest) (*models.RoutingPolicy, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	policy := &models.RoutingPolicy{
+		ChannelID:            channelID,
+		Strategy:             req.Strategy,
+		ReassignAfterSeconds: req.ReassignAfterSeconds,
+		Enabled:              enabled,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"strategy", "reassign_after_seconds", "enabled", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save routing policy: %w", err)
+	}
+
+	return r.FindByChannel(channelID)
+}
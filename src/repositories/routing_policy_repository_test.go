@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingPolicyRepository_Upsert(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewRoutingPolicyRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+
+	policy, err := repo.FindByChannel(channel.ID)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = repo.Upsert(channel.ID, &models.UpsertRoutingPolicyRequest{Strategy: models.RoutingRoundRobin, ReassignAfterSeconds: 300})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoutingRoundRobin, policy.Strategy)
+	assert.True(t, policy.Enabled)
+
+	disabled := false
+	updated, err := repo.Upsert(channel.ID, &models.UpsertRoutingPolicyRequest{Strategy: models.RoutingSticky, Enabled: &disabled})
+	require.NoError(t, err)
+	assert.Equal(t, policy.ID, updated.ID)
+	assert.Equal(t, models.RoutingSticky, updated.Strategy)
+	assert.Equal(t, 0, updated.ReassignAfterSeconds)
+	assert.False(t, updated.Enabled)
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SLARepository interface {
+	CreatePolicy(req *models.CreateSLAPolicyRequest) (*models.SLAPolicy, error)
+	GetPolicy(id int64) (*models.SLAPolicy, error)
+	ListPolicies(orgID int64, limit, offset int) ([]*models.SLAPolicy, error)
+	UpdatePolicy(id int64, req *models.UpdateSLAPolicyRequest) error
+	DeletePolicy(id int64) error
+	// MatchPolicy returns the most specific enabled policy for a
+	// conversation, preferring channel matches over priority matches, or
+	// nil if none applies
+	MatchPolicy(orgID, channelID int64, priority models.ConversationPriority) (*models.SLAPolicy, error)
+	// RecordAlert stores an alert and reports false if it was already
+	// recorded
+	RecordAlert(alert *models.SLAAlert) (bool, error)
+}
+
+type slaRepository struct {
+	db *gorm.DB
+}
+
+func NewSLARepository(db *gorm.DB) SLARepository {
+	return &slaRepository{db: db}
+}
+
+func (r *slaRepository) CreatePolicy(req *models.CreateSLAPolicyRequest) (*models.SLAPolicy, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	policy := &models.SLAPolicy{
+		OrganizationID:       req.OrganizationID,
+		Name:                 req.Name,
+		ChannelID:            req.ChannelID,
+		Priority:             req.Priority,
+		FirstResponseSeconds: req.FirstResponseSeconds,
+		NextResponseSeconds:  req.NextResponseSeconds,
+		ResolutionSeconds:    req.ResolutionSeconds,
+		WarnBeforeSeconds:    req.WarnBeforeSeconds,
+		BusinessHoursOnly:    req.BusinessHoursOnly,
+		Enabled:              enabled,
+	}
+
+	if err := r.db.Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to create SLA policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (r *slaRepository) GetPolicy(id int64) (*models.SLAPolicy, error) {
+	var policy models.SLAPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("SLA policy not found")
+		}
+		return nil, fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *slaRepository) ListPolicies(orgID int64, limit, offset int) ([]*models.SLAPolicy, error) {
+	var policies []*models.SLAPolicy
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&policies).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLA policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (r *slaRepository) UpdatePolicy(id int64, req *models.UpdateSLAPolicyRequest) error {
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.FirstResponseSeconds != nil {
+		updates["first_response_seconds"] = *req.FirstResponseSeconds
+	}
+	if req.NextResponseSeconds != nil {
+		updates["next_response_seconds"] = *req.NextResponseSeconds
+	}
+	if req.ResolutionSeconds != nil {
+		updates["resolution_seconds"] = *req.ResolutionSeconds
+	}
+	if req.WarnBeforeSeconds != nil {
+		updates["warn_before_seconds"] = *req.WarnBeforeSeconds
+	}
+	if req.BusinessHoursOnly != nil {
+		updates["business_hours_only"] = *req.BusinessHoursOnly
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+
+	result := r.db.Model(&models.SLAPolicy{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update SLA policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("SLA policy not found")
+	}
+
+	return nil
+}
+
+// DeletePolicy removes the policy. Conversations that used it keep their
+// due times but are no longer checked.
+func (r *slaRepository) DeletePolicy(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.SLAPolicy{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete SLA policy: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("SLA policy not found")
+		}
+
+		if err := tx.Model(&models.Conversation{}).Where("sla_policy_id = ?", id).
+			Update("sla_policy_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach SLA policy: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *slaRepository) MatchPolicy(orgID, channelID int64, priority models.ConversationPriority) (*models.SLAPolicy, error) {
+	var policy models.SLAPolicy
+	err := r.db.Where("organization_id = ? AND enabled = ?", orgID, true).
+		Where("(channel_id IS NULL OR channel_id = ?)", channelID).
+		Where("(priority IS NULL OR priority = ?)", priority).
+		Order("channel_id IS NULL").
+		Order("priority IS NULL").
+		Order("id").
+		First(&policy).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to match SLA policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (r *slaRepository) RecordAlert(alert *models.SLAAlert) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record SLA alert: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLARepository_MatchPolicy(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewSLARepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	wa := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	tg := testutils.CreateTestChannel(t, db, org.ID, models.PlatformTelegram, "TG")
+	urgent := models.PriorityUrgent
+	disabled := false
+
+	policy, err := repo.MatchPolicy(org.ID, wa.ID, models.PriorityNormal)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	orgDefault, err := repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "Default", FirstResponseSeconds: 3600})
+	require.NoError(t, err)
+	assert.True(t, orgDefault.Enabled)
+	urgentPolicy, err := repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "Urgent", Priority: &urgent, FirstResponseSeconds: 600})
+	require.NoError(t, err)
+	waPolicy, err := repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "WhatsApp", ChannelID: &wa.ID, FirstResponseSeconds: 1800})
+	require.NoError(t, err)
+	_, err = repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "WhatsApp urgent", ChannelID: &wa.ID, Priority: &urgent, Enabled: &disabled})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		channelID int64
+		priority  models.ConversationPriority
+		want      int64
+	}{
+		{"channel beats priority", wa.ID, models.PriorityUrgent, waPolicy.ID},
+		{"channel", wa.ID, models.PriorityNormal, waPolicy.ID},
+		{"priority", tg.ID, models.PriorityUrgent, urgentPolicy.ID},
+		{"organization default", tg.ID, models.PriorityLow, orgDefault.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := repo.MatchPolicy(org.ID, tt.channelID, tt.priority)
+			require.NoError(t, err)
+			require.NotNil(t, policy)
+			assert.Equal(t, tt.want, policy.ID)
+		})
+	}
+}
+
+func TestSLARepository_DeletePolicy(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewSLARepository(db)
+	convRepo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user1", "User 1")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	policy, err := repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: org.ID, Name: "Default", FirstResponseSeconds: 60})
+	require.NoError(t, err)
+
+	conv.SLAPolicyID = &policy.ID
+	conv.SLAStatus = models.SLAStatusOK
+	require.NoError(t, convRepo.SaveSLA(conv))
+
+	require.NoError(t, repo.DeletePolicy(policy.ID))
+
+	stored, err := convRepo.GetByID(conv.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.SLAPolicyID)
+
+	_, err = repo.GetPolicy(policy.ID)
+	assert.Error(t, err)
+}
+
+func TestSLARepository_RecordAlert(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewSLARepository(db)
+	due := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	created, err := repo.RecordAlert(&models.SLAAlert{ConversationID: 1, Target: models.SLATargetFirstResponse, Kind: models.SLAAlertWarning, DueAt: due})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repo.RecordAlert(&models.SLAAlert{ConversationID: 1, Target: models.SLATargetFirstResponse, Kind: models.SLAAlertWarning, DueAt: due})
+	require.NoError(t, err)
+	assert.False(t, created, "duplicate alert")
+
+	created, err = repo.RecordAlert(&models.SLAAlert{ConversationID: 1, Target: models.SLATargetFirstResponse, Kind: models.SLAAlertBreached, DueAt: due})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repo.RecordAlert(&models.SLAAlert{ConversationID: 1, Target: models.SLATargetNextResponse, Kind: models.SLAAlertWarning, DueAt: due.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.True(t, created)
+}
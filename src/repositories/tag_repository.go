@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	Create(req *models.CreateTagRequest) (*models.Tag, error)
+	GetByID(id int64) (*models.Tag, error)
+	ListByOrganization(orgID int64, limit, offset int) ([]*models.Tag, error)
+	Update(id int64, req *models.UpdateTagRequest) error
+	// Delete removes the tag from the taxonomy and from every conversation
+	Delete(id int64) error
+	// FindByNames returns the organization's tags with the given names,
+	// which are normalized first
+	FindByNames(orgID int64, names []string) ([]*models.Tag, error)
+	ListByConversation(conversationID int64) ([]*models.Tag, error)
+	// AddToConversation links the tags to the conversation and returns the
+	// IDs of those it did not already have
+	AddToConversation(conversationID int64, tagIDs []int64) ([]int64, error)
+	// RemoveFromConversation unlinks the tags from the conversation and
+	// returns the IDs of those it had
+	RemoveFromConversation(conversationID int64, tagIDs []int64) ([]int64, error)
+	// Counts returns every tag of the organization with the number of
+	// matching conversations carrying it, most used first
+	Counts(q *models.TagCountQuery) ([]*models.TagCount, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(req *models.CreateTagRequest) (*models.Tag, error) {
+	tag := &models.Tag{
+		OrganizationID: req.OrganizationID,
+		Name:           models.NormalizeTagName(req.Name),
+		Color:          req.Color,
+		Description:    req.Description,
+	}
+
+	if err := r.db.Create(tag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) GetByID(id int64) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&tags).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) Update(id int64, req *models.UpdateTagRequest) error {
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = models.NormalizeTagName(*req.Name)
+	}
+	if req.Color != nil {
+		updates["color"] = *req.Color
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	result := r.db.Model(&models.Tag{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update tag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete tag: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("tag not found")
+		}
+
+		if err := tx.Where("tag_id = ?", id).Delete(&models.ConversationTag{}).Error; err != nil {
+			return fmt.Errorf("failed to untag conversations: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *tagRepository) FindByNames(orgID int64, names []string) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	err := r.db.Where("organization_id = ? AND name IN ?", orgID, normalizeTagNames(names)).
+		Order("name").
+		Find(&tags).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) ListByConversation(conversationID int64) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	err := r.db.Joins("JOIN conversation_tags ON conversation_tags.tag_id = tags.id").
+		Where("conversation_tags.conversation_id = ?", conversationID).
+		Order("tags.name").
+		Find(&tags).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversation tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) AddToConversation(conversationID int64, tagIDs []int64) ([]int64, error) {
+	added := make([]int64, 0, len(tagIDs))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := conversationTagIDs(tx, conversationID, tagIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, tagID := range tagIDs {
+			if existing[tagID] {
+				continue
+			}
+			if err := tx.Create(&models.ConversationTag{ConversationID: conversationID, TagID: tagID}).Error; err != nil {
+				return err
+			}
+			existing[tagID] = true
+			added = append(added, tagID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to tag conversation: %w", err)
+	}
+	return added, nil
+}
+
+func (r *tagRepository) RemoveFromConversation(conversationID int64, tagIDs []int64) ([]int64, error) {
+	removed := make([]int64, 0, len(tagIDs))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := conversationTagIDs(tx, conversationID, tagIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, tagID := range tagIDs {
+			if existing[tagID] {
+				removed = append(removed, tagID)
+				delete(existing, tagID)
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		return tx.Where("conversation_id = ? AND tag_id IN ?", conversationID, removed).
+			Delete(&models.ConversationTag{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to untag conversation: %w", err)
+	}
+	return removed, nil
+}
+
+func (r *tagRepository) Counts(q *models.TagCountQuery) ([]*models.TagCount, error) {
+	on := "conversations.id = conversation_tags.conversation_id"
+	var args []interface{}
+	if len(q.Statuses) > 0 {
+		on += " AND conversations.status IN ?"
+		args = append(args, q.Statuses)
+	}
+	if q.CreatedAfter != nil {
+		on += " AND datetime(conversations.created_at) >= datetime(?)"
+		args = append(args, sqliteTime(*q.CreatedAfter))
+	}
+	if q.CreatedBefore != nil {
+		on += " AND datetime(conversations.created_at) < datetime(?)"
+		args = append(args, sqliteTime(*q.CreatedBefore))
+	}
+
+	counts := make([]*models.TagCount, 0)
+	err := r.db.Model(&models.Tag{}).
+		Select("tags.id AS tag_id, tags.name, tags.color, COUNT(conversations.id) AS conversations").
+		Joins("LEFT JOIN conversation_tags ON conversation_tags.tag_id = tags.id").
+		Joins("LEFT JOIN conversations ON "+on, args...).
+		Where("tags.organization_id = ?", q.OrganizationID).
+		Group("tags.id").
+		Order("conversations DESC").
+		Order("tags.name").
+		Scan(&counts).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	return counts, nil
+}
+
+// conversationTagIDs returns which of tagIDs the conversation already has
+func conversationTagIDs(tx *gorm.DB, conversationID int64, tagIDs []int64) (map[int64]bool, error) {
+	var ids []int64
+	err := tx.Model(&models.ConversationTag{}).
+		Where("conversation_id = ? AND tag_id IN ?", conversationID, tagIDs).
+		Pluck("tag_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		existing[id] = true
+	}
+	return existing, nil
+}
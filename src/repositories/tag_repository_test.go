@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRepository(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewTagRepository(db)
+	convRepo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	otherOrg := testutils.CreateTestOrganization(t, db, "Other Org", "otherorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+	other := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	color := "#ff0000"
+	billing, err := repo.Create(&models.CreateTagRequest{OrganizationID: org.ID, Name: " Billing ", Color: &color})
+	require.NoError(t, err)
+	assert.Equal(t, "billing", billing.Name)
+	vip, err := repo.Create(&models.CreateTagRequest{OrganizationID: org.ID, Name: "vip"})
+	require.NoError(t, err)
+	_, err = repo.Create(&models.CreateTagRequest{OrganizationID: otherOrg.ID, Name: "billing"})
+	require.NoError(t, err)
+
+	t.Run("names are unique per organization", func(t *testing.T) {
+		_, err := repo.Create(&models.CreateTagRequest{OrganizationID: org.ID, Name: "BILLING"})
+		assert.Error(t, err)
+	})
+
+	t.Run("find by names", func(t *testing.T) {
+		tags, err := repo.FindByNames(org.ID, []string{"Billing", "unknown"})
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		assert.Equal(t, billing.ID, tags[0].ID)
+	})
+
+	t.Run("add and remove report what changed", func(t *testing.T) {
+		added, err := repo.AddToConversation(conv.ID, []int64{billing.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{billing.ID}, added)
+
+		added, err = repo.AddToConversation(conv.ID, []int64{billing.ID, vip.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{vip.ID}, added)
+
+		tags, err := repo.ListByConversation(conv.ID)
+		require.NoError(t, err)
+		assert.Len(t, tags, 2)
+
+		removed, err := repo.RemoveFromConversation(conv.ID, []int64{vip.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{vip.ID}, removed)
+
+		removed, err = repo.RemoveFromConversation(conv.ID, []int64{vip.ID})
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+	})
+
+	t.Run("channel list filters by tag", func(t *testing.T) {
+		conversations, err := convRepo.List(channel.ID, nil, []string{"Billing"}, nil, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, conversations, 1)
+		assert.Equal(t, conv.ID, conversations[0].ID)
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		_, err := repo.AddToConversation(other.ID, []int64{billing.ID})
+		require.NoError(t, err)
+		resolved := models.ConversationStatusResolved
+		require.NoError(t, convRepo.Update(other.ID, &models.UpdateConversationRequest{Status: &resolved}))
+
+		counts, err := repo.Counts(&models.TagCountQuery{OrganizationID: org.ID})
+		require.NoError(t, err)
+		require.Len(t, counts, 2)
+		assert.Equal(t, "billing", counts[0].Name)
+		assert.Equal(t, int64(2), counts[0].Conversations)
+		assert.Equal(t, &color, counts[0].Color)
+		assert.Equal(t, "vip", counts[1].Name)
+		assert.Equal(t, int64(0), counts[1].Conversations)
+
+		counts, err = repo.Counts(&models.TagCountQuery{
+			OrganizationID: org.ID,
+			Statuses:       []models.ConversationStatus{models.ConversationStatusOpen},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), counts[0].Conversations)
+
+		future := time.Now().Add(time.Hour)
+		counts, err = repo.Counts(&models.TagCountQuery{OrganizationID: org.ID, CreatedAfter: &future})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), counts[0].Conversations)
+	})
+
+	t.Run("delete untags conversations", func(t *testing.T) {
+		require.NoError(t, repo.Delete(billing.ID))
+
+		tags, err := repo.ListByConversation(conv.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+
+		_, err = repo.GetByID(billing.ID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+	"gorm.io/gorm"
+)
+
+type TeamRepository interface {
+	Create(req *models.CreateTeamRequest) (*models.Team, error)
+	GetByID(id int64) (*models.Team, error)
+	ListByOrganization(orgID int64, limit, offset int) ([]*models.Team, error)
+	Update(id int64, req *models.UpdateTeamRequest) error
+	Delete(id int64) error
+	// SetMembers replaces the team's members
+	SetMembers(id int64, agentIDs []string) error
+	ListMembers(id int64) ([]string, error)
+	IsMember(id int64, agentID string) (bool, error)
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(req *models.CreateTeamRequest) (*models.Team, error) {
+	team := &models.Team{
+		OrganizationID: req.OrganizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		return replaceTeamMembers(tx, team.ID, req.MemberIDs)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	team.MemberIDs = dedupe(req.MemberIDs)
+	return team, nil
+}
+
+func (r *teamRepository) GetByID(id int64) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("team not found")
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	members, err := r.ListMembers(id)
+	if err != nil {
+		return nil, err
+	}
+	team.MemberIDs = members
+
+	return &team, nil
+}
+
+func (r *teamRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Team, error) {
+	var teams []*models.Team
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&teams).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	for _, team := range teams {
+		if team.MemberIDs, err = r.ListMembers(team.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return teams, nil
+}
+
+func (r *teamRepository) Update(id int64, req *models.UpdateTeamRequest) error {
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	result := r.db.Model(&models.Team{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update team: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("team not found")
+	}
+
+	return nil
+}
+
+// Delete removes the team and its members. Conversations and channels that
+// referenced it are left without a team.
+func (r *teamRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Team{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete team: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("team not found")
+		}
+
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("failed to delete team members: %w", err)
+		}
+		if err := tx.Model(&models.Conversation{}).Where("team_id = ?", id).Update("team_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unassign team conversations: %w", err)
+		}
+		if err := tx.Model(&models.ChatChannel{}).Where("default_team_id = ?", id).Update("default_team_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear channel default team: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *teamRepository) SetMembers(id int64, agentIDs []string) error {
+	var count int64
+	if err := r.db.Model(&models.Team{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("team not found")
+	}
+
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceTeamMembers(tx, id, agentIDs)
+	}); err != nil {
+		return fmt.Errorf("failed to set team members: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) ListMembers(id int64) ([]string, error) {
+	members := make([]string, 0)
+	err := r.db.Model(&models.TeamMember{}).
+		Where("team_id = ?", id).
+		Order("agent_id").
+		Pluck("agent_id", &members).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+	return members, nil
+}
+
+func (r *teamRepository) IsMember(id int64, agentID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.TeamMember{}).
+		Where("team_id = ? AND agent_id = ?", id, agentID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+func replaceTeamMembers(tx *gorm.DB, teamID int64, agentIDs []string) error {
+	if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+		return err
+	}
+
+	members := make([]*models.TeamMember, 0, len(agentIDs))
+	for _, agentID := range dedupe(agentIDs) {
+		members = append(members, &models.TeamMember{TeamID: teamID, AgentID: agentID})
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return tx.Create(members).Error
+}
+
+// dedupe returns the distinct values in their first-seen order
+func dedupe(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamRepository_Members(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewTeamRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+
+	team, err := repo.Create(&models.CreateTeamRequest{
+		OrganizationID: org.ID,
+		Name:           "billing",
+		MemberIDs:      []string{"agent-2", "agent-1", "agent-2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"agent-2", "agent-1"}, team.MemberIDs)
+
+	t.Run("name is unique per organization", func(t *testing.T) {
+		_, err := repo.Create(&models.CreateTeamRequest{OrganizationID: org.ID, Name: "billing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("set members replaces them", func(t *testing.T) {
+		require.NoError(t, repo.SetMembers(team.ID, []string{"agent-3", "agent-1"}))
+
+		got, err := repo.GetByID(team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"agent-1", "agent-3"}, got.MemberIDs)
+
+		member, err := repo.IsMember(team.ID, "agent-2")
+		require.NoError(t, err)
+		assert.False(t, member)
+	})
+
+	t.Run("set members of unknown team", func(t *testing.T) {
+		assert.Error(t, repo.SetMembers(999, []string{"agent-1"}))
+	})
+}
+
+func TestTeamRepository_Delete(t *testing.T) {
+	db, cleanup := testutils.SetupTestDBFile(t)
+	defer cleanup()
+
+	repo := NewTeamRepository(db)
+	channelRepo := NewChannelRepository(db)
+	convRepo := NewConversationRepository(db)
+	org := testutils.CreateTestOrganization(t, db, "Test Org", "testorg")
+	channel := testutils.CreateTestChannel(t, db, org.ID, models.PlatformWhatsApp, "WA")
+	user := testutils.CreateTestExternalUser(t, db, channel.ID, "user-123", "John Doe")
+	conv := testutils.CreateTestConversation(t, db, channel.ID, user.ID)
+
+	team, err := repo.Create(&models.CreateTeamRequest{OrganizationID: org.ID, Name: "billing", MemberIDs: []string{"agent-1"}})
+	require.NoError(t, err)
+	require.NoError(t, channelRepo.Update(channel.ID, &models.UpdateChannelRequest{DefaultTeamID: &team.ID}))
+	require.NoError(t, convRepo.Update(conv.ID, &models.UpdateConversationRequest{TeamID: &team.ID}))
+
+	require.NoError(t, repo.Delete(team.ID))
+
+	gotChannel, err := channelRepo.GetByID(channel.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotChannel.DefaultTeamID)
+
+	gotConv, err := convRepo.GetByID(conv.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gotConv.TeamID)
+
+	members, err := repo.ListMembers(team.ID)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	assert.Error(t, repo.Delete(team.ID))
+}
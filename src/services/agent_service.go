@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+type AgentService interface {
+	Create(ctx context.Context, req *models.CreateAgentRequest) (*models.Agent, error)
+	GetByID(ctx context.Context, id int64) (*models.Agent, error)
+	ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Agent, error)
+	Update(ctx context.Context, id int64, req *models.UpdateAgentRequest) error
+	// UpdateStatus changes an agent's availability. Going offline hands
+	// their open conversations back to routing.
+	UpdateStatus(ctx context.Context, id int64, status models.AgentStatus) error
+}
+
+type agentService struct {
+	repo    repositories.AgentRepository
+	routing RoutingService
+}
+
+func NewAgentService(repo repositories.AgentRepository, routing RoutingService) AgentService {
+	return &agentService{
+		repo:    repo,
+		routing: routing,
+	}
+}
+
+func (s *agentService) Create(ctx context.Context, req *models.CreateAgentRequest) (*models.Agent, error) {
+	return s.repo.Create(req)
+}
+
+func (s *agentService) GetByID(ctx context.Context, id int64) (*models.Agent, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *agentService) ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Agent, error) {
+	return s.repo.ListByOrganization(orgID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *agentService) Update(ctx context.Context, id int64, req *models.UpdateAgentRequest) error {
+	return s.repo.Update(id, req)
+}
+
+func (s *agentService) UpdateStatus(ctx context.Context, id int64, status models.AgentStatus) error {
+	agent, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if agent == nil {
+		return fmt.Errorf("agent not found")
+	}
+	wasOffline := agent.Status == models.AgentStatusOffline
+
+	if err := s.repo.UpdateStatus(id, status); err != nil {
+		return err
+	}
+
+	if status == models.AgentStatusOffline && !wasOffline {
+		if _, err := s.routing.ReassignFromAgent(ctx, agent.OrganizationID, agent.ExternalID); err != nil {
+			return fmt.Errorf("failed to reassign conversations: %w", err)
+		}
+	}
+
+	return nil
+}
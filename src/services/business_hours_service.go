@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// BusinessHoursService manages the weekly schedules and holidays of
+// organizations and channels, and answers customers who write while closed
+type BusinessHoursService interface {
+	GetForOrganization(ctx context.Context, orgID int64) (*models.BusinessHours, error)
+	SetForOrganization(ctx context.Context, orgID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error)
+	// GetForChannel returns the schedule in effect for a channel, which is
+	// its organization's unless the channel has its own
+	GetForChannel(ctx context.Context, channelID int64) (*models.BusinessHours, error)
+	SetForChannel(ctx context.Context, channelID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error)
+	// StatusForOrganization and StatusForChannel report whether the schedule
+	// in effect is open at the given time. Without a schedule it is always
+	// open.
+	StatusForOrganization(ctx context.Context, orgID int64, at time.Time) (*models.BusinessHoursStatus, error)
+	StatusForChannel(ctx context.Context, channelID int64, at time.Time) (*models.BusinessHoursStatus, error)
+	// AwayReply sends the schedule's away message when a customer writes
+	// to a conversation outside business hours, unless one was sent within
+	// the cooldown. It reports whether a reply was sent.
+	AwayReply(ctx context.Context, conv *models.Conversation, at time.Time) (bool, error)
+}
+
+type businessHoursService struct {
+	repo             repositories.BusinessHoursRepository
+	channelRepo      repositories.ChannelRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	emitter          events.Emitter
+}
+
+func NewBusinessHoursService(
+	repo repositories.BusinessHoursRepository,
+	channelRepo repositories.ChannelRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	emitter events.Emitter,
+) BusinessHoursService {
+	return &businessHoursService{
+		repo:             repo,
+		channelRepo:      channelRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *businessHoursService) GetForOrganization(ctx context.Context, orgID int64) (*models.BusinessHours, error) {
+	hours, err := s.repo.Find(orgID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if hours == nil {
+		return nil, fmt.Errorf("business hours not found")
+	}
+	return hours, nil
+}
+
+func (s *businessHoursService) SetForOrganization(ctx context.Context, orgID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error) {
+	if err := models.ValidateBusinessHoursWindows(req.Windows); err != nil {
+		return nil, err
+	}
+	return s.repo.Upsert(orgID, 0, req)
+}
+
+func (s *businessHoursService) GetForChannel(ctx context.Context, channelID int64) (*models.BusinessHours, error) {
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := s.repo.Resolve(channel.OrganizationID, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hours == nil {
+		return nil, fmt.Errorf("business hours not found")
+	}
+	return hours, nil
+}
+
+func (s *businessHoursService) SetForChannel(ctx context.Context, channelID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error) {
+	if err := models.ValidateBusinessHoursWindows(req.Windows); err != nil {
+		return nil, err
+	}
+
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Upsert(channel.OrganizationID, channel.ID, req)
+}
+
+func (s *businessHoursService) StatusForOrganization(ctx context.Context, orgID int64, at time.Time) (*models.BusinessHoursStatus, error) {
+	hours, err := s.repo.Find(orgID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return hours.Status(at), nil
+}
+
+func (s *businessHoursService) StatusForChannel(ctx context.Context, channelID int64, at time.Time) (*models.BusinessHoursStatus, error) {
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := s.repo.Resolve(channel.OrganizationID, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+	return hours.Status(at), nil
+}
+
+func (s *businessHoursService) AwayReply(ctx context.Context, conv *models.Conversation, at time.Time) (bool, error) {
+	channel, err := s.channel(conv.ChannelID)
+	if err != nil {
+		return false, err
+	}
+	hours, err := s.repo.Resolve(channel.OrganizationID, channel.ID)
+	if err != nil {
+		return false, err
+	}
+	if hours == nil || hours.AwayMessage == nil || *hours.AwayMessage == "" || hours.IsOpen(at) {
+		return false, nil
+	}
+
+	claimed, err := s.conversationRepo.ClaimAwayReply(conv.ID, at, at.Add(-hours.AwayCooldown()))
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if _, err := sendSystemMessage(ctx, s.messageRepo, s.emitter, conv.ID, conv.ChannelID, *hours.AwayMessage, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *businessHoursService) channel(id int64) (*models.ChatChannel, error) {
+	channel, err := s.channelRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	return channel, nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type businessHoursFixture struct {
+	service  BusinessHoursService
+	repo     *testutils.MockBusinessHoursRepository
+	convRepo *testutils.MockConversationRepository
+	msgRepo  *testutils.MockMessageRepository
+	emitter  *testutils.MockEmitter
+	loc      *time.Location
+}
+
+func newBusinessHoursFixture(t *testing.T) *businessHoursFixture {
+	f := &businessHoursFixture{
+		repo:     testutils.NewMockBusinessHoursRepository(),
+		convRepo: testutils.NewMockConversationRepository(),
+		msgRepo:  testutils.NewMockMessageRepository(),
+		emitter:  testutils.NewMockEmitter(),
+	}
+	channelRepo := testutils.NewMockChannelRepository()
+	f.service = NewBusinessHoursService(f.repo, channelRepo, f.convRepo, f.msgRepo, f.emitter)
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+
+	var err error
+	f.loc, err = time.LoadLocation("Asia/Kathmandu")
+	require.NoError(t, err)
+	return f
+}
+
+func weekdayWindows(start, end string) []models.BusinessHoursWindow {
+	var windows []models.BusinessHoursWindow
+	for _, day := range []string{"mon", "tue", "wed", "thu", "fri"} {
+		windows = append(windows, models.BusinessHoursWindow{Day: day, Start: start, End: end})
+	}
+	return windows
+}
+
+func TestBusinessHoursService_Status(t *testing.T) {
+	f := newBusinessHoursFixture(t)
+	ctx := context.Background()
+
+	t.Run("always open without a schedule", func(t *testing.T) {
+		status, err := f.service.StatusForChannel(ctx, 1, time.Now())
+		require.NoError(t, err)
+		assert.True(t, status.Open)
+		assert.Nil(t, status.OpensAt)
+	})
+
+	_, err := f.service.SetForOrganization(ctx, 1, &models.SetBusinessHoursRequest{
+		Timezone: "Asia/Kathmandu",
+		Windows:  weekdayWindows("09:00", "17:00"),
+		Holidays: []models.BusinessHoliday{
+			{Date: "2026-10-20", Name: "Dashain"},
+			{Date: "2020-12-25", Name: "Christmas", Recurring: true},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("open", func(t *testing.T) {
+		// Monday 10:00
+		status, err := f.service.StatusForChannel(ctx, 1, time.Date(2026, 10, 19, 10, 0, 0, 0, f.loc))
+		require.NoError(t, err)
+		assert.True(t, status.Open)
+		assert.Equal(t, "Asia/Kathmandu", status.Timezone)
+		require.NotNil(t, status.ClosesAt)
+		assert.True(t, time.Date(2026, 10, 19, 17, 0, 0, 0, f.loc).Equal(*status.ClosesAt))
+	})
+
+	t.Run("closed until the day after a holiday", func(t *testing.T) {
+		// Monday 18:00; Tuesday is a holiday
+		status, err := f.service.StatusForOrganization(ctx, 1, time.Date(2026, 10, 19, 18, 0, 0, 0, f.loc))
+		require.NoError(t, err)
+		assert.False(t, status.Open)
+		assert.Nil(t, status.Holiday)
+		require.NotNil(t, status.OpensAt)
+		assert.True(t, time.Date(2026, 10, 21, 9, 0, 0, 0, f.loc).Equal(*status.OpensAt))
+	})
+
+	t.Run("recurring holiday", func(t *testing.T) {
+		// Friday 25 December 2026, within the usual hours
+		status, err := f.service.StatusForOrganization(ctx, 1, time.Date(2026, 12, 25, 10, 0, 0, 0, f.loc))
+		require.NoError(t, err)
+		assert.False(t, status.Open)
+		require.NotNil(t, status.Holiday)
+		assert.Equal(t, "Christmas", status.Holiday.Name)
+		assert.True(t, time.Date(2026, 12, 28, 9, 0, 0, 0, f.loc).Equal(*status.OpensAt))
+	})
+
+	t.Run("holidays pause business time", func(t *testing.T) {
+		hours, err := f.service.GetForOrganization(ctx, 1)
+		require.NoError(t, err)
+		// Two business hours from Monday 16:00 skip the Tuesday holiday
+		due := hours.Add(time.Date(2026, 10, 19, 16, 0, 0, 0, f.loc), 2*time.Hour)
+		assert.True(t, time.Date(2026, 10, 21, 10, 0, 0, 0, f.loc).Equal(due))
+	})
+}
+
+func TestBusinessHoursService_AwayReply(t *testing.T) {
+	f := newBusinessHoursFixture(t)
+	ctx := context.Background()
+
+	away := "We're closed right now and will reply when we open."
+	_, err := f.service.SetForOrganization(ctx, 1, &models.SetBusinessHoursRequest{
+		Timezone:            "Asia/Kathmandu",
+		Windows:             weekdayWindows("09:00", "17:00"),
+		AwayMessage:         &away,
+		AwayCooldownSeconds: 1800,
+	})
+	require.NoError(t, err)
+
+	conv, err := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	require.NoError(t, err)
+
+	t.Run("not sent while open", func(t *testing.T) {
+		sent, err := f.service.AwayReply(ctx, conv, time.Date(2026, 10, 19, 10, 0, 0, 0, f.loc))
+		require.NoError(t, err)
+		assert.False(t, sent)
+	})
+
+	closed := time.Date(2026, 10, 19, 20, 0, 0, 0, f.loc)
+
+	t.Run("sent once within the cooldown", func(t *testing.T) {
+		sent, err := f.service.AwayReply(ctx, conv, closed)
+		require.NoError(t, err)
+		assert.True(t, sent)
+
+		sent, err = f.service.AwayReply(ctx, conv, closed.Add(10*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, sent)
+
+		sent, err = f.service.AwayReply(ctx, conv, closed.Add(31*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, sent)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, f.emitter.EmittedEvents, 2)
+	event := f.emitter.EmittedEvents[0]
+	assert.Equal(t, events.EventNewMessage, event.EventType)
+	assert.Equal(t, away, event.Payload["content"])
+	assert.Equal(t, string(models.DirectionOutbound), event.Payload["direction"])
+
+	messages, err := f.msgRepo.ListByConversation(conv.ID, 10, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, models.SenderSystem, messages[0].SenderType)
+}
+
+func TestMessageService_AwayReplyOnIncomingMessage(t *testing.T) {
+	f := newBusinessHoursFixture(t)
+	ctx := context.Background()
+
+	// Closed all day today
+	away := "We're closed today."
+	_, err := f.service.SetForOrganization(ctx, 1, &models.SetBusinessHoursRequest{
+		Timezone: "Asia/Kathmandu",
+		Windows:  []models.BusinessHoursWindow{{Day: "mon", Start: "09:00", End: "17:00"}},
+		Holidays: []models.BusinessHoliday{
+			{Date: time.Now().In(f.loc).Format("2006-01-02")},
+			{Date: time.Now().In(f.loc).AddDate(0, 0, 1).Format("2006-01-02")},
+		},
+		AwayMessage: &away,
+	})
+	require.NoError(t, err)
+
+	userRepo := testutils.NewMockExternalUserRepository()
+	messages := NewMessageService(f.msgRepo, f.convRepo, userRepo, f.emitter, nil, nil, nil, nil, f.service, nil)
+	for _, content := range []string{"Hello?", "Anyone there?"} {
+		_, err := messages.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+			ChannelID:      1,
+			PlatformUserID: "user-1",
+			Content:        content,
+			MessageType:    models.MessageTypeText,
+		})
+		require.NoError(t, err)
+	}
+
+	stored, err := f.msgRepo.ListByConversation(1, 10, 0, nil)
+	require.NoError(t, err)
+	var replies int
+	for _, msg := range stored {
+		if msg.SenderType == models.SenderSystem {
+			replies++
+			assert.Equal(t, away, msg.Content)
+		}
+	}
+	assert.Equal(t, 1, replies)
+}
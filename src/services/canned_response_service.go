@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// placeholderPattern matches {{entity.key}} placeholders with an optional
+// |fallback used when the value is unknown or empty
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(contact|conversation|agent)\.(\w+)\s*(?:\|([^}]*))?\}\}`)
+
+// CannedResponseService manages an organization's canned responses and
+// fills in their placeholders for a conversation
+type CannedResponseService interface {
+	Create(ctx context.Context, req *models.CreateCannedResponseRequest) (*models.CannedResponse, error)
+	GetByID(ctx context.Context, id int64) (*models.CannedResponse, error)
+	List(ctx context.Context, q *models.CannedResponseQuery) ([]*models.CannedResponse, error)
+	Update(ctx context.Context, id int64, req *models.UpdateCannedResponseRequest) error
+	Delete(ctx context.Context, id int64) error
+	// Render returns the canned response's content with its placeholders
+	// filled in for the conversation, which must be of the same
+	// organization
+	Render(ctx context.Context, id, conversationID int64) (string, error)
+	// RenderContent fills in the placeholders of content for the
+	// conversation. Placeholders of unknown values are replaced with their
+	// fallback, or removed.
+	RenderContent(ctx context.Context, conversationID int64, content string) (string, error)
+}
+
+type cannedResponseService struct {
+	repo             repositories.CannedResponseRepository
+	teamRepo         repositories.TeamRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	externalUserRepo repositories.ExternalUserRepository
+	agentRepo        repositories.AgentRepository
+}
+
+func NewCannedResponseService(
+	repo repositories.CannedResponseRepository,
+	teamRepo repositories.TeamRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	externalUserRepo repositories.ExternalUserRepository,
+	agentRepo repositories.AgentRepository,
+) CannedResponseService {
+	return &cannedResponseService{
+		repo:             repo,
+		teamRepo:         teamRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		externalUserRepo: externalUserRepo,
+		agentRepo:        agentRepo,
+	}
+}
+
+func (s *cannedResponseService) Create(ctx context.Context, req *models.CreateCannedResponseRequest) (*models.CannedResponse, error) {
+	if err := checkTeam(s.teamRepo, req.OrganizationID, req.TeamID); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(req)
+}
+
+func (s *cannedResponseService) GetByID(ctx context.Context, id int64) (*models.CannedResponse, error) {
+	response, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, fmt.Errorf("canned response not found")
+	}
+	return response, nil
+}
+
+func (s *cannedResponseService) List(ctx context.Context, q *models.CannedResponseQuery) ([]*models.CannedResponse, error) {
+	q.Limit = utils.NormalizeLimit(q.Limit)
+	q.Offset = utils.NormalizeOffset(q.Offset)
+	return s.repo.List(q)
+}
+
+func (s *cannedResponseService) Update(ctx context.Context, id int64, req *models.UpdateCannedResponseRequest) error {
+	if req.TeamID != nil && *req.TeamID != 0 {
+		response, err := s.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkTeam(s.teamRepo, response.OrganizationID, req.TeamID); err != nil {
+			return err
+		}
+	}
+	return s.repo.Update(id, req)
+}
+
+func (s *cannedResponseService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *cannedResponseService) Render(ctx context.Context, id, conversationID int64) (string, error) {
+	response, err := s.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	conv, orgID, err := s.conversation(conversationID)
+	if err != nil {
+		return "", err
+	}
+	if response.OrganizationID != orgID {
+		return "", fmt.Errorf("canned response not found")
+	}
+	return s.render(ctx, conv, orgID, response.Content)
+}
+
+func (s *cannedResponseService) RenderContent(ctx context.Context, conversationID int64, content string) (string, error) {
+	conv, orgID, err := s.conversation(conversationID)
+	if err != nil {
+		return "", err
+	}
+	return s.render(ctx, conv, orgID, content)
+}
+
+// conversation gets a conversation and the organization it belongs to
+func (s *cannedResponseService) conversation(id int64) (*models.Conversation, int64, error) {
+	conv, err := s.conversationRepo.GetByID(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if conv == nil {
+		return nil, 0, fmt.Errorf("conversation not found")
+	}
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if channel == nil {
+		return nil, 0, fmt.Errorf("channel not found")
+	}
+	return conv, channel.OrganizationID, nil
+}
+
+func (s *cannedResponseService) render(ctx context.Context, conv *models.Conversation, orgID int64, content string) (string, error) {
+	if !placeholderPattern.MatchString(content) {
+		return content, nil
+	}
+
+	contact, err := s.externalUserRepo.GetByID(conv.ExternalUserID)
+	if err != nil {
+		return "", err
+	}
+	var agent *models.Agent
+	if userID := middleware.UserIDFromContext(ctx); userID != "" {
+		// Agents not known to routing have no name to fill in
+		agent, _ = s.agentRepo.GetByExternalID(orgID, userID)
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		match := placeholderPattern.FindStringSubmatch(placeholder)
+		var value string
+		switch match[1] {
+		case "contact":
+			value = contactValue(contact, match[2])
+		case "conversation":
+			value = conversationValue(conv, match[2])
+		case "agent":
+			if agent != nil && match[2] == "name" {
+				value = agent.Name
+			}
+		}
+		if value == "" {
+			return strings.TrimSpace(match[3])
+		}
+		return value
+	}), nil
+}
+
+func contactValue(contact *models.ExternalUser, key string) string {
+	if contact == nil {
+		return ""
+	}
+	name := ""
+	if contact.DisplayName != nil {
+		name = *contact.DisplayName
+	} else if contact.PlatformUsername != nil {
+		name = *contact.PlatformUsername
+	}
+
+	switch key {
+	case "name":
+		return name
+	case "first_name":
+		if fields := strings.Fields(name); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	case "email":
+		return stringValue(contact.Email)
+	case "phone":
+		return stringValue(contact.PhoneNumber)
+	}
+	return attributeValue(contact.CustomAttributes, key)
+}
+
+func conversationValue(conv *models.Conversation, key string) string {
+	switch key {
+	case "id":
+		return strconv.FormatInt(conv.ID, 10)
+	case "subject":
+		return stringValue(conv.Subject)
+	case "status":
+		return string(conv.Status)
+	case "priority":
+		return string(conv.Priority)
+	}
+	return attributeValue(conv.CustomAttributes, key)
+}
+
+// attributeValue formats a custom attribute value as text
+func attributeValue(attrs models.CustomAttributes, key string) string {
+	switch v := attrs[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// checkTeam reports a team that is not one of the organization's as
+// models.ErrUnknownTeam
+func checkTeam(teamRepo repositories.TeamRepository, orgID int64, teamID *int64) error {
+	if teamID == nil || *teamID == 0 {
+		return nil
+	}
+	team, err := teamRepo.GetByID(*teamID)
+	if err != nil || team == nil || team.OrganizationID != orgID {
+		return fmt.Errorf("%w: %d", models.ErrUnknownTeam, *teamID)
+	}
+	return nil
+}
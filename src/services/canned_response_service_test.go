@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cannedResponseFixture struct {
+	service  CannedResponseService
+	repo     *testutils.MockCannedResponseRepository
+	teamRepo *testutils.MockTeamRepository
+	convRepo *testutils.MockConversationRepository
+	userRepo *testutils.MockExternalUserRepository
+	conv     *models.Conversation
+}
+
+func newCannedResponseFixture(t *testing.T) *cannedResponseFixture {
+	f := &cannedResponseFixture{
+		repo:     testutils.NewMockCannedResponseRepository(),
+		teamRepo: testutils.NewMockTeamRepository(),
+		convRepo: testutils.NewMockConversationRepository(),
+		userRepo: testutils.NewMockExternalUserRepository(),
+	}
+	channelRepo := testutils.NewMockChannelRepository()
+	agentRepo := testutils.NewMockAgentRepository()
+	f.service = NewCannedResponseService(f.repo, f.teamRepo, f.convRepo, channelRepo, f.userRepo, agentRepo)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	agentRepo.Create(&models.CreateAgentRequest{OrganizationID: 1, ExternalID: "agent-1", Name: "Priya"})
+
+	name := "Jane Doe"
+	user, err := f.userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "+15550100", DisplayName: &name})
+	require.NoError(t, err)
+	user.CustomAttributes = models.CustomAttributes{"plan": "pro", "seats": float64(12)}
+
+	f.conv, err = f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: user.ID, Priority: models.PriorityHigh})
+	require.NoError(t, err)
+	f.conv.CustomAttributes = models.CustomAttributes{"vip": true}
+	return f
+}
+
+func TestCannedResponseService_Render(t *testing.T) {
+	f := newCannedResponseFixture(t)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	response, err := f.service.Create(ctx, &models.CreateCannedResponseRequest{
+		OrganizationID: 1,
+		Shortcut:       "hi",
+		Title:          "Greeting",
+		Content:        "Hi {{ contact.first_name }}, {{agent.name}} here about #{{conversation.id}} ({{conversation.priority}}).",
+	})
+	require.NoError(t, err)
+
+	content, err := f.service.Render(ctx, response.ID, f.conv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Jane, Priya here about #1 (high).", content)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"custom attributes", "{{contact.plan}}/{{contact.seats}}/{{conversation.vip}}", "pro/12/true"},
+		{"fallback", "Order {{conversation.order_id | unknown}}", "Order unknown"},
+		{"unknown without fallback", "Email: {{contact.email}}.", "Email: ."},
+		{"other braces are kept", "{{ticket.id}} {not}", "{{ticket.id}} {not}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := f.service.RenderContent(ctx, f.conv.ID, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+
+	t.Run("agents without a name", func(t *testing.T) {
+		content, err := f.service.RenderContent(context.Background(), f.conv.ID, "{{agent.name|The team}}")
+		require.NoError(t, err)
+		assert.Equal(t, "The team", content)
+	})
+
+	t.Run("other organizations' responses", func(t *testing.T) {
+		other, err := f.service.Create(ctx, &models.CreateCannedResponseRequest{OrganizationID: 2, Shortcut: "hi", Title: "Hi", Content: "Hi"})
+		require.NoError(t, err)
+		_, err = f.service.Render(ctx, other.ID, f.conv.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestCannedResponseService_Teams(t *testing.T) {
+	f := newCannedResponseFixture(t)
+	ctx := context.Background()
+	support, _ := f.teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 1, Name: "Support"})
+	outside, _ := f.teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 2, Name: "Support"})
+
+	response, err := f.service.Create(ctx, &models.CreateCannedResponseRequest{OrganizationID: 1, TeamID: &support.ID, Shortcut: "hi", Title: "Hi", Content: "Hi"})
+	require.NoError(t, err)
+
+	_, err = f.service.Create(ctx, &models.CreateCannedResponseRequest{OrganizationID: 1, TeamID: &outside.ID, Shortcut: "bye", Title: "Bye", Content: "Bye"})
+	assert.ErrorIs(t, err, models.ErrUnknownTeam)
+
+	err = f.service.Update(ctx, response.ID, &models.UpdateCannedResponseRequest{TeamID: &outside.ID})
+	assert.ErrorIs(t, err, models.ErrUnknownTeam)
+
+	zero := int64(0)
+	require.NoError(t, f.service.Update(ctx, response.ID, &models.UpdateCannedResponseRequest{TeamID: &zero}))
+	assert.Nil(t, f.repo.Responses[response.ID].TeamID)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
@@ -8,12 +9,12 @@ import (
 )
 
 type ChannelService interface {
-	Create(req *models.CreateChannelRequest) (*models.ChatChannel, error)
-	GetByID(id int64) (*models.ChatChannel, error)
-	ListByOrganization(orgID int64, limit, offset int) ([]*models.ChatChannel, error)
-	Update(id int64, req *models.UpdateChannelRequest) error
-	UpdateStatus(id int64, status models.ChannelStatus) error
-	Delete(id int64) error
+	Create(ctx context.Context, req *models.CreateChannelRequest) (*models.ChatChannel, error)
+	GetByID(ctx context.Context, id int64) (*models.ChatChannel, error)
+	ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.ChatChannel, error)
+	Update(ctx context.Context, id int64, req *models.UpdateChannelRequest) error
+	UpdateStatus(ctx context.Context, id int64, status models.ChannelStatus) error
+	Delete(ctx context.Context, id int64) error
 }
 
 type channelService struct {
@@ -28,62 +29,63 @@ func NewChannelService(repo repositories.ChannelRepository, emitter events.Emitt
 	}
 }
 
-func (s *channelService) Create(req *models.CreateChannelRequest) (*models.ChatChannel, error) {
+func (s *channelService) Create(ctx context.Context, req *models.CreateChannelRequest) (*models.ChatChannel, error) {
 	channel, err := s.repo.Create(req)
 	if err != nil {
 		return nil, err
 	}
 
-	go s.emitter.Emit(events.EventChannelCreated, map[string]interface{}{
-		"channel_id":      channel.ID,
-		"organization_id": channel.OrganizationID,
-		"platform":        channel.Platform,
-		"name":            channel.Name,
+	go events.Publish(ctx, s.emitter, events.ChannelCreatedPayload{
+		ChannelID:      channel.ID,
+		OrganizationID: channel.OrganizationID,
+		Platform:       string(channel.Platform),
+		Name:           channel.Name,
 	})
 
 	return channel, nil
 }
 
-func (s *channelService) GetByID(id int64) (*models.ChatChannel, error) {
+func (s *channelService) GetByID(ctx context.Context, id int64) (*models.ChatChannel, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *channelService) ListByOrganization(orgID int64, limit, offset int) ([]*models.ChatChannel, error) {
+func (s *channelService) ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.ChatChannel, error) {
 	return s.repo.ListByOrganization(orgID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
 }
 
-func (s *channelService) Update(id int64, req *models.UpdateChannelRequest) error {
+func (s *channelService) Update(ctx context.Context, id int64, req *models.UpdateChannelRequest) error {
 	if err := s.repo.Update(id, req); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventChannelUpdated, map[string]interface{}{
-		"channel_id": id,
+	go events.Publish(ctx, s.emitter, events.ChannelUpdatedPayload{
+		ChannelID: id,
 	})
 
 	return nil
 }
 
-func (s *channelService) UpdateStatus(id int64, status models.ChannelStatus) error {
+func (s *channelService) UpdateStatus(ctx context.Context, id int64, status models.ChannelStatus) error {
 	if err := s.repo.UpdateStatus(id, status); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventChannelUpdated, map[string]interface{}{
-		"channel_id": id,
-		"status":     status,
+	statusStr := string(status)
+	go events.Publish(ctx, s.emitter, events.ChannelUpdatedPayload{
+		ChannelID: id,
+		Status:    &statusStr,
 	})
 
 	return nil
 }
 
-func (s *channelService) Delete(id int64) error {
+func (s *channelService) Delete(ctx context.Context, id int64) error {
 	if err := s.repo.Delete(id); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventChannelDeleted, map[string]interface{}{
-		"channel_id": id,
+	go events.Publish(ctx, s.emitter, events.ChannelDeletedPayload{
+		ChannelID: id,
 	})
 
 	return nil
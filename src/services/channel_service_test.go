@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ func TestChannelService_Create(t *testing.T) {
 		AccountIdentifier: "+1234567890",
 	}
 
-	channel, err := service.Create(req)
+	channel, err := service.Create(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, "Test Channel", channel.Name)
 	assert.Equal(t, models.PlatformWhatsApp, channel.Platform)
@@ -43,7 +44,7 @@ func TestChannelService_Create_RepoError(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewChannelService(repo, emitter)
 
-	_, err := service.Create(&models.CreateChannelRequest{
+	_, err := service.Create(context.Background(), &models.CreateChannelRequest{
 		OrganizationID:    1,
 		Platform:          models.PlatformWhatsApp,
 		Name:              "Test Channel",
@@ -66,7 +67,7 @@ func TestChannelService_GetByID(t *testing.T) {
 		AccountIdentifier: "@testbot",
 	})
 
-	channel, err := service.GetByID(created.ID)
+	channel, err := service.GetByID(context.Background(), created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Telegram Channel", channel.Name)
 }
@@ -76,7 +77,7 @@ func TestChannelService_GetByID_NotFound(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewChannelService(repo, emitter)
 
-	channel, err := service.GetByID(999)
+	channel, err := service.GetByID(context.Background(), 999)
 	require.NoError(t, err)
 	assert.Nil(t, channel)
 }
@@ -106,7 +107,7 @@ func TestChannelService_ListByOrganization(t *testing.T) {
 		AccountIdentifier: "@insta",
 	})
 
-	channels, err := service.ListByOrganization(1, 10, 0)
+	channels, err := service.ListByOrganization(context.Background(), 1, 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, channels, 2)
 }
@@ -124,12 +125,12 @@ func TestChannelService_ListByOrganization_DefaultLimit(t *testing.T) {
 	})
 
 	// Test with invalid limit (should default to 20)
-	channels, err := service.ListByOrganization(1, 0, 0)
+	channels, err := service.ListByOrganization(context.Background(), 1, 0, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, channels)
 
 	// Test with limit > 100 (should default to 20)
-	channels, err = service.ListByOrganization(1, 200, 0)
+	channels, err = service.ListByOrganization(context.Background(), 1, 200, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, channels)
 }
@@ -148,7 +149,7 @@ func TestChannelService_Update(t *testing.T) {
 	})
 
 	newName := "Updated Name"
-	err := service.Update(created.ID, &models.UpdateChannelRequest{
+	err := service.Update(context.Background(), created.ID, &models.UpdateChannelRequest{
 		Name: &newName,
 	})
 	require.NoError(t, err)
@@ -170,7 +171,7 @@ func TestChannelService_Update_RepoError(t *testing.T) {
 	service := NewChannelService(repo, emitter)
 
 	newName := "Updated Name"
-	err := service.Update(1, &models.UpdateChannelRequest{
+	err := service.Update(context.Background(), 1, &models.UpdateChannelRequest{
 		Name: &newName,
 	})
 	assert.Error(t, err)
@@ -189,7 +190,7 @@ func TestChannelService_UpdateStatus(t *testing.T) {
 		AccountIdentifier: "+1111111111",
 	})
 
-	err := service.UpdateStatus(created.ID, models.ChannelStatusActive)
+	err := service.UpdateStatus(context.Background(), created.ID, models.ChannelStatusActive)
 	require.NoError(t, err)
 
 	// Verify status update
@@ -208,7 +209,7 @@ func TestChannelService_UpdateStatus_RepoError(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewChannelService(repo, emitter)
 
-	err := service.UpdateStatus(1, models.ChannelStatusActive)
+	err := service.UpdateStatus(context.Background(), 1, models.ChannelStatusActive)
 	assert.Error(t, err)
 }
 
@@ -225,7 +226,7 @@ func TestChannelService_Delete(t *testing.T) {
 		AccountIdentifier: "+1111111111",
 	})
 
-	err := service.Delete(created.ID)
+	err := service.Delete(context.Background(), created.ID)
 	require.NoError(t, err)
 
 	// Verify deletion
@@ -244,7 +245,7 @@ func TestChannelService_Delete_RepoError(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewChannelService(repo, emitter)
 
-	err := service.Delete(1)
+	err := service.Delete(context.Background(), 1)
 	assert.Error(t, err)
 	assert.Equal(t, "delete failed", err.Error())
 }
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CommandService executes commands received over the command bus using the
+// same service methods and validation as the HTTP handlers
+type CommandService interface {
+	Execute(ctx context.Context, cmd *models.Command) (*events.CommandResultPayload, error)
+}
+
+type commandService struct {
+	repo                repositories.CommandRepository
+	messageService      MessageService
+	conversationService ConversationService
+	externalUserService ExternalUserService
+	emitter             events.Emitter
+	validator           *validator.Validate
+	claimTimeout        time.Duration
+}
+
+func NewCommandService(
+	repo repositories.CommandRepository,
+	messageService MessageService,
+	conversationService ConversationService,
+	externalUserService ExternalUserService,
+	emitter events.Emitter,
+	claimTimeout time.Duration,
+) CommandService {
+	return &commandService{
+		repo:                repo,
+		messageService:      messageService,
+		conversationService: conversationService,
+		externalUserService: externalUserService,
+		emitter:             emitter,
+		validator:           validator.New(),
+		claimTimeout:        claimTimeout,
+	}
+}
+
+// Execute runs a command once per command ID and publishes its result.
+// Redelivered commands are not executed again; the stored result is
+// published instead. A command still pending after claimTimeout was
+// abandoned by a consumer that died executing it, and is executed again.
+// The returned error is only set for infrastructure failures and for
+// commands still being executed; command failures are reported in the
+// result.
+func (s *commandService) Execute(ctx context.Context, cmd *models.Command) (*events.CommandResultPayload, error) {
+	correlationID := cmd.CorrelationID
+	if correlationID == "" {
+		correlationID = cmd.ID
+	}
+	ctx = events.WithTrace(ctx, events.Trace{
+		CorrelationID: correlationID,
+		CausationID:   cmd.ID,
+	})
+
+	if err := s.validator.Struct(cmd); err != nil {
+		result := failedResult(cmd, err)
+		go events.Publish(ctx, s.emitter, *result)
+		return result, nil
+	}
+
+	now := time.Now()
+	claimed, err := s.repo.Claim(cmd.ID, cmd.Name, now, now.Add(-s.claimTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return s.replay(ctx, cmd)
+	}
+
+	output, execErr := s.dispatch(ctx, cmd)
+
+	result := &events.CommandResultPayload{
+		CommandID: cmd.ID,
+		Name:      string(cmd.Name),
+		Status:    string(models.CommandStatusSucceeded),
+		Result:    output,
+	}
+	var stored, errorMsg *string
+	if execErr != nil {
+		result = failedResult(cmd, execErr)
+		errorMsg = result.Error
+	} else if output != nil {
+		data, _ := json.Marshal(output)
+		str := string(data)
+		stored = &str
+	}
+
+	if err := s.repo.Complete(cmd.ID, models.CommandStatus(result.Status), stored, errorMsg); err != nil {
+		return nil, err
+	}
+
+	go events.Publish(ctx, s.emitter, *result)
+	return result, nil
+}
+
+// replay republishes the outcome of a command that was already processed
+func (s *commandService) replay(ctx context.Context, cmd *models.Command) (*events.CommandResultPayload, error) {
+	processed, err := s.repo.GetByID(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if processed.Status == models.CommandStatusPending {
+		return nil, fmt.Errorf("command %s is still being processed", cmd.ID)
+	}
+
+	result := &events.CommandResultPayload{
+		CommandID: processed.CommandID,
+		Name:      string(processed.Name),
+		Status:    string(processed.Status),
+		Error:     processed.Error,
+	}
+	if processed.Result != nil {
+		_ = json.Unmarshal([]byte(*processed.Result), &result.Result)
+	}
+
+	go events.Publish(ctx, s.emitter, *result)
+	return result, nil
+}
+
+func (s *commandService) dispatch(ctx context.Context, cmd *models.Command) (map[string]interface{}, error) {
+	switch cmd.Name {
+	case models.CommandSendMessage:
+		var req models.SendMessageCommand
+		if err := s.decode(cmd, &req); err != nil {
+			return nil, err
+		}
+		msg, err := s.messageService.SendOutgoingMessage(ctx, &SendOutgoingMessageRequest{
+			ConversationID: req.ConversationID,
+			Content:        req.Content,
+			MessageType:    req.MessageType,
+			MediaURL:       req.MediaURL,
+			Metadata:       req.Metadata,
+			ReplyTo:        req.ReplyTo,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message_id": msg.ID}, nil
+
+	case models.CommandAssignConversation:
+		var req models.AssignConversationCommand
+		if err := s.decode(cmd, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.conversationService.Assign(ctx, req.ConversationID, &req.AssignConversationRequest)
+
+	case models.CommandUpdateStatus:
+		var req models.UpdateStatusCommand
+		if err := s.decode(cmd, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.conversationService.UpdateStatus(ctx, req.ConversationID, &req.UpdateConversationStatusRequest)
+
+	case models.CommandBlockUser, models.CommandUnblockUser:
+		var req models.BlockUserCommand
+		if err := s.decode(cmd, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.externalUserService.SetBlocked(ctx, req.ExternalUserID, cmd.Name == models.CommandBlockUser)
+	}
+
+	return nil, fmt.Errorf("unknown command: %s", cmd.Name)
+}
+
+// decode unmarshals and validates a command payload
+func (s *commandService) decode(cmd *models.Command, dst interface{}) error {
+	if err := json.Unmarshal(cmd.Payload, dst); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return s.validator.Struct(dst)
+}
+
+func failedResult(cmd *models.Command, err error) *events.CommandResultPayload {
+	msg := err.Error()
+	return &events.CommandResultPayload{
+		CommandID: cmd.ID,
+		Name:      string(cmd.Name),
+		Status:    string(models.CommandStatusFailed),
+		Error:     &msg,
+	}
+}
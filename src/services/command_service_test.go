@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commandServiceFixture struct {
+	service  CommandService
+	cmdRepo  *testutils.MockCommandRepository
+	msgRepo  *testutils.MockMessageRepository
+	convRepo *testutils.MockConversationRepository
+	userRepo *testutils.MockExternalUserRepository
+	emitter  *testutils.MockEmitter
+}
+
+func newCommandServiceFixture() *commandServiceFixture {
+	f := &commandServiceFixture{
+		cmdRepo:  testutils.NewMockCommandRepository(),
+		msgRepo:  testutils.NewMockMessageRepository(),
+		convRepo: testutils.NewMockConversationRepository(),
+		userRepo: testutils.NewMockExternalUserRepository(),
+		emitter:  testutils.NewMockEmitter(),
+	}
+	f.service = NewCommandService(
+		f.cmdRepo,
+		NewMessageService(f.msgRepo, f.convRepo, f.userRepo, f.emitter, nil, nil, nil, nil, nil, nil),
+		NewConversationService(f.convRepo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, f.emitter),
+		NewExternalUserService(f.userRepo, f.emitter),
+		f.emitter,
+		time.Minute,
+	)
+	return f
+}
+
+func newCommand(id string, name models.CommandName, payload interface{}) *models.Command {
+	data, _ := json.Marshal(payload)
+	return &models.Command{ID: id, Name: name, Payload: data}
+}
+
+func TestCommandService_SendMessage(t *testing.T) {
+	f := newCommandServiceFixture()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+
+	result, err := f.service.Execute(context.Background(), newCommand("cmd-1", models.CommandSendMessage, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"content":         "Hello from NestJS",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", result.Status)
+	assert.Equal(t, int64(1), result.Result["message_id"])
+
+	require.Len(t, f.msgRepo.Messages, 1)
+	assert.Equal(t, models.MessageTypeText, f.msgRepo.Messages[1].MessageType)
+	assert.Equal(t, models.CommandStatusSucceeded, f.cmdRepo.Commands["cmd-1"].Status)
+
+	time.Sleep(10 * time.Millisecond)
+	var types []string
+	for _, e := range f.emitter.EmittedEvents {
+		types = append(types, e.EventType)
+	}
+	assert.Contains(t, types, events.EventCommandResult)
+}
+
+func TestCommandService_Idempotent(t *testing.T) {
+	f := newCommandServiceFixture()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	cmd := newCommand("cmd-dup", models.CommandSendMessage, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"content":         "Only once",
+	})
+
+	first, err := f.service.Execute(context.Background(), cmd)
+	require.NoError(t, err)
+	second, err := f.service.Execute(context.Background(), cmd)
+	require.NoError(t, err)
+
+	assert.Len(t, f.msgRepo.Messages, 1)
+	assert.Equal(t, first.Status, second.Status)
+	assert.Equal(t, float64(1), second.Result["message_id"])
+}
+
+func TestCommandService_AbandonedClaim(t *testing.T) {
+	f := newCommandServiceFixture()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	cmd := newCommand("cmd-crash", models.CommandSendMessage, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"content":         "After the crash",
+	})
+
+	// A consumer claimed the command and died before completing it
+	_, err := f.cmdRepo.Claim(cmd.ID, cmd.Name, time.Now(), time.Now())
+	require.NoError(t, err)
+
+	_, err = f.service.Execute(context.Background(), cmd)
+	assert.Error(t, err, "a fresh claim is still being processed")
+	assert.Empty(t, f.msgRepo.Messages)
+
+	f.cmdRepo.Commands[cmd.ID].ClaimedAt = time.Now().Add(-2 * time.Minute)
+	result, err := f.service.Execute(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, string(models.CommandStatusSucceeded), result.Status)
+	assert.Len(t, f.msgRepo.Messages, 1)
+	assert.Equal(t, models.CommandStatusSucceeded, f.cmdRepo.Commands[cmd.ID].Status)
+}
+
+func TestCommandService_Correlation(t *testing.T) {
+	f := newCommandServiceFixture()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+
+	cmd := newCommand("cmd-c", models.CommandAssignConversation, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"assignee_id":     "agent-1",
+	})
+	cmd.CorrelationID = "req-42"
+	_, err := f.service.Execute(context.Background(), cmd)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	require.NotEmpty(t, f.emitter.EmittedEvents)
+	for _, e := range f.emitter.EmittedEvents {
+		assert.Equal(t, "req-42", e.Metadata[events.MetadataCorrelationID], e.EventType)
+		assert.Equal(t, "cmd-c", e.Metadata[events.MetadataCausationID], e.EventType)
+	}
+}
+
+func TestCommandService_AssignAndUpdateStatus(t *testing.T) {
+	f := newCommandServiceFixture()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+
+	result, err := f.service.Execute(context.Background(), newCommand("cmd-a", models.CommandAssignConversation, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"assignee_id":     "agent-7",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", result.Status)
+	assert.Equal(t, "agent-7", *conv.AssignedToExternalID)
+
+	result, err = f.service.Execute(context.Background(), newCommand("cmd-s", models.CommandUpdateStatus, map[string]interface{}{
+		"conversation_id": conv.ID,
+		"status":          "pending",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", result.Status)
+	assert.Equal(t, models.ConversationStatusPending, conv.Status)
+}
+
+func TestCommandService_BlockUser(t *testing.T) {
+	f := newCommandServiceFixture()
+	user, _ := f.userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "u-1"})
+
+	result, err := f.service.Execute(context.Background(), newCommand("cmd-b", models.CommandBlockUser, map[string]interface{}{
+		"external_user_id": user.ID,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", result.Status)
+	assert.True(t, user.IsBlocked)
+
+	_, err = f.service.Execute(context.Background(), newCommand("cmd-u", models.CommandUnblockUser, map[string]interface{}{
+		"external_user_id": user.ID,
+	}))
+	require.NoError(t, err)
+	assert.False(t, user.IsBlocked)
+}
+
+func TestCommandService_ValidationFailure(t *testing.T) {
+	f := newCommandServiceFixture()
+
+	t.Run("invalid payload is reported as failed", func(t *testing.T) {
+		result, err := f.service.Execute(context.Background(), newCommand("cmd-v", models.CommandAssignConversation, map[string]interface{}{
+			"conversation_id": 1,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "failed", result.Status)
+		assert.Contains(t, *result.Error, "AssigneeID")
+		assert.Equal(t, models.CommandStatusFailed, f.cmdRepo.Commands["cmd-v"].Status)
+	})
+
+	t.Run("unknown command name is rejected before claiming", func(t *testing.T) {
+		result, err := f.service.Execute(context.Background(), newCommand("cmd-x", "delete_everything", map[string]interface{}{}))
+		require.NoError(t, err)
+		assert.Equal(t, "failed", result.Status)
+		assert.NotContains(t, f.cmdRepo.Commands, "cmd-x")
+	})
+}
+
+func TestCommandService_RepoError(t *testing.T) {
+	f := newCommandServiceFixture()
+	f.cmdRepo.ClaimError = errors.New("database error")
+
+	_, err := f.service.Execute(context.Background(), newCommand("cmd-e", models.CommandBlockUser, map[string]interface{}{
+		"external_user_id": 1,
+	}))
+	assert.Error(t, err)
+}
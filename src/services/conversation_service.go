@@ -1,81 +1,431 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+	"strconv"
+	"time"
 )
 
 type ConversationService interface {
-	GetByID(id int64) (*models.Conversation, error)
-	ListByChannel(channelID int64, status *models.ConversationStatus, limit, offset int) ([]*models.Conversation, error)
-	Assign(conversationID int64, assigneeID string) error
-	UpdateStatus(conversationID int64, status models.ConversationStatus) error
-	UpdatePriority(conversationID int64, priority models.ConversationPriority) error
+	GetByID(ctx context.Context, id int64) (*models.Conversation, error)
+	ListByChannel(ctx context.Context, channelID int64, status *models.ConversationStatus, tags []string, attrs *models.AttributeQuery, limit, offset int) ([]*models.Conversation, error)
+	Inbox(ctx context.Context, q *models.InboxQuery) (*models.InboxPage, error)
+	// Assign assigns a conversation to an agent, a team, or an agent within
+	// a team. Team changes are recorded in the conversation's history. A
+	// team that is not one of the organization's is models.ErrUnknownTeam.
+	Assign(ctx context.Context, conversationID int64, req *models.AssignConversationRequest) error
+	// UpdateStatus moves a conversation to another status, returning
+	// models.ErrInvalidTransition if the state machine does not allow it.
+	// Setting the current status again is a no-op, except that snoozing a
+	// snoozed conversation changes when it wakes up. Resolving sends the
+	// channel's satisfaction survey.
+	UpdateStatus(ctx context.Context, conversationID int64, req *models.UpdateConversationStatusRequest) error
+	UpdatePriority(ctx context.Context, conversationID int64, priority models.ConversationPriority) error
+	UpdateSubject(ctx context.Context, conversationID int64, subject string) error
+	// Timeline lists the conversation's change history, merged with its
+	// messages when includeMessages is set
+	Timeline(ctx context.Context, conversationID int64, includeMessages bool, limit, offset int) ([]*models.TimelineEntry, error)
+	// Merge moves a conversation's messages, tags and history into another
+	// conversation of the same customer and closes it with a pointer to the
+	// target. It returns models.ErrInvalidMerge if the two can't be merged.
+	Merge(ctx context.Context, conversationID int64, req *models.MergeConversationRequest) (*models.Conversation, error)
+	// Split moves a range of a conversation's messages into a new
+	// conversation and returns it. Since a customer has one open
+	// conversation at a time, the new one is resolved while the one being
+	// split is active. Otherwise it is opened, and models.ErrInvalidMerge
+	// is returned if the customer already has another open conversation.
+	Split(ctx context.Context, conversationID int64, req *models.SplitConversationRequest) (*models.Conversation, error)
 }
 
 type conversationService struct {
-	repo    repositories.ConversationRepository
-	emitter events.Emitter
+	repo        repositories.ConversationRepository
+	channelRepo repositories.ChannelRepository
+	teamRepo    repositories.TeamRepository
+	historyRepo repositories.ConversationEventRepository
+	sla         SLAService
+	csat        CSATService
+	emitter     events.Emitter
 }
 
-func NewConversationService(repo repositories.ConversationRepository, emitter events.Emitter) ConversationService {
+func NewConversationService(
+	repo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	teamRepo repositories.TeamRepository,
+	historyRepo repositories.ConversationEventRepository,
+	sla SLAService,
+	csat CSATService,
+	emitter events.Emitter,
+) ConversationService {
 	return &conversationService{
-		repo:    repo,
-		emitter: emitter,
+		repo:        repo,
+		channelRepo: channelRepo,
+		teamRepo:    teamRepo,
+		historyRepo: historyRepo,
+		sla:         sla,
+		csat:        csat,
+		emitter:     emitter,
 	}
 }
 
-func (s *conversationService) GetByID(id int64) (*models.Conversation, error) {
+func (s *conversationService) GetByID(ctx context.Context, id int64) (*models.Conversation, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *conversationService) ListByChannel(channelID int64, status *models.ConversationStatus, limit, offset int) ([]*models.Conversation, error) {
-	return s.repo.List(channelID, status, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+func (s *conversationService) ListByChannel(ctx context.Context, channelID int64, status *models.ConversationStatus, tags []string, attrs *models.AttributeQuery, limit, offset int) ([]*models.Conversation, error) {
+	return s.repo.List(channelID, status, tags, attrs, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
 }
 
-func (s *conversationService) Assign(conversationID int64, assigneeID string) error {
+// Inbox lists conversations across every channel of an organization
+func (s *conversationService) Inbox(ctx context.Context, q *models.InboxQuery) (*models.InboxPage, error) {
+	q.Limit = utils.NormalizeLimit(q.Limit)
+	return s.repo.ListInbox(q)
+}
+
+func (s *conversationService) Assign(ctx context.Context, conversationID int64, req *models.AssignConversationRequest) error {
+	conv, err := s.repo.GetByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conv == nil {
+		return fmt.Errorf("conversation not found")
+	}
+	previousTeam, previousAssignee := conv.TeamID, conv.AssignedToExternalID
+
+	update := &models.UpdateConversationRequest{
+		AssignedToExternalID: &req.AssigneeID,
+	}
+	if req.TeamID != nil {
+		channel, err := s.channelRepo.GetByID(conv.ChannelID)
+		if err != nil {
+			return err
+		}
+		if channel == nil {
+			return fmt.Errorf("channel not found")
+		}
+		if err := checkTeam(s.teamRepo, channel.OrganizationID, req.TeamID); err != nil {
+			return err
+		}
+		team, err := s.teamRepo.GetByID(*req.TeamID)
+		if err != nil {
+			return err
+		}
+		if req.AssigneeID != "" {
+			member, err := s.teamRepo.IsMember(team.ID, req.AssigneeID)
+			if err != nil {
+				return err
+			}
+			if !member {
+				return fmt.Errorf("assignee is not a member of team %s", team.Name)
+			}
+		}
+		update.TeamID = req.TeamID
+	}
+
+	if err := s.repo.Update(conversationID, update); err != nil {
+		return err
+	}
+
+	if req.TeamID != nil && !sameTeam(previousTeam, req.TeamID) {
+		recordTeamChange(ctx, s.historyRepo, conversationID, previousTeam, req.TeamID, models.AssignReasonManual)
+	}
+	if assignee := optional(req.AssigneeID); !sameValue(previousAssignee, assignee) {
+		recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventAssigneeChanged, previousAssignee, assignee, models.AssignReasonManual)
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationAssignedPayload{
+		ConversationID:     conversationID,
+		AssigneeID:         req.AssigneeID,
+		TeamID:             req.TeamID,
+		PreviousAssigneeID: previousAssignee,
+		Reason:             models.AssignReasonManual,
+	})
+
+	return nil
+}
+
+func (s *conversationService) UpdateStatus(ctx context.Context, conversationID int64, req *models.UpdateConversationStatusRequest) error {
+	conv, err := s.get(conversationID)
+	if err != nil {
+		return err
+	}
+	if req.SnoozedUntil != nil {
+		if req.Status != models.ConversationStatusSnoozed {
+			return fmt.Errorf("%w: snoozed_until requires status snoozed", models.ErrInvalidSnooze)
+		}
+		if !req.SnoozedUntil.After(time.Now()) {
+			return fmt.Errorf("%w: snoozed_until must be in the future", models.ErrInvalidSnooze)
+		}
+	}
+
+	// A snoozed conversation may be snoozed again to change its wake-up time
+	previous := conv.Status
+	if previous == req.Status &&
+		(previous != models.ConversationStatusSnoozed || sameTime(conv.SnoozedUntil, req.SnoozedUntil)) {
+		return nil
+	}
+	if previous != req.Status && !previous.CanTransitionTo(req.Status) {
+		return fmt.Errorf("%w: %s to %s", models.ErrInvalidTransition, previous, req.Status)
+	}
+
 	if err := s.repo.Update(conversationID, &models.UpdateConversationRequest{
-		AssignedToExternalID: &assigneeID,
+		Status:       &req.Status,
+		SnoozedUntil: req.SnoozedUntil,
 	}); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit("conversation.assigned", map[string]interface{}{
-		"conversation_id": conversationID,
-		"assignee_id":     assigneeID,
+	reason := ""
+	if req.Reason != nil {
+		reason = *req.Reason
+	}
+	previousStr, statusStr := string(previous), string(req.Status)
+	if previous != req.Status {
+		recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventStatusChanged, &previousStr, &statusStr, reason)
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+		ConversationID: conversationID,
+		Status:         &statusStr,
+		PreviousStatus: &previousStr,
+		Reason:         req.Reason,
+		SnoozedUntil:   req.SnoozedUntil,
 	})
 
+	if req.Status == models.ConversationStatusResolved && previous != req.Status && s.csat != nil {
+		conv.Status = req.Status
+		if err := s.csat.Send(ctx, conv); err != nil {
+
+			fmt.Printf("Warning: failed to send CSAT survey: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
-func (s *conversationService) UpdateStatus(conversationID int64, status models.ConversationStatus) error {
+func (s *conversationService) UpdatePriority(ctx context.Context, conversationID int64, priority models.ConversationPriority) error {
+	conv, err := s.get(conversationID)
+	if err != nil {
+		return err
+	}
+	previous := conv.Priority
+	if previous == priority {
+		return nil
+	}
+
 	if err := s.repo.Update(conversationID, &models.UpdateConversationRequest{
-		Status: &status,
+		Priority: &priority,
 	}); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventConversationUpdated, map[string]interface{}{
-		"conversation_id": conversationID,
-		"status":          status,
+	previousStr, priorityStr := string(previous), string(priority)
+	recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventPriorityChanged, &previousStr, &priorityStr, "")
+
+	// The priority may select a different SLA policy
+	if s.sla != nil {
+		conv, err := s.repo.GetByID(conversationID)
+		if err == nil && conv != nil {
+			err = s.sla.Apply(ctx, conv)
+		}
+		if err != nil {
+
+			fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+		}
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+		ConversationID: conversationID,
+		Priority:       &priorityStr,
 	})
 
 	return nil
 }
 
-func (s *conversationService) UpdatePriority(conversationID int64, priority models.ConversationPriority) error {
+func (s *conversationService) UpdateSubject(ctx context.Context, conversationID int64, subject string) error {
+	conv, err := s.get(conversationID)
+	if err != nil {
+		return err
+	}
+	previous := conv.Subject
+	if sameValue(previous, optional(subject)) {
+		return nil
+	}
+
 	if err := s.repo.Update(conversationID, &models.UpdateConversationRequest{
-		Priority: &priority,
+		Subject: &subject,
 	}); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventConversationUpdated, map[string]interface{}{
-		"conversation_id": conversationID,
-		"priority":        priority,
+	recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventSubjectChanged, previous, optional(subject), "")
+
+	go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+		ConversationID: conversationID,
+		Subject:        &subject,
 	})
 
 	return nil
 }
+
+func (s *conversationService) Timeline(ctx context.Context, conversationID int64, includeMessages bool, limit, offset int) ([]*models.TimelineEntry, error) {
+	return s.historyRepo.ListTimeline(conversationID, includeMessages, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *conversationService) Merge(ctx context.Context, conversationID int64, req *models.MergeConversationRequest) (*models.Conversation, error) {
+	source, err := s.get(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.get(req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case source.ID == target.ID:
+		return nil, fmt.Errorf("%w: conversation cannot be merged into itself", models.ErrInvalidMerge)
+	case source.MergedIntoID != nil:
+		return nil, fmt.Errorf("%w: conversation %d is already merged", models.ErrInvalidMerge, source.ID)
+	case target.MergedIntoID != nil || target.Status == models.ConversationStatusClosed:
+		return nil, fmt.Errorf("%w: conversation %d is closed", models.ErrInvalidMerge, target.ID)
+	case source.ChannelID != target.ChannelID || source.ExternalUserID != target.ExternalUserID:
+		return nil, fmt.Errorf("%w: conversations belong to different customers", models.ErrInvalidMerge)
+	}
+
+	sourceStr, targetStr := strconv.FormatInt(source.ID, 10), strconv.FormatInt(target.ID, 10)
+	moved, err := s.repo.Merge(source.ID, target.ID, []*models.ConversationEvent{
+		changeEvent(ctx, target.ID, models.ConversationEventMerged, &sourceStr, &targetStr, ""),
+		changeEvent(ctx, source.ID, models.ConversationEventMerged, &sourceStr, &targetStr, ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationMergedPayload{
+		ConversationID:       target.ID,
+		SourceConversationID: source.ID,
+		MessagesMoved:        moved,
+	})
+
+	return s.get(target.ID)
+}
+
+func (s *conversationService) Split(ctx context.Context, conversationID int64, req *models.SplitConversationRequest) (*models.Conversation, error) {
+	source, err := s.get(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if source.MergedIntoID != nil {
+		return nil, fmt.Errorf("%w: conversation %d is already merged", models.ErrInvalidMerge, source.ID)
+	}
+
+	conv, moved, err := s.repo.Split(source.ID, req.FromMessageID, req.ToMessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceStr, convStr := strconv.FormatInt(source.ID, 10), strconv.FormatInt(conv.ID, 10)
+	recordChange(ctx, s.historyRepo, source.ID, models.ConversationEventSplit, &sourceStr, &convStr, "")
+	recordChange(ctx, s.historyRepo, conv.ID, models.ConversationEventSplit, &sourceStr, &convStr, "")
+
+	if s.sla != nil && conv.Status == models.ConversationStatusOpen {
+		if err := s.sla.Apply(ctx, conv); err != nil {
+
+			fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+		}
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationSplitPayload{
+		ConversationID:       conv.ID,
+		SourceConversationID: source.ID,
+		FromMessageID:        req.FromMessageID,
+		ToMessageID:          req.ToMessageID,
+		MessagesMoved:        moved,
+	})
+
+	return conv, nil
+}
+
+func (s *conversationService) get(id int64) (*models.Conversation, error) {
+	conv, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+	return conv, nil
+}
+
+func sameTeam(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// recordChange adds an entry to a conversation's history. Failures are
+// logged rather than returned since the change itself has been applied.
+func recordChange(ctx context.Context, repo repositories.ConversationEventRepository, conversationID int64, eventType models.ConversationEventType, from, to *string, reason string) {
+	if err := repo.Create(changeEvent(ctx, conversationID, eventType, from, to, reason)); err != nil {
+
+		fmt.Printf("Warning: failed to record conversation %s: %v\n", eventType, err)
+	}
+}
+
+// changeEvent builds a conversation history entry, attributed to the
+// authenticated caller if there is one
+func changeEvent(ctx context.Context, conversationID int64, eventType models.ConversationEventType, from, to *string, reason string) *models.ConversationEvent {
+	event := &models.ConversationEvent{
+		ConversationID: conversationID,
+		Type:           eventType,
+		FromValue:      from,
+		ToValue:        to,
+	}
+	if reason != "" {
+		event.Reason = &reason
+	}
+	if actor := middleware.UserIDFromContext(ctx); actor != "" {
+		event.ActorID = &actor
+	}
+	return event
+}
+
+func recordTeamChange(ctx context.Context, repo repositories.ConversationEventRepository, conversationID int64, from, to *int64, reason string) {
+	recordChange(ctx, repo, conversationID, models.ConversationEventTeamChanged, teamValue(from), teamValue(to), reason)
+}
+
+// optional treats an empty string as no value
+func optional(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func teamValue(teamID *int64) *string {
+	if teamID == nil || *teamID == 0 {
+		return nil
+	}
+	v := strconv.FormatInt(*teamID, 10)
+	return &v
+}
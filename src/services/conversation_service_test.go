@@ -1,10 +1,13 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
 
@@ -15,7 +18,7 @@ import (
 func TestConversationService_GetByID(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create a conversation first
 	created, _ := repo.Create(&models.CreateConversationRequest{
@@ -24,7 +27,7 @@ func TestConversationService_GetByID(t *testing.T) {
 		Priority:       models.PriorityNormal,
 	})
 
-	conv, err := service.GetByID(created.ID)
+	conv, err := service.GetByID(context.Background(), created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, created.ID, conv.ID)
 	assert.Equal(t, int64(1), conv.ChannelID)
@@ -33,9 +36,9 @@ func TestConversationService_GetByID(t *testing.T) {
 func TestConversationService_GetByID_NotFound(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
-	conv, err := service.GetByID(999)
+	conv, err := service.GetByID(context.Background(), 999)
 	require.NoError(t, err)
 	assert.Nil(t, conv)
 }
@@ -44,16 +47,16 @@ func TestConversationService_GetByID_RepoError(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	repo.GetError = errors.New("database error")
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
-	_, err := service.GetByID(1)
+	_, err := service.GetByID(context.Background(), 1)
 	assert.Error(t, err)
 }
 
 func TestConversationService_ListByChannel(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create conversations
 	repo.Create(&models.CreateConversationRequest{
@@ -72,7 +75,7 @@ func TestConversationService_ListByChannel(t *testing.T) {
 		Priority:       models.PriorityLow,
 	})
 
-	convs, err := service.ListByChannel(1, nil, 10, 0)
+	convs, err := service.ListByChannel(context.Background(), 1, nil, nil, nil, 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, convs, 2)
 }
@@ -80,7 +83,7 @@ func TestConversationService_ListByChannel(t *testing.T) {
 func TestConversationService_ListByChannel_WithStatus(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create conversations (all default to Open status)
 	repo.Create(&models.CreateConversationRequest{
@@ -90,7 +93,7 @@ func TestConversationService_ListByChannel_WithStatus(t *testing.T) {
 	})
 
 	status := models.ConversationStatusOpen
-	convs, err := service.ListByChannel(1, &status, 10, 0)
+	convs, err := service.ListByChannel(context.Background(), 1, &status, nil, nil, 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, convs, 1)
 }
@@ -98,7 +101,7 @@ func TestConversationService_ListByChannel_WithStatus(t *testing.T) {
 func TestConversationService_ListByChannel_DefaultLimit(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	repo.Create(&models.CreateConversationRequest{
 		ChannelID:      1,
@@ -107,20 +110,31 @@ func TestConversationService_ListByChannel_DefaultLimit(t *testing.T) {
 	})
 
 	// Test with invalid limit (should default to 20)
-	convs, err := service.ListByChannel(1, nil, 0, 0)
+	convs, err := service.ListByChannel(context.Background(), 1, nil, nil, nil, 0, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, convs)
 
 	// Test with limit > 100 (should default to 20)
-	convs, err = service.ListByChannel(1, nil, 200, 0)
+	convs, err = service.ListByChannel(context.Background(), 1, nil, nil, nil, 200, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, convs)
 }
 
+func TestConversationService_Inbox_DefaultLimit(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
+
+	page, err := service.Inbox(context.Background(), &models.InboxQuery{OrganizationID: 1, Limit: 500})
+	require.NoError(t, err)
+	assert.NotNil(t, page)
+	assert.Equal(t, 20, repo.LastInboxQuery.Limit)
+}
+
 func TestConversationService_Assign(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create a conversation first
 	created, _ := repo.Create(&models.CreateConversationRequest{
@@ -129,7 +143,7 @@ func TestConversationService_Assign(t *testing.T) {
 		Priority:       models.PriorityNormal,
 	})
 
-	err := service.Assign(created.ID, "agent-123")
+	err := service.Assign(context.Background(), created.ID, &models.AssignConversationRequest{AssigneeID: "agent-123"})
 	require.NoError(t, err)
 
 	// Verify assignment
@@ -140,7 +154,7 @@ func TestConversationService_Assign(t *testing.T) {
 	// Wait for async event emission
 	time.Sleep(10 * time.Millisecond)
 	assert.Len(t, emitter.EmittedEvents, 1)
-	assert.Equal(t, "conversation.assigned", emitter.EmittedEvents[0].EventType)
+	assert.Equal(t, "chat.conversation.assigned", emitter.EmittedEvents[0].EventType)
 	assert.Equal(t, "agent-123", emitter.EmittedEvents[0].Payload["assignee_id"])
 }
 
@@ -148,16 +162,101 @@ func TestConversationService_Assign_RepoError(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	repo.UpdateError = errors.New("update failed")
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
+
+	created, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
 
-	err := service.Assign(1, "agent-123")
+	err := service.Assign(context.Background(), created.ID, &models.AssignConversationRequest{AssigneeID: "agent-123"})
 	assert.Error(t, err)
 }
 
+func TestConversationService_AssignTeam(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	teamRepo := testutils.NewMockTeamRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	channelRepo := testutils.NewMockChannelRepository()
+	service := NewConversationService(repo, channelRepo, teamRepo, historyRepo, nil, nil, emitter)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	created, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	billing, _ := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 1, Name: "billing", MemberIDs: []string{"agent-1"}})
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "supervisor-1")
+
+	t.Run("rejects agents outside the team", func(t *testing.T) {
+		err := service.Assign(ctx, created.ID, &models.AssignConversationRequest{AssigneeID: "agent-2", TeamID: &billing.ID})
+		assert.Error(t, err)
+		assert.Nil(t, repo.Conversations[created.ID].TeamID)
+	})
+
+	t.Run("assigns team and member", func(t *testing.T) {
+		err := service.Assign(ctx, created.ID, &models.AssignConversationRequest{AssigneeID: "agent-1", TeamID: &billing.ID})
+		require.NoError(t, err)
+
+		conv := repo.Conversations[created.ID]
+		require.NotNil(t, conv.TeamID)
+		assert.Equal(t, billing.ID, *conv.TeamID)
+		assert.Equal(t, "agent-1", *conv.AssignedToExternalID)
+
+		require.Len(t, historyRepo.Events, 2)
+		event := historyRepo.Events[0]
+		assert.Equal(t, models.ConversationEventTeamChanged, event.Type)
+		assert.Nil(t, event.FromValue)
+		assert.Equal(t, "1", *event.ToValue)
+		assert.Equal(t, "supervisor-1", *event.ActorID)
+
+		event = historyRepo.Events[1]
+		assert.Equal(t, models.ConversationEventAssigneeChanged, event.Type)
+		assert.Nil(t, event.FromValue)
+		assert.Equal(t, "agent-1", *event.ToValue)
+		assert.Equal(t, models.AssignReasonManual, *event.Reason)
+	})
+
+	t.Run("queues to a team without an agent", func(t *testing.T) {
+		tier2, _ := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 1, Name: "tier 2"})
+		time.Sleep(10 * time.Millisecond)
+		emitter.EmittedEvents = nil
+
+		err := service.Assign(ctx, created.ID, &models.AssignConversationRequest{TeamID: &tier2.ID})
+		require.NoError(t, err)
+
+		conv := repo.Conversations[created.ID]
+		assert.Equal(t, tier2.ID, *conv.TeamID)
+		assert.Nil(t, conv.AssignedToExternalID)
+
+		require.Len(t, historyRepo.Events, 4)
+		assert.Equal(t, models.ConversationEventTeamChanged, historyRepo.Events[2].Type)
+		assert.Equal(t, "1", *historyRepo.Events[2].FromValue)
+		assert.Equal(t, "2", *historyRepo.Events[2].ToValue)
+		assert.Equal(t, models.ConversationEventAssigneeChanged, historyRepo.Events[3].Type)
+		assert.Equal(t, "agent-1", *historyRepo.Events[3].FromValue)
+		assert.Nil(t, historyRepo.Events[3].ToValue)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 1)
+		payload := emitter.EmittedEvents[0].Payload
+		assert.Equal(t, tier2.ID, payload["team_id"])
+		assert.NotContains(t, payload, "assignee_id")
+		assert.Equal(t, "agent-1", payload["previous_assignee_id"])
+	})
+
+	t.Run("rejects teams of other organizations", func(t *testing.T) {
+		previous := *repo.Conversations[created.ID].TeamID
+		foreign, _ := teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 2, Name: "billing", MemberIDs: []string{"agent-1"}})
+		err := service.Assign(ctx, created.ID, &models.AssignConversationRequest{AssigneeID: "agent-1", TeamID: &foreign.ID})
+		assert.ErrorIs(t, err, models.ErrUnknownTeam)
+
+		missing := int64(99)
+		err = service.Assign(ctx, created.ID, &models.AssignConversationRequest{TeamID: &missing})
+		assert.ErrorIs(t, err, models.ErrUnknownTeam)
+		assert.Equal(t, previous, *repo.Conversations[created.ID].TeamID)
+	})
+}
+
 func TestConversationService_UpdateStatus(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create a conversation first
 	created, _ := repo.Create(&models.CreateConversationRequest{
@@ -166,7 +265,7 @@ func TestConversationService_UpdateStatus(t *testing.T) {
 		Priority:       models.PriorityNormal,
 	})
 
-	err := service.UpdateStatus(created.ID, models.ConversationStatusClosed)
+	err := service.UpdateStatus(context.Background(), created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusClosed})
 	require.NoError(t, err)
 
 	// Verify status update
@@ -182,16 +281,16 @@ func TestConversationService_UpdateStatus_RepoError(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	repo.UpdateError = errors.New("update failed")
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
-	err := service.UpdateStatus(1, models.ConversationStatusClosed)
+	err := service.UpdateStatus(context.Background(), 1, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusClosed})
 	assert.Error(t, err)
 }
 
 func TestConversationService_UpdatePriority(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
 	// Create a conversation first
 	created, _ := repo.Create(&models.CreateConversationRequest{
@@ -200,7 +299,7 @@ func TestConversationService_UpdatePriority(t *testing.T) {
 		Priority:       models.PriorityNormal,
 	})
 
-	err := service.UpdatePriority(created.ID, models.PriorityUrgent)
+	err := service.UpdatePriority(context.Background(), created.ID, models.PriorityUrgent)
 	require.NoError(t, err)
 
 	// Verify priority update
@@ -216,8 +315,219 @@ func TestConversationService_UpdatePriority_RepoError(t *testing.T) {
 	repo := testutils.NewMockConversationRepository()
 	repo.UpdateError = errors.New("update failed")
 	emitter := testutils.NewMockEmitter()
-	service := NewConversationService(repo, emitter)
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, nil, emitter)
 
-	err := service.UpdatePriority(1, models.PriorityHigh)
+	err := service.UpdatePriority(context.Background(), 1, models.PriorityHigh)
 	assert.Error(t, err)
 }
+
+func TestConversationService_StatusTransitions(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), historyRepo, nil, nil, emitter)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	created, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	reason := "customer confirmed the fix"
+
+	require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusResolved, Reason: &reason}))
+	assert.NotNil(t, repo.Conversations[created.ID].ResolvedAt)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, emitter.EmittedEvents, 1)
+	assert.Equal(t, "resolved", emitter.EmittedEvents[0].Payload["status"])
+	assert.Equal(t, "open", emitter.EmittedEvents[0].Payload["previous_status"])
+
+	require.Len(t, historyRepo.Events, 1)
+	event := historyRepo.Events[0]
+	assert.Equal(t, models.ConversationEventStatusChanged, event.Type)
+	assert.Equal(t, "open", *event.FromValue)
+	assert.Equal(t, "resolved", *event.ToValue)
+	assert.Equal(t, reason, *event.Reason)
+	assert.Equal(t, "agent-1", *event.ActorID)
+
+	t.Run("same status is a no-op", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusResolved}))
+		assert.Len(t, historyRepo.Events, 1)
+	})
+
+	t.Run("reopen clears resolved_at", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusOpen}))
+		assert.Nil(t, repo.Conversations[created.ID].ResolvedAt)
+	})
+
+	t.Run("closed is final", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusClosed}))
+
+		err := service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusOpen})
+		assert.ErrorIs(t, err, models.ErrInvalidTransition)
+		assert.Equal(t, models.ConversationStatusClosed, repo.Conversations[created.ID].Status)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, emitter.EmittedEvents, 3)
+}
+
+func TestConversationService_PriorityAndSubjectHistory(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), historyRepo, nil, nil, testutils.NewMockEmitter())
+	ctx := context.Background()
+
+	created, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1, Priority: models.PriorityNormal})
+
+	require.NoError(t, service.UpdatePriority(ctx, created.ID, models.PriorityHigh))
+	require.NoError(t, service.UpdateSubject(ctx, created.ID, "Refund request"))
+	require.NoError(t, service.UpdateSubject(ctx, created.ID, "Refund request"))
+
+	assert.Equal(t, "Refund request", *repo.Conversations[created.ID].Subject)
+
+	timeline, err := service.Timeline(ctx, created.ID, false, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+
+	assert.Equal(t, models.ConversationEventPriorityChanged, timeline[0].Event.Type)
+	assert.Equal(t, "normal", *timeline[0].Event.FromValue)
+	assert.Equal(t, "high", *timeline[0].Event.ToValue)
+	assert.Nil(t, timeline[0].Event.ActorID)
+
+	assert.Equal(t, models.ConversationEventSubjectChanged, timeline[1].Event.Type)
+	assert.Nil(t, timeline[1].Event.FromValue)
+	assert.Equal(t, "Refund request", *timeline[1].Event.ToValue)
+}
+
+func TestConversationService_Merge(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), historyRepo, nil, nil, emitter)
+	ctx := context.Background()
+
+	target, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	source, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	stranger, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 2})
+	repo.Moved = 3
+
+	t.Run("rejects invalid merges", func(t *testing.T) {
+		for _, targetID := range []int64{source.ID, stranger.ID} {
+			_, err := service.Merge(ctx, source.ID, &models.MergeConversationRequest{TargetID: targetID})
+			assert.ErrorIs(t, err, models.ErrInvalidMerge)
+		}
+		assert.Nil(t, source.MergedIntoID)
+	})
+
+	t.Run("merges into target", func(t *testing.T) {
+		merged, err := service.Merge(ctx, source.ID, &models.MergeConversationRequest{TargetID: target.ID})
+		require.NoError(t, err)
+		assert.Equal(t, target.ID, merged.ID)
+		require.NotNil(t, source.MergedIntoID)
+		assert.Equal(t, target.ID, *source.MergedIntoID)
+
+		// History is recorded by the repository, in the merge transaction
+		assert.Empty(t, historyRepo.Events)
+		require.Len(t, repo.History, 2)
+		assert.Equal(t, models.ConversationEventMerged, repo.History[0].Type)
+		assert.Equal(t, target.ID, repo.History[0].ConversationID)
+		assert.Equal(t, source.ID, repo.History[1].ConversationID)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 1)
+		event := emitter.EmittedEvents[0]
+		assert.Equal(t, events.EventConversationMerged, event.EventType)
+		assert.Equal(t, target.ID, event.Payload["conversation_id"])
+		assert.Equal(t, source.ID, event.Payload["source_conversation_id"])
+		assert.Equal(t, int64(3), event.Payload["messages_moved"])
+	})
+
+	t.Run("rejects merging twice", func(t *testing.T) {
+		_, err := service.Merge(ctx, source.ID, &models.MergeConversationRequest{TargetID: target.ID})
+		assert.ErrorIs(t, err, models.ErrInvalidMerge)
+		_, err = service.Split(ctx, source.ID, &models.SplitConversationRequest{FromMessageID: 1, ToMessageID: 2})
+		assert.ErrorIs(t, err, models.ErrInvalidMerge)
+	})
+}
+
+func TestConversationService_Split(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), historyRepo, nil, nil, emitter)
+
+	source, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1, Priority: models.PriorityHigh})
+	repo.Moved = 2
+
+	// An open conversation stays the customer's only open one
+	conv, err := service.Split(context.Background(), source.ID, &models.SplitConversationRequest{FromMessageID: 5, ToMessageID: 6})
+	require.NoError(t, err)
+	assert.NotEqual(t, source.ID, conv.ID)
+	assert.Equal(t, source.ExternalUserID, conv.ExternalUserID)
+	assert.Equal(t, models.PriorityHigh, conv.Priority)
+	assert.Equal(t, models.ConversationStatusResolved, conv.Status)
+	assert.Equal(t, models.ConversationStatusOpen, source.Status)
+
+	require.Len(t, historyRepo.Events, 2)
+	assert.Equal(t, models.ConversationEventSplit, historyRepo.Events[0].Type)
+	assert.Equal(t, source.ID, historyRepo.Events[0].ConversationID)
+	assert.Equal(t, conv.ID, historyRepo.Events[1].ConversationID)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, emitter.EmittedEvents, 1)
+	event := emitter.EmittedEvents[0]
+	assert.Equal(t, events.EventConversationSplit, event.EventType)
+	assert.Equal(t, conv.ID, event.Payload["conversation_id"])
+	assert.Equal(t, int64(5), event.Payload["from_message_id"])
+	assert.Equal(t, int64(2), event.Payload["messages_moved"])
+
+	// A resolved one is split into an open one, unless the customer
+	// already has one
+	_, err = service.Split(context.Background(), conv.ID, &models.SplitConversationRequest{FromMessageID: 5, ToMessageID: 6})
+	assert.ErrorIs(t, err, models.ErrInvalidMerge)
+
+	source.Status = models.ConversationStatusResolved
+	reopened, err := service.Split(context.Background(), conv.ID, &models.SplitConversationRequest{FromMessageID: 5, ToMessageID: 6})
+	require.NoError(t, err)
+	assert.Equal(t, models.ConversationStatusOpen, reopened.Status)
+}
+
+func TestConversationService_Snooze(t *testing.T) {
+	repo := testutils.NewMockConversationRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewConversationService(repo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), historyRepo, nil, nil, emitter)
+	ctx := context.Background()
+
+	created, _ := repo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	past, until, later := time.Now().Add(-time.Minute), time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+
+	t.Run("rejects invalid wake-up times", func(t *testing.T) {
+		err := service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusSnoozed, SnoozedUntil: &past})
+		assert.ErrorIs(t, err, models.ErrInvalidSnooze)
+		err = service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusPending, SnoozedUntil: &until})
+		assert.ErrorIs(t, err, models.ErrInvalidSnooze)
+		assert.Equal(t, models.ConversationStatusOpen, created.Status)
+	})
+
+	t.Run("snoozes until a time", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusSnoozed, SnoozedUntil: &until}))
+		assert.Equal(t, models.ConversationStatusSnoozed, created.Status)
+		assert.True(t, until.Equal(*created.SnoozedUntil))
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 1)
+		assert.Equal(t, "snoozed", emitter.EmittedEvents[0].Payload["status"])
+		assert.Contains(t, emitter.EmittedEvents[0].Payload, "snoozed_until")
+	})
+
+	t.Run("snoozing again changes the wake-up time", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusSnoozed, SnoozedUntil: &later}))
+		assert.True(t, later.Equal(*created.SnoozedUntil))
+		assert.Len(t, historyRepo.Events, 1)
+	})
+
+	t.Run("waking clears the wake-up time", func(t *testing.T) {
+		require.NoError(t, service.UpdateStatus(ctx, created.ID, &models.UpdateConversationStatusRequest{Status: models.ConversationStatusOpen}))
+		assert.Nil(t, created.SnoozedUntil)
+		assert.Len(t, historyRepo.Events, 2)
+	})
+}
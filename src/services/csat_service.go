@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// CSATService sends satisfaction surveys when conversations are resolved
+// and records the customer's rating and comment
+type CSATService interface {
+	GetPolicy(ctx context.Context, channelID int64) (*models.CSATPolicy, error)
+	SetPolicy(ctx context.Context, channelID int64, req *models.UpsertCSATPolicyRequest) (*models.CSATPolicy, error)
+	// Send sends the channel's survey for a resolved conversation, if the
+	// channel has one enabled
+	Send(ctx context.Context, conv *models.Conversation) error
+	// CaptureReply records a customer's message as the answer to their open
+	// survey on the channel. It returns the survey if the message is a
+	// rating, which is kept in the surveyed conversation, and nil if the
+	// message is to be handled as usual. That includes the comment a rated
+	// survey asks for, which is recorded too.
+	CaptureReply(ctx context.Context, channelID, externalUserID int64, content string, at time.Time) (*models.CSATSurvey, error)
+	ListByConversation(ctx context.Context, conversationID int64) ([]*models.CSATSurvey, error)
+	Report(ctx context.Context, q *models.CSATReportQuery) (*models.CSATReport, error)
+}
+
+type csatService struct {
+	repo        repositories.CSATRepository
+	channelRepo repositories.ChannelRepository
+	messageRepo repositories.MessageRepository
+	emitter     events.Emitter
+}
+
+func NewCSATService(
+	repo repositories.CSATRepository,
+	channelRepo repositories.ChannelRepository,
+	messageRepo repositories.MessageRepository,
+	emitter events.Emitter,
+) CSATService {
+	return &csatService{
+		repo:        repo,
+		channelRepo: channelRepo,
+		messageRepo: messageRepo,
+		emitter:     emitter,
+	}
+}
+
+func (s *csatService) GetPolicy(ctx context.Context, channelID int64) (*models.CSATPolicy, error) {
+	policy, err := s.repo.FindPolicy(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("CSAT policy not found")
+	}
+	return policy, nil
+}
+
+func (s *csatService) SetPolicy(ctx context.Context, channelID int64, req *models.UpsertCSATPolicyRequest) (*models.CSATPolicy, error) {
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	return s.repo.UpsertPolicy(channelID, req)
+}
+
+func (s *csatService) Send(ctx context.Context, conv *models.Conversation) error {
+	policy, err := s.repo.FindPolicy(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+	if policy == nil || !policy.Enabled {
+		return nil
+	}
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return fmt.Errorf("channel not found")
+	}
+
+	content := policy.Question
+	var quickReplies []events.QuickReply
+	if channel.Platform.SupportsQuickReplies() {
+		for rating := models.CSATMinRating; rating <= models.CSATMaxRating; rating++ {
+			quickReplies = append(quickReplies, events.QuickReply{
+				Title:   models.CSATQuickReplyTitle(rating),
+				Payload: models.CSATQuickReplyPayload(rating),
+			})
+		}
+	} else {
+		content += "\n\n" + models.CSATNumericPrompt
+	}
+
+	message, err := sendSystemMessage(ctx, s.messageRepo, s.emitter, conv.ID, conv.ChannelID, content, quickReplies)
+	if err != nil {
+		return err
+	}
+
+	window := time.Duration(policy.ResponseWindowSeconds) * time.Second
+	if window <= 0 {
+		window = models.DefaultCSATResponseWindow
+	}
+	survey, err := s.repo.CreateSurvey(&models.CSATSurvey{
+		ConversationID: conv.ID,
+		ChannelID:      conv.ChannelID,
+		ExternalUserID: conv.ExternalUserID,
+		AgentID:        conv.AssignedToExternalID,
+		TeamID:         conv.TeamID,
+		MessageID:      &message.ID,
+		Status:         models.CSATStatusPending,
+		SentAt:         message.CreatedAt,
+		ExpiresAt:      message.CreatedAt.Add(window),
+	})
+	if err != nil {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.CSATSentPayload{
+		SurveyID:       survey.ID,
+		ConversationID: conv.ID,
+		ChannelID:      conv.ChannelID,
+		MessageID:      message.ID,
+		AgentID:        survey.AgentID,
+		TeamID:         survey.TeamID,
+	})
+
+	return nil
+}
+
+func (s *csatService) CaptureReply(ctx context.Context, channelID, externalUserID int64, content string, at time.Time) (*models.CSATSurvey, error) {
+	survey, err := s.repo.FindOpen(channelID, externalUserID, at)
+	if err != nil || survey == nil {
+		return nil, err
+	}
+
+	switch survey.Status {
+	case models.CSATStatusPending:
+		rating, ok := models.ParseCSATReply(content)
+		if !ok {
+			return nil, nil
+		}
+		policy, err := s.repo.FindPolicy(channelID)
+		if err != nil {
+			return nil, err
+		}
+
+		survey.Status = models.CSATStatusCompleted
+		if policy != nil && policy.CommentPrompt != nil && *policy.CommentPrompt != "" {
+			survey.Status = models.CSATStatusAwaitingComment
+		}
+		if err := s.repo.Rate(survey.ID, rating, survey.Comment, survey.Status, at); err != nil {
+			return nil, err
+		}
+		survey.Rating = &rating
+		survey.RatedAt = &at
+
+		if survey.Status == models.CSATStatusAwaitingComment {
+			if _, err := sendSystemMessage(ctx, s.messageRepo, s.emitter, survey.ConversationID, channelID, *policy.CommentPrompt, nil); err != nil {
+
+				fmt.Printf("Warning: failed to send CSAT comment prompt: %v\n", err)
+			}
+		}
+	case models.CSATStatusAwaitingComment:
+		// The comment may be a new question too, so it is not kept from
+		// the agents
+		comment := strings.TrimSpace(content)
+		if err := s.repo.Comment(survey.ID, comment); err != nil {
+			return nil, err
+		}
+		survey.Comment = &comment
+		survey.Status = models.CSATStatusCompleted
+		s.publishResponse(ctx, survey)
+		return nil, nil
+	default:
+		return nil, nil
+	}
+
+	s.publishResponse(ctx, survey)
+	return survey, nil
+}
+
+func (s *csatService) publishResponse(ctx context.Context, survey *models.CSATSurvey) {
+	go events.Publish(ctx, s.emitter, events.CSATRespondedPayload{
+		SurveyID:       survey.ID,
+		ConversationID: survey.ConversationID,
+		AgentID:        survey.AgentID,
+		TeamID:         survey.TeamID,
+		Status:         string(survey.Status),
+		Rating:         *survey.Rating,
+		Comment:        survey.Comment,
+	})
+}
+
+func (s *csatService) ListByConversation(ctx context.Context, conversationID int64) ([]*models.CSATSurvey, error) {
+	return s.repo.ListByConversation(conversationID)
+}
+
+func (s *csatService) Report(ctx context.Context, q *models.CSATReportQuery) (*models.CSATReport, error) {
+	return s.repo.Report(q)
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type csatFixture struct {
+	service       CSATService
+	messages      MessageService
+	conversations ConversationService
+	repo          *testutils.MockCSATRepository
+	convRepo      *testutils.MockConversationRepository
+	msgRepo       *testutils.MockMessageRepository
+	channelRepo   *testutils.MockChannelRepository
+	emitter       *testutils.MockEmitter
+	conv          *models.Conversation
+}
+
+func newCSATFixture(t *testing.T, platform models.Platform) *csatFixture {
+	f := &csatFixture{
+		repo:        testutils.NewMockCSATRepository(),
+		convRepo:    testutils.NewMockConversationRepository(),
+		msgRepo:     testutils.NewMockMessageRepository(),
+		channelRepo: testutils.NewMockChannelRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	f.service = NewCSATService(f.repo, f.channelRepo, f.msgRepo, f.emitter)
+	userRepo := testutils.NewMockExternalUserRepository()
+	f.messages = NewMessageService(f.msgRepo, f.convRepo, userRepo, f.emitter, nil, nil, nil, f.service, nil, nil)
+	f.conversations = NewConversationService(f.convRepo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), nil, f.service, f.emitter)
+
+	f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: platform, Name: "Support"})
+	user, err := userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "user-1"})
+	require.NoError(t, err)
+	f.conv, err = f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: user.ID})
+	require.NoError(t, err)
+	agent := "agent-1"
+	f.conv.AssignedToExternalID = &agent
+	return f
+}
+
+func (f *csatFixture) resolve(t *testing.T) {
+	require.NoError(t, f.conversations.UpdateStatus(context.Background(), f.conv.ID, &models.UpdateConversationStatusRequest{
+		Status: models.ConversationStatusResolved,
+	}))
+}
+
+func (f *csatFixture) reply(t *testing.T, content string) *models.Message {
+	msg, err := f.messages.ProcessIncomingMessage(context.Background(), &ProcessIncomingMessageRequest{
+		ChannelID:      1,
+		PlatformUserID: "user-1",
+		Content:        content,
+		MessageType:    models.MessageTypeText,
+	})
+	require.NoError(t, err)
+	return msg
+}
+
+func (f *csatFixture) survey(t *testing.T) *models.CSATSurvey {
+	surveys, err := f.service.ListByConversation(context.Background(), f.conv.ID)
+	require.NoError(t, err)
+	require.Len(t, surveys, 1)
+	return surveys[0]
+}
+
+func (f *csatFixture) emitted(eventType string) []testutils.EmittedEvent {
+	time.Sleep(10 * time.Millisecond)
+	var matching []testutils.EmittedEvent
+	for _, event := range f.emitter.EmittedEvents {
+		if event.EventType == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestCSATService_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("offers quick replies on platforms that support them", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformWhatsApp)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?"})
+		require.NoError(t, err)
+
+		f.resolve(t)
+
+		surveys, err := f.service.ListByConversation(ctx, f.conv.ID)
+		require.NoError(t, err)
+		require.Len(t, surveys, 1)
+		assert.Equal(t, "agent-1", *surveys[0].AgentID)
+		assert.Equal(t, models.CSATStatusPending, surveys[0].Status)
+		assert.Equal(t, 24*time.Hour, surveys[0].ExpiresAt.Sub(surveys[0].SentAt))
+
+		message := f.msgRepo.Messages[*surveys[0].MessageID]
+		assert.Equal(t, "How did we do?", message.Content)
+		assert.Equal(t, models.SenderSystem, message.SenderType)
+
+		sent := f.emitted(events.EventNewMessage)
+		require.Len(t, sent, 1)
+		replies := sent[0].Payload["quick_replies"].([]events.QuickReply)
+		require.Len(t, replies, 5)
+		assert.Equal(t, events.QuickReply{Title: "5 - Excellent", Payload: "csat:5"}, replies[4])
+		require.Len(t, f.emitted(events.EventCSATSent), 1)
+	})
+
+	t.Run("asks for a number on other platforms", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformSMS)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?"})
+		require.NoError(t, err)
+
+		f.resolve(t)
+
+		sent := f.emitted(events.EventNewMessage)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "How did we do?\n\n"+models.CSATNumericPrompt, sent[0].Payload["content"])
+		assert.NotContains(t, sent[0].Payload, "quick_replies")
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformWhatsApp)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Question: "How did we do?"})
+		require.NoError(t, err)
+
+		f.resolve(t)
+
+		assert.Empty(t, f.repo.Surveys)
+		assert.Empty(t, f.msgRepo.Messages)
+	})
+}
+
+func TestCSATService_CaptureReply(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records the rating in the resolved conversation", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformWhatsApp)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?"})
+		require.NoError(t, err)
+		f.resolve(t)
+
+		msg := f.reply(t, "5 - Excellent")
+		assert.Equal(t, f.conv.ID, msg.ConversationID)
+		assert.Len(t, f.convRepo.Conversations, 1)
+		assert.Equal(t, models.ConversationStatusResolved, f.conv.Status)
+
+		survey := f.survey(t)
+		assert.Equal(t, models.CSATStatusCompleted, survey.Status)
+		assert.Equal(t, 5, *survey.Rating)
+		assert.Nil(t, survey.Comment)
+
+		responded := f.emitted(events.EventCSATResponded)
+		require.Len(t, responded, 1)
+		assert.Equal(t, 5, responded[0].Payload["rating"])
+		assert.Equal(t, "agent-1", responded[0].Payload["agent_id"])
+
+		t.Run("later messages start a new conversation", func(t *testing.T) {
+			msg := f.reply(t, "4 more questions about my order")
+			assert.NotEqual(t, f.conv.ID, msg.ConversationID)
+		})
+	})
+
+	t.Run("asks for and records a comment", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformSMS)
+		prompt := "What could we do better?"
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?", CommentPrompt: &prompt})
+		require.NoError(t, err)
+		f.resolve(t)
+
+		f.reply(t, " 2 ")
+		survey := f.survey(t)
+		assert.Equal(t, models.CSATStatusAwaitingComment, survey.Status)
+		assert.Equal(t, 2, *survey.Rating)
+		assert.Len(t, f.msgRepo.Messages, 3)
+
+		// The comment is recorded, and handled as any other message in case
+		// it needs an answer
+		msg := f.reply(t, "Took too long to answer")
+		assert.NotEqual(t, f.conv.ID, msg.ConversationID)
+		survey = f.survey(t)
+		assert.Equal(t, models.CSATStatusCompleted, survey.Status)
+		assert.Equal(t, "Took too long to answer", *survey.Comment)
+		assert.Len(t, f.emitted(events.EventCSATResponded), 2)
+	})
+
+	t.Run("treats a rating with more text as a message", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformWhatsApp)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?"})
+		require.NoError(t, err)
+		f.resolve(t)
+
+		msg := f.reply(t, "2 more questions about my order")
+		assert.NotEqual(t, f.conv.ID, msg.ConversationID)
+		survey := f.survey(t)
+		assert.Equal(t, models.CSATStatusPending, survey.Status)
+		assert.Nil(t, survey.Rating)
+	})
+
+	t.Run("treats other messages as new conversations", func(t *testing.T) {
+		f := newCSATFixture(t, models.PlatformWhatsApp)
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertCSATPolicyRequest{Enabled: true, Question: "How did we do?"})
+		require.NoError(t, err)
+		f.resolve(t)
+
+		msg := f.reply(t, "Where is my order?")
+		assert.NotEqual(t, f.conv.ID, msg.ConversationID)
+		assert.Equal(t, models.CSATStatusPending, f.survey(t).Status)
+	})
+}
+
+func TestParseCSATReply(t *testing.T) {
+	tests := []struct {
+		content string
+		rating  int
+		ok      bool
+	}{
+		{" 5 ", 5, true},
+		{"csat:3", 3, true},
+		{"1 - very poor", 1, true},
+		{"4/5", 4, true},
+		{"2. slow replies", 0, false},
+		{"2 more questions", 0, false},
+		{"4/5 - quick and friendly", 0, false},
+		{"6", 0, false},
+		{"10", 0, false},
+		{"0", 0, false},
+		{"+3", 0, false},
+		{"3pm works", 0, false},
+		{"thanks!", 0, false},
+	}
+
+	for _, tt := range tests {
+		rating, ok := models.ParseCSATReply(tt.content)
+		assert.Equal(t, tt.ok, ok, tt.content)
+		assert.Equal(t, tt.rating, rating, tt.content)
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// CustomAttributeService manages an organization's typed custom attributes
+// and writes their values on conversations and contacts. Invalid keys and
+// values are rejected with models.ErrInvalidAttribute.
+type CustomAttributeService interface {
+	Create(ctx context.Context, req *models.CreateCustomAttributeRequest) (*models.CustomAttributeDefinition, error)
+	GetByID(ctx context.Context, id int64) (*models.CustomAttributeDefinition, error)
+	ListByOrganization(ctx context.Context, orgID int64, entity *models.CustomAttributeEntity, limit, offset int) ([]*models.CustomAttributeDefinition, error)
+	Update(ctx context.Context, id int64, req *models.UpdateCustomAttributeRequest) error
+	Delete(ctx context.Context, id int64) error
+	// SetConversationAttributes validates values against the organization's
+	// conversation attributes and merges them into the conversation's.
+	// A nil value removes the attribute.
+	SetConversationAttributes(ctx context.Context, conversationID int64, values map[string]interface{}) (*models.Conversation, error)
+	SetContactAttributes(ctx context.Context, externalUserID int64, values map[string]interface{}) (*models.ExternalUser, error)
+}
+
+type customAttributeService struct {
+	repo             repositories.CustomAttributeRepository
+	conversationRepo repositories.ConversationRepository
+	externalUserRepo repositories.ExternalUserRepository
+	channelRepo      repositories.ChannelRepository
+}
+
+func NewCustomAttributeService(
+	repo repositories.CustomAttributeRepository,
+	conversationRepo repositories.ConversationRepository,
+	externalUserRepo repositories.ExternalUserRepository,
+	channelRepo repositories.ChannelRepository,
+) CustomAttributeService {
+	return &customAttributeService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		externalUserRepo: externalUserRepo,
+		channelRepo:      channelRepo,
+	}
+}
+
+func (s *customAttributeService) Create(ctx context.Context, req *models.CreateCustomAttributeRequest) (*models.CustomAttributeDefinition, error) {
+	if !models.ValidAttributeKey(req.Key) {
+		return nil, fmt.Errorf("%w: key must be lowercase letters, digits and underscores, starting with a letter", models.ErrInvalidAttribute)
+	}
+	if req.Type != models.AttributeTypeEnum && len(req.Options) > 0 {
+		return nil, fmt.Errorf("%w: only enum attributes take options", models.ErrInvalidAttribute)
+	}
+	return s.repo.Create(req)
+}
+
+func (s *customAttributeService) GetByID(ctx context.Context, id int64) (*models.CustomAttributeDefinition, error) {
+	def, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, fmt.Errorf("custom attribute not found")
+	}
+	return def, nil
+}
+
+func (s *customAttributeService) ListByOrganization(ctx context.Context, orgID int64, entity *models.CustomAttributeEntity, limit, offset int) ([]*models.CustomAttributeDefinition, error) {
+	return s.repo.ListByOrganization(orgID, entity, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *customAttributeService) Update(ctx context.Context, id int64, req *models.UpdateCustomAttributeRequest) error {
+	if req.Options != nil {
+		def, err := s.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if def.Type != models.AttributeTypeEnum {
+			return fmt.Errorf("%w: only enum attributes take options", models.ErrInvalidAttribute)
+		}
+	}
+	return s.repo.Update(id, req)
+}
+
+func (s *customAttributeService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *customAttributeService) SetConversationAttributes(ctx context.Context, conversationID int64, values map[string]interface{}) (*models.Conversation, error) {
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+
+	attrs, err := s.coerce(conv.ChannelID, models.AttributeEntityConversation, values)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.conversationRepo.SetAttributes(conversationID, attrs); err != nil {
+		return nil, err
+	}
+	return s.conversationRepo.GetByID(conversationID)
+}
+
+func (s *customAttributeService) SetContactAttributes(ctx context.Context, externalUserID int64, values map[string]interface{}) (*models.ExternalUser, error) {
+	user, err := s.externalUserRepo.GetByID(externalUserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	attrs, err := s.coerce(user.ChannelID, models.AttributeEntityContact, values)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.externalUserRepo.SetAttributes(externalUserID, attrs); err != nil {
+		return nil, err
+	}
+	return s.externalUserRepo.GetByID(externalUserID)
+}
+
+// coerce checks values against the attributes defined for entity by the
+// organization owning the channel, keeping nil values as removals
+func (s *customAttributeService) coerce(channelID int64, entity models.CustomAttributeEntity, values map[string]interface{}) (models.CustomAttributes, error) {
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+
+	defs, err := s.repo.Definitions(channel.OrganizationID, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(models.CustomAttributes, len(values))
+	for key, value := range values {
+		def, ok := defs[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not defined", models.ErrInvalidAttribute, key)
+		}
+		if value == nil {
+			attrs[key] = nil
+			continue
+		}
+		if attrs[key], err = def.Coerce(value); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomAttributeService_Create(t *testing.T) {
+	service := NewCustomAttributeService(testutils.NewMockCustomAttributeRepository(), testutils.NewMockConversationRepository(),
+		testutils.NewMockExternalUserRepository(), testutils.NewMockChannelRepository())
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, &models.CreateCustomAttributeRequest{
+		OrganizationID: 1, Entity: models.AttributeEntityContact, Key: "Plan Name", Label: "Plan", Type: models.AttributeTypeString,
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidAttribute)
+
+	_, err = service.Create(ctx, &models.CreateCustomAttributeRequest{
+		OrganizationID: 1, Entity: models.AttributeEntityContact, Key: "plan", Label: "Plan", Type: models.AttributeTypeString,
+		Options: []string{"free"},
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidAttribute)
+
+	def, err := service.Create(ctx, &models.CreateCustomAttributeRequest{
+		OrganizationID: 1, Entity: models.AttributeEntityContact, Key: "plan_name", Label: "Plan", Type: models.AttributeTypeString,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "plan_name", def.Key)
+}
+
+func TestCustomAttributeService_SetConversationAttributes(t *testing.T) {
+	attrRepo := testutils.NewMockCustomAttributeRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	service := NewCustomAttributeService(attrRepo, convRepo, testutils.NewMockExternalUserRepository(), channelRepo)
+	ctx := context.Background()
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	conv, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	for _, req := range []*models.CreateCustomAttributeRequest{
+		{OrganizationID: 1, Entity: models.AttributeEntityConversation, Key: "plan", Type: models.AttributeTypeEnum, Options: []string{"free", "pro"}},
+		{OrganizationID: 1, Entity: models.AttributeEntityConversation, Key: "seats", Type: models.AttributeTypeNumber},
+		{OrganizationID: 1, Entity: models.AttributeEntityConversation, Key: "renews_on", Type: models.AttributeTypeDate},
+		{OrganizationID: 1, Entity: models.AttributeEntityContact, Key: "vip", Type: models.AttributeTypeBoolean},
+		{OrganizationID: 2, Entity: models.AttributeEntityConversation, Key: "region", Type: models.AttributeTypeString},
+	} {
+		_, err := attrRepo.Create(req)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+	}{
+		{"enum outside options", map[string]interface{}{"plan": "enterprise"}},
+		{"string for number", map[string]interface{}{"seats": "10"}},
+		{"invalid date", map[string]interface{}{"renews_on": "next week"}},
+		{"contact attribute", map[string]interface{}{"vip": true}},
+		{"another organization's attribute", map[string]interface{}{"region": "eu"}},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			_, err := service.SetConversationAttributes(ctx, conv.ID, tt.values)
+			assert.ErrorIs(t, err, models.ErrInvalidAttribute)
+			assert.Empty(t, conv.CustomAttributes)
+		})
+	}
+
+	t.Run("stores coerced values", func(t *testing.T) {
+		updated, err := service.SetConversationAttributes(ctx, conv.ID, map[string]interface{}{
+			"plan":      "pro",
+			"seats":     10.0,
+			"renews_on": "2027-01-31T09:00:00+05:45",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.CustomAttributes{
+			"plan":      "pro",
+			"seats":     10.0,
+			"renews_on": "2027-01-31T03:15:00Z",
+		}, updated.CustomAttributes)
+	})
+
+	t.Run("null removes a value", func(t *testing.T) {
+		updated, err := service.SetConversationAttributes(ctx, conv.ID, map[string]interface{}{"plan": nil})
+		require.NoError(t, err)
+		assert.NotContains(t, updated.CustomAttributes, "plan")
+		assert.Equal(t, 10.0, updated.CustomAttributes["seats"])
+	})
+}
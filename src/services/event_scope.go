@@ -0,0 +1,60 @@
+package services
+
+import (
+	"sync"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// NewEventScopeResolver resolves the organization and platform of payloads
+// that only reference a channel, conversation or message. Channel scopes
+// never change, so they are cached.
+func NewEventScopeResolver(
+	channelRepo repositories.ChannelRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+) events.ScopeResolver {
+	var cache sync.Map
+
+	channelScope := func(channelID int64) (events.Scope, bool) {
+		if scope, ok := cache.Load(channelID); ok {
+			return scope.(events.Scope), true
+		}
+		channel, err := channelRepo.GetByID(channelID)
+		if err != nil || channel == nil {
+			return events.Scope{}, false
+		}
+		scope := events.Scope{
+			OrganizationID: channel.OrganizationID,
+			Platform:       string(channel.Platform),
+		}
+		cache.Store(channelID, scope)
+		return scope, true
+	}
+
+	conversationScope := func(conversationID int64) (events.Scope, bool) {
+		conv, err := conversationRepo.GetByID(conversationID)
+		if err != nil || conv == nil {
+			return events.Scope{}, false
+		}
+		return channelScope(conv.ChannelID)
+	}
+
+	return func(payload map[string]interface{}) (events.Scope, bool) {
+		if id, ok := payload["channel_id"].(int64); ok {
+			return channelScope(id)
+		}
+		if id, ok := payload["conversation_id"].(int64); ok {
+			return conversationScope(id)
+		}
+		if id, ok := payload["message_id"].(int64); ok {
+			msg, err := messageRepo.GetByID(id)
+			if err != nil || msg == nil {
+				return events.Scope{}, false
+			}
+			return conversationScope(msg.ConversationID)
+		}
+		return events.Scope{}, false
+	}
+}
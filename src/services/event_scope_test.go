@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventScopeResolver(t *testing.T) {
+	channelRepo := testutils.NewMockChannelRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	msgRepo := testutils.NewMockMessageRepository()
+	resolve := NewEventScopeResolver(channelRepo, convRepo, msgRepo)
+
+	channel, _ := channelRepo.Create(&models.CreateChannelRequest{
+		OrganizationID: 4,
+		Platform:       models.PlatformTelegram,
+		Name:           "TG",
+	})
+	conv, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: channel.ID, ExternalUserID: 1})
+	msg, _ := msgRepo.Create(&models.Message{ConversationID: conv.ID})
+
+	expected := struct {
+		org      int64
+		platform string
+	}{4, "telegram"}
+
+	for name, payload := range map[string]map[string]interface{}{
+		"channel":      {"channel_id": channel.ID},
+		"conversation": {"conversation_id": conv.ID},
+		"message":      {"message_id": msg.ID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			scope, ok := resolve(payload)
+			assert.True(t, ok)
+			assert.Equal(t, expected.org, scope.OrganizationID)
+			assert.Equal(t, expected.platform, scope.Platform)
+		})
+	}
+
+	t.Run("unknown references", func(t *testing.T) {
+		_, ok := resolve(map[string]interface{}{"conversation_id": int64(999)})
+		assert.False(t, ok)
+
+		_, ok = resolve(map[string]interface{}{"organization_id": int64(1)})
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+type ExternalUserService interface {
+	GetByID(ctx context.Context, id int64) (*models.ExternalUser, error)
+	List(ctx context.Context, q *models.ExternalUserQuery) ([]*models.ExternalUser, error)
+	SetBlocked(ctx context.Context, id int64, blocked bool) error
+}
+
+type externalUserService struct {
+	repo    repositories.ExternalUserRepository
+	emitter events.Emitter
+}
+
+func NewExternalUserService(repo repositories.ExternalUserRepository, emitter events.Emitter) ExternalUserService {
+	return &externalUserService{
+		repo:    repo,
+		emitter: emitter,
+	}
+}
+
+func (s *externalUserService) GetByID(ctx context.Context, id int64) (*models.ExternalUser, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *externalUserService) List(ctx context.Context, q *models.ExternalUserQuery) ([]*models.ExternalUser, error) {
+	q.Limit = utils.NormalizeLimit(q.Limit)
+	q.Offset = utils.NormalizeOffset(q.Offset)
+	return s.repo.List(q)
+}
+
+func (s *externalUserService) SetBlocked(ctx context.Context, id int64, blocked bool) error {
+	if err := s.repo.Update(id, &models.UpdateExternalUserRequest{
+		IsBlocked: &blocked,
+	}); err != nil {
+		return err
+	}
+
+	if blocked {
+		go events.Publish(ctx, s.emitter, events.UserBlockedPayload{ExternalUserID: id})
+	} else {
+		go events.Publish(ctx, s.emitter, events.UserUnblockedPayload{ExternalUserID: id})
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalUserService_SetBlocked(t *testing.T) {
+	repo := testutils.NewMockExternalUserRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewExternalUserService(repo, emitter)
+
+	user, _ := repo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "u-1"})
+
+	require.NoError(t, service.SetBlocked(context.Background(), user.ID, true))
+	assert.True(t, repo.Users[user.ID].IsBlocked)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, emitter.EmittedEvents, 1)
+	assert.Equal(t, events.EventUserBlocked, emitter.EmittedEvents[0].EventType)
+
+	require.NoError(t, service.SetBlocked(context.Background(), user.ID, false))
+	assert.False(t, repo.Users[user.ID].IsBlocked)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, emitter.EmittedEvents, 2)
+	assert.Equal(t, events.EventUserUnblocked, emitter.EmittedEvents[1].EventType)
+}
+
+func TestExternalUserService_SetBlocked_RepoError(t *testing.T) {
+	repo := testutils.NewMockExternalUserRepository()
+	repo.UpdateError = errors.New("update failed")
+	emitter := testutils.NewMockEmitter()
+	service := NewExternalUserService(repo, emitter)
+
+	err := service.SetBlocked(context.Background(), 1, true)
+	assert.Error(t, err)
+}
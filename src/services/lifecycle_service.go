@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// LifecycleService decides whether a customer's message reopens their last
+// conversation or starts a new one, and closes conversations that have gone
+// idle, according to the channel's lifecycle policy. It also wakes snoozed
+// conversations when their time comes or the customer writes.
+type LifecycleService interface {
+	GetPolicy(ctx context.Context, channelID int64) (*models.LifecyclePolicy, error)
+	SetPolicy(ctx context.Context, channelID int64, req *models.UpsertLifecyclePolicyRequest) (*models.LifecyclePolicy, error)
+	// ConversationFor returns the conversation a customer's new message
+	// belongs to: their open conversation, woken up if it was snoozed, their
+	// last one reopened if it was resolved within the reopen window, or a
+	// new one. Messages in a group chat go to the chat's conversation
+	// instead of the customer's own. The flag reports whether it was
+	// created.
+	ConversationFor(ctx context.Context, channelID, externalUserID int64, platformChatID string, at time.Time) (*models.Conversation, bool, error)
+	// CloseIdle resolves or closes idle conversations, sending the closing
+	// message if one is set and the satisfaction survey to resolved ones,
+	// and returns how many were closed
+	CloseIdle(ctx context.Context, now time.Time) (int, error)
+	// WakeSnoozed reopens snoozed conversations whose wake-up time has
+	// passed and returns how many were woken
+	WakeSnoozed(ctx context.Context, now time.Time) (int, error)
+}
+
+type lifecycleService struct {
+	policyRepo       repositories.LifecyclePolicyRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	historyRepo      repositories.ConversationEventRepository
+	channelRepo      repositories.ChannelRepository
+	csat             CSATService
+	emitter          events.Emitter
+}
+
+func NewLifecycleService(
+	policyRepo repositories.LifecyclePolicyRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	historyRepo repositories.ConversationEventRepository,
+	channelRepo repositories.ChannelRepository,
+	csat CSATService,
+	emitter events.Emitter,
+) LifecycleService {
+	return &lifecycleService{
+		policyRepo:       policyRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		historyRepo:      historyRepo,
+		channelRepo:      channelRepo,
+		csat:             csat,
+		emitter:          emitter,
+	}
+}
+
+func (s *lifecycleService) GetPolicy(ctx context.Context, channelID int64) (*models.LifecyclePolicy, error) {
+	policy, err := s.policyRepo.FindByChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("lifecycle policy not found")
+	}
+	return policy, nil
+}
+
+func (s *lifecycleService) SetPolicy(ctx context.Context, channelID int64, req *models.UpsertLifecyclePolicyRequest) (*models.LifecyclePolicy, error) {
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	return s.policyRepo.Upsert(channelID, req)
+}
+
+func (s *lifecycleService) ConversationFor(ctx context.Context, channelID, externalUserID int64, platformChatID string, at time.Time) (*models.Conversation, bool, error) {
+	policy, err := s.policyRepo.FindByChannel(channelID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if policy != nil && policy.ReopenWithinSeconds > 0 {
+		var latest *models.Conversation
+		if platformChatID != "" {
+			latest, err = s.conversationRepo.LatestByChat(channelID, platformChatID)
+		} else {
+			latest, err = s.conversationRepo.LatestByUser(channelID, externalUserID)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		window := time.Duration(policy.ReopenWithinSeconds) * time.Second
+		if latest != nil && latest.Status == models.ConversationStatusResolved &&
+			latest.ResolvedAt != nil && at.Sub(*latest.ResolvedAt) <= window {
+			if err := s.transition(ctx, latest, models.ConversationStatusOpen, models.StatusReasonCustomerReply); err != nil {
+				return nil, false, err
+			}
+			return latest, false, nil
+		}
+	}
+
+	conv, created, err := getOrCreateConversation(s.conversationRepo, channelID, externalUserID, platformChatID)
+	if err != nil {
+		return nil, false, err
+	}
+	if conv.Status == models.ConversationStatusSnoozed {
+		if err := s.wake(ctx, conv, models.StatusReasonCustomerReply); err != nil {
+			return nil, false, err
+		}
+	}
+	return conv, created, nil
+}
+
+func (s *lifecycleService) CloseIdle(ctx context.Context, now time.Time) (int, error) {
+	conversations, err := s.conversationRepo.ListIdle(now)
+	if err != nil {
+		return 0, err
+	}
+
+	policies := make(map[int64]*models.LifecyclePolicy)
+	closed := 0
+	for _, conv := range conversations {
+		policy, ok := policies[conv.ChannelID]
+		if !ok {
+			if policy, err = s.policyRepo.FindByChannel(conv.ChannelID); err != nil {
+				return closed, err
+			}
+			policies[conv.ChannelID] = policy
+		}
+		if policy == nil {
+			continue
+		}
+
+		if policy.ClosingMessage != nil && *policy.ClosingMessage != "" {
+			if err := s.sendClosingMessage(ctx, conv, *policy.ClosingMessage); err != nil {
+
+				fmt.Printf("Warning: failed to send closing message: %v\n", err)
+			}
+		}
+
+		if err := s.transition(ctx, conv, policy.IdleStatus, models.StatusReasonIdle); err != nil {
+			return closed, err
+		}
+		closed++
+
+		if conv.Status == models.ConversationStatusResolved && s.csat != nil {
+			if err := s.csat.Send(ctx, conv); err != nil {
+
+				fmt.Printf("Warning: failed to send CSAT survey: %v\n", err)
+			}
+		}
+	}
+
+	return closed, nil
+}
+
+func (s *lifecycleService) WakeSnoozed(ctx context.Context, now time.Time) (int, error) {
+	conversations, err := s.conversationRepo.ListSnoozedDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	woken := 0
+	for _, conv := range conversations {
+		if err := s.wake(ctx, conv, models.StatusReasonSnoozeExpired); err != nil {
+			return woken, err
+		}
+		woken++
+	}
+
+	return woken, nil
+}
+
+// wake reopens a snoozed conversation and publishes the wake-up
+func (s *lifecycleService) wake(ctx context.Context, conv *models.Conversation, reason string) error {
+	snoozedUntil := conv.SnoozedUntil
+	if err := s.transition(ctx, conv, models.ConversationStatusOpen, reason); err != nil {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationWokenPayload{
+		ConversationID: conv.ID,
+		Reason:         reason,
+		SnoozedUntil:   snoozedUntil,
+	})
+
+	return nil
+}
+
+// transition changes a conversation's status on the system's behalf,
+// recording and publishing the change like a manual one
+func (s *lifecycleService) transition(ctx context.Context, conv *models.Conversation, status models.ConversationStatus, reason string) error {
+	previous := conv.Status
+	if !previous.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s to %s", models.ErrInvalidTransition, previous, status)
+	}
+
+	if err := s.conversationRepo.Update(conv.ID, &models.UpdateConversationRequest{
+		Status: &status,
+	}); err != nil {
+		return err
+	}
+	conv.Status = status
+	conv.SnoozedUntil = nil
+	if status == models.ConversationStatusOpen {
+		conv.ResolvedAt = nil
+	}
+
+	previousStr, statusStr := string(previous), string(status)
+	recordChange(ctx, s.historyRepo, conv.ID, models.ConversationEventStatusChanged, &previousStr, &statusStr, reason)
+
+	go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+		ConversationID: conv.ID,
+		Status:         &statusStr,
+		PreviousStatus: &previousStr,
+		Reason:         &reason,
+	})
+
+	return nil
+}
+
+func (s *lifecycleService) sendClosingMessage(ctx context.Context, conv *models.Conversation, content string) error {
+	message, err := s.messageRepo.Create(&models.Message{
+		ConversationID: conv.ID,
+		SenderType:     models.SenderInternal,
+		Content:        content,
+		MessageType:    models.MessageTypeText,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	go events.Publish(ctx, s.emitter, events.MessageNewPayload{
+		MessageID:      message.ID,
+		ConversationID: conv.ID,
+		ChannelID:      conv.ChannelID,
+		Content:        content,
+		MessageType:    string(models.MessageTypeText),
+		Direction:      string(models.DirectionOutbound),
+		Timestamp:      message.CreatedAt,
+	})
+
+	return nil
+}
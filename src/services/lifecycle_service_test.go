@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lifecycleFixture struct {
+	service     LifecycleService
+	policyRepo  *testutils.MockLifecyclePolicyRepository
+	convRepo    *testutils.MockConversationRepository
+	msgRepo     *testutils.MockMessageRepository
+	historyRepo *testutils.MockConversationEventRepository
+	emitter     *testutils.MockEmitter
+}
+
+func newLifecycleFixture() *lifecycleFixture {
+	f := &lifecycleFixture{
+		policyRepo:  testutils.NewMockLifecyclePolicyRepository(),
+		convRepo:    testutils.NewMockConversationRepository(),
+		msgRepo:     testutils.NewMockMessageRepository(),
+		historyRepo: testutils.NewMockConversationEventRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	channelRepo := testutils.NewMockChannelRepository()
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	f.service = NewLifecycleService(f.policyRepo, f.convRepo, f.msgRepo, f.historyRepo, channelRepo, nil, f.emitter)
+	return f
+}
+
+func (f *lifecycleFixture) resolvedConversation(resolvedAt time.Time) *models.Conversation {
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	conv.Status = models.ConversationStatusResolved
+	conv.ResolvedAt = &resolvedAt
+	return conv
+}
+
+func TestLifecycleService_ConversationFor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("reopens within the window", func(t *testing.T) {
+		f := newLifecycleFixture()
+		_, err := f.service.SetPolicy(ctx, 1, &models.UpsertLifecyclePolicyRequest{ReopenWithinSeconds: 3600})
+		require.NoError(t, err)
+		previous := f.resolvedConversation(now.Add(-30 * time.Minute))
+
+		conv, created, err := f.service.ConversationFor(ctx, 1, 1, "", now)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, previous.ID, conv.ID)
+		assert.Equal(t, models.ConversationStatusOpen, conv.Status)
+		assert.Nil(t, conv.ResolvedAt)
+
+		require.Len(t, f.historyRepo.Events, 1)
+		assert.Equal(t, models.StatusReasonCustomerReply, *f.historyRepo.Events[0].Reason)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, f.emitter.EmittedEvents, 1)
+		assert.Equal(t, events.EventConversationUpdated, f.emitter.EmittedEvents[0].EventType)
+		assert.Equal(t, "resolved", f.emitter.EmittedEvents[0].Payload["previous_status"])
+	})
+
+	t.Run("starts a new conversation after the window", func(t *testing.T) {
+		f := newLifecycleFixture()
+		f.service.SetPolicy(ctx, 1, &models.UpsertLifecyclePolicyRequest{ReopenWithinSeconds: 3600})
+		previous := f.resolvedConversation(now.Add(-2 * time.Hour))
+
+		conv, created, err := f.service.ConversationFor(ctx, 1, 1, "", now)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, previous.ID, conv.ID)
+		assert.Equal(t, models.ConversationStatusResolved, previous.Status)
+	})
+
+	t.Run("starts a new conversation without a policy", func(t *testing.T) {
+		f := newLifecycleFixture()
+		previous := f.resolvedConversation(now.Add(-time.Minute))
+
+		conv, created, err := f.service.ConversationFor(ctx, 1, 1, "", now)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, previous.ID, conv.ID)
+	})
+
+	t.Run("closed conversations are not reopened", func(t *testing.T) {
+		f := newLifecycleFixture()
+		f.service.SetPolicy(ctx, 1, &models.UpsertLifecyclePolicyRequest{ReopenWithinSeconds: 3600})
+		previous := f.resolvedConversation(now.Add(-time.Minute))
+		previous.Status = models.ConversationStatusClosed
+
+		_, created, err := f.service.ConversationFor(ctx, 1, 1, "", now)
+		require.NoError(t, err)
+		assert.True(t, created)
+	})
+}
+
+func TestLifecycleService_CloseIdle(t *testing.T) {
+	f := newLifecycleFixture()
+	ctx := context.Background()
+
+	closing := "We haven't heard back, so we're closing this chat."
+	_, err := f.service.SetPolicy(ctx, 1, &models.UpsertLifecyclePolicyRequest{
+		IdleAfterSeconds: 86400,
+		IdleStatus:       models.ConversationStatusClosed,
+		ClosingMessage:   &closing,
+	})
+	require.NoError(t, err)
+
+	idle, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	f.convRepo.Idle = []*models.Conversation{idle}
+
+	closed, err := f.service.CloseIdle(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.Equal(t, models.ConversationStatusClosed, idle.Status)
+
+	messages, _ := f.msgRepo.ListByConversation(idle.ID, 10, 0, nil)
+	require.Len(t, messages, 1)
+	assert.Equal(t, closing, messages[0].Content)
+	assert.Equal(t, models.DirectionOutbound, messages[0].Direction)
+
+	require.Len(t, f.historyRepo.Events, 1)
+	assert.Equal(t, models.StatusReasonIdle, *f.historyRepo.Events[0].Reason)
+
+	time.Sleep(10 * time.Millisecond)
+	types := make([]string, 0, len(f.emitter.EmittedEvents))
+	for _, e := range f.emitter.EmittedEvents {
+		types = append(types, e.EventType)
+	}
+	assert.ElementsMatch(t, []string{events.EventNewMessage, events.EventConversationUpdated}, types)
+}
+
+func TestMessageService_ProcessIncomingMessage_Reopens(t *testing.T) {
+	f := newLifecycleFixture()
+	ctx := context.Background()
+	f.service.SetPolicy(ctx, 1, &models.UpsertLifecyclePolicyRequest{ReopenWithinSeconds: 3600})
+
+	userRepo := testutils.NewMockExternalUserRepository()
+	user, _ := userRepo.FindOrCreate(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "user-1"})
+	previous, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: user.ID})
+	resolvedAt := time.Now().Add(-time.Minute)
+	previous.Status = models.ConversationStatusResolved
+	previous.ResolvedAt = &resolvedAt
+
+	messages := NewMessageService(f.msgRepo, f.convRepo, userRepo, f.emitter, nil, nil, f.service, nil, nil, nil)
+	msg, err := messages.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+		ChannelID:      1,
+		PlatformUserID: "user-1",
+		Content:        "one more thing",
+		MessageType:    models.MessageTypeText,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, previous.ID, msg.ConversationID)
+	assert.Equal(t, models.ConversationStatusOpen, previous.Status)
+}
+
+func TestLifecycleService_WakeSnoozed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	f := newLifecycleFixture()
+
+	snooze := func(userID int64, until *time.Time) *models.Conversation {
+		conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: userID})
+		conv.Status = models.ConversationStatusSnoozed
+		conv.SnoozedUntil = until
+		return conv
+	}
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	due := snooze(1, &past)
+	notDue := snooze(2, &future)
+	untilReply := snooze(3, nil)
+
+	t.Run("on schedule", func(t *testing.T) {
+		n, err := f.service.WakeSnoozed(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, models.ConversationStatusOpen, due.Status)
+		assert.Nil(t, due.SnoozedUntil)
+		assert.Equal(t, models.ConversationStatusSnoozed, notDue.Status)
+		assert.Equal(t, models.ConversationStatusSnoozed, untilReply.Status)
+
+		require.Len(t, f.historyRepo.Events, 1)
+		assert.Equal(t, models.StatusReasonSnoozeExpired, *f.historyRepo.Events[0].Reason)
+
+		time.Sleep(10 * time.Millisecond)
+		var woken *testutils.EmittedEvent
+		for i, e := range f.emitter.EmittedEvents {
+			if e.EventType == events.EventConversationWoken {
+				woken = &f.emitter.EmittedEvents[i]
+			}
+		}
+		require.NotNil(t, woken)
+		assert.Equal(t, due.ID, woken.Payload["conversation_id"])
+		assert.Equal(t, models.StatusReasonSnoozeExpired, woken.Payload["reason"])
+	})
+
+	t.Run("when the customer writes", func(t *testing.T) {
+		conv, created, err := f.service.ConversationFor(ctx, 1, 3, "", now)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, untilReply.ID, conv.ID)
+		assert.Equal(t, models.ConversationStatusOpen, conv.Status)
+		assert.Equal(t, models.StatusReasonCustomerReply, *f.historyRepo.Events[1].Reason)
+	})
+}
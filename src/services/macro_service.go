@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// MacroService manages an organization's macros and runs them against
+// conversations
+type MacroService interface {
+	Create(ctx context.Context, req *models.CreateMacroRequest) (*models.Macro, error)
+	GetByID(ctx context.Context, id int64) (*models.Macro, error)
+	ListByOrganization(ctx context.Context, orgID int64, teamID *int64, limit, offset int) ([]*models.Macro, error)
+	Update(ctx context.Context, id int64, req *models.UpdateMacroRequest) error
+	Delete(ctx context.Context, id int64) error
+	// Run applies the macro's actions to the conversation in order, in one
+	// transaction: a macro that cannot be applied as a whole changes
+	// nothing. Replies are sent and changes published once all actions
+	// are applied. Each run is recorded, rejected or not.
+	Run(ctx context.Context, conversationID, macroID int64) (*models.MacroRun, error)
+	ListRuns(ctx context.Context, macroID int64, limit, offset int) ([]*models.MacroRun, error)
+}
+
+type macroService struct {
+	repo             repositories.MacroRepository
+	teamRepo         repositories.TeamRepository
+	tagRepo          repositories.TagRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	cannedResponses  CannedResponseService
+	sla              SLAService
+	csat             CSATService
+	emitter          events.Emitter
+}
+
+func NewMacroService(
+	repo repositories.MacroRepository,
+	teamRepo repositories.TeamRepository,
+	tagRepo repositories.TagRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	cannedResponses CannedResponseService,
+	sla SLAService,
+	csat CSATService,
+	emitter events.Emitter,
+) MacroService {
+	return &macroService{
+		repo:             repo,
+		teamRepo:         teamRepo,
+		tagRepo:          tagRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		cannedResponses:  cannedResponses,
+		sla:              sla,
+		csat:             csat,
+		emitter:          emitter,
+	}
+}
+
+func (s *macroService) Create(ctx context.Context, req *models.CreateMacroRequest) (*models.Macro, error) {
+	if err := checkActions(req.Actions); err != nil {
+		return nil, err
+	}
+	if err := checkTeam(s.teamRepo, req.OrganizationID, req.TeamID); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(req)
+}
+
+func (s *macroService) GetByID(ctx context.Context, id int64) (*models.Macro, error) {
+	macro, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if macro == nil {
+		return nil, fmt.Errorf("macro not found")
+	}
+	return macro, nil
+}
+
+func (s *macroService) ListByOrganization(ctx context.Context, orgID int64, teamID *int64, limit, offset int) ([]*models.Macro, error) {
+	return s.repo.ListByOrganization(orgID, teamID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *macroService) Update(ctx context.Context, id int64, req *models.UpdateMacroRequest) error {
+	if err := checkActions(req.Actions); err != nil {
+		return err
+	}
+	if req.TeamID != nil && *req.TeamID != 0 {
+		macro, err := s.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkTeam(s.teamRepo, macro.OrganizationID, req.TeamID); err != nil {
+			return err
+		}
+	}
+	return s.repo.Update(id, req)
+}
+
+func (s *macroService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *macroService) Run(ctx context.Context, conversationID, macroID int64) (*models.MacroRun, error) {
+	macro, err := s.GetByID(ctx, macroID)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+
+	run := &models.MacroRun{
+		MacroID:        macro.ID,
+		ConversationID: conv.ID,
+		ActorID:        optional(middleware.UserIDFromContext(ctx)),
+		Actions:        macro.Actions,
+	}
+
+	reason := "macro " + macro.Name
+	steps, err := s.prepare(ctx, conv, macro)
+	if err == nil {
+		err = s.repo.Apply(conv.ID, steps, run.ActorID, reason)
+	}
+	if err != nil {
+		msg := err.Error()
+		run.Error = &msg
+	}
+
+	if recordErr := s.repo.RecordRun(run); recordErr != nil {
+
+		fmt.Printf("Warning: failed to record run of macro %d: %v\n", macro.ID, recordErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, conv, steps, reason)
+	return run, nil
+}
+
+func (s *macroService) ListRuns(ctx context.Context, macroID int64, limit, offset int) ([]*models.MacroRun, error) {
+	return s.repo.ListRuns(macroID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+// prepare checks that every action of the macro can be applied to the
+// conversation, following the status it will be in by then, and resolves
+// each into a step to apply
+func (s *macroService) prepare(ctx context.Context, conv *models.Conversation, macro *models.Macro) ([]*models.MacroStep, error) {
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	if channel.OrganizationID != macro.OrganizationID {
+		return nil, fmt.Errorf("%w: macro belongs to another organization", models.ErrInvalidMacro)
+	}
+
+	steps := make([]*models.MacroStep, 0, len(macro.Actions))
+	status := conv.Status
+	for _, action := range macro.Actions {
+		if err := action.Check(); err != nil {
+			return nil, err
+		}
+
+		step := &models.MacroStep{Action: action}
+
+		switch action.Type {
+		case models.MacroActionSendReply:
+			content := ""
+			if action.CannedResponseID != nil {
+				content, err = s.cannedResponses.Render(ctx, *action.CannedResponseID, conv.ID)
+				if err != nil {
+					return nil, fmt.Errorf("%w: canned response %d: %v", models.ErrInvalidMacro, *action.CannedResponseID, err)
+				}
+			} else {
+				content, err = s.cannedResponses.RenderContent(ctx, conv.ID, *action.Content)
+				if err != nil {
+					return nil, err
+				}
+			}
+			step.Reply = content
+		case models.MacroActionAddTags, models.MacroActionRemoveTags:
+			step.Tags, err = resolveTags(s.tagRepo, channel.OrganizationID, action.Tags)
+			if err != nil {
+				return nil, err
+			}
+		case models.MacroActionSetStatus:
+			if *action.Status != status && !status.CanTransitionTo(*action.Status) {
+				return nil, fmt.Errorf("%w: %s to %s", models.ErrInvalidTransition, status, *action.Status)
+			}
+			status = *action.Status
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// publish announces what the applied steps changed, as the services that
+// own them would one by one, and brings the conversation's SLA and survey
+// up to date
+func (s *macroService) publish(ctx context.Context, conv *models.Conversation, steps []*models.MacroStep, reason string) {
+	priorityChanged := false
+	for _, step := range steps {
+		switch step.Action.Type {
+		case models.MacroActionSendReply:
+			if step.Message == nil {
+				continue
+			}
+			if s.sla != nil {
+				if err := s.sla.AgentReply(ctx, conv, step.Message.CreatedAt); err != nil {
+					fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+				}
+			}
+			go events.Publish(ctx, s.emitter, events.MessageNewPayload{
+				MessageID:      step.Message.ID,
+				ConversationID: conv.ID,
+				ChannelID:      conv.ChannelID,
+				Content:        step.Message.Content,
+				MessageType:    string(step.Message.MessageType),
+				Direction:      string(models.DirectionOutbound),
+				Timestamp:      step.Message.CreatedAt,
+			})
+		case models.MacroActionAddTags, models.MacroActionRemoveTags:
+			if len(step.Changed) == 0 {
+				continue
+			}
+			payload := events.ConversationTagsChangedPayload{ConversationID: conv.ID}
+			if step.Action.Type == models.MacroActionAddTags {
+				payload.Added = step.Changed
+			} else {
+				payload.Removed = step.Changed
+			}
+			go events.Publish(ctx, s.emitter, payload)
+		case models.MacroActionSetPriority:
+			if step.Previous == nil {
+				continue
+			}
+			priorityChanged = true
+			priority := string(*step.Action.Priority)
+			go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+				ConversationID: conv.ID,
+				Priority:       &priority,
+			})
+		case models.MacroActionSetStatus:
+			if step.Previous == nil {
+				continue
+			}
+			status := string(*step.Action.Status)
+			go events.Publish(ctx, s.emitter, events.ConversationUpdatedPayload{
+				ConversationID: conv.ID,
+				Status:         &status,
+				PreviousStatus: step.Previous,
+				Reason:         &reason,
+			})
+			if *step.Action.Status == models.ConversationStatusResolved && s.csat != nil {
+				resolved := *conv
+				resolved.Status = models.ConversationStatusResolved
+				if err := s.csat.Send(ctx, &resolved); err != nil {
+
+					fmt.Printf("Warning: failed to send CSAT survey: %v\n", err)
+				}
+			}
+		}
+	}
+
+	// The priority may select a different SLA policy
+	if priorityChanged && s.sla != nil {
+		updated, err := s.conversationRepo.GetByID(conv.ID)
+		if err == nil && updated != nil {
+			err = s.sla.Apply(ctx, updated)
+		}
+		if err != nil {
+
+			fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+		}
+	}
+}
+
+func checkActions(actions []models.MacroAction) error {
+	for _, action := range actions {
+		if err := action.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
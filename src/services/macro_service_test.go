@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type macroFixture struct {
+	service      MacroService
+	repo         *testutils.MockMacroRepository
+	tagRepo      *testutils.MockTagRepository
+	convRepo     *testutils.MockConversationRepository
+	responseRepo *testutils.MockCannedResponseRepository
+	emitter      *testutils.MockEmitter
+	conv         *models.Conversation
+}
+
+func newMacroFixture(t *testing.T) *macroFixture {
+	f := &macroFixture{
+		repo:         testutils.NewMockMacroRepository(),
+		tagRepo:      testutils.NewMockTagRepository(),
+		convRepo:     testutils.NewMockConversationRepository(),
+		responseRepo: testutils.NewMockCannedResponseRepository(),
+	}
+	teamRepo := testutils.NewMockTeamRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	userRepo := testutils.NewMockExternalUserRepository()
+	f.emitter = testutils.NewMockEmitter()
+
+	cannedResponses := NewCannedResponseService(f.responseRepo, teamRepo, f.convRepo, channelRepo, userRepo, testutils.NewMockAgentRepository())
+	f.service = NewMacroService(
+		f.repo,
+		teamRepo,
+		f.tagRepo,
+		f.convRepo,
+		channelRepo,
+		cannedResponses,
+		nil,
+		nil,
+		f.emitter,
+	)
+	f.repo.Conversations = f.convRepo.Conversations
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	name := "Jane Doe"
+	user, err := userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "jane", DisplayName: &name})
+	require.NoError(t, err)
+	f.tagRepo.Create(&models.CreateTagRequest{OrganizationID: 1, Name: "billing"})
+	f.tagRepo.Create(&models.CreateTagRequest{OrganizationID: 2, Name: "refund"})
+
+	f.conv, err = f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: user.ID, Priority: models.PriorityNormal})
+	require.NoError(t, err)
+	return f
+}
+
+func (f *macroFixture) create(t *testing.T, actions ...models.MacroAction) *models.Macro {
+	macro, err := f.service.Create(context.Background(), &models.CreateMacroRequest{
+		OrganizationID: 1,
+		Name:           "Billing resolved",
+		Actions:        actions,
+	})
+	require.NoError(t, err)
+	return macro
+}
+
+func TestMacroService_Run(t *testing.T) {
+	f := newMacroFixture(t)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	response, _ := f.responseRepo.Create(&models.CreateCannedResponseRequest{OrganizationID: 1, Shortcut: "done", Title: "Done", Content: "All sorted, {{contact.first_name}}!"})
+	high, resolved := models.PriorityHigh, models.ConversationStatusResolved
+	macro := f.create(t,
+		models.MacroAction{Type: models.MacroActionSendReply, CannedResponseID: &response.ID},
+		models.MacroAction{Type: models.MacroActionAddTags, Tags: []string{"Billing"}},
+		models.MacroAction{Type: models.MacroActionSetPriority, Priority: &high},
+		models.MacroAction{Type: models.MacroActionSetStatus, Status: &resolved},
+	)
+
+	run, err := f.service.Run(ctx, f.conv.ID, macro.ID)
+	require.NoError(t, err)
+	assert.Nil(t, run.Error)
+
+	require.Len(t, f.repo.Applied, 4)
+	reply := f.repo.Applied[0]
+	assert.Equal(t, "All sorted, Jane!", reply.Reply)
+	require.NotNil(t, reply.Message)
+	require.Len(t, f.repo.Applied[1].Tags, 1)
+	assert.Equal(t, "billing", f.repo.Applied[1].Tags[0].Name)
+	assert.Equal(t, models.PriorityHigh, f.conv.Priority)
+	assert.Equal(t, models.ConversationStatusResolved, f.conv.Status)
+
+	// Each change is published once the run is applied, the status change
+	// attributed to the macro
+	time.Sleep(10 * time.Millisecond)
+	published := make(map[string][]testutils.EmittedEvent)
+	for _, event := range f.emitter.EmittedEvents {
+		published[event.EventType] = append(published[event.EventType], event)
+	}
+	require.Len(t, published[events.EventNewMessage], 1)
+	assert.Equal(t, "All sorted, Jane!", published[events.EventNewMessage][0].Payload["content"])
+	require.Len(t, published[events.EventConversationTagged], 1)
+	assert.Equal(t, []string{"billing"}, published[events.EventConversationTagged][0].Payload["added"])
+	require.Len(t, published[events.EventConversationUpdated], 2)
+	for _, event := range published[events.EventConversationUpdated] {
+		if event.Payload["status"] != nil {
+			assert.Equal(t, "open", event.Payload["previous_status"])
+			assert.Equal(t, "macro Billing resolved", event.Payload["reason"])
+		}
+	}
+
+	runs, err := f.service.ListRuns(ctx, macro.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "agent-1", *runs[0].ActorID)
+	assert.Equal(t, f.conv.ID, runs[0].ConversationID)
+	assert.Len(t, runs[0].Actions, 4)
+}
+
+func TestMacroService_RunRejected(t *testing.T) {
+	reply := "Closing this now"
+	high, resolved, pending := models.PriorityHigh, models.ConversationStatusResolved, models.ConversationStatusPending
+	missing := int64(99)
+
+	tests := []struct {
+		name     string
+		action   models.MacroAction
+		expected error
+	}{
+		{"unknown tag", models.MacroAction{Type: models.MacroActionAddTags, Tags: []string{"refund"}}, models.ErrUnknownTag},
+		{"invalid transition", models.MacroAction{Type: models.MacroActionSetStatus, Status: &pending}, models.ErrInvalidTransition},
+		{"missing canned response", models.MacroAction{Type: models.MacroActionSendReply, CannedResponseID: &missing}, models.ErrInvalidMacro},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMacroFixture(t)
+			// The failing action comes last, after a resolve
+			macro := f.create(t,
+				models.MacroAction{Type: models.MacroActionSendReply, Content: &reply},
+				models.MacroAction{Type: models.MacroActionSetPriority, Priority: &high},
+				models.MacroAction{Type: models.MacroActionSetStatus, Status: &resolved},
+				tt.action,
+			)
+
+			_, err := f.service.Run(context.Background(), f.conv.ID, macro.ID)
+			assert.ErrorIs(t, err, tt.expected)
+
+			// Nothing was applied
+			assert.Empty(t, f.repo.Applied)
+			assert.Equal(t, models.PriorityNormal, f.conv.Priority)
+			assert.Equal(t, models.ConversationStatusOpen, f.conv.Status)
+
+			require.Len(t, f.repo.Runs, 1)
+			assert.NotNil(t, f.repo.Runs[0].Error)
+		})
+	}
+
+	t.Run("other organizations' macros", func(t *testing.T) {
+		f := newMacroFixture(t)
+		macro, err := f.service.Create(context.Background(), &models.CreateMacroRequest{
+			OrganizationID: 2,
+			Name:           "Escalate",
+			Actions:        []models.MacroAction{{Type: models.MacroActionSetPriority, Priority: &high}},
+		})
+		require.NoError(t, err)
+
+		_, err = f.service.Run(context.Background(), f.conv.ID, macro.ID)
+		assert.ErrorIs(t, err, models.ErrInvalidMacro)
+		assert.Equal(t, models.PriorityNormal, f.conv.Priority)
+	})
+}
+
+func TestMacroService_RunFailed(t *testing.T) {
+	f := newMacroFixture(t)
+	reply := "Closing this now"
+	resolved := models.ConversationStatusResolved
+	macro := f.create(t,
+		models.MacroAction{Type: models.MacroActionSendReply, Content: &reply},
+		models.MacroAction{Type: models.MacroActionSetStatus, Status: &resolved},
+	)
+	f.repo.ApplyError = errors.New("database is locked")
+
+	_, err := f.service.Run(context.Background(), f.conv.ID, macro.ID)
+	assert.Error(t, err)
+
+	// Nothing is sent or published for a run that was rolled back
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, f.emitter.EmittedEvents)
+	require.Len(t, f.repo.Runs, 1)
+	require.NotNil(t, f.repo.Runs[0].Error)
+	assert.Equal(t, "database is locked", *f.repo.Runs[0].Error)
+}
+
+func TestMacroService_CreateChecksActions(t *testing.T) {
+	f := newMacroFixture(t)
+
+	_, err := f.service.Create(context.Background(), &models.CreateMacroRequest{
+		OrganizationID: 1,
+		Name:           "Broken",
+		Actions:        []models.MacroAction{{Type: models.MacroActionSetPriority}},
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidMacro)
+	assert.Empty(t, f.repo.Macros)
+}
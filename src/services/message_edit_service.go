@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// MessageEditService edits and deletes messages, by agents or by
+// customers on the platform. Agent changes to outbound messages are
+// published for the platform to apply when it still allows it.
+type MessageEditService interface {
+	// Edit changes an agent's outbound message. The platform must still
+	// let the message be edited.
+	Edit(ctx context.Context, id int64, req *models.EditMessageRequest) (*models.Message, error)
+	// Delete deletes a message, retracting it from the platform when it is
+	// outbound and the platform still allows it. Notes and activity
+	// messages cannot be deleted here.
+	Delete(ctx context.Context, id int64) error
+	// ListEdits lists the earlier versions of a message, including the
+	// content of a deleted message while its channel retains it
+	ListEdits(ctx context.Context, id int64) ([]*models.MessageEdit, error)
+	// ApplyPlatformEdit applies an edit the customer made on the platform
+	ApplyPlatformEdit(ctx context.Context, channelID int64, platformMessageID, content string, at time.Time) error
+	// ApplyPlatformDelete applies a message the customer deleted on the
+	// platform
+	ApplyPlatformDelete(ctx context.Context, channelID int64, platformMessageID string, at time.Time) error
+	// PurgeDeleted drops the content kept of deleted messages whose
+	// channel's retention has passed
+	PurgeDeleted(ctx context.Context, now time.Time) (int64, error)
+}
+
+type messageEditService struct {
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	emitter          events.Emitter
+}
+
+func NewMessageEditService(
+	messageRepo repositories.MessageRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	emitter events.Emitter,
+) MessageEditService {
+	return &messageEditService{
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *messageEditService) Edit(ctx context.Context, id int64, req *models.EditMessageRequest) (*models.Message, error) {
+	msg, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Direction != models.DirectionOutbound || msg.SenderType != models.SenderInternal || !changeable(msg) {
+		return nil, fmt.Errorf("%w: only agent replies can be edited", models.ErrMessageNotEditable)
+	}
+
+	conv, channel, err := conversationChannel(s.conversationRepo, s.channelRepo, msg.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !channel.Platform.CanEdit(msg.CreatedAt, now) {
+		return nil, fmt.Errorf("%w: %s does not allow editing this message", models.ErrMessageNotEditable, channel.Platform)
+	}
+
+	editedBy := optional(middleware.UserIDFromContext(ctx))
+	msg, err = s.messageRepo.Edit(id, req.Content, editedBy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	go events.Publish(ctx, s.emitter, events.MessageUpdatedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    conv.ID,
+		ChannelID:         conv.ChannelID,
+		PlatformMessageID: msg.PlatformMessageID,
+		Content:           msg.Content,
+		Direction:         string(msg.Direction),
+		EditedBy:          editedBy,
+		Propagate:         true,
+		EditedAt:          now,
+	})
+
+	return msg, nil
+}
+
+func (s *messageEditService) Delete(ctx context.Context, id int64) error {
+	msg, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if !changeable(msg) {
+		return fmt.Errorf("%w: %s messages cannot be deleted", models.ErrMessageNotEditable, msg.MessageType)
+	}
+
+	conv, channel, err := conversationChannel(s.conversationRepo, s.channelRepo, msg.ConversationID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	deletedBy := optional(middleware.UserIDFromContext(ctx))
+	if err := s.messageRepo.Delete(id, deletedBy, now, channel.RetainDeletedDays > 0); err != nil {
+		return err
+	}
+
+	// Customers' messages are only removed here; bots cannot delete them
+	propagate := msg.Direction == models.DirectionOutbound && channel.Platform.CanDelete(msg.CreatedAt, now)
+	go events.Publish(ctx, s.emitter, events.MessageDeletedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    conv.ID,
+		ChannelID:         conv.ChannelID,
+		PlatformMessageID: msg.PlatformMessageID,
+		Direction:         string(msg.Direction),
+		DeletedBy:         deletedBy,
+		Propagate:         propagate,
+		DeletedAt:         now,
+	})
+
+	return nil
+}
+
+func (s *messageEditService) ListEdits(ctx context.Context, id int64) ([]*models.MessageEdit, error) {
+	if _, err := s.messageRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.messageRepo.ListEdits(id)
+}
+
+func (s *messageEditService) ApplyPlatformEdit(ctx context.Context, channelID int64, platformMessageID, content string, at time.Time) error {
+	msg, err := s.platformMessage(channelID, platformMessageID)
+	if err != nil || msg == nil {
+		return err
+	}
+	if msg.Content == content {
+		return nil
+	}
+
+	msg, err = s.messageRepo.Edit(msg.ID, content, nil, at)
+	if err != nil {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.MessageUpdatedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    msg.ConversationID,
+		ChannelID:         channelID,
+		PlatformMessageID: msg.PlatformMessageID,
+		Content:           msg.Content,
+		Direction:         string(msg.Direction),
+		EditedAt:          at,
+	})
+
+	return nil
+}
+
+func (s *messageEditService) ApplyPlatformDelete(ctx context.Context, channelID int64, platformMessageID string, at time.Time) error {
+	msg, err := s.platformMessage(channelID, platformMessageID)
+	if err != nil || msg == nil {
+		return err
+	}
+
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return fmt.Errorf("channel not found")
+	}
+	if err := s.messageRepo.Delete(msg.ID, nil, at, channel.RetainDeletedDays > 0); err != nil {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.MessageDeletedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    msg.ConversationID,
+		ChannelID:         channelID,
+		PlatformMessageID: msg.PlatformMessageID,
+		Direction:         string(msg.Direction),
+		DeletedAt:         at,
+	})
+
+	return nil
+}
+
+func (s *messageEditService) PurgeDeleted(ctx context.Context, now time.Time) (int64, error) {
+	return s.messageRepo.PurgeDeleted(now)
+}
+
+// get gets a message that has not been deleted
+func (s *messageEditService) get(id int64) (*models.Message, error) {
+	msg, err := s.messageRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.DeletedAt != nil {
+		return nil, fmt.Errorf("message %w", models.ErrNotFound)
+	}
+	return msg, nil
+}
+
+// platformMessage gets the message a platform event refers to. It returns
+// nil for a message already deleted, whose later changes are ignored.
+func (s *messageEditService) platformMessage(channelID int64, platformMessageID string) (*models.Message, error) {
+	msg, err := s.messageRepo.FindByPlatformID(channelID, platformMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, fmt.Errorf("message %s %w", platformMessageID, models.ErrNotFound)
+	}
+	if msg.DeletedAt != nil {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// conversationChannel gets a conversation and the channel it is on
+func conversationChannel(conversationRepo repositories.ConversationRepository, channelRepo repositories.ChannelRepository, conversationID int64) (*models.Conversation, *models.ChatChannel, error) {
+	conv, err := conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conv == nil {
+		return nil, nil, fmt.Errorf("conversation not found")
+	}
+	channel, err := channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if channel == nil {
+		return nil, nil, fmt.Errorf("channel not found")
+	}
+	return conv, channel, nil
+}
+
+// changeable reports whether a message is one of the conversation with the
+// customer, rather than a note or an activity message
+func changeable(msg *models.Message) bool {
+	return msg.MessageType != models.MessageTypeNote && msg.MessageType != models.MessageTypeSystem
+}
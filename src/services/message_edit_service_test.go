@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type messageEditFixture struct {
+	service     MessageEditService
+	msgRepo     *testutils.MockMessageRepository
+	channelRepo *testutils.MockChannelRepository
+	emitter     *testutils.MockEmitter
+	conv        *models.Conversation
+}
+
+func newMessageEditFixture(t *testing.T, platform models.Platform) *messageEditFixture {
+	f := &messageEditFixture{
+		msgRepo:     testutils.NewMockMessageRepository(),
+		channelRepo: testutils.NewMockChannelRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	convRepo := testutils.NewMockConversationRepository()
+	f.service = NewMessageEditService(f.msgRepo, convRepo, f.channelRepo, f.emitter)
+
+	f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: platform, Name: "Channel"})
+	var err error
+	f.conv, err = convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	require.NoError(t, err)
+	return f
+}
+
+func (f *messageEditFixture) message(direction models.MessageDirection, messageType models.MessageType, age time.Duration) *models.Message {
+	platformID := "platform-msg"
+	sender := models.SenderInternal
+	if direction == models.DirectionInbound {
+		sender = models.SenderExternal
+	}
+	msg, _ := f.msgRepo.Create(&models.Message{
+		ConversationID:    f.conv.ID,
+		PlatformMessageID: &platformID,
+		SenderType:        sender,
+		Content:           "Your order ships Monday",
+		MessageType:       messageType,
+		Direction:         direction,
+		CreatedAt:         time.Now().Add(-age),
+	})
+	return msg
+}
+
+func (f *messageEditFixture) published(eventType string) []map[string]interface{} {
+	time.Sleep(10 * time.Millisecond)
+	var payloads []map[string]interface{}
+	for _, event := range f.emitter.EmittedEvents {
+		if event.EventType == eventType {
+			payloads = append(payloads, event.Payload)
+		}
+	}
+	return payloads
+}
+
+func TestMessageEditService_Edit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+	req := &models.EditMessageRequest{Content: "Your order ships Tuesday"}
+
+	t.Run("agent reply", func(t *testing.T) {
+		f := newMessageEditFixture(t, models.PlatformTelegram)
+		msg := f.message(models.DirectionOutbound, models.MessageTypeText, time.Hour)
+
+		edited, err := f.service.Edit(ctx, msg.ID, req)
+		require.NoError(t, err)
+		assert.Equal(t, "Your order ships Tuesday", edited.Content)
+
+		edits, err := f.service.ListEdits(ctx, msg.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, "Your order ships Monday", edits[0].Content)
+
+		payloads := f.published(events.EventMessageUpdated)
+		require.Len(t, payloads, 1)
+		assert.Equal(t, "Your order ships Tuesday", payloads[0]["content"])
+		assert.Equal(t, "agent-1", payloads[0]["edited_by"])
+		assert.Equal(t, true, payloads[0]["propagate"])
+	})
+
+	tests := []struct {
+		name        string
+		platform    models.Platform
+		direction   models.MessageDirection
+		messageType models.MessageType
+		age         time.Duration
+	}{
+		{"platform without edits", models.PlatformEmail, models.DirectionOutbound, models.MessageTypeText, time.Minute},
+		{"past the platform's window", models.PlatformWhatsApp, models.DirectionOutbound, models.MessageTypeText, time.Hour},
+		{"customer message", models.PlatformTelegram, models.DirectionInbound, models.MessageTypeText, time.Minute},
+		{"note", models.PlatformTelegram, models.DirectionOutbound, models.MessageTypeNote, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMessageEditFixture(t, tt.platform)
+			msg := f.message(tt.direction, tt.messageType, tt.age)
+
+			_, err := f.service.Edit(ctx, msg.ID, req)
+			assert.ErrorIs(t, err, models.ErrMessageNotEditable)
+			assert.Equal(t, "Your order ships Monday", msg.Content)
+			assert.Empty(t, f.published(events.EventMessageUpdated))
+		})
+	}
+}
+
+func TestMessageEditService_Delete(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	tests := []struct {
+		name      string
+		direction models.MessageDirection
+		age       time.Duration
+		propagate bool
+	}{
+		{"recent reply is retracted", models.DirectionOutbound, time.Hour, true},
+		{"old reply is only deleted here", models.DirectionOutbound, 72 * time.Hour, false},
+		{"customer message is only deleted here", models.DirectionInbound, time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMessageEditFixture(t, models.PlatformWhatsApp)
+			msg := f.message(tt.direction, models.MessageTypeText, tt.age)
+
+			require.NoError(t, f.service.Delete(ctx, msg.ID))
+			assert.NotNil(t, msg.DeletedAt)
+			assert.Empty(t, msg.Content)
+
+			payloads := f.published(events.EventMessageDeleted)
+			require.Len(t, payloads, 1)
+			assert.Equal(t, "agent-1", payloads[0]["deleted_by"])
+			assert.Equal(t, tt.propagate, payloads[0]["propagate"])
+
+			assert.ErrorIs(t, f.service.Delete(ctx, msg.ID), models.ErrNotFound)
+		})
+	}
+
+	t.Run("unknown messages", func(t *testing.T) {
+		f := newMessageEditFixture(t, models.PlatformWhatsApp)
+		assert.ErrorIs(t, f.service.Delete(ctx, 99), models.ErrNotFound)
+		_, err := f.service.ListEdits(ctx, 99)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("retention keeps the content", func(t *testing.T) {
+		f := newMessageEditFixture(t, models.PlatformWhatsApp)
+		days := 30
+		require.NoError(t, f.channelRepo.Update(1, &models.UpdateChannelRequest{RetainDeletedDays: &days}))
+		msg := f.message(models.DirectionOutbound, models.MessageTypeText, time.Hour)
+
+		require.NoError(t, f.service.Delete(ctx, msg.ID))
+		edits, err := f.service.ListEdits(ctx, msg.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, "Your order ships Monday", edits[0].Content)
+	})
+
+	t.Run("activity messages", func(t *testing.T) {
+		f := newMessageEditFixture(t, models.PlatformWhatsApp)
+		msg := f.message(models.DirectionOutbound, models.MessageTypeSystem, time.Minute)
+		assert.ErrorIs(t, f.service.Delete(ctx, msg.ID), models.ErrMessageNotEditable)
+	})
+}
+
+func TestMessageEditService_PlatformChanges(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageEditFixture(t, models.PlatformTelegram)
+	msg := f.message(models.DirectionInbound, models.MessageTypeText, time.Minute)
+	at := time.Now()
+
+	require.NoError(t, f.service.ApplyPlatformEdit(ctx, 1, "platform-msg", "Actually, Wednesday", at))
+	assert.Equal(t, "Actually, Wednesday", msg.Content)
+
+	updated := f.published(events.EventMessageUpdated)
+	require.Len(t, updated, 1)
+	assert.Nil(t, updated[0]["edited_by"])
+	assert.Equal(t, false, updated[0]["propagate"])
+
+	require.NoError(t, f.service.ApplyPlatformDelete(ctx, 1, "platform-msg", at))
+	assert.NotNil(t, msg.DeletedAt)
+	assert.Len(t, f.published(events.EventMessageDeleted), 1)
+
+	// Changes to deleted messages are ignored
+	require.NoError(t, f.service.ApplyPlatformEdit(ctx, 1, "platform-msg", "Thursday", at))
+	assert.Empty(t, msg.Content)
+
+	assert.ErrorIs(t, f.service.ApplyPlatformEdit(ctx, 1, "unknown", "Thursday", at), models.ErrNotFound)
+}
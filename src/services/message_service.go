@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,26 +12,35 @@ import (
 )
 
 type MessageService interface {
-	ProcessIncomingMessage(req *ProcessIncomingMessageRequest) (*models.Message, error)
-	SendOutgoingMessage(req *SendOutgoingMessageRequest) (*models.Message, error)
-	GetMessageHistory(conversationID int64, limit, offset int, before *int64) ([]*models.Message, error)
-	MarkDelivered(messageID int64) error
-	MarkRead(messageID int64) error
+	ProcessIncomingMessage(ctx context.Context, req *ProcessIncomingMessageRequest) (*models.Message, error)
+	SendOutgoingMessage(ctx context.Context, req *SendOutgoingMessageRequest) (*models.Message, error)
+	GetMessageHistory(ctx context.Context, query *models.MessageListQuery) ([]*models.Message, error)
+	MarkDelivered(ctx context.Context, messageID int64) error
+	MarkRead(ctx context.Context, messageID int64) error
 }
 
+// ProcessIncomingMessageRequest is a message from a customer. PlatformChatID
+// is set for messages in a group chat or email thread, and CC lists the
+// platform user IDs copied on an email. ReplyToPlatformID is the platform
+// message ID of the message the customer replied to.
 type ProcessIncomingMessageRequest struct {
 	ChannelID         int64
 	PlatformMessageID string
 	PlatformUserID    string
+	PlatformChatID    string
 	UserDisplayName   string
 	UserPhone         *string
 	UserEmail         *string
+	CC                []string
+	ReplyToPlatformID string
 	Content           string
 	MessageType       models.MessageType
 	MediaURL          *string
 	Metadata          *string
 }
 
+// SendOutgoingMessageRequest is a reply to the customer. ReplyTo is the
+// message of the conversation it replies to.
 type SendOutgoingMessageRequest struct {
 	ConversationID int64
 	Content        string
@@ -38,6 +48,7 @@ type SendOutgoingMessageRequest struct {
 	MediaURL       *string
 	SenderID       *int64
 	Metadata       *string
+	ReplyTo        *int64
 }
 
 type messageService struct {
@@ -45,6 +56,12 @@ type messageService struct {
 	conversationRepo repositories.ConversationRepository
 	externalUserRepo repositories.ExternalUserRepository
 	emitter          events.Emitter
+	routing          RoutingService
+	sla              SLAService
+	lifecycle        LifecycleService
+	csat             CSATService
+	hours            BusinessHoursService
+	participants     ParticipantService
 }
 
 func NewMessageService(
@@ -52,16 +69,28 @@ func NewMessageService(
 	conversationRepo repositories.ConversationRepository,
 	externalUserRepo repositories.ExternalUserRepository,
 	emitter events.Emitter,
+	routing RoutingService,
+	sla SLAService,
+	lifecycle LifecycleService,
+	csat CSATService,
+	hours BusinessHoursService,
+	participants ParticipantService,
 ) MessageService {
 	return &messageService{
 		messageRepo:      messageRepo,
 		conversationRepo: conversationRepo,
 		externalUserRepo: externalUserRepo,
 		emitter:          emitter,
+		routing:          routing,
+		sla:              sla,
+		lifecycle:        lifecycle,
+		csat:             csat,
+		hours:            hours,
+		participants:     participants,
 	}
 }
 
-func (s *messageService) ProcessIncomingMessage(req *ProcessIncomingMessageRequest) (*models.Message, error) {
+func (s *messageService) ProcessIncomingMessage(ctx context.Context, req *ProcessIncomingMessageRequest) (*models.Message, error) {
 
 	user, err := s.externalUserRepo.FindOrCreate(&models.CreateExternalUserRequest{
 		ChannelID:      req.ChannelID,
@@ -74,14 +103,113 @@ func (s *messageService) ProcessIncomingMessage(req *ProcessIncomingMessageReque
 		return nil, fmt.Errorf("failed to find/create user: %w", err)
 	}
 
-	conversation, err := s.conversationRepo.GetOrCreateByUser(req.ChannelID, user.ID)
+	// A rating answering a satisfaction survey is kept in the surveyed
+	// conversation instead of reopening it or starting a new one
+	if s.csat != nil && req.MessageType == models.MessageTypeText {
+		survey, err := s.csat.CaptureReply(ctx, req.ChannelID, user.ID, req.Content, time.Now())
+		if err != nil {
+
+			fmt.Printf("Warning: failed to capture CSAT reply: %v\n", err)
+		} else if survey != nil {
+			return s.saveIncoming(ctx, req, user, survey.ConversationID)
+		}
+	}
+
+	var conversation *models.Conversation
+	var created bool
+	if s.lifecycle != nil {
+		conversation, created, err = s.lifecycle.ConversationFor(ctx, req.ChannelID, user.ID, req.PlatformChatID, time.Now())
+	} else {
+		conversation, created, err = getOrCreateConversation(s.conversationRepo, req.ChannelID, user.ID, req.PlatformChatID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get/create conversation: %w", err)
 	}
 
+	savedMessage, err := s.saveIncoming(ctx, req, user, conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.participants != nil {
+		s.trackParticipants(ctx, req, user, conversation, savedMessage.CreatedAt)
+	}
+
+	if s.hours != nil {
+		if _, err := s.hours.AwayReply(ctx, conversation, savedMessage.CreatedAt); err != nil {
+
+			fmt.Printf("Warning: failed to send away reply: %v\n", err)
+		}
+	}
+
+	if s.sla != nil {
+		if created {
+			err = s.sla.Apply(ctx, conversation)
+		} else {
+			err = s.sla.CustomerMessage(ctx, conversation, savedMessage.CreatedAt)
+		}
+		if err != nil {
+
+			fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+		}
+	}
+
+	if created && s.routing != nil {
+		if err := s.routing.RouteNew(ctx, conversation); err != nil {
+
+			fmt.Printf("Warning: failed to route conversation: %v\n", err)
+		}
+	}
+
+	return savedMessage, nil
+}
+
+// getOrCreateConversation returns the open conversation of a group chat, or
+// of the user when platformChatID is empty
+func getOrCreateConversation(repo repositories.ConversationRepository, channelID, externalUserID int64, platformChatID string) (*models.Conversation, bool, error) {
+	if platformChatID != "" {
+		return repo.GetOrCreateByChat(channelID, platformChatID, externalUserID)
+	}
+	return repo.GetOrCreateByUser(channelID, externalUserID)
+}
+
+// trackParticipants records the sender of a message and anyone copied on
+// it as taking part in the conversation
+func (s *messageService) trackParticipants(ctx context.Context, req *ProcessIncomingMessageRequest, sender *models.ExternalUser, conv *models.Conversation, at time.Time) {
+	role := models.ParticipantRoleMember
+	if sender.ID == conv.ExternalUserID {
+		role = models.ParticipantRoleRequester
+	}
+	if err := s.participants.Join(ctx, conv, sender.ID, role, models.ParticipantReasonMessage, at); err != nil {
+
+		fmt.Printf("Warning: failed to record participant: %v\n", err)
+	}
+
+	for _, platformUserID := range req.CC {
+		if platformUserID == "" || platformUserID == req.PlatformUserID {
+			continue
+		}
+		user, err := s.externalUserRepo.FindOrCreate(&models.CreateExternalUserRequest{
+			ChannelID:      req.ChannelID,
+			PlatformUserID: platformUserID,
+		})
+		if err == nil {
+			err = s.participants.Join(ctx, conv, user.ID, models.ParticipantRoleCC, models.ParticipantReasonMessage, at)
+		}
+		if err != nil {
+
+			fmt.Printf("Warning: failed to record CC participant: %v\n", err)
+		}
+	}
+}
+
+// saveIncoming stores a customer's message in a conversation and publishes it
+func (s *messageService) saveIncoming(ctx context.Context, req *ProcessIncomingMessageRequest, user *models.ExternalUser, conversationID int64) (*models.Message, error) {
+	parent := s.repliedTo(req)
 	message := &models.Message{
-		ConversationID:    conversation.ID,
+		ConversationID:    conversationID,
 		PlatformMessageID: &req.PlatformMessageID,
+		ReplyToMessageID:  messageID(parent),
 		SenderType:        models.SenderExternal,
 		SenderID:          &user.ID,
 		Content:           req.Content,
@@ -98,7 +226,7 @@ func (s *messageService) ProcessIncomingMessage(req *ProcessIncomingMessageReque
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
-	if err := s.conversationRepo.UpdateLastMessage(conversation.ID); err != nil {
+	if err := s.conversationRepo.UpdateLastMessage(conversationID); err != nil {
 
 		fmt.Printf("Warning: failed to update conversation last message: %v\n", err)
 	}
@@ -108,21 +236,52 @@ func (s *messageService) ProcessIncomingMessage(req *ProcessIncomingMessageReque
 		fmt.Printf("Warning: failed to update user last seen: %v\n", err)
 	}
 
-	go s.emitter.Emit(events.EventNewMessage, map[string]interface{}{
-		"message_id":       savedMessage.ID,
-		"conversation_id":  conversation.ID,
-		"channel_id":       req.ChannelID,
-		"external_user_id": user.ID,
-		"content":          req.Content,
-		"message_type":     req.MessageType,
-		"direction":        models.DirectionInbound,
-		"timestamp":        savedMessage.CreatedAt,
+	sender := &events.Sender{
+		ExternalUserID: user.ID,
+		PlatformUserID: user.PlatformUserID,
+		DisplayName:    user.DisplayName,
+	}
+	var platformChatID *string
+	if req.PlatformChatID != "" {
+		platformChatID = &req.PlatformChatID
+	}
+	go events.Publish(ctx, s.emitter, events.MessageNewPayload{
+		MessageID:      savedMessage.ID,
+		ConversationID: conversationID,
+		ChannelID:      req.ChannelID,
+		ExternalUserID: &user.ID,
+		PlatformChatID: platformChatID,
+		Sender:         sender,
+		Content:        req.Content,
+		MessageType:    string(req.MessageType),
+		Direction:      string(models.DirectionInbound),
+		ReplyTo:        messageRef(parent),
+		Timestamp:      savedMessage.CreatedAt,
 	})
 
 	return savedMessage, nil
 }
 
-func (s *messageService) SendOutgoingMessage(req *SendOutgoingMessageRequest) (*models.Message, error) {
+// repliedTo finds the message a customer replied to by its platform message
+// ID. A reply to a message the service never stored is kept unlinked, as is
+// one whose lookup failed; only the failure is logged.
+func (s *messageService) repliedTo(req *ProcessIncomingMessageRequest) *models.Message {
+	if req.ReplyToPlatformID == "" {
+		return nil
+	}
+	parent, err := s.messageRepo.FindByPlatformID(req.ChannelID, req.ReplyToPlatformID)
+	if err != nil {
+
+		fmt.Printf("Warning: failed to find replied-to message %s: %v\n", req.ReplyToPlatformID, err)
+		return nil
+	}
+	return parent
+}
+
+func (s *messageService) SendOutgoingMessage(ctx context.Context, req *SendOutgoingMessageRequest) (*models.Message, error) {
+	if req.MessageType == "" {
+		req.MessageType = models.MessageTypeText
+	}
 
 	conversation, err := s.conversationRepo.GetByID(req.ConversationID)
 	if err != nil {
@@ -132,6 +291,14 @@ func (s *messageService) SendOutgoingMessage(req *SendOutgoingMessageRequest) (*
 		return nil, fmt.Errorf("conversation not found")
 	}
 
+	var parent *models.Message
+	if req.ReplyTo != nil {
+		parent, err = s.replyTarget(conversation.ID, *req.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	message := &models.Message{
 		ConversationID: req.ConversationID,
 		SenderType:     models.SenderInternal,
@@ -144,6 +311,7 @@ func (s *messageService) SendOutgoingMessage(req *SendOutgoingMessageRequest) (*
 		CreatedAt:      time.Now(),
 		Metadata:       req.Metadata,
 	}
+	message.ReplyToMessageID = messageID(parent)
 
 	savedMessage, err := s.messageRepo.Create(message)
 	if err != nil {
@@ -154,45 +322,114 @@ func (s *messageService) SendOutgoingMessage(req *SendOutgoingMessageRequest) (*
 		fmt.Printf("Warning: failed to update conversation: %v\n", err)
 	}
 
-	go s.emitter.Emit(events.EventNewMessage, map[string]interface{}{
-		"message_id":      savedMessage.ID,
-		"conversation_id": conversation.ID,
-		"channel_id":      conversation.ChannelID,
-		"content":         req.Content,
-		"message_type":    req.MessageType,
-		"direction":       models.DirectionOutbound,
-		"timestamp":       savedMessage.CreatedAt,
+	if s.sla != nil {
+		if err := s.sla.AgentReply(ctx, conversation, savedMessage.CreatedAt); err != nil {
+			fmt.Printf("Warning: failed to update conversation SLA: %v\n", err)
+		}
+	}
+
+	go events.Publish(ctx, s.emitter, events.MessageNewPayload{
+		MessageID:      savedMessage.ID,
+		ConversationID: conversation.ID,
+		ChannelID:      conversation.ChannelID,
+		Content:        req.Content,
+		MessageType:    string(req.MessageType),
+		Direction:      string(models.DirectionOutbound),
+		ReplyTo:        messageRef(parent),
+		Timestamp:      savedMessage.CreatedAt,
 	})
 
 	return savedMessage, nil
 }
 
-func (s *messageService) GetMessageHistory(conversationID int64, limit, offset int, before *int64) ([]*models.Message, error) {
-	return s.messageRepo.ListByConversation(conversationID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset), before)
+// replyTarget gets the message an agent replies to, which must be a message
+// of the conversation with the customer that has not been deleted
+func (s *messageService) replyTarget(conversationID, id int64) (*models.Message, error) {
+	parent, err := s.messageRepo.GetByID(id)
+	if err != nil || parent == nil || parent.ConversationID != conversationID {
+		return nil, fmt.Errorf("%w: message %d is not in the conversation", models.ErrInvalidReply, id)
+	}
+	if !changeable(parent) || parent.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: message %d cannot be replied to", models.ErrInvalidReply, id)
+	}
+	return parent, nil
 }
 
-func (s *messageService) MarkDelivered(messageID int64) error {
+func messageID(msg *models.Message) *int64 {
+	if msg == nil {
+		return nil
+	}
+	return &msg.ID
+}
+
+func messageRef(msg *models.Message) *events.MessageRef {
+	if msg == nil {
+		return nil
+	}
+	return &events.MessageRef{
+		MessageID:         msg.ID,
+		PlatformMessageID: msg.PlatformMessageID,
+	}
+}
+
+func (s *messageService) GetMessageHistory(ctx context.Context, query *models.MessageListQuery) ([]*models.Message, error) {
+	query.Limit = utils.NormalizeLimit(query.Limit)
+	query.Offset = utils.NormalizeOffset(query.Offset)
+	return s.messageRepo.ListHistory(query)
+}
+
+func (s *messageService) MarkDelivered(ctx context.Context, messageID int64) error {
 	if err := s.messageRepo.UpdateStatus(messageID, models.MessageStatusDelivered); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventMessageDelivered, map[string]interface{}{
-		"message_id": messageID,
-		"status":     models.MessageStatusDelivered,
+	go events.Publish(ctx, s.emitter, events.MessageDeliveredPayload{
+		MessageID: messageID,
+		Status:    string(models.MessageStatusDelivered),
 	})
 
 	return nil
 }
 
-func (s *messageService) MarkRead(messageID int64) error {
+func (s *messageService) MarkRead(ctx context.Context, messageID int64) error {
 	if err := s.messageRepo.UpdateStatus(messageID, models.MessageStatusRead); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventMessageRead, map[string]interface{}{
-		"message_id": messageID,
-		"status":     models.MessageStatusRead,
+	go events.Publish(ctx, s.emitter, events.MessageReadPayload{
+		MessageID: messageID,
+		Status:    string(models.MessageStatusRead),
 	})
 
 	return nil
 }
+
+// sendSystemMessage stores an automated message in a conversation and
+// publishes it for delivery to the customer
+func sendSystemMessage(ctx context.Context, messageRepo repositories.MessageRepository, emitter events.Emitter, conversationID, channelID int64, content string, quickReplies []events.QuickReply) (*models.Message, error) {
+	message, err := messageRepo.Create(&models.Message{
+		ConversationID: conversationID,
+		SenderType:     models.SenderSystem,
+		Content:        content,
+		MessageType:    models.MessageTypeText,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	go events.Publish(ctx, emitter, events.MessageNewPayload{
+		MessageID:      message.ID,
+		ConversationID: conversationID,
+		ChannelID:      channelID,
+		Content:        content,
+		MessageType:    string(models.MessageTypeText),
+		Direction:      string(models.DirectionOutbound),
+		QuickReplies:   quickReplies,
+		Timestamp:      message.CreatedAt,
+	})
+
+	return message, nil
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
 
@@ -17,7 +19,7 @@ func TestMessageService_ProcessIncomingMessage(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	req := &ProcessIncomingMessageRequest{
 		ChannelID:         1,
@@ -28,7 +30,7 @@ func TestMessageService_ProcessIncomingMessage(t *testing.T) {
 		MessageType:       models.MessageTypeText,
 	}
 
-	msg, err := service.ProcessIncomingMessage(req)
+	msg, err := service.ProcessIncomingMessage(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, msg)
 	assert.Equal(t, "Hello, world!", msg.Content)
@@ -51,7 +53,7 @@ func TestMessageService_ProcessIncomingMessage_ExistingUser(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Create existing user
 	displayName := "John Doe"
@@ -70,7 +72,7 @@ func TestMessageService_ProcessIncomingMessage_ExistingUser(t *testing.T) {
 		MessageType:       models.MessageTypeText,
 	}
 
-	msg, err := service.ProcessIncomingMessage(req)
+	msg, err := service.ProcessIncomingMessage(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, msg)
 
@@ -84,7 +86,7 @@ func TestMessageService_ProcessIncomingMessage_UserRepoError(t *testing.T) {
 	userRepo := testutils.NewMockExternalUserRepository()
 	userRepo.GetError = errors.New("database error")
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	req := &ProcessIncomingMessageRequest{
 		ChannelID:         1,
@@ -95,7 +97,7 @@ func TestMessageService_ProcessIncomingMessage_UserRepoError(t *testing.T) {
 		MessageType:       models.MessageTypeText,
 	}
 
-	_, err := service.ProcessIncomingMessage(req)
+	_, err := service.ProcessIncomingMessage(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to find/create user")
 }
@@ -106,7 +108,7 @@ func TestMessageService_ProcessIncomingMessage_ConvRepoError(t *testing.T) {
 	convRepo.GetError = errors.New("database error")
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	req := &ProcessIncomingMessageRequest{
 		ChannelID:         1,
@@ -117,7 +119,7 @@ func TestMessageService_ProcessIncomingMessage_ConvRepoError(t *testing.T) {
 		MessageType:       models.MessageTypeText,
 	}
 
-	_, err := service.ProcessIncomingMessage(req)
+	_, err := service.ProcessIncomingMessage(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get/create conversation")
 }
@@ -128,7 +130,7 @@ func TestMessageService_ProcessIncomingMessage_MsgRepoError(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	req := &ProcessIncomingMessageRequest{
 		ChannelID:         1,
@@ -139,7 +141,7 @@ func TestMessageService_ProcessIncomingMessage_MsgRepoError(t *testing.T) {
 		MessageType:       models.MessageTypeText,
 	}
 
-	_, err := service.ProcessIncomingMessage(req)
+	_, err := service.ProcessIncomingMessage(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create message")
 }
@@ -149,7 +151,7 @@ func TestMessageService_SendOutgoingMessage(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Create a conversation first
 	conv, _ := convRepo.Create(&models.CreateConversationRequest{
@@ -164,7 +166,7 @@ func TestMessageService_SendOutgoingMessage(t *testing.T) {
 		MessageType:    models.MessageTypeText,
 	}
 
-	msg, err := service.SendOutgoingMessage(req)
+	msg, err := service.SendOutgoingMessage(context.Background(), req)
 	require.NoError(t, err)
 	assert.NotNil(t, msg)
 	assert.Equal(t, "Hello from agent!", msg.Content)
@@ -181,7 +183,7 @@ func TestMessageService_SendOutgoingMessage_ConversationNotFound(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	req := &SendOutgoingMessageRequest{
 		ConversationID: 999,
@@ -189,7 +191,7 @@ func TestMessageService_SendOutgoingMessage_ConversationNotFound(t *testing.T) {
 		MessageType:    models.MessageTypeText,
 	}
 
-	_, err := service.SendOutgoingMessage(req)
+	_, err := service.SendOutgoingMessage(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "conversation not found")
 }
@@ -200,7 +202,7 @@ func TestMessageService_SendOutgoingMessage_MsgRepoError(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Create a conversation first
 	conv, _ := convRepo.Create(&models.CreateConversationRequest{
@@ -215,7 +217,7 @@ func TestMessageService_SendOutgoingMessage_MsgRepoError(t *testing.T) {
 		MessageType:    models.MessageTypeText,
 	}
 
-	_, err := service.SendOutgoingMessage(req)
+	_, err := service.SendOutgoingMessage(context.Background(), req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create message")
 }
@@ -225,7 +227,7 @@ func TestMessageService_GetMessageHistory(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Add some messages
 	msgRepo.Create(&models.Message{
@@ -239,7 +241,7 @@ func TestMessageService_GetMessageHistory(t *testing.T) {
 		MessageType:    models.MessageTypeText,
 	})
 
-	msgs, err := service.GetMessageHistory(1, 10, 0, nil)
+	msgs, err := service.GetMessageHistory(context.Background(), &models.MessageListQuery{ConversationID: 1, Limit: 10})
 	require.NoError(t, err)
 	assert.Len(t, msgs, 2)
 }
@@ -249,15 +251,15 @@ func TestMessageService_GetMessageHistory_DefaultLimit(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Test with invalid limit (should default to 50)
-	msgs, err := service.GetMessageHistory(1, 0, 0, nil)
+	msgs, err := service.GetMessageHistory(context.Background(), &models.MessageListQuery{ConversationID: 1})
 	require.NoError(t, err)
 	assert.NotNil(t, msgs)
 
 	// Test with limit > 100 (should default to 50)
-	msgs, err = service.GetMessageHistory(1, 200, 0, nil)
+	msgs, err = service.GetMessageHistory(context.Background(), &models.MessageListQuery{ConversationID: 1, Limit: 200})
 	require.NoError(t, err)
 	assert.NotNil(t, msgs)
 }
@@ -267,7 +269,7 @@ func TestMessageService_MarkDelivered(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Create a message first
 	msg, _ := msgRepo.Create(&models.Message{
@@ -276,7 +278,7 @@ func TestMessageService_MarkDelivered(t *testing.T) {
 		Status:         models.MessageStatusSent,
 	})
 
-	err := service.MarkDelivered(msg.ID)
+	err := service.MarkDelivered(context.Background(), msg.ID)
 	require.NoError(t, err)
 
 	// Verify status update
@@ -294,9 +296,9 @@ func TestMessageService_MarkDelivered_RepoError(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
-	err := service.MarkDelivered(1)
+	err := service.MarkDelivered(context.Background(), 1)
 	assert.Error(t, err)
 }
 
@@ -305,7 +307,7 @@ func TestMessageService_MarkRead(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
 	// Create a message first
 	msg, _ := msgRepo.Create(&models.Message{
@@ -314,7 +316,7 @@ func TestMessageService_MarkRead(t *testing.T) {
 		Status:         models.MessageStatusDelivered,
 	})
 
-	err := service.MarkRead(msg.ID)
+	err := service.MarkRead(context.Background(), msg.ID)
 	require.NoError(t, err)
 
 	// Verify status update
@@ -332,8 +334,116 @@ func TestMessageService_MarkRead_RepoError(t *testing.T) {
 	convRepo := testutils.NewMockConversationRepository()
 	userRepo := testutils.NewMockExternalUserRepository()
 	emitter := testutils.NewMockEmitter()
-	service := NewMessageService(msgRepo, convRepo, userRepo, emitter)
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
 
-	err := service.MarkRead(1)
+	err := service.MarkRead(context.Background(), 1)
 	assert.Error(t, err)
 }
+
+func TestMessageService_Replies(t *testing.T) {
+	msgRepo := testutils.NewMockMessageRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	userRepo := testutils.NewMockExternalUserRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewMessageService(msgRepo, convRepo, userRepo, emitter, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	first, err := service.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+		ChannelID:         1,
+		PlatformMessageID: "wamid-1",
+		PlatformUserID:    "user-456",
+		Content:           "Where is my order?",
+		MessageType:       models.MessageTypeText,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, first.ReplyToMessageID)
+
+	t.Run("inbound reply resolves the platform context", func(t *testing.T) {
+		msg, err := service.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+			ChannelID:         1,
+			PlatformMessageID: "wamid-2",
+			PlatformUserID:    "user-456",
+			ReplyToPlatformID: "wamid-1",
+			Content:           "It was due yesterday",
+			MessageType:       models.MessageTypeText,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, msg.ReplyToMessageID)
+		assert.Equal(t, first.ID, *msg.ReplyToMessageID)
+	})
+
+	t.Run("reply to an unknown message is kept unlinked", func(t *testing.T) {
+		msg, err := service.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+			ChannelID:         1,
+			PlatformMessageID: "wamid-3",
+			PlatformUserID:    "user-456",
+			ReplyToPlatformID: "wamid-0",
+			Content:           "Any news?",
+			MessageType:       models.MessageTypeText,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, msg.ReplyToMessageID)
+	})
+
+	t.Run("reply is kept unlinked when the lookup fails", func(t *testing.T) {
+		msgRepo.GetError = errors.New("database is locked")
+		defer func() { msgRepo.GetError = nil }()
+
+		msg, err := service.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
+			ChannelID:         1,
+			PlatformMessageID: "wamid-4",
+			PlatformUserID:    "user-456",
+			ReplyToPlatformID: "wamid-1",
+			Content:           "Hello?",
+			MessageType:       models.MessageTypeText,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, msg.ReplyToMessageID)
+	})
+
+	t.Run("outbound reply is passed through", func(t *testing.T) {
+		time.Sleep(10 * time.Millisecond)
+		emitter.EmittedEvents = nil
+		msg, err := service.SendOutgoingMessage(ctx, &SendOutgoingMessageRequest{
+			ConversationID: first.ConversationID,
+			Content:        "It ships today",
+			ReplyTo:        &first.ID,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, msg.ReplyToMessageID)
+		assert.Equal(t, first.ID, *msg.ReplyToMessageID)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 1)
+		replyTo, ok := emitter.EmittedEvents[0].Payload["reply_to"].(events.MessageRef)
+		require.True(t, ok)
+		assert.Equal(t, first.ID, replyTo.MessageID)
+		assert.Equal(t, "wamid-1", *replyTo.PlatformMessageID)
+	})
+
+	t.Run("outbound reply to another conversation", func(t *testing.T) {
+		other, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 2})
+		_, err := service.SendOutgoingMessage(ctx, &SendOutgoingMessageRequest{
+			ConversationID: other.ID,
+			Content:        "It ships today",
+			ReplyTo:        &first.ID,
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidReply)
+	})
+
+	t.Run("outbound reply to a note", func(t *testing.T) {
+		note, _ := msgRepo.Create(&models.Message{
+			ConversationID: first.ConversationID,
+			SenderType:     models.SenderInternal,
+			Content:        "Check with the warehouse",
+			MessageType:    models.MessageTypeNote,
+			Direction:      models.DirectionOutbound,
+		})
+		_, err := service.SendOutgoingMessage(ctx, &SendOutgoingMessageRequest{
+			ConversationID: first.ConversationID,
+			Content:        "It ships today",
+			ReplyTo:        &note.ID,
+		})
+		assert.ErrorIs(t, err, models.ErrInvalidReply)
+	})
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// mentionPattern matches @external_id mentions that do not follow a word
+// character, so email addresses are not taken for mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]+)`)
+
+// NoteService manages internal notes: messages between agents that are
+// kept in the conversation but never delivered to the customer
+type NoteService interface {
+	// Create leaves a note as the authenticated agent and notifies the
+	// agents it mentions
+	Create(ctx context.Context, conversationID int64, req *models.CreateNoteRequest) (*models.Message, error)
+	// Update edits a note, keeping its previous content, and notifies
+	// agents newly mentioned. Only the author may edit or delete a note.
+	Update(ctx context.Context, id int64, req *models.UpdateNoteRequest) (*models.Message, error)
+	Delete(ctx context.Context, id int64) error
+	ListEdits(ctx context.Context, id int64) ([]*models.MessageEdit, error)
+}
+
+type noteService struct {
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	agentRepo        repositories.AgentRepository
+	emitter          events.Emitter
+}
+
+func NewNoteService(
+	messageRepo repositories.MessageRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	agentRepo repositories.AgentRepository,
+	emitter events.Emitter,
+) NoteService {
+	return &noteService{
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		agentRepo:        agentRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *noteService) Create(ctx context.Context, conversationID int64, req *models.CreateNoteRequest) (*models.Message, error) {
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+
+	note, err := s.messageRepo.Create(&models.Message{
+		ConversationID: conv.ID,
+		SenderType:     models.SenderInternal,
+		AuthorID:       optional(middleware.UserIDFromContext(ctx)),
+		Content:        req.Content,
+		MessageType:    models.MessageTypeNote,
+		Direction:      models.DirectionOutbound,
+		Status:         models.MessageStatusSent,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	s.notifyMentions(ctx, conv, note, nil)
+	return note, nil
+}
+
+func (s *noteService) Update(ctx context.Context, id int64, req *models.UpdateNoteRequest) (*models.Message, error) {
+	note, err := s.authored(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]bool)
+	for _, externalID := range mentions(note.Content) {
+		previous[externalID] = true
+	}
+
+	note, err = s.messageRepo.Edit(id, req.Content, optional(middleware.UserIDFromContext(ctx)), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := s.conversationRepo.GetByID(note.ConversationID)
+	if err != nil || conv == nil {
+
+		fmt.Printf("Warning: failed to get conversation %d for note mentions: %v\n", note.ConversationID, err)
+		return note, nil
+	}
+	s.notifyMentions(ctx, conv, note, previous)
+	return note, nil
+}
+
+func (s *noteService) Delete(ctx context.Context, id int64) error {
+	note, err := s.authored(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	retain := false
+	conv, err := s.conversationRepo.GetByID(note.ConversationID)
+	if err != nil {
+		return err
+	}
+	if conv != nil {
+		channel, err := s.channelRepo.GetByID(conv.ChannelID)
+		if err != nil {
+			return err
+		}
+		retain = channel != nil && channel.RetainDeletedDays > 0
+	}
+	return s.messageRepo.Delete(id, optional(middleware.UserIDFromContext(ctx)), time.Now(), retain)
+}
+
+func (s *noteService) ListEdits(ctx context.Context, id int64) ([]*models.MessageEdit, error) {
+	if _, err := s.get(id); err != nil {
+		return nil, err
+	}
+	return s.messageRepo.ListEdits(id)
+}
+
+func (s *noteService) get(id int64) (*models.Message, error) {
+	note, err := s.messageRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil || note.MessageType != models.MessageTypeNote || note.DeletedAt != nil {
+		return nil, fmt.Errorf("note not found")
+	}
+	return note, nil
+}
+
+// authored gets a note the caller may change, which is one they wrote.
+// Notes without an author can't be changed.
+func (s *noteService) authored(ctx context.Context, id int64) (*models.Message, error) {
+	note, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if note.AuthorID == nil || *note.AuthorID != middleware.UserIDFromContext(ctx) {
+		return nil, models.ErrNotNoteAuthor
+	}
+	return note, nil
+}
+
+// notifyMentions publishes a mention event for every agent of the
+// organization mentioned in the note, other than its author and those in
+// skip
+func (s *noteService) notifyMentions(ctx context.Context, conv *models.Conversation, note *models.Message, skip map[string]bool) {
+	mentioned := mentions(note.Content)
+	if len(mentioned) == 0 {
+		return
+	}
+
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil || channel == nil {
+
+		fmt.Printf("Warning: failed to get channel %d for note mentions: %v\n", conv.ChannelID, err)
+		return
+	}
+
+	for _, externalID := range mentioned {
+		if skip[externalID] || (note.AuthorID != nil && *note.AuthorID == externalID) {
+			continue
+		}
+		agent, err := s.agentRepo.GetByExternalID(channel.OrganizationID, externalID)
+		if err != nil || agent == nil {
+			continue
+		}
+		go events.Publish(ctx, s.emitter, events.NoteMentionPayload{
+			NoteID:         note.ID,
+			ConversationID: conv.ID,
+			ChannelID:      conv.ChannelID,
+			AgentID:        agent.ExternalID,
+			AuthorID:       note.AuthorID,
+			Content:        note.Content,
+		})
+	}
+}
+
+// mentions returns the external IDs mentioned in a note, once each, in the
+// order they appear
+func mentions(content string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// A mention may end a sentence
+		id := strings.TrimRight(match[1], ".")
+		if id != "" && !seen[id] {
+			seen[id] = true
+			found = append(found, id)
+		}
+	}
+	return found
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noteFixture struct {
+	service NoteService
+	msgRepo *testutils.MockMessageRepository
+	emitter *testutils.MockEmitter
+	conv    *models.Conversation
+}
+
+func newNoteFixture(t *testing.T) *noteFixture {
+	f := &noteFixture{
+		msgRepo: testutils.NewMockMessageRepository(),
+		emitter: testutils.NewMockEmitter(),
+	}
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	agentRepo := testutils.NewMockAgentRepository()
+	f.service = NewNoteService(f.msgRepo, convRepo, channelRepo, agentRepo, f.emitter)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	for _, id := range []string{"agent-1", "agent-2", "agent.3"} {
+		agentRepo.Create(&models.CreateAgentRequest{OrganizationID: 1, ExternalID: id, Name: id})
+	}
+	agentRepo.Create(&models.CreateAgentRequest{OrganizationID: 2, ExternalID: "outsider", Name: "Outsider"})
+
+	var err error
+	f.conv, err = convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	require.NoError(t, err)
+	return f
+}
+
+func (f *noteFixture) mentioned() []string {
+	var agents []string
+	for _, event := range f.emitter.EmittedEvents {
+		if event.EventType == events.EventNoteMention {
+			agents = append(agents, event.Payload["agent_id"].(string))
+		}
+	}
+	return agents
+}
+
+func TestNoteService_Create(t *testing.T) {
+	f := newNoteFixture(t)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	note, err := f.service.Create(ctx, f.conv.ID, &models.CreateNoteRequest{
+		Content: "@agent-2 and @agent.3, see billing@example.com. Thanks @agent-1, @outsider @nobody @agent-2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.MessageTypeNote, note.MessageType)
+	assert.Equal(t, models.SenderInternal, note.SenderType)
+	require.NotNil(t, note.AuthorID)
+	assert.Equal(t, "agent-1", *note.AuthorID)
+
+	time.Sleep(10 * time.Millisecond)
+	// Only mentions are published; notes are never delivered
+	assert.Len(t, f.emitter.EmittedEvents, 2)
+	assert.ElementsMatch(t, []string{"agent-2", "agent.3"}, f.mentioned())
+
+	event := f.emitter.EmittedEvents[0]
+	assert.Equal(t, note.ID, event.Payload["note_id"])
+	assert.Equal(t, f.conv.ID, event.Payload["conversation_id"])
+	assert.Equal(t, "agent-1", event.Payload["author_id"])
+}
+
+func TestNoteService_UpdateAndDelete(t *testing.T) {
+	f := newNoteFixture(t)
+	author := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+	other := context.WithValue(context.Background(), middleware.UserIDKey, "agent-2")
+
+	note, err := f.service.Create(author, f.conv.ID, &models.CreateNoteRequest{Content: "Ask @agent-2"})
+	require.NoError(t, err)
+
+	t.Run("only the author may edit", func(t *testing.T) {
+		_, err := f.service.Update(other, note.ID, &models.UpdateNoteRequest{Content: "changed"})
+		assert.ErrorIs(t, err, models.ErrNotNoteAuthor)
+		assert.ErrorIs(t, f.service.Delete(other, note.ID), models.ErrNotNoteAuthor)
+	})
+
+	t.Run("edit notifies new mentions only", func(t *testing.T) {
+		time.Sleep(10 * time.Millisecond)
+		f.emitter.EmittedEvents = nil
+
+		edited, err := f.service.Update(author, note.ID, &models.UpdateNoteRequest{Content: "Ask @agent-2 or @agent.3"})
+		require.NoError(t, err)
+		assert.Equal(t, "Ask @agent-2 or @agent.3", edited.Content)
+		assert.NotNil(t, edited.EditedAt)
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, []string{"agent.3"}, f.mentioned())
+
+		edits, err := f.service.ListEdits(author, note.ID)
+		require.NoError(t, err)
+		require.Len(t, edits, 1)
+		assert.Equal(t, "Ask @agent-2", edits[0].Content)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, f.service.Delete(author, note.ID))
+
+		_, err := f.service.ListEdits(author, note.ID)
+		assert.Error(t, err)
+		_, err = f.service.Update(author, note.ID, &models.UpdateNoteRequest{Content: "again"})
+		assert.Error(t, err)
+	})
+
+	t.Run("notes without an author can't be changed", func(t *testing.T) {
+		anonymous, _ := f.msgRepo.Create(&models.Message{ConversationID: f.conv.ID, Content: "left by the system", MessageType: models.MessageTypeNote})
+		for _, ctx := range []context.Context{author, context.Background()} {
+			_, err := f.service.Update(ctx, anonymous.ID, &models.UpdateNoteRequest{Content: "changed"})
+			assert.ErrorIs(t, err, models.ErrNotNoteAuthor)
+			assert.ErrorIs(t, f.service.Delete(ctx, anonymous.ID), models.ErrNotNoteAuthor)
+		}
+	})
+
+	t.Run("messages are not notes", func(t *testing.T) {
+		msg, _ := f.msgRepo.Create(&models.Message{ConversationID: f.conv.ID, Content: "hello", MessageType: models.MessageTypeText})
+		_, err := f.service.Update(author, msg.ID, &models.UpdateNoteRequest{Content: "changed"})
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
@@ -9,12 +10,12 @@ import (
 
 // OrganizationService handles organization business logic
 type OrganizationService interface {
-	Create(req *models.CreateOrganizationRequest) (*models.Organization, error)
-	GetByID(id int64) (*models.Organization, error)
-	GetBySlug(slug string) (*models.Organization, error)
-	List(limit, offset int) ([]*models.Organization, error)
-	Update(id int64, req *models.UpdateOrganizationRequest) error
-	Delete(id int64) error
+	Create(ctx context.Context, req *models.CreateOrganizationRequest) (*models.Organization, error)
+	GetByID(ctx context.Context, id int64) (*models.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Organization, error)
+	List(ctx context.Context, limit, offset int) ([]*models.Organization, error)
+	Update(ctx context.Context, id int64, req *models.UpdateOrganizationRequest) error
+	Delete(ctx context.Context, id int64) error
 }
 
 type organizationService struct {
@@ -29,53 +30,53 @@ func NewOrganizationService(repo repositories.OrganizationRepository, emitter ev
 	}
 }
 
-func (s *organizationService) Create(req *models.CreateOrganizationRequest) (*models.Organization, error) {
+func (s *organizationService) Create(ctx context.Context, req *models.CreateOrganizationRequest) (*models.Organization, error) {
 	org, err := s.repo.Create(req)
 	if err != nil {
 		return nil, err
 	}
 
 	// Emit event (non-blocking, fire and forget)
-	go s.emitter.Emit(events.EventOrganizationCreated, map[string]interface{}{
-		"organization_id": org.ID,
-		"slug":            org.Slug,
-		"name":            org.Name,
+	go events.Publish(ctx, s.emitter, events.OrganizationCreatedPayload{
+		OrganizationID: org.ID,
+		Slug:           org.Slug,
+		Name:           org.Name,
 	})
 
 	return org, nil
 }
 
-func (s *organizationService) GetByID(id int64) (*models.Organization, error) {
+func (s *organizationService) GetByID(ctx context.Context, id int64) (*models.Organization, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *organizationService) GetBySlug(slug string) (*models.Organization, error) {
+func (s *organizationService) GetBySlug(ctx context.Context, slug string) (*models.Organization, error) {
 	return s.repo.GetBySlug(slug)
 }
 
-func (s *organizationService) List(limit, offset int) ([]*models.Organization, error) {
+func (s *organizationService) List(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
 	return s.repo.List(utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
 }
 
-func (s *organizationService) Update(id int64, req *models.UpdateOrganizationRequest) error {
+func (s *organizationService) Update(ctx context.Context, id int64, req *models.UpdateOrganizationRequest) error {
 	if err := s.repo.Update(id, req); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventOrganizationUpdated, map[string]interface{}{
-		"organization_id": id,
+	go events.Publish(ctx, s.emitter, events.OrganizationUpdatedPayload{
+		OrganizationID: id,
 	})
 
 	return nil
 }
 
-func (s *organizationService) Delete(id int64) error {
+func (s *organizationService) Delete(ctx context.Context, id int64) error {
 	if err := s.repo.Delete(id); err != nil {
 		return err
 	}
 
-	go s.emitter.Emit(events.EventOrganizationDeleted, map[string]interface{}{
-		"organization_id": id,
+	go events.Publish(ctx, s.emitter, events.OrganizationDeletedPayload{
+		OrganizationID: id,
 	})
 
 	return nil
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -22,7 +23,7 @@ func TestOrganizationService_Create(t *testing.T) {
 		Slug: "test-org",
 	}
 
-	org, err := service.Create(req)
+	org, err := service.Create(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, "Test Org", org.Name)
 	assert.Equal(t, "test-org", org.Slug)
@@ -40,7 +41,7 @@ func TestOrganizationService_Create_RepoError(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewOrganizationService(repo, emitter)
 
-	_, err := service.Create(&models.CreateOrganizationRequest{
+	_, err := service.Create(context.Background(), &models.CreateOrganizationRequest{
 		Name: "Test Org",
 		Slug: "test-org",
 	})
@@ -59,7 +60,7 @@ func TestOrganizationService_GetByID(t *testing.T) {
 		Slug: "test-org",
 	})
 
-	org, err := service.GetByID(created.ID)
+	org, err := service.GetByID(context.Background(), created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Test Org", org.Name)
 }
@@ -69,7 +70,7 @@ func TestOrganizationService_GetByID_NotFound(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewOrganizationService(repo, emitter)
 
-	org, err := service.GetByID(999)
+	org, err := service.GetByID(context.Background(), 999)
 	require.NoError(t, err)
 	assert.Nil(t, org)
 }
@@ -85,7 +86,7 @@ func TestOrganizationService_GetBySlug(t *testing.T) {
 		Slug: "test-org",
 	})
 
-	org, err := service.GetBySlug("test-org")
+	org, err := service.GetBySlug(context.Background(), "test-org")
 	require.NoError(t, err)
 	assert.Equal(t, "Test Org", org.Name)
 	assert.Equal(t, "test-org", org.Slug)
@@ -96,7 +97,7 @@ func TestOrganizationService_GetBySlug_NotFound(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewOrganizationService(repo, emitter)
 
-	org, err := service.GetBySlug("non-existent")
+	org, err := service.GetBySlug(context.Background(), "non-existent")
 	require.NoError(t, err)
 	assert.Nil(t, org)
 }
@@ -110,7 +111,7 @@ func TestOrganizationService_List(t *testing.T) {
 	repo.Create(&models.CreateOrganizationRequest{Name: "Org 1", Slug: "org-1"})
 	repo.Create(&models.CreateOrganizationRequest{Name: "Org 2", Slug: "org-2"})
 
-	orgs, err := service.List(10, 0)
+	orgs, err := service.List(context.Background(), 10, 0)
 	require.NoError(t, err)
 	assert.Len(t, orgs, 2)
 }
@@ -124,12 +125,12 @@ func TestOrganizationService_List_DefaultLimit(t *testing.T) {
 	repo.Create(&models.CreateOrganizationRequest{Name: "Org 1", Slug: "org-1"})
 
 	// Test with invalid limit (should default to 20)
-	orgs, err := service.List(0, 0)
+	orgs, err := service.List(context.Background(), 0, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, orgs)
 
 	// Test with limit > 100 (should default to 20)
-	orgs, err = service.List(200, 0)
+	orgs, err = service.List(context.Background(), 200, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, orgs)
 }
@@ -146,7 +147,7 @@ func TestOrganizationService_Update(t *testing.T) {
 	})
 
 	newName := "Updated Org"
-	err := service.Update(created.ID, &models.UpdateOrganizationRequest{
+	err := service.Update(context.Background(), created.ID, &models.UpdateOrganizationRequest{
 		Name: &newName,
 	})
 	require.NoError(t, err)
@@ -168,7 +169,7 @@ func TestOrganizationService_Update_RepoError(t *testing.T) {
 	service := NewOrganizationService(repo, emitter)
 
 	newName := "Updated Org"
-	err := service.Update(1, &models.UpdateOrganizationRequest{
+	err := service.Update(context.Background(), 1, &models.UpdateOrganizationRequest{
 		Name: &newName,
 	})
 	assert.Error(t, err)
@@ -185,7 +186,7 @@ func TestOrganizationService_Delete(t *testing.T) {
 		Slug: "test-org",
 	})
 
-	err := service.Delete(created.ID)
+	err := service.Delete(context.Background(), created.ID)
 	require.NoError(t, err)
 
 	// Verify deletion
@@ -204,7 +205,7 @@ func TestOrganizationService_Delete_RepoError(t *testing.T) {
 	emitter := testutils.NewMockEmitter()
 	service := NewOrganizationService(repo, emitter)
 
-	err := service.Delete(1)
+	err := service.Delete(context.Background(), 1)
 	assert.Error(t, err)
 	assert.Equal(t, "delete failed", err.Error())
 }
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// ParticipantService tracks the external users taking part in
+// conversations: the customer, others writing in the same group chat and
+// those copied on an email thread
+type ParticipantService interface {
+	List(ctx context.Context, conversationID int64) ([]*models.ConversationParticipant, error)
+	// Add adds a user of the conversation's channel as a member, or with
+	// the requested role
+	Add(ctx context.Context, conversationID int64, req *models.AddParticipantRequest) (*models.ConversationParticipant, error)
+	Remove(ctx context.Context, conversationID, externalUserID int64) error
+	// Join records that a user takes part in a conversation, for the given
+	// reason, and publishes an event if they were not already
+	Join(ctx context.Context, conv *models.Conversation, externalUserID int64, role models.ParticipantRole, reason string, at time.Time) error
+	// JoinChat and LeaveChat record a user joining or leaving a group chat,
+	// as reported by the platform, in the chat's latest conversation. They
+	// do nothing if the chat has none yet.
+	JoinChat(ctx context.Context, channelID int64, platformChatID, platformUserID, displayName string, at time.Time) error
+	LeaveChat(ctx context.Context, channelID int64, platformChatID, platformUserID string, at time.Time) error
+}
+
+type participantService struct {
+	repo             repositories.ParticipantRepository
+	conversationRepo repositories.ConversationRepository
+	externalUserRepo repositories.ExternalUserRepository
+	emitter          events.Emitter
+}
+
+func NewParticipantService(
+	repo repositories.ParticipantRepository,
+	conversationRepo repositories.ConversationRepository,
+	externalUserRepo repositories.ExternalUserRepository,
+	emitter events.Emitter,
+) ParticipantService {
+	return &participantService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		externalUserRepo: externalUserRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *participantService) List(ctx context.Context, conversationID int64) ([]*models.ConversationParticipant, error) {
+	return s.repo.ListByConversation(conversationID)
+}
+
+func (s *participantService) Add(ctx context.Context, conversationID int64, req *models.AddParticipantRequest) (*models.ConversationParticipant, error) {
+	conv, err := s.conversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.externalUserRepo.GetByID(req.ExternalUserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.ChannelID != conv.ChannelID {
+		return nil, fmt.Errorf("%w: user %d is not on the conversation's channel", models.ErrInvalidParticipant, req.ExternalUserID)
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.ParticipantRoleMember
+	}
+	participant, joined, err := s.repo.Join(conv.ID, user.ID, role, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if joined {
+		s.publishJoined(ctx, conv, participant, models.ParticipantReasonAgent)
+	}
+	return participant, nil
+}
+
+func (s *participantService) Remove(ctx context.Context, conversationID, externalUserID int64) error {
+	conv, err := s.conversation(conversationID)
+	if err != nil {
+		return err
+	}
+	return s.leave(ctx, conv, externalUserID, models.ParticipantReasonAgent, time.Now())
+}
+
+func (s *participantService) Join(ctx context.Context, conv *models.Conversation, externalUserID int64, role models.ParticipantRole, reason string, at time.Time) error {
+	participant, joined, err := s.repo.Join(conv.ID, externalUserID, role, at)
+	if err != nil {
+		return err
+	}
+	if joined {
+		s.publishJoined(ctx, conv, participant, reason)
+	}
+	return nil
+}
+
+func (s *participantService) JoinChat(ctx context.Context, channelID int64, platformChatID, platformUserID, displayName string, at time.Time) error {
+	conv, err := s.conversationRepo.LatestByChat(channelID, platformChatID)
+	if err != nil || conv == nil {
+		return err
+	}
+	user, err := s.externalUserRepo.FindOrCreate(&models.CreateExternalUserRequest{
+		ChannelID:      channelID,
+		PlatformUserID: platformUserID,
+		DisplayName:    &displayName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find/create user: %w", err)
+	}
+	return s.Join(ctx, conv, user.ID, models.ParticipantRoleMember, models.ParticipantReasonPlatform, at)
+}
+
+func (s *participantService) LeaveChat(ctx context.Context, channelID int64, platformChatID, platformUserID string, at time.Time) error {
+	conv, err := s.conversationRepo.LatestByChat(channelID, platformChatID)
+	if err != nil || conv == nil {
+		return err
+	}
+	user, err := s.externalUserRepo.GetByPlatformUser(channelID, platformUserID)
+	if err != nil || user == nil {
+		// A user we never saw cannot have taken part
+		return nil
+	}
+	return s.leave(ctx, conv, user.ID, models.ParticipantReasonPlatform, at)
+}
+
+func (s *participantService) leave(ctx context.Context, conv *models.Conversation, externalUserID int64, reason string, at time.Time) error {
+	left, err := s.repo.Leave(conv.ID, externalUserID, at)
+	if err != nil || !left {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.ParticipantLeftPayload{
+		ConversationID: conv.ID,
+		ChannelID:      conv.ChannelID,
+		ExternalUserID: externalUserID,
+		Reason:         reason,
+	})
+	return nil
+}
+
+func (s *participantService) publishJoined(ctx context.Context, conv *models.Conversation, participant *models.ConversationParticipant, reason string) {
+	go events.Publish(ctx, s.emitter, events.ParticipantJoinedPayload{
+		ConversationID: conv.ID,
+		ChannelID:      conv.ChannelID,
+		ExternalUserID: participant.ExternalUserID,
+		Role:           string(participant.Role),
+		Reason:         reason,
+	})
+}
+
+func (s *participantService) conversation(id int64) (*models.Conversation, error) {
+	conv, err := s.conversationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+	return conv, nil
+}
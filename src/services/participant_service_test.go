@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type participantFixture struct {
+	service  ParticipantService
+	messages MessageService
+	webhooks WebhookService
+	repo     *testutils.MockParticipantRepository
+	convRepo *testutils.MockConversationRepository
+	msgRepo  *testutils.MockMessageRepository
+	userRepo *testutils.MockExternalUserRepository
+	emitter  *testutils.MockEmitter
+}
+
+func newParticipantFixture() *participantFixture {
+	f := &participantFixture{
+		repo:     testutils.NewMockParticipantRepository(),
+		convRepo: testutils.NewMockConversationRepository(),
+		msgRepo:  testutils.NewMockMessageRepository(),
+		userRepo: testutils.NewMockExternalUserRepository(),
+		emitter:  testutils.NewMockEmitter(),
+	}
+	f.service = NewParticipantService(f.repo, f.convRepo, f.userRepo, f.emitter)
+	f.messages = NewMessageService(f.msgRepo, f.convRepo, f.userRepo, f.emitter, nil, nil, nil, nil, nil, f.service)
+	f.webhooks = NewWebhookService(testutils.NewMockWebhookEventRepository(), f.messages, f.service, nil, nil)
+	return f
+}
+
+func (f *participantFixture) receive(t *testing.T, req *ProcessIncomingMessageRequest) *models.Message {
+	req.ChannelID = 1
+	req.MessageType = models.MessageTypeText
+	msg, err := f.messages.ProcessIncomingMessage(context.Background(), req)
+	require.NoError(t, err)
+	return msg
+}
+
+func (f *participantFixture) emitted(eventType string) []testutils.EmittedEvent {
+	time.Sleep(10 * time.Millisecond)
+	var matching []testutils.EmittedEvent
+	for _, event := range f.emitter.EmittedEvents {
+		if event.EventType == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestParticipantService_GroupChat(t *testing.T) {
+	f := newParticipantFixture()
+	ctx := context.Background()
+
+	first := f.receive(t, &ProcessIncomingMessageRequest{PlatformUserID: "alice", UserDisplayName: "Alice", PlatformChatID: "group-1", Content: "Hi all"})
+	second := f.receive(t, &ProcessIncomingMessageRequest{PlatformUserID: "bob", UserDisplayName: "Bob", PlatformChatID: "group-1", Content: "Hello"})
+	direct := f.receive(t, &ProcessIncomingMessageRequest{PlatformUserID: "alice", Content: "Just me"})
+
+	// Both group messages land in one conversation, each from its sender
+	assert.Equal(t, first.ConversationID, second.ConversationID)
+	assert.NotEqual(t, first.ConversationID, direct.ConversationID)
+	assert.NotEqual(t, *first.SenderID, *second.SenderID)
+
+	conv, err := f.convRepo.GetByID(first.ConversationID)
+	require.NoError(t, err)
+	assert.Equal(t, *first.SenderID, conv.ExternalUserID)
+
+	participants, err := f.service.List(ctx, conv.ID)
+	require.NoError(t, err)
+	require.Len(t, participants, 2)
+	assert.Equal(t, models.ParticipantRoleRequester, participants[0].Role)
+	assert.Equal(t, *second.SenderID, participants[1].ExternalUserID)
+	assert.Equal(t, models.ParticipantRoleMember, participants[1].Role)
+
+	t.Run("message events carry the sender", func(t *testing.T) {
+		var bobs events.Sender
+		for _, event := range f.emitted(events.EventNewMessage) {
+			if event.Payload["message_id"] == second.ID {
+				bobs = event.Payload["sender"].(events.Sender)
+				assert.Equal(t, "group-1", event.Payload["platform_chat_id"])
+			}
+		}
+		assert.Equal(t, "bob", bobs.PlatformUserID)
+		assert.Equal(t, "Bob", *bobs.DisplayName)
+	})
+
+	t.Run("joins are published once", func(t *testing.T) {
+		f.receive(t, &ProcessIncomingMessageRequest{PlatformUserID: "bob", PlatformChatID: "group-1", Content: "Again"})
+		joined := f.emitted(events.EventParticipantJoined)
+		// Alice twice (group and direct) and Bob once
+		assert.Len(t, joined, 3)
+	})
+
+	t.Run("platform leave and join", func(t *testing.T) {
+		require.NoError(t, f.webhooks.ProcessWebhook(ctx, 1, "participant_left", map[string]interface{}{
+			"chat_id": "group-1",
+			"user_id": "bob",
+		}))
+		left := f.emitted(events.EventParticipantLeft)
+		require.Len(t, left, 1)
+		assert.Equal(t, models.ParticipantReasonPlatform, left[0].Payload["reason"])
+		assert.NotNil(t, participants[1].LeftAt)
+
+		require.NoError(t, f.webhooks.ProcessWebhook(ctx, 1, "participant_joined", map[string]interface{}{
+			"chat_id":   "group-1",
+			"user_id":   "carol",
+			"user_name": "Carol",
+		}))
+		participants, err := f.service.List(ctx, conv.ID)
+		require.NoError(t, err)
+		assert.Len(t, participants, 3)
+	})
+}
+
+func TestParticipantService_EmailCC(t *testing.T) {
+	f := newParticipantFixture()
+	ctx := context.Background()
+
+	msg := f.receive(t, &ProcessIncomingMessageRequest{
+		PlatformUserID: "john@example.com",
+		PlatformChatID: "<thread-1@example.com>",
+		CC:             []string{"jane@example.com", "john@example.com"},
+		Content:        "Please see below",
+	})
+
+	participants, err := f.service.List(ctx, msg.ConversationID)
+	require.NoError(t, err)
+	require.Len(t, participants, 2)
+	assert.Equal(t, models.ParticipantRoleCC, participants[1].Role)
+	jane, err := f.userRepo.GetByID(participants[1].ExternalUserID)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", jane.PlatformUserID)
+}
+
+func TestParticipantService_AddAndRemove(t *testing.T) {
+	f := newParticipantFixture()
+	ctx := context.Background()
+
+	msg := f.receive(t, &ProcessIncomingMessageRequest{PlatformUserID: "alice", Content: "Hi"})
+	bob, err := f.userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "bob"})
+	require.NoError(t, err)
+	stranger, err := f.userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 2, PlatformUserID: "eve"})
+	require.NoError(t, err)
+
+	_, err = f.service.Add(ctx, msg.ConversationID, &models.AddParticipantRequest{ExternalUserID: stranger.ID})
+	assert.True(t, errors.Is(err, models.ErrInvalidParticipant))
+
+	participant, err := f.service.Add(ctx, msg.ConversationID, &models.AddParticipantRequest{ExternalUserID: bob.ID, Role: models.ParticipantRoleCC})
+	require.NoError(t, err)
+	assert.Equal(t, models.ParticipantRoleCC, participant.Role)
+
+	joined := f.emitted(events.EventParticipantJoined)
+	require.Len(t, joined, 2)
+
+	require.NoError(t, f.service.Remove(ctx, msg.ConversationID, bob.ID))
+	left := f.emitted(events.EventParticipantLeft)
+	require.Len(t, left, 1)
+	assert.Equal(t, models.ParticipantReasonAgent, left[0].Payload["reason"])
+	assert.Equal(t, bob.ID, left[0].Payload["external_user_id"])
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// ReactionService manages emoji reactions to messages, by agents or by
+// customers on the platform. Agents' reactions are published for the
+// platform to show them too.
+type ReactionService interface {
+	// React adds the authenticated agent's reaction to a message and
+	// returns the message's reactions. On a platform that keeps one
+	// reaction per sender, it replaces the agent's previous reaction.
+	// Without an authenticated agent it returns ErrUserRequired.
+	React(ctx context.Context, messageID int64, req *models.ReactRequest) ([]*models.MessageReaction, error)
+	// Unreact removes the authenticated agent's reaction with an emoji
+	Unreact(ctx context.Context, messageID int64, emoji string) error
+	List(ctx context.Context, messageID int64) ([]*models.MessageReaction, error)
+	// ApplyPlatformReaction applies a reaction a customer made on the
+	// platform. With removed, the reaction is taken back instead; without
+	// an emoji, as WhatsApp reports a withdrawn reaction, all of the
+	// customer's reactions to the message are.
+	ApplyPlatformReaction(ctx context.Context, channelID int64, platformMessageID, platformUserID, emoji string, removed bool, at time.Time) error
+}
+
+type reactionService struct {
+	repo             repositories.ReactionRepository
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	emitter          events.Emitter
+}
+
+func NewReactionService(
+	repo repositories.ReactionRepository,
+	messageRepo repositories.MessageRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	emitter events.Emitter,
+) ReactionService {
+	return &reactionService{
+		repo:             repo,
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *reactionService) React(ctx context.Context, messageID int64, req *models.ReactRequest) ([]*models.MessageReaction, error) {
+	agentID := middleware.UserIDFromContext(ctx)
+	if agentID == "" {
+		return nil, models.ErrUserRequired
+	}
+	msg, channel, err := s.target(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.add(ctx, msg, channel, &models.MessageReaction{
+		MessageID:  msg.ID,
+		SenderType: models.SenderInternal,
+		SenderID:   agentID,
+		Emoji:      req.Emoji,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByMessage(msg.ID)
+}
+
+func (s *reactionService) Unreact(ctx context.Context, messageID int64, emoji string) error {
+	agentID := middleware.UserIDFromContext(ctx)
+	if agentID == "" {
+		return models.ErrUserRequired
+	}
+	msg, channel, err := s.target(messageID)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, msg, channel, models.SenderInternal, agentID, emoji, true, time.Now())
+}
+
+func (s *reactionService) List(ctx context.Context, messageID int64) ([]*models.MessageReaction, error) {
+	msg, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByMessage(msg.ID)
+}
+
+func (s *reactionService) ApplyPlatformReaction(ctx context.Context, channelID int64, platformMessageID, platformUserID, emoji string, removed bool, at time.Time) error {
+	msg, err := s.messageRepo.FindByPlatformID(channelID, platformMessageID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message %s %w", platformMessageID, models.ErrNotFound)
+	}
+	if msg.DeletedAt != nil {
+		return nil
+	}
+
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return fmt.Errorf("channel not found")
+	}
+
+	if emoji == "" {
+		return s.removeAll(ctx, msg, channel, models.SenderExternal, platformUserID, "", at)
+	}
+	if removed {
+		return s.remove(ctx, msg, channel, models.SenderExternal, platformUserID, emoji, false, at)
+	}
+	return s.add(ctx, msg, channel, &models.MessageReaction{
+		MessageID:  msg.ID,
+		SenderType: models.SenderExternal,
+		SenderID:   platformUserID,
+		Emoji:      emoji,
+		CreatedAt:  at,
+	})
+}
+
+// target gets a message agents may react to, and the channel it is on
+func (s *reactionService) target(messageID int64) (*models.Message, *models.ChatChannel, error) {
+	msg, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !changeable(msg) || msg.DeletedAt != nil {
+		return nil, nil, fmt.Errorf("%w: message %d", models.ErrCannotReact, msg.ID)
+	}
+
+	_, channel, err := conversationChannel(s.conversationRepo, s.channelRepo, msg.ConversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, channel, nil
+}
+
+// add records a reaction and publishes it if it is new. On a platform that
+// keeps one reaction per sender, the sender's other reactions are removed
+// first; the platform replaces them itself, so that is not propagated.
+func (s *reactionService) add(ctx context.Context, msg *models.Message, channel *models.ChatChannel, reaction *models.MessageReaction) error {
+	if channel.Platform.SingleReaction() {
+		if err := s.removeAll(ctx, msg, channel, reaction.SenderType, reaction.SenderID, reaction.Emoji, reaction.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	added, err := s.repo.Add(reaction)
+	if err != nil || !added {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.ReactionAddedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    msg.ConversationID,
+		ChannelID:         channel.ID,
+		PlatformMessageID: msg.PlatformMessageID,
+		SenderType:        string(reaction.SenderType),
+		SenderID:          reaction.SenderID,
+		Emoji:             reaction.Emoji,
+		Propagate:         reaction.SenderType == models.SenderInternal,
+		ReactedAt:         reaction.CreatedAt,
+	})
+	return nil
+}
+
+// removeAll removes a sender's reactions to a message, except the one with
+// the emoji kept
+func (s *reactionService) removeAll(ctx context.Context, msg *models.Message, channel *models.ChatChannel, senderType models.MessageSenderType, senderID, kept string, at time.Time) error {
+	reactions, err := s.repo.ListByMessage(msg.ID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reactions {
+		if r.SenderType != senderType || r.SenderID != senderID || r.Emoji == kept {
+			continue
+		}
+		if err := s.remove(ctx, msg, channel, senderType, senderID, r.Emoji, false, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes a sender's reaction and publishes it if there was one
+func (s *reactionService) remove(ctx context.Context, msg *models.Message, channel *models.ChatChannel, senderType models.MessageSenderType, senderID, emoji string, propagate bool, at time.Time) error {
+	removed, err := s.repo.Remove(msg.ID, senderType, senderID, emoji)
+	if err != nil || !removed {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.ReactionRemovedPayload{
+		MessageID:         msg.ID,
+		ConversationID:    msg.ConversationID,
+		ChannelID:         channel.ID,
+		PlatformMessageID: msg.PlatformMessageID,
+		SenderType:        string(senderType),
+		SenderID:          senderID,
+		Emoji:             emoji,
+		Propagate:         propagate,
+		RemovedAt:         at,
+	})
+	return nil
+}
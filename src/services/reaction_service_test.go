@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/middleware"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reactionFixture struct {
+	service ReactionService
+	repo    *testutils.MockReactionRepository
+	msgRepo *testutils.MockMessageRepository
+	emitter *testutils.MockEmitter
+	msg     *models.Message
+}
+
+func newReactionFixture(t *testing.T, platform models.Platform) *reactionFixture {
+	f := &reactionFixture{
+		repo:    testutils.NewMockReactionRepository(),
+		msgRepo: testutils.NewMockMessageRepository(),
+		emitter: testutils.NewMockEmitter(),
+	}
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	f.service = NewReactionService(f.repo, f.msgRepo, convRepo, channelRepo, f.emitter)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: platform, Name: "Channel"})
+	conv, err := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	require.NoError(t, err)
+
+	platformID := "platform-msg"
+	f.msg, _ = f.msgRepo.Create(&models.Message{
+		ConversationID:    conv.ID,
+		PlatformMessageID: &platformID,
+		SenderType:        models.SenderExternal,
+		Content:           "Thanks for the help!",
+		MessageType:       models.MessageTypeText,
+		Direction:         models.DirectionInbound,
+	})
+	return f
+}
+
+func (f *reactionFixture) published(eventType string) []map[string]interface{} {
+	time.Sleep(10 * time.Millisecond)
+	var payloads []map[string]interface{}
+	for _, event := range f.emitter.EmittedEvents {
+		if event.EventType == eventType {
+			payloads = append(payloads, event.Payload)
+		}
+	}
+	return payloads
+}
+
+func emojis(reactions []*models.MessageReaction) []string {
+	result := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		result = append(result, r.Emoji)
+	}
+	return result
+}
+
+func TestReactionService_React(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "agent-1")
+
+	t.Run("agent reactions", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+
+		_, err := f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		require.NoError(t, err)
+		reactions, err := f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "❤️"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"👍", "❤️"}, emojis(reactions))
+		assert.Equal(t, "agent-1", reactions[0].SenderID)
+
+		// Reacting again with the same emoji changes nothing
+		_, err = f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		require.NoError(t, err)
+
+		added := f.published(events.EventReactionAdded)
+		require.Len(t, added, 2)
+		for _, payload := range added {
+			assert.Equal(t, "internal", payload["sender_type"])
+			assert.Equal(t, true, payload["propagate"])
+		}
+
+		require.NoError(t, f.service.Unreact(ctx, f.msg.ID, "👍"))
+		reactions, err = f.service.List(ctx, f.msg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"❤️"}, emojis(reactions))
+
+		removed := f.published(events.EventReactionRemoved)
+		require.Len(t, removed, 1)
+		assert.Equal(t, true, removed[0]["propagate"])
+	})
+
+	t.Run("single reaction platform replaces it", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformWhatsApp)
+
+		_, err := f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		require.NoError(t, err)
+		reactions, err := f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "🎉"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"🎉"}, emojis(reactions))
+
+		removed := f.published(events.EventReactionRemoved)
+		require.Len(t, removed, 1)
+		assert.Equal(t, "👍", removed[0]["emoji"])
+		assert.Equal(t, false, removed[0]["propagate"])
+	})
+
+	t.Run("notes and deleted messages", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+		note, _ := f.msgRepo.Create(&models.Message{
+			ConversationID: f.msg.ConversationID,
+			SenderType:     models.SenderInternal,
+			Content:        "VIP customer",
+			MessageType:    models.MessageTypeNote,
+			Direction:      models.DirectionOutbound,
+		})
+		_, err := f.service.React(ctx, note.ID, &models.ReactRequest{Emoji: "👍"})
+		assert.ErrorIs(t, err, models.ErrCannotReact)
+
+		require.NoError(t, f.msgRepo.Delete(f.msg.ID, nil, time.Now(), false))
+		_, err = f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		assert.ErrorIs(t, err, models.ErrCannotReact)
+		assert.Empty(t, f.repo.Reactions)
+	})
+
+	t.Run("unknown messages", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+
+		_, err := f.service.React(ctx, 99, &models.ReactRequest{Emoji: "👍"})
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		_, err = f.service.List(ctx, 99)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("an agent is required", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+
+		_, err := f.service.React(context.Background(), f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		assert.ErrorIs(t, err, models.ErrUserRequired)
+		assert.Empty(t, f.repo.Reactions)
+
+		_, err = f.service.React(ctx, f.msg.ID, &models.ReactRequest{Emoji: "👍"})
+		require.NoError(t, err)
+		assert.ErrorIs(t, f.service.Unreact(context.Background(), f.msg.ID, "👍"), models.ErrUserRequired)
+		assert.Len(t, f.repo.Reactions, 1)
+		assert.Len(t, f.published(events.EventReactionAdded), 1)
+		assert.Empty(t, f.published(events.EventReactionRemoved))
+	})
+}
+
+func TestReactionService_ApplyPlatformReaction(t *testing.T) {
+	ctx := context.Background()
+	at := time.Now()
+
+	t.Run("add and take back", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "👍", false, at))
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "🔥", false, at))
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-2", "👍", false, at))
+		assert.Len(t, f.repo.Reactions, 3)
+
+		added := f.published(events.EventReactionAdded)
+		require.Len(t, added, 3)
+		for _, payload := range added {
+			assert.Equal(t, "external", payload["sender_type"])
+			assert.Equal(t, false, payload["propagate"])
+		}
+
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "👍", true, at))
+		assert.Len(t, f.repo.Reactions, 2)
+
+		// Without an emoji, all of the user's reactions are taken back
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "", false, at))
+		require.Len(t, f.repo.Reactions, 1)
+		assert.Equal(t, "user-2", f.repo.Reactions[0].SenderID)
+		assert.Len(t, f.published(events.EventReactionRemoved), 2)
+	})
+
+	t.Run("single reaction platform", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformWhatsApp)
+
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "👍", false, at))
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "😂", false, at))
+		require.Len(t, f.repo.Reactions, 1)
+		assert.Equal(t, "😂", f.repo.Reactions[0].Emoji)
+	})
+
+	t.Run("unknown and deleted messages", func(t *testing.T) {
+		f := newReactionFixture(t, models.PlatformTelegram)
+		err := f.service.ApplyPlatformReaction(ctx, 1, "unknown", "user-1", "👍", false, at)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		require.NoError(t, f.msgRepo.Delete(f.msg.ID, nil, at, false))
+		require.NoError(t, f.service.ApplyPlatformReaction(ctx, 1, "platform-msg", "user-1", "👍", false, at))
+		assert.Empty(t, f.repo.Reactions)
+	})
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// ReadService tracks which inbound messages each agent has seen, through a
+// per-agent read cursor on every conversation
+type ReadService interface {
+	// MarkRead moves the agent's cursor on the conversation forward to the
+	// given message, which must belong to the conversation
+	MarkRead(ctx context.Context, conversationID int64, agentID string, messageID int64) (*models.ReadCursor, error)
+	// AgentUnread returns the agent's unread totals over the open
+	// conversations assigned to them
+	AgentUnread(ctx context.Context, agentID int64) (*models.UnreadTotals, error)
+}
+
+type readService struct {
+	cursorRepo       repositories.ReadCursorRepository
+	messageRepo      repositories.MessageRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	agentRepo        repositories.AgentRepository
+	emitter          events.Emitter
+}
+
+func NewReadService(
+	cursorRepo repositories.ReadCursorRepository,
+	messageRepo repositories.MessageRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	agentRepo repositories.AgentRepository,
+	emitter events.Emitter,
+) ReadService {
+	return &readService{
+		cursorRepo:       cursorRepo,
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		agentRepo:        agentRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *readService) MarkRead(ctx context.Context, conversationID int64, agentID string, messageID int64) (*models.ReadCursor, error) {
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+	message, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.ConversationID != conversationID {
+		return nil, fmt.Errorf("message %w", models.ErrNotFound)
+	}
+
+	cursor, err := s.cursorRepo.Advance(conversationID, agentID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.cursorRepo.UnreadCount(conversationID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err == nil && channel != nil {
+		var totals *models.UnreadTotals
+		if totals, err = s.cursorRepo.Totals(channel.OrganizationID, agentID); err == nil {
+			total = totals.Messages
+		}
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to count unread messages: %v\n", err)
+	}
+
+	go events.Publish(ctx, s.emitter, events.ConversationReadPayload{
+		ConversationID:    conversationID,
+		AgentID:           agentID,
+		LastReadMessageID: cursor.LastReadMessageID,
+		UnreadCount:       unread,
+		TotalUnread:       total,
+	})
+
+	return cursor, nil
+}
+
+func (s *readService) AgentUnread(ctx context.Context, agentID int64) (*models.UnreadTotals, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("agent %w", models.ErrNotFound)
+	}
+	return s.cursorRepo.Totals(agent.OrganizationID, agent.ExternalID)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readFixture struct {
+	service    ReadService
+	cursorRepo *testutils.MockReadCursorRepository
+	msgRepo    *testutils.MockMessageRepository
+	convRepo   *testutils.MockConversationRepository
+	agentRepo  *testutils.MockAgentRepository
+	emitter    *testutils.MockEmitter
+}
+
+func newReadFixture() *readFixture {
+	f := &readFixture{
+		cursorRepo: testutils.NewMockReadCursorRepository(),
+		msgRepo:    testutils.NewMockMessageRepository(),
+		convRepo:   testutils.NewMockConversationRepository(),
+		agentRepo:  testutils.NewMockAgentRepository(),
+		emitter:    testutils.NewMockEmitter(),
+	}
+	channelRepo := testutils.NewMockChannelRepository()
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	f.service = NewReadService(f.cursorRepo, f.msgRepo, f.convRepo, channelRepo, f.agentRepo, f.emitter)
+	return f
+}
+
+func TestReadService_MarkRead(t *testing.T) {
+	f := newReadFixture()
+	ctx := context.Background()
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	other, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 2})
+	msg, _ := f.msgRepo.Create(&models.Message{ConversationID: conv.ID, Direction: models.DirectionInbound})
+	foreign, _ := f.msgRepo.Create(&models.Message{ConversationID: other.ID, Direction: models.DirectionInbound})
+	f.cursorRepo.Unread[conv.ID] = 0
+	f.cursorRepo.Unread[other.ID] = 4
+
+	t.Run("rejects messages of another conversation", func(t *testing.T) {
+		_, err := f.service.MarkRead(ctx, conv.ID, "agent-1", foreign.ID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		assert.Empty(t, f.cursorRepo.Cursors)
+	})
+
+	t.Run("reports other failures", func(t *testing.T) {
+		f.convRepo.GetError = errors.New("database is locked")
+		defer func() { f.convRepo.GetError = nil }()
+
+		_, err := f.service.MarkRead(ctx, conv.ID, "agent-1", msg.ID)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("moves the cursor and publishes counts", func(t *testing.T) {
+		cursor, err := f.service.MarkRead(ctx, conv.ID, "agent-1", msg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, msg.ID, cursor.LastReadMessageID)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, f.emitter.EmittedEvents, 1)
+		event := f.emitter.EmittedEvents[0]
+		assert.Equal(t, events.EventConversationRead, event.EventType)
+		assert.Equal(t, "agent-1", event.Payload["agent_id"])
+		assert.Equal(t, msg.ID, event.Payload["last_read_message_id"])
+		assert.Equal(t, int64(0), event.Payload["unread_count"])
+		assert.Equal(t, int64(4), event.Payload["total_unread"])
+	})
+}
+
+func TestReadService_AgentUnread(t *testing.T) {
+	f := newReadFixture()
+	agent, _ := f.agentRepo.Create(&models.CreateAgentRequest{OrganizationID: 1, ExternalID: "agent-1", Name: "Agent"})
+	f.cursorRepo.Unread[1] = 2
+	f.cursorRepo.Unread[2] = 3
+
+	totals, err := f.service.AgentUnread(context.Background(), agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", totals.AgentID)
+	assert.Equal(t, int64(5), totals.Messages)
+	assert.Equal(t, int64(2), totals.Conversations)
+
+	_, err = f.service.AgentUnread(context.Background(), 99)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
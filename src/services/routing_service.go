@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+)
+
+// RoutingService assigns conversations to available agents according to
+// the routing policy of their channel. Conversations that belong to a team
+// are only assigned to the team's members.
+type RoutingService interface {
+	GetPolicy(ctx context.Context, channelID int64) (*models.RoutingPolicy, error)
+	SetPolicy(ctx context.Context, channelID int64, req *models.UpsertRoutingPolicyRequest) (*models.RoutingPolicy, error)
+	// RouteNew puts a newly created conversation in its channel's default
+	// team, if any, and assigns it to an agent. Assignment is skipped when
+	// the channel has no enabled policy or no agent is available.
+	RouteNew(ctx context.Context, conv *models.Conversation) error
+	// ReassignUnanswered moves conversations whose agent has not replied in
+	// time to another agent and returns how many were reassigned
+	ReassignUnanswered(ctx context.Context, now time.Time) (int, error)
+	// ReassignFromAgent moves an agent's open conversations to other agents
+	ReassignFromAgent(ctx context.Context, orgID int64, agentExternalID string) (int, error)
+}
+
+type routingService struct {
+	policyRepo       repositories.RoutingPolicyRepository
+	agentRepo        repositories.AgentRepository
+	teamRepo         repositories.TeamRepository
+	conversationRepo repositories.ConversationRepository
+	historyRepo      repositories.ConversationEventRepository
+	channelRepo      repositories.ChannelRepository
+	emitter          events.Emitter
+}
+
+func NewRoutingService(
+	policyRepo repositories.RoutingPolicyRepository,
+	agentRepo repositories.AgentRepository,
+	teamRepo repositories.TeamRepository,
+	conversationRepo repositories.ConversationRepository,
+	historyRepo repositories.ConversationEventRepository,
+	channelRepo repositories.ChannelRepository,
+	emitter events.Emitter,
+) RoutingService {
+	return &routingService{
+		policyRepo:       policyRepo,
+		agentRepo:        agentRepo,
+		teamRepo:         teamRepo,
+		conversationRepo: conversationRepo,
+		historyRepo:      historyRepo,
+		channelRepo:      channelRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *routingService) GetPolicy(ctx context.Context, channelID int64) (*models.RoutingPolicy, error) {
+	policy, err := s.policyRepo.FindByChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("routing policy not found")
+	}
+	return policy, nil
+}
+
+func (s *routingService) SetPolicy(ctx context.Context, channelID int64, req *models.UpsertRoutingPolicyRequest) (*models.RoutingPolicy, error) {
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	return s.policyRepo.Upsert(channelID, req)
+}
+
+func (s *routingService) RouteNew(ctx context.Context, conv *models.Conversation) error {
+	channel, err := s.channel(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	queued := false
+	if conv.TeamID == nil && channel.DefaultTeamID != nil {
+		if err := s.conversationRepo.Update(conv.ID, &models.UpdateConversationRequest{
+			TeamID: channel.DefaultTeamID,
+		}); err != nil {
+			return err
+		}
+		recordTeamChange(ctx, s.historyRepo, conv.ID, nil, channel.DefaultTeamID, models.AssignReasonNew)
+		conv.TeamID = channel.DefaultTeamID
+		queued = true
+	}
+
+	routed := false
+	policy, err := s.policyRepo.FindByChannel(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+	if policy != nil && policy.Enabled {
+		if routed, err = s.route(ctx, conv, policy, models.AssignReasonNew); err != nil {
+			return err
+		}
+	}
+
+	if queued && !routed {
+		go events.Publish(ctx, s.emitter, events.ConversationAssignedPayload{
+			ConversationID: conv.ID,
+			TeamID:         conv.TeamID,
+			Reason:         models.AssignReasonNew,
+		})
+	}
+
+	return nil
+}
+
+func (s *routingService) ReassignUnanswered(ctx context.Context, now time.Time) (int, error) {
+	conversations, err := s.conversationRepo.ListUnanswered(now)
+	if err != nil {
+		return 0, err
+	}
+
+	reassigned := 0
+	for _, conv := range conversations {
+		policy, err := s.policyRepo.FindByChannel(conv.ChannelID)
+		if err != nil {
+			return reassigned, err
+		}
+		if policy == nil {
+			continue
+		}
+
+		ok, err := s.route(ctx, conv, policy, models.AssignReasonTimeout)
+		if err != nil {
+			return reassigned, err
+		}
+		if ok {
+			reassigned++
+		}
+	}
+
+	return reassigned, nil
+}
+
+func (s *routingService) ReassignFromAgent(ctx context.Context, orgID int64, agentExternalID string) (int, error) {
+	conversations, err := s.conversationRepo.ListOpenByAssignee(orgID, agentExternalID)
+	if err != nil {
+		return 0, err
+	}
+
+	reassigned := 0
+	for _, conv := range conversations {
+		policy, err := s.policyRepo.FindByChannel(conv.ChannelID)
+		if err != nil {
+			return reassigned, err
+		}
+		if policy == nil || !policy.Enabled {
+			continue
+		}
+
+		ok, err := s.route(ctx, conv, policy, models.AssignReasonAgentOffline)
+		if err != nil {
+			return reassigned, err
+		}
+		if ok {
+			reassigned++
+		}
+	}
+
+	return reassigned, nil
+}
+
+func (s *routingService) channel(id int64) (*models.ChatChannel, error) {
+	channel, err := s.channelRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	return channel, nil
+}
+
+// route picks an agent other than the current assignee, from the
+// conversation's team if it has one, and assigns the conversation to them.
+// It returns false when nobody is available.
+func (s *routingService) route(ctx context.Context, conv *models.Conversation, policy *models.RoutingPolicy, reason string) (bool, error) {
+	channel, err := s.channel(conv.ChannelID)
+	if err != nil {
+		return false, err
+	}
+
+	loads, err := s.agentRepo.ListAvailable(channel.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+
+	var members map[string]bool
+	if conv.TeamID != nil {
+		ids, err := s.teamRepo.ListMembers(*conv.TeamID)
+		if err != nil {
+			return false, err
+		}
+		members = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			members[id] = true
+		}
+	}
+
+	var current string
+	if conv.AssignedToExternalID != nil {
+		current = *conv.AssignedToExternalID
+	}
+
+	candidates := make([]*models.AgentLoad, 0, len(loads))
+	for _, load := range loads {
+		if members != nil && !members[load.ExternalID] {
+			continue
+		}
+		if load.ExternalID != current && load.HasCapacity() {
+			candidates = append(candidates, load)
+		}
+	}
+	if len(candidates) == 0 {
+		return false, nil
+	}
+
+	agent, err := s.pick(conv, policy.Strategy, candidates)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.conversationRepo.Update(conv.ID, &models.UpdateConversationRequest{
+		AssignedToExternalID: &agent.ExternalID,
+	}); err != nil {
+		return false, err
+	}
+	if err := s.agentRepo.MarkAssigned(agent.ID); err != nil {
+		fmt.Printf("Warning: failed to record agent assignment: %v\n", err)
+	}
+	recordChange(ctx, s.historyRepo, conv.ID, models.ConversationEventAssigneeChanged, optional(current), &agent.ExternalID, reason)
+
+	payload := events.ConversationAssignedPayload{
+		ConversationID: conv.ID,
+		AssigneeID:     agent.ExternalID,
+		TeamID:         conv.TeamID,
+		Reason:         reason,
+	}
+	if current != "" {
+		payload.PreviousAssigneeID = &current
+	}
+	strategy := string(policy.Strategy)
+	payload.Strategy = &strategy
+	go events.Publish(ctx, s.emitter, payload)
+
+	conv.AssignedToExternalID = &agent.ExternalID
+	return true, nil
+}
+
+// pick chooses among agents that are online and below capacity
+func (s *routingService) pick(conv *models.Conversation, strategy models.RoutingStrategy, candidates []*models.AgentLoad) (*models.AgentLoad, error) {
+	switch strategy {
+	case models.RoutingRoundRobin:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i].LastAssignedAt, candidates[j].LastAssignedAt
+			if a == nil || b == nil {
+				return a == nil && b != nil
+			}
+			return a.Before(*b)
+		})
+		return candidates[0], nil
+
+	case models.RoutingCapacity:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return freeShare(candidates[i]) > freeShare(candidates[j])
+		})
+		return candidates[0], nil
+
+	case models.RoutingSticky:
+		previous, err := s.conversationRepo.LastAssigneeForUser(conv.ExternalUserID, conv.ID)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			for _, c := range candidates {
+				if c.ExternalID == *previous {
+					return c, nil
+				}
+			}
+		}
+	}
+
+	// least_open, and the sticky fallback
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].OpenConversations < candidates[j].OpenConversations
+	})
+	return candidates[0], nil
+}
+
+// freeShare is the fraction of an agent's capacity that is unused. Agents
+// without a limit count as fully free.
+func freeShare(a *models.AgentLoad) float64 {
+	if a.MaxConcurrent <= 0 {
+		return 1
+	}
+	return float64(a.MaxConcurrent-a.OpenConversations) / float64(a.MaxConcurrent)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type routingFixture struct {
+	service     RoutingService
+	policyRepo  *testutils.MockRoutingPolicyRepository
+	agentRepo   *testutils.MockAgentRepository
+	teamRepo    *testutils.MockTeamRepository
+	convRepo    *testutils.MockConversationRepository
+	historyRepo *testutils.MockConversationEventRepository
+	channelRepo *testutils.MockChannelRepository
+	emitter     *testutils.MockEmitter
+}
+
+func newRoutingFixture(strategy models.RoutingStrategy) *routingFixture {
+	f := &routingFixture{
+		policyRepo:  testutils.NewMockRoutingPolicyRepository(),
+		agentRepo:   testutils.NewMockAgentRepository(),
+		teamRepo:    testutils.NewMockTeamRepository(),
+		convRepo:    testutils.NewMockConversationRepository(),
+		historyRepo: testutils.NewMockConversationEventRepository(),
+		channelRepo: testutils.NewMockChannelRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	f.service = NewRoutingService(f.policyRepo, f.agentRepo, f.teamRepo, f.convRepo, f.historyRepo, f.channelRepo, f.emitter)
+
+	f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	f.policyRepo.Upsert(1, &models.UpsertRoutingPolicyRequest{Strategy: strategy})
+	return f
+}
+
+func (f *routingFixture) addAgent(externalID string, status models.AgentStatus, maxConcurrent, open int) *models.Agent {
+	agent, _ := f.agentRepo.Create(&models.CreateAgentRequest{
+		OrganizationID: 1,
+		ExternalID:     externalID,
+		Name:           externalID,
+		Status:         status,
+		MaxConcurrent:  maxConcurrent,
+	})
+	f.agentRepo.OpenCounts[externalID] = open
+	return agent
+}
+
+func (f *routingFixture) newConversation(externalUserID int64) *models.Conversation {
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: externalUserID})
+	return conv
+}
+
+func assigneeOf(t *testing.T, conv *models.Conversation) string {
+	t.Helper()
+	require.NotNil(t, conv.AssignedToExternalID)
+	return *conv.AssignedToExternalID
+}
+
+func TestRoutingService_RoundRobin(t *testing.T) {
+	f := newRoutingFixture(models.RoutingRoundRobin)
+	f.addAgent("a", models.AgentStatusOnline, 0, 0)
+	f.addAgent("b", models.AgentStatusOnline, 0, 0)
+	f.addAgent("c", models.AgentStatusOffline, 0, 0)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		conv := f.newConversation(int64(i + 1))
+		require.NoError(t, f.service.RouteNew(context.Background(), conv))
+		got = append(got, assigneeOf(t, conv))
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, []string{"a", "b", "a", "b"}, got)
+}
+
+func TestRoutingService_LeastOpenRespectsCapacity(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	f.addAgent("busy", models.AgentStatusOnline, 2, 2)
+	f.addAgent("light", models.AgentStatusOnline, 10, 3)
+	f.addAgent("idle-but-away", models.AgentStatusAway, 0, 0)
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	assert.Equal(t, "light", assigneeOf(t, conv))
+}
+
+func TestRoutingService_Capacity(t *testing.T) {
+	f := newRoutingFixture(models.RoutingCapacity)
+	f.addAgent("small", models.AgentStatusOnline, 2, 1)  // 50% free
+	f.addAgent("large", models.AgentStatusOnline, 10, 3) // 70% free
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	assert.Equal(t, "large", assigneeOf(t, conv))
+}
+
+func TestRoutingService_Sticky(t *testing.T) {
+	f := newRoutingFixture(models.RoutingSticky)
+	f.addAgent("a", models.AgentStatusOnline, 0, 0)
+	f.addAgent("b", models.AgentStatusOnline, 0, 5)
+
+	previous := f.newConversation(7)
+	agentB := "b"
+	f.convRepo.Update(previous.ID, &models.UpdateConversationRequest{AssignedToExternalID: &agentB})
+	resolved := models.ConversationStatusResolved
+	f.convRepo.Update(previous.ID, &models.UpdateConversationRequest{Status: &resolved})
+
+	t.Run("prefers previous agent", func(t *testing.T) {
+		conv := f.newConversation(7)
+		require.NoError(t, f.service.RouteNew(context.Background(), conv))
+		assert.Equal(t, "b", assigneeOf(t, conv))
+	})
+
+	t.Run("falls back to least open for new customers", func(t *testing.T) {
+		conv := f.newConversation(8)
+		require.NoError(t, f.service.RouteNew(context.Background(), conv))
+		assert.Equal(t, "a", assigneeOf(t, conv))
+	})
+}
+
+func TestRoutingService_RouteNew_NoPolicyOrAgents(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	assert.Nil(t, conv.AssignedToExternalID)
+
+	f.addAgent("a", models.AgentStatusOnline, 0, 0)
+	disabled := false
+	f.policyRepo.Upsert(1, &models.UpsertRoutingPolicyRequest{Strategy: models.RoutingLeastOpen, Enabled: &disabled})
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	assert.Nil(t, conv.AssignedToExternalID)
+}
+
+func TestRoutingService_ReassignUnanswered(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	f.addAgent("a", models.AgentStatusOnline, 0, 0)
+	f.addAgent("b", models.AgentStatusOnline, 0, 0)
+
+	conv := f.newConversation(1)
+	agentA := "a"
+	f.convRepo.Update(conv.ID, &models.UpdateConversationRequest{AssignedToExternalID: &agentA})
+	f.convRepo.Unanswered = []*models.Conversation{conv}
+
+	n, err := f.service.ReassignUnanswered(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "b", assigneeOf(t, conv))
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, f.emitter.EmittedEvents, 1)
+	event := f.emitter.EmittedEvents[0]
+	assert.Equal(t, events.EventConversationAssigned, event.EventType)
+	assert.Equal(t, models.AssignReasonTimeout, event.Payload["reason"])
+	assert.Equal(t, "a", event.Payload["previous_assignee_id"])
+	assert.Equal(t, "least_open", event.Payload["strategy"])
+}
+
+func TestRoutingService_ReassignFromAgent(t *testing.T) {
+	f := newRoutingFixture(models.RoutingRoundRobin)
+	f.addAgent("leaving", models.AgentStatusOnline, 0, 0)
+	f.addAgent("staying", models.AgentStatusOnline, 0, 0)
+
+	leaving := "leaving"
+	for i := 0; i < 2; i++ {
+		conv := f.newConversation(int64(i + 1))
+		f.convRepo.Update(conv.ID, &models.UpdateConversationRequest{AssignedToExternalID: &leaving})
+	}
+
+	n, err := f.service.ReassignFromAgent(context.Background(), 1, "leaving")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, conv := range f.convRepo.Conversations {
+		assert.Equal(t, "staying", assigneeOf(t, conv))
+	}
+}
+
+func TestRoutingService_ChannelDefaultTeam(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	f.addAgent("outsider", models.AgentStatusOnline, 0, 0)
+	f.addAgent("billing-1", models.AgentStatusOnline, 0, 4)
+	billing, _ := f.teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 1, Name: "billing", MemberIDs: []string{"billing-1"}})
+	f.channelRepo.Channels[1].DefaultTeamID = &billing.ID
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+
+	require.NotNil(t, conv.TeamID)
+	assert.Equal(t, billing.ID, *conv.TeamID)
+	assert.Equal(t, "billing-1", assigneeOf(t, conv))
+
+	require.Len(t, f.historyRepo.Events, 2)
+	assert.Equal(t, models.ConversationEventTeamChanged, f.historyRepo.Events[0].Type)
+	assert.Equal(t, models.AssignReasonNew, *f.historyRepo.Events[0].Reason)
+	assert.Nil(t, f.historyRepo.Events[0].ActorID)
+	assert.Equal(t, models.ConversationEventAssigneeChanged, f.historyRepo.Events[1].Type)
+	assert.Equal(t, "billing-1", *f.historyRepo.Events[1].ToValue)
+	assert.Equal(t, models.AssignReasonNew, *f.historyRepo.Events[1].Reason)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, f.emitter.EmittedEvents, 1)
+	assert.Equal(t, billing.ID, f.emitter.EmittedEvents[0].Payload["team_id"])
+}
+
+func TestRoutingService_ChannelDefaultTeamWithoutAgents(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	f.addAgent("outsider", models.AgentStatusOnline, 0, 0)
+	billing, _ := f.teamRepo.Create(&models.CreateTeamRequest{OrganizationID: 1, Name: "billing"})
+	f.channelRepo.Channels[1].DefaultTeamID = &billing.ID
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	assert.Equal(t, billing.ID, *conv.TeamID)
+	assert.Nil(t, conv.AssignedToExternalID)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, f.emitter.EmittedEvents, 1)
+	payload := f.emitter.EmittedEvents[0].Payload
+	assert.Equal(t, billing.ID, payload["team_id"])
+	assert.NotContains(t, payload, "assignee_id")
+}
+
+func TestAgentService_GoingOfflineReassigns(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	agent := f.addAgent("a", models.AgentStatusOnline, 0, 0)
+	f.addAgent("b", models.AgentStatusOnline, 0, 0)
+
+	conv := f.newConversation(1)
+	require.NoError(t, f.service.RouteNew(context.Background(), conv))
+	require.Equal(t, "a", assigneeOf(t, conv))
+
+	service := NewAgentService(f.agentRepo, f.service)
+	require.NoError(t, service.UpdateStatus(context.Background(), agent.ID, models.AgentStatusOffline))
+
+	assert.Equal(t, models.AgentStatusOffline, f.agentRepo.Agents[agent.ID].Status)
+	assert.Equal(t, "b", assigneeOf(t, conv))
+}
+
+func TestMessageService_RoutesNewConversations(t *testing.T) {
+	f := newRoutingFixture(models.RoutingLeastOpen)
+	f.addAgent("a", models.AgentStatusOnline, 0, 0)
+
+	service := NewMessageService(testutils.NewMockMessageRepository(), f.convRepo,
+		testutils.NewMockExternalUserRepository(), f.emitter, f.service, nil, nil, nil, nil, nil)
+
+	msg, err := service.ProcessIncomingMessage(context.Background(), &ProcessIncomingMessageRequest{
+		ChannelID:      1,
+		PlatformUserID: "user-1",
+		Content:        "Hello",
+		MessageType:    models.MessageTypeText,
+	})
+	require.NoError(t, err)
+
+	conv := f.convRepo.Conversations[msg.ConversationID]
+	assert.Equal(t, "a", assigneeOf(t, conv))
+}
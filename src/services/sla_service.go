@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// SLAService manages SLA policies and tracks each conversation's first
+// response, next response and resolution due times
+type SLAService interface {
+	CreatePolicy(ctx context.Context, req *models.CreateSLAPolicyRequest) (*models.SLAPolicy, error)
+	GetPolicy(ctx context.Context, id int64) (*models.SLAPolicy, error)
+	ListPolicies(ctx context.Context, orgID int64, limit, offset int) ([]*models.SLAPolicy, error)
+	UpdatePolicy(ctx context.Context, id int64, req *models.UpdateSLAPolicyRequest) error
+	DeletePolicy(ctx context.Context, id int64) error
+	// Apply selects the conversation's policy and sets its first response
+	// and resolution due times, counted from the conversation's creation
+	Apply(ctx context.Context, conv *models.Conversation) error
+	// CustomerMessage starts the next response timer when a customer
+	// writes after the first response
+	CustomerMessage(ctx context.Context, conv *models.Conversation, at time.Time) error
+	// AgentReply meets the pending first or next response target
+	AgentReply(ctx context.Context, conv *models.Conversation, at time.Time) error
+	// Check emits warnings and breaches for targets close to or past due
+	// and returns how many conversations were checked
+	Check(ctx context.Context, now time.Time) (int, error)
+}
+
+type slaService struct {
+	repo             repositories.SLARepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	hoursRepo        repositories.BusinessHoursRepository
+	historyRepo      repositories.ConversationEventRepository
+	emitter          events.Emitter
+}
+
+func NewSLAService(
+	repo repositories.SLARepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	hoursRepo repositories.BusinessHoursRepository,
+	historyRepo repositories.ConversationEventRepository,
+	emitter events.Emitter,
+) SLAService {
+	return &slaService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		hoursRepo:        hoursRepo,
+		historyRepo:      historyRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *slaService) CreatePolicy(ctx context.Context, req *models.CreateSLAPolicyRequest) (*models.SLAPolicy, error) {
+	return s.repo.CreatePolicy(req)
+}
+
+func (s *slaService) GetPolicy(ctx context.Context, id int64) (*models.SLAPolicy, error) {
+	return s.repo.GetPolicy(id)
+}
+
+func (s *slaService) ListPolicies(ctx context.Context, orgID int64, limit, offset int) ([]*models.SLAPolicy, error) {
+	return s.repo.ListPolicies(orgID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *slaService) UpdatePolicy(ctx context.Context, id int64, req *models.UpdateSLAPolicyRequest) error {
+	return s.repo.UpdatePolicy(id, req)
+}
+
+func (s *slaService) DeletePolicy(ctx context.Context, id int64) error {
+	return s.repo.DeletePolicy(id)
+}
+
+func (s *slaService) Apply(ctx context.Context, conv *models.Conversation) error {
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return fmt.Errorf("channel not found")
+	}
+
+	policy, err := s.repo.MatchPolicy(channel.OrganizationID, conv.ChannelID, conv.Priority)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		if conv.SLAPolicyID == nil {
+			return nil
+		}
+		conv.SLAPolicyID = nil
+		conv.SLAStatus = ""
+		conv.FirstResponseDueAt = nil
+		conv.NextResponseDueAt = nil
+		conv.ResolutionDueAt = nil
+		return s.conversationRepo.SaveSLA(conv)
+	}
+
+	hours, err := s.schedule(policy, channel)
+	if err != nil {
+		return err
+	}
+
+	conv.SLAPolicyID = &policy.ID
+	conv.FirstResponseDueAt = dueAt(hours, conv.CreatedAt, policy.FirstResponseSeconds)
+	conv.ResolutionDueAt = dueAt(hours, conv.CreatedAt, policy.ResolutionSeconds)
+	conv.SLAStatus = slaStatus(conv, policy, time.Now())
+
+	return s.conversationRepo.SaveSLA(conv)
+}
+
+func (s *slaService) CustomerMessage(ctx context.Context, conv *models.Conversation, at time.Time) error {
+	// Until the first response, the first response target covers the wait
+	if conv.SLAPolicyID == nil || conv.FirstResponseAt == nil || conv.NextResponseDueAt != nil {
+		return nil
+	}
+
+	policy, channel, err := s.policyFor(conv)
+	if err != nil || policy == nil || policy.NextResponseSeconds == 0 {
+		return err
+	}
+	hours, err := s.schedule(policy, channel)
+	if err != nil {
+		return err
+	}
+
+	conv.NextResponseDueAt = dueAt(hours, at, policy.NextResponseSeconds)
+	conv.SLAStatus = slaStatus(conv, policy, at)
+	return s.conversationRepo.SaveSLA(conv)
+}
+
+func (s *slaService) AgentReply(ctx context.Context, conv *models.Conversation, at time.Time) error {
+	if conv.SLAPolicyID == nil || (conv.FirstResponseAt != nil && conv.NextResponseDueAt == nil) {
+		return nil
+	}
+
+	policy, _, err := s.policyFor(conv)
+	if err != nil {
+		return err
+	}
+
+	if conv.FirstResponseAt == nil {
+		replied := at.UTC()
+		conv.FirstResponseAt = &replied
+	}
+	conv.NextResponseDueAt = nil
+	if policy != nil {
+		conv.SLAStatus = slaStatus(conv, policy, at)
+	}
+	return s.conversationRepo.SaveSLA(conv)
+}
+
+func (s *slaService) Check(ctx context.Context, now time.Time) (int, error) {
+	conversations, err := s.conversationRepo.ListSLADue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	policies := make(map[int64]*models.SLAPolicy)
+	for _, conv := range conversations {
+		policy, ok := policies[*conv.SLAPolicyID]
+		if !ok {
+			if policy, err = s.repo.GetPolicy(*conv.SLAPolicyID); err != nil {
+				return 0, err
+			}
+			policies[*conv.SLAPolicyID] = policy
+		}
+		if policy == nil {
+			continue
+		}
+
+		for _, target := range pendingTargets(conv) {
+			kind := ""
+			switch {
+			case !now.Before(target.due):
+				kind = models.SLAAlertBreached
+			case !now.Before(target.due.Add(-warnWindow(policy))):
+				kind = models.SLAAlertWarning
+			default:
+				continue
+			}
+
+			created, err := s.repo.RecordAlert(&models.SLAAlert{
+				ConversationID: conv.ID,
+				Target:         target.name,
+				Kind:           kind,
+				DueAt:          target.due,
+			})
+			if err != nil {
+				return 0, err
+			}
+			if !created {
+				continue
+			}
+
+			if kind == models.SLAAlertBreached {
+				recordChange(ctx, s.historyRepo, conv.ID, models.ConversationEventSLABreached, nil, &target.name, "")
+				go events.Publish(ctx, s.emitter, events.SLABreachedPayload{
+					ConversationID: conv.ID,
+					PolicyID:       policy.ID,
+					Target:         target.name,
+					DueAt:          target.due,
+				})
+			} else {
+				recordChange(ctx, s.historyRepo, conv.ID, models.ConversationEventSLAWarning, nil, &target.name, "")
+				go events.Publish(ctx, s.emitter, events.SLAWarningPayload{
+					ConversationID: conv.ID,
+					PolicyID:       policy.ID,
+					Target:         target.name,
+					DueAt:          target.due,
+				})
+			}
+		}
+
+		if status := slaStatus(conv, policy, now); status != conv.SLAStatus {
+			conv.SLAStatus = status
+			if err := s.conversationRepo.SaveSLA(conv); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(conversations), nil
+}
+
+func (s *slaService) policyFor(conv *models.Conversation) (*models.SLAPolicy, *models.ChatChannel, error) {
+	policy, err := s.repo.GetPolicy(*conv.SLAPolicyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if channel == nil {
+		return nil, nil, fmt.Errorf("channel not found")
+	}
+	return policy, channel, nil
+}
+
+// schedule returns the business hours a policy counts, or nil to count
+// every hour
+func (s *slaService) schedule(policy *models.SLAPolicy, channel *models.ChatChannel) (*models.BusinessHours, error) {
+	if !policy.BusinessHoursOnly {
+		return nil, nil
+	}
+	return s.hoursRepo.Resolve(channel.OrganizationID, channel.ID)
+}
+
+func dueAt(hours *models.BusinessHours, from time.Time, seconds int) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	due := hours.Add(from, time.Duration(seconds)*time.Second).UTC()
+	return &due
+}
+
+func warnWindow(policy *models.SLAPolicy) time.Duration {
+	return time.Duration(policy.WarnBeforeSeconds) * time.Second
+}
+
+type slaTarget struct {
+	name string
+	due  time.Time
+}
+
+// pendingTargets lists the targets the conversation has yet to meet
+func pendingTargets(conv *models.Conversation) []slaTarget {
+	var targets []slaTarget
+	if conv.FirstResponseAt == nil && conv.FirstResponseDueAt != nil {
+		targets = append(targets, slaTarget{models.SLATargetFirstResponse, *conv.FirstResponseDueAt})
+	}
+	if conv.NextResponseDueAt != nil {
+		targets = append(targets, slaTarget{models.SLATargetNextResponse, *conv.NextResponseDueAt})
+	}
+	if conv.ResolutionDueAt != nil &&
+		(conv.Status == models.ConversationStatusOpen || conv.Status == models.ConversationStatusPending) {
+		targets = append(targets, slaTarget{models.SLATargetResolution, *conv.ResolutionDueAt})
+	}
+	return targets
+}
+
+// slaStatus is breached once any target has been missed, warning while a
+// pending target is within the warning window, and ok otherwise
+func slaStatus(conv *models.Conversation, policy *models.SLAPolicy, now time.Time) models.SLAStatus {
+	if conv.SLAStatus == models.SLAStatusBreached {
+		return models.SLAStatusBreached
+	}
+	if conv.FirstResponseAt != nil && conv.FirstResponseDueAt != nil && conv.FirstResponseAt.After(*conv.FirstResponseDueAt) {
+		return models.SLAStatusBreached
+	}
+
+	status := models.SLAStatusOK
+	for _, target := range pendingTargets(conv) {
+		if !now.Before(target.due) {
+			return models.SLAStatusBreached
+		}
+		if !now.Before(target.due.Add(-warnWindow(policy))) {
+			status = models.SLAStatusWarning
+		}
+	}
+	return status
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slaFixture struct {
+	service     SLAService
+	repo        *testutils.MockSLARepository
+	convRepo    *testutils.MockConversationRepository
+	channelRepo *testutils.MockChannelRepository
+	hoursRepo   *testutils.MockBusinessHoursRepository
+	historyRepo *testutils.MockConversationEventRepository
+	emitter     *testutils.MockEmitter
+}
+
+func newSLAFixture() *slaFixture {
+	f := &slaFixture{
+		repo:        testutils.NewMockSLARepository(),
+		convRepo:    testutils.NewMockConversationRepository(),
+		channelRepo: testutils.NewMockChannelRepository(),
+		hoursRepo:   testutils.NewMockBusinessHoursRepository(),
+		historyRepo: testutils.NewMockConversationEventRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	f.service = NewSLAService(f.repo, f.convRepo, f.channelRepo, f.hoursRepo, f.historyRepo, f.emitter)
+
+	f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	return f
+}
+
+func (f *slaFixture) newConversation(priority models.ConversationPriority, createdAt time.Time) *models.Conversation {
+	conv, _ := f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1, Priority: priority})
+	conv.CreatedAt = createdAt
+	return conv
+}
+
+func TestSLAService_ApplySelectsMostSpecificPolicy(t *testing.T) {
+	f := newSLAFixture()
+	ctx := context.Background()
+	channelID := int64(1)
+	urgent := models.PriorityUrgent
+
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Default", FirstResponseSeconds: 3600, ResolutionSeconds: 86400})
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Urgent", Priority: &urgent, FirstResponseSeconds: 900})
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "WhatsApp urgent", ChannelID: &channelID, Priority: &urgent, FirstResponseSeconds: 300})
+
+	created := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("org default", func(t *testing.T) {
+		conv := f.newConversation(models.PriorityNormal, created)
+		require.NoError(t, f.service.Apply(ctx, conv))
+
+		require.NotNil(t, conv.SLAPolicyID)
+		assert.Equal(t, int64(1), *conv.SLAPolicyID)
+		require.NotNil(t, conv.FirstResponseDueAt)
+		assert.Equal(t, created.Add(time.Hour), *conv.FirstResponseDueAt)
+		require.NotNil(t, conv.ResolutionDueAt)
+		assert.Equal(t, created.Add(24*time.Hour), *conv.ResolutionDueAt)
+		assert.Equal(t, models.SLAStatusOK, conv.SLAStatus)
+	})
+
+	t.Run("channel and priority", func(t *testing.T) {
+		conv := f.newConversation(models.PriorityUrgent, created)
+		require.NoError(t, f.service.Apply(ctx, conv))
+
+		require.NotNil(t, conv.SLAPolicyID)
+		assert.Equal(t, int64(3), *conv.SLAPolicyID)
+		assert.Equal(t, created.Add(5*time.Minute), *conv.FirstResponseDueAt)
+		assert.Nil(t, conv.ResolutionDueAt)
+	})
+
+	t.Run("no matching policy", func(t *testing.T) {
+		empty := newSLAFixture()
+		conv := empty.newConversation(models.PriorityNormal, created)
+		require.NoError(t, empty.service.Apply(ctx, conv))
+		assert.Nil(t, conv.SLAPolicyID)
+	})
+}
+
+func TestSLAService_BusinessHours(t *testing.T) {
+	f := newSLAFixture()
+	ctx := context.Background()
+
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Office", FirstResponseSeconds: 2 * 3600, BusinessHoursOnly: true})
+	f.hoursRepo.Upsert(1, 0, &models.SetBusinessHoursRequest{
+		Timezone: "Asia/Kathmandu",
+		Windows: []models.BusinessHoursWindow{
+			{Day: "mon", Start: "09:00", End: "17:00"},
+			{Day: "tue", Start: "09:00", End: "17:00"},
+		},
+	})
+
+	kathmandu, err := time.LoadLocation("Asia/Kathmandu")
+	require.NoError(t, err)
+
+	// Monday 16:00 local leaves one hour that day; the rest runs into Tuesday
+	conv := f.newConversation(models.PriorityNormal, time.Date(2026, 10, 19, 16, 0, 0, 0, kathmandu))
+	require.NoError(t, f.service.Apply(ctx, conv))
+
+	require.NotNil(t, conv.FirstResponseDueAt)
+	assert.Equal(t, time.Date(2026, 10, 20, 10, 0, 0, 0, kathmandu).UTC(), *conv.FirstResponseDueAt)
+
+	// A channel schedule takes precedence over the organization's
+	f.hoursRepo.Upsert(1, 1, &models.SetBusinessHoursRequest{
+		Timezone: "Asia/Kathmandu",
+		Windows:  []models.BusinessHoursWindow{{Day: "mon", Start: "00:00", End: "24:00"}},
+	})
+	require.NoError(t, f.service.Apply(ctx, conv))
+	assert.Equal(t, time.Date(2026, 10, 19, 18, 0, 0, 0, kathmandu).UTC(), *conv.FirstResponseDueAt)
+}
+
+func TestSLAService_ResponseTimers(t *testing.T) {
+	f := newSLAFixture()
+	ctx := context.Background()
+
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Default", FirstResponseSeconds: 600, NextResponseSeconds: 300})
+
+	created := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	conv := f.newConversation(models.PriorityNormal, created)
+	require.NoError(t, f.service.Apply(ctx, conv))
+
+	// Customer follow-ups before the first response don't start a new timer
+	require.NoError(t, f.service.CustomerMessage(ctx, conv, created.Add(time.Minute)))
+	assert.Nil(t, conv.NextResponseDueAt)
+
+	replied := created.Add(2 * time.Minute)
+	require.NoError(t, f.service.AgentReply(ctx, conv, replied))
+	require.NotNil(t, conv.FirstResponseAt)
+	assert.Equal(t, replied, *conv.FirstResponseAt)
+	assert.Equal(t, models.SLAStatusOK, conv.SLAStatus)
+
+	asked := created.Add(10 * time.Minute)
+	require.NoError(t, f.service.CustomerMessage(ctx, conv, asked))
+	require.NotNil(t, conv.NextResponseDueAt)
+	assert.Equal(t, asked.Add(5*time.Minute), *conv.NextResponseDueAt)
+
+	require.NoError(t, f.service.AgentReply(ctx, conv, asked.Add(time.Minute)))
+	assert.Nil(t, conv.NextResponseDueAt)
+	assert.Equal(t, replied, *conv.FirstResponseAt, "first response is kept")
+}
+
+func TestSLAService_Check(t *testing.T) {
+	f := newSLAFixture()
+	ctx := context.Background()
+
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Default", FirstResponseSeconds: 600, WarnBeforeSeconds: 120})
+
+	created := time.Now().UTC().Truncate(time.Second)
+	conv := f.newConversation(models.PriorityNormal, created)
+	require.NoError(t, f.service.Apply(ctx, conv))
+
+	t.Run("nothing due", func(t *testing.T) {
+		_, err := f.service.Check(ctx, created.Add(time.Minute))
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		assert.Empty(t, f.emitter.EmittedEvents)
+	})
+
+	t.Run("warning once", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := f.service.Check(ctx, created.Add(9*time.Minute))
+			require.NoError(t, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		require.Len(t, f.emitter.EmittedEvents, 1)
+		assert.Equal(t, events.EventSLAWarning, f.emitter.EmittedEvents[0].EventType)
+		assert.Equal(t, models.SLAStatusWarning, conv.SLAStatus)
+	})
+
+	t.Run("breach once", func(t *testing.T) {
+		f.emitter.EmittedEvents = nil
+		for i := 0; i < 2; i++ {
+			_, err := f.service.Check(ctx, created.Add(11*time.Minute))
+			require.NoError(t, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		require.Len(t, f.emitter.EmittedEvents, 1)
+		assert.Equal(t, events.EventSLABreached, f.emitter.EmittedEvents[0].EventType)
+		assert.Equal(t, models.SLAStatusBreached, conv.SLAStatus)
+
+		require.Len(t, f.historyRepo.Events, 2)
+		assert.Equal(t, models.ConversationEventSLAWarning, f.historyRepo.Events[0].Type)
+		assert.Equal(t, models.ConversationEventSLABreached, f.historyRepo.Events[1].Type)
+		assert.Equal(t, "SLA first response target breached", f.historyRepo.Events[1].Describe())
+	})
+
+	t.Run("breach is sticky after reply", func(t *testing.T) {
+		require.NoError(t, f.service.AgentReply(ctx, conv, created.Add(12*time.Minute)))
+		assert.Equal(t, models.SLAStatusBreached, conv.SLAStatus)
+	})
+}
+
+func TestConversationService_UpdatePriorityReappliesSLA(t *testing.T) {
+	f := newSLAFixture()
+	ctx := context.Background()
+	urgent := models.PriorityUrgent
+
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Default", FirstResponseSeconds: 3600})
+	f.repo.CreatePolicy(&models.CreateSLAPolicyRequest{OrganizationID: 1, Name: "Urgent", Priority: &urgent, FirstResponseSeconds: 600})
+
+	created := time.Now().UTC().Truncate(time.Second)
+	conv := f.newConversation(models.PriorityNormal, created)
+	require.NoError(t, f.service.Apply(ctx, conv))
+
+	conversations := NewConversationService(f.convRepo, testutils.NewMockChannelRepository(), testutils.NewMockTeamRepository(), testutils.NewMockConversationEventRepository(), f.service, nil, f.emitter)
+	require.NoError(t, conversations.UpdatePriority(ctx, conv.ID, models.PriorityUrgent))
+
+	stored, _ := f.convRepo.GetByID(conv.ID)
+	require.NotNil(t, stored.SLAPolicyID)
+	assert.Equal(t, int64(2), *stored.SLAPolicyID)
+	assert.Equal(t, created.Add(10*time.Minute), *stored.FirstResponseDueAt)
+}
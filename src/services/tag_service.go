@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+// TagService manages an organization's tag taxonomy and tags conversations
+// with it. Names outside the taxonomy are rejected with models.ErrUnknownTag.
+type TagService interface {
+	Create(ctx context.Context, req *models.CreateTagRequest) (*models.Tag, error)
+	GetByID(ctx context.Context, id int64) (*models.Tag, error)
+	ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Tag, error)
+	Update(ctx context.Context, id int64, req *models.UpdateTagRequest) error
+	Delete(ctx context.Context, id int64) error
+	ConversationTags(ctx context.Context, conversationID int64) ([]*models.Tag, error)
+	// AddTags tags the conversations, which must belong to one
+	// organization, with the named tags of its taxonomy
+	AddTags(ctx context.Context, conversationIDs []int64, names []string) error
+	RemoveTags(ctx context.Context, conversationIDs []int64, names []string) error
+	Counts(ctx context.Context, q *models.TagCountQuery) ([]*models.TagCount, error)
+}
+
+type tagService struct {
+	repo             repositories.TagRepository
+	conversationRepo repositories.ConversationRepository
+	channelRepo      repositories.ChannelRepository
+	historyRepo      repositories.ConversationEventRepository
+	emitter          events.Emitter
+}
+
+func NewTagService(
+	repo repositories.TagRepository,
+	conversationRepo repositories.ConversationRepository,
+	channelRepo repositories.ChannelRepository,
+	historyRepo repositories.ConversationEventRepository,
+	emitter events.Emitter,
+) TagService {
+	return &tagService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		historyRepo:      historyRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *tagService) Create(ctx context.Context, req *models.CreateTagRequest) (*models.Tag, error) {
+	return s.repo.Create(req)
+}
+
+func (s *tagService) GetByID(ctx context.Context, id int64) (*models.Tag, error) {
+	tag, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, fmt.Errorf("tag not found")
+	}
+	return tag, nil
+}
+
+func (s *tagService) ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Tag, error) {
+	return s.repo.ListByOrganization(orgID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *tagService) Update(ctx context.Context, id int64, req *models.UpdateTagRequest) error {
+	return s.repo.Update(id, req)
+}
+
+func (s *tagService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *tagService) ConversationTags(ctx context.Context, conversationID int64) ([]*models.Tag, error) {
+	return s.repo.ListByConversation(conversationID)
+}
+
+func (s *tagService) AddTags(ctx context.Context, conversationIDs []int64, names []string) error {
+	return s.change(ctx, conversationIDs, names, true)
+}
+
+func (s *tagService) RemoveTags(ctx context.Context, conversationIDs []int64, names []string) error {
+	return s.change(ctx, conversationIDs, names, false)
+}
+
+func (s *tagService) Counts(ctx context.Context, q *models.TagCountQuery) ([]*models.TagCount, error) {
+	return s.repo.Counts(q)
+}
+
+// change adds or removes tags on every conversation once they have all
+// been checked, recording and publishing the changes per conversation
+func (s *tagService) change(ctx context.Context, conversationIDs []int64, names []string, add bool) error {
+	orgID, err := s.organizationOf(conversationIDs)
+	if err != nil {
+		return err
+	}
+	tags, err := resolveTags(s.repo, orgID, names)
+	if err != nil {
+		return err
+	}
+
+	tagIDs := make([]int64, 0, len(tags))
+	tagNames := make(map[int64]string, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+		tagNames[tag.ID] = tag.Name
+	}
+
+	for _, conversationID := range conversationIDs {
+		var changed []int64
+		if add {
+			changed, err = s.repo.AddToConversation(conversationID, tagIDs)
+		} else {
+			changed, err = s.repo.RemoveFromConversation(conversationID, tagIDs)
+		}
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		changedNames := make([]string, 0, len(changed))
+		for _, tagID := range changed {
+			name := tagNames[tagID]
+			changedNames = append(changedNames, name)
+			if add {
+				recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventTagAdded, nil, &name, "")
+			} else {
+				recordChange(ctx, s.historyRepo, conversationID, models.ConversationEventTagRemoved, &name, nil, "")
+			}
+		}
+
+		payload := events.ConversationTagsChangedPayload{ConversationID: conversationID}
+		if add {
+			payload.Added = changedNames
+		} else {
+			payload.Removed = changedNames
+		}
+		go events.Publish(ctx, s.emitter, payload)
+	}
+
+	return nil
+}
+
+// organizationOf returns the organization the conversations all belong to
+func (s *tagService) organizationOf(conversationIDs []int64) (int64, error) {
+	var orgID int64
+	channels := make(map[int64]int64)
+	for _, conversationID := range conversationIDs {
+		conv, err := s.conversationRepo.GetByID(conversationID)
+		if err != nil {
+			return 0, err
+		}
+		if conv == nil {
+			return 0, fmt.Errorf("conversation %d not found", conversationID)
+		}
+
+		channelOrg, ok := channels[conv.ChannelID]
+		if !ok {
+			channel, err := s.channelRepo.GetByID(conv.ChannelID)
+			if err != nil {
+				return 0, err
+			}
+			if channel == nil {
+				return 0, fmt.Errorf("channel not found")
+			}
+			channelOrg = channel.OrganizationID
+			channels[conv.ChannelID] = channelOrg
+		}
+
+		if orgID != 0 && channelOrg != orgID {
+			return 0, fmt.Errorf("conversations belong to different organizations")
+		}
+		orgID = channelOrg
+	}
+	return orgID, nil
+}
+
+// resolveTags looks the names up in the organization's taxonomy
+func resolveTags(repo repositories.TagRepository, orgID int64, names []string) ([]*models.Tag, error) {
+	tags, err := repo.FindByNames(orgID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		found[tag.Name] = true
+	}
+	var unknown []string
+	for _, name := range names {
+		if normalized := models.NormalizeTagName(name); !found[normalized] {
+			unknown = append(unknown, normalized)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", models.ErrUnknownTag, strings.Join(unknown, ", "))
+	}
+	return tags, nil
+}
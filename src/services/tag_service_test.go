@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagService_AddAndRemove(t *testing.T) {
+	tagRepo := testutils.NewMockTagRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	historyRepo := testutils.NewMockConversationEventRepository()
+	emitter := testutils.NewMockEmitter()
+	service := NewTagService(tagRepo, convRepo, channelRepo, historyRepo, emitter)
+	ctx := context.Background()
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 2, Platform: models.PlatformWhatsApp, Name: "Other"})
+	first, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	second, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 2})
+	foreign, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 2, ExternalUserID: 3})
+	billing, _ := service.Create(ctx, &models.CreateTagRequest{OrganizationID: 1, Name: "billing"})
+	service.Create(ctx, &models.CreateTagRequest{OrganizationID: 2, Name: "vip"})
+
+	t.Run("rejects tags outside the taxonomy", func(t *testing.T) {
+		err := service.AddTags(ctx, []int64{first.ID}, []string{"Billing", "vip"})
+		assert.ErrorIs(t, err, models.ErrUnknownTag)
+		assert.Contains(t, err.Error(), "vip")
+		assert.Empty(t, tagRepo.Links[first.ID])
+	})
+
+	t.Run("rejects conversations of different organizations", func(t *testing.T) {
+		err := service.AddTags(ctx, []int64{first.ID, foreign.ID}, []string{"billing"})
+		assert.Error(t, err)
+		assert.Empty(t, tagRepo.Links[first.ID])
+	})
+
+	t.Run("bulk add records and publishes each change", func(t *testing.T) {
+		require.NoError(t, service.AddTags(ctx, []int64{first.ID}, []string{"billing"}))
+		require.NoError(t, service.AddTags(ctx, []int64{first.ID, second.ID}, []string{" BILLING"}))
+		assert.Equal(t, []int64{billing.ID}, tagRepo.Links[first.ID])
+		assert.Equal(t, []int64{billing.ID}, tagRepo.Links[second.ID])
+
+		require.Len(t, historyRepo.Events, 2)
+		assert.Equal(t, models.ConversationEventTagAdded, historyRepo.Events[0].Type)
+		assert.Equal(t, "billing", *historyRepo.Events[0].ToValue)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 2)
+		for _, event := range emitter.EmittedEvents {
+			assert.Equal(t, events.EventConversationTagged, event.EventType)
+			assert.Equal(t, []string{"billing"}, event.Payload["added"])
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, service.RemoveTags(ctx, []int64{first.ID}, []string{"billing"}))
+		assert.Empty(t, tagRepo.Links[first.ID])
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, emitter.EmittedEvents, 3)
+		assert.Equal(t, []string{"billing"}, emitter.EmittedEvents[2].Payload["removed"])
+		assert.Equal(t, models.ConversationEventTagRemoved, historyRepo.Events[2].Type)
+	})
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+type TeamService interface {
+	Create(ctx context.Context, req *models.CreateTeamRequest) (*models.Team, error)
+	GetByID(ctx context.Context, id int64) (*models.Team, error)
+	ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Team, error)
+	Update(ctx context.Context, id int64, req *models.UpdateTeamRequest) error
+	Delete(ctx context.Context, id int64) error
+	SetMembers(ctx context.Context, id int64, memberIDs []string) error
+}
+
+type teamService struct {
+	repo repositories.TeamRepository
+}
+
+func NewTeamService(repo repositories.TeamRepository) TeamService {
+	return &teamService{repo: repo}
+}
+
+func (s *teamService) Create(ctx context.Context, req *models.CreateTeamRequest) (*models.Team, error) {
+	return s.repo.Create(req)
+}
+
+func (s *teamService) GetByID(ctx context.Context, id int64) (*models.Team, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *teamService) ListByOrganization(ctx context.Context, orgID int64, limit, offset int) ([]*models.Team, error) {
+	return s.repo.ListByOrganization(orgID, utils.NormalizeLimit(limit), utils.NormalizeOffset(offset))
+}
+
+func (s *teamService) Update(ctx context.Context, id int64, req *models.UpdateTeamRequest) error {
+	return s.repo.Update(id, req)
+}
+
+func (s *teamService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(id)
+}
+
+func (s *teamService) SetMembers(ctx context.Context, id int64, memberIDs []string) error {
+	return s.repo.SetMembers(id, memberIDs)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+const transcriptTimeLayout = "2006-01-02 15:04:05 -07:00"
+
+// RenderTranscript renders a transcript in the given format and returns it
+// with its MIME type
+func RenderTranscript(t *models.Transcript, format models.TranscriptFormat) (string, string, error) {
+	switch format {
+	case "", models.TranscriptFormatJSON:
+		body, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to render transcript: %w", err)
+		}
+		return string(body), "application/json", nil
+	case models.TranscriptFormatText:
+		return renderTranscriptText(t), "text/plain; charset=utf-8", nil
+	case models.TranscriptFormatMarkdown:
+		return renderTranscriptMarkdown(t), "text/markdown; charset=utf-8", nil
+	case models.TranscriptFormatHTML:
+		var b strings.Builder
+		if err := transcriptHTML.Execute(&b, t); err != nil {
+			return "", "", fmt.Errorf("failed to render transcript: %w", err)
+		}
+		return b.String(), "text/html; charset=utf-8", nil
+	}
+	return "", "", fmt.Errorf("unsupported transcript format %q", format)
+}
+
+func transcriptTitle(t *models.Transcript) string {
+	title := fmt.Sprintf("Conversation #%d", t.ConversationID)
+	if t.Subject != nil && *t.Subject != "" {
+		title += ": " + *t.Subject
+	}
+	return title
+}
+
+func renderTranscriptText(t *models.Transcript) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, transcriptTitle(t))
+	fmt.Fprintf(&b, "Channel: %s (%s)\n", t.Channel, t.Platform)
+	fmt.Fprintf(&b, "Customer: %s\n", t.Customer)
+	fmt.Fprintf(&b, "Status: %s\n", t.Status)
+	fmt.Fprintf(&b, "Generated: %s (%s)\n", t.GeneratedAt.Format(transcriptTimeLayout), t.Timezone)
+
+	for _, entry := range t.Entries {
+		b.WriteString("\n")
+		at := entry.At.Format(transcriptTimeLayout)
+		if entry.Kind == models.TimelineEntryEvent {
+			fmt.Fprintf(&b, "[%s] * %s (by %s)\n", at, entry.Text, entry.Author)
+			continue
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s\n", at, entry.Author, entry.Text)
+		if entry.AttachmentURL != nil {
+			fmt.Fprintf(&b, "    Attachment: %s\n", *entry.AttachmentURL)
+		}
+	}
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+func renderTranscriptMarkdown(t *models.Transcript) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscaper.Replace(transcriptTitle(t)))
+	fmt.Fprintf(&b, "- **Channel:** %s (%s)\n", markdownEscaper.Replace(t.Channel), t.Platform)
+	fmt.Fprintf(&b, "- **Customer:** %s\n", markdownEscaper.Replace(t.Customer))
+	fmt.Fprintf(&b, "- **Status:** %s\n", t.Status)
+	fmt.Fprintf(&b, "- **Generated:** %s (%s)\n", t.GeneratedAt.Format(transcriptTimeLayout), t.Timezone)
+
+	for _, entry := range t.Entries {
+		at := entry.At.Format(transcriptTimeLayout)
+		if entry.Kind == models.TimelineEntryEvent {
+			fmt.Fprintf(&b, "\n_%s · %s (by %s)_\n", at, markdownEscaper.Replace(entry.Text), markdownEscaper.Replace(entry.Author))
+			continue
+		}
+		fmt.Fprintf(&b, "\n**%s** · %s\n\n", markdownEscaper.Replace(entry.Author), at)
+		for _, line := range strings.Split(entry.Text, "\n") {
+			fmt.Fprintf(&b, "> %s\n", markdownEscaper.Replace(line))
+		}
+		if entry.AttachmentURL != nil {
+			fmt.Fprintf(&b, ">\n> [Attachment](<%s>)\n", *entry.AttachmentURL)
+		}
+	}
+	return b.String()
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"title": transcriptTitle,
+	"time":  func(e *models.TranscriptEntry) string { return e.At.Format(transcriptTimeLayout) },
+	"event": func(e *models.TranscriptEntry) bool { return e.Kind == models.TimelineEntryEvent },
+	"inbound": func(e *models.TranscriptEntry) bool {
+		return e.Direction != nil && *e.Direction == models.DirectionInbound
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{title .}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 760px; margin: 24px auto; padding: 0 16px; }
+h1 { font-size: 20px; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 4px 12px; font-size: 14px; }
+dt { color: #59636e; }
+dd { margin: 0; }
+.entry { margin: 12px 0; padding: 8px 12px; border-radius: 8px; background: #f6f8fa; }
+.entry.inbound { background: #ddf4ff; }
+.entry.redacted .text { color: #59636e; font-style: italic; }
+.event { margin: 8px 0; color: #59636e; font-size: 13px; text-align: center; }
+.meta { font-size: 12px; color: #59636e; }
+.text { white-space: pre-wrap; margin-top: 4px; }
+</style>
+</head>
+<body>
+<h1>{{title .}}</h1>
+<dl>
+<dt>Channel</dt><dd>{{.Channel}} ({{.Platform}})</dd>
+<dt>Customer</dt><dd>{{.Customer}}</dd>
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>Generated</dt><dd>{{.GeneratedAt.Format "2006-01-02 15:04:05 -07:00"}} ({{.Timezone}})</dd>
+</dl>
+{{range .Entries}}{{if event .}}<div class="event">{{time .}} · {{.Text}} (by {{.Author}})</div>
+{{else}}<div class="entry{{if inbound .}} inbound{{end}}{{if .Redacted}} redacted{{end}}">
+<div class="meta"><strong>{{.Author}}</strong> · {{time .}}</div>
+<div class="text">{{.Text}}</div>
+{{with .AttachmentURL}}<div class="meta"><a href="{{.}}">Attachment</a></div>{{end}}
+</div>
+{{end}}{{end}}</body>
+</html>
+`))
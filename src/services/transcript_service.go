@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/utils"
+)
+
+const redactedNote = "[internal note redacted]"
+
+// TranscriptService builds full conversation transcripts and emails them
+// to customers through the organization's email channel
+type TranscriptService interface {
+	Build(ctx context.Context, conversationID int64, opts *models.TranscriptOptions) (*models.Transcript, error)
+	// Email renders the transcript without internal notes and asks the
+	// email channel to send it to `to`, or to the customer's address
+	Email(ctx context.Context, conversationID int64, to *string, opts *models.TranscriptOptions) error
+}
+
+type transcriptService struct {
+	conversationRepo repositories.ConversationRepository
+	historyRepo      repositories.ConversationEventRepository
+	channelRepo      repositories.ChannelRepository
+	externalUserRepo repositories.ExternalUserRepository
+	emitter          events.Emitter
+}
+
+func NewTranscriptService(
+	conversationRepo repositories.ConversationRepository,
+	historyRepo repositories.ConversationEventRepository,
+	channelRepo repositories.ChannelRepository,
+	externalUserRepo repositories.ExternalUserRepository,
+	emitter events.Emitter,
+) TranscriptService {
+	return &transcriptService{
+		conversationRepo: conversationRepo,
+		historyRepo:      historyRepo,
+		channelRepo:      channelRepo,
+		externalUserRepo: externalUserRepo,
+		emitter:          emitter,
+	}
+}
+
+func (s *transcriptService) Build(ctx context.Context, conversationID int64, opts *models.TranscriptOptions) (*models.Transcript, error) {
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+	channel, err := s.channelRepo.GetByID(conv.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel %w", models.ErrNotFound)
+	}
+	user, err := s.externalUserRepo.GetByID(conv.ExternalUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	transcript := &models.Transcript{
+		ConversationID: conv.ID,
+		Subject:        conv.Subject,
+		Channel:        channel.Name,
+		Platform:       channel.Platform,
+		Customer:       customerName(user),
+		Status:         conv.Status,
+		Timezone:       loc.String(),
+		GeneratedAt:    time.Now().In(loc),
+		Entries:        make([]*models.TranscriptEntry, 0),
+	}
+
+	for offset := 0; ; offset += utils.MaxLimit {
+		page, err := s.historyRepo.ListTimeline(conversationID, true, utils.MaxLimit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			if opts.ExcludeNotes && item.Message != nil && item.Message.MessageType == models.MessageTypeNote {
+				continue
+			}
+			transcript.Entries = append(transcript.Entries, transcriptEntry(item, transcript.Customer, loc, opts.RedactNotes))
+		}
+		if len(page) < utils.MaxLimit {
+			break
+		}
+	}
+
+	return transcript, nil
+}
+
+func (s *transcriptService) Email(ctx context.Context, conversationID int64, to *string, opts *models.TranscriptOptions) error {
+	conv, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conv == nil {
+		return fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+
+	if to == nil {
+		user, err := s.externalUserRepo.GetByID(conv.ExternalUserID)
+		if err != nil {
+			return err
+		}
+		if user == nil || user.Email == nil || *user.Email == "" {
+			return fmt.Errorf("%w: customer has no email address", models.ErrTranscriptUndeliverable)
+		}
+		to = user.Email
+	}
+
+	channel, err := s.emailChannel(conv.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = models.TranscriptFormatHTML
+	}
+	transcript, err := s.Build(ctx, conversationID, &models.TranscriptOptions{
+		Format:       format,
+		Location:     opts.Location,
+		ExcludeNotes: true,
+	})
+	if err != nil {
+		return err
+	}
+	body, contentType, err := RenderTranscript(transcript, format)
+	if err != nil {
+		return err
+	}
+
+	go events.Publish(ctx, s.emitter, events.TranscriptEmailPayload{
+		ConversationID: conversationID,
+		ChannelID:      channel.ID,
+		To:             *to,
+		Subject:        transcriptTitle(transcript),
+		ContentType:    contentType,
+		Body:           body,
+	})
+
+	return nil
+}
+
+// emailChannel returns the conversation's channel if it is an email
+// channel, or else the first active email channel of its organization
+func (s *transcriptService) emailChannel(channelID int64) (*models.ChatChannel, error) {
+	channel, err := s.channelRepo.GetByID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("channel %w", models.ErrNotFound)
+	}
+	if usableEmailChannel(channel) {
+		return channel, nil
+	}
+
+	channels, err := s.channelRepo.ListByOrganization(channel.OrganizationID, utils.MaxLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range channels {
+		if usableEmailChannel(candidate) {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: organization has no active email channel", models.ErrTranscriptUndeliverable)
+}
+
+func usableEmailChannel(channel *models.ChatChannel) bool {
+	return channel.Platform == models.PlatformEmail && channel.IsActive && channel.Status == models.ChannelStatusActive
+}
+
+func customerName(user *models.ExternalUser) string {
+	switch {
+	case user == nil:
+		return "Customer"
+	case user.DisplayName != nil && *user.DisplayName != "":
+		return *user.DisplayName
+	case user.PlatformUsername != nil && *user.PlatformUsername != "":
+		return *user.PlatformUsername
+	}
+	return user.PlatformUserID
+}
+
+func transcriptEntry(item *models.TimelineEntry, customer string, loc *time.Location, redactNotes bool) *models.TranscriptEntry {
+	entry := &models.TranscriptEntry{Kind: item.Kind, At: item.At.In(loc)}
+
+	if event := item.Event; event != nil {
+		entry.EventType = &event.Type
+		entry.Author = "System"
+		if event.ActorID != nil {
+			entry.Author = *event.ActorID
+		}
+		entry.Text = event.Describe()
+		return entry
+	}
+
+	msg := item.Message
+	entry.MessageID = &msg.ID
+	entry.Direction = &msg.Direction
+	entry.MessageType = &msg.MessageType
+	entry.Text = msg.Content
+	entry.AttachmentURL = msg.MediaURL
+	switch {
+	case msg.SenderType == models.SenderExternal:
+		entry.Author = customer
+	case msg.SenderType == models.SenderSystem:
+		entry.Author = "System"
+	default:
+		entry.Author = "Agent"
+	}
+
+	if msg.MessageType == models.MessageTypeNote && redactNotes {
+		entry.Text = redactedNote
+		entry.AttachmentURL = nil
+		entry.Redacted = true
+	}
+	return entry
+}
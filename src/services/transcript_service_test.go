@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transcriptFixture struct {
+	service     TranscriptService
+	convRepo    *testutils.MockConversationRepository
+	historyRepo *testutils.MockConversationEventRepository
+	channelRepo *testutils.MockChannelRepository
+	userRepo    *testutils.MockExternalUserRepository
+	emitter     *testutils.MockEmitter
+	conv        *models.Conversation
+}
+
+func newTranscriptFixture(t *testing.T) *transcriptFixture {
+	f := &transcriptFixture{
+		convRepo:    testutils.NewMockConversationRepository(),
+		historyRepo: testutils.NewMockConversationEventRepository(),
+		channelRepo: testutils.NewMockChannelRepository(),
+		userRepo:    testutils.NewMockExternalUserRepository(),
+		emitter:     testutils.NewMockEmitter(),
+	}
+	f.service = NewTranscriptService(f.convRepo, f.historyRepo, f.channelRepo, f.userRepo, f.emitter)
+
+	f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformWhatsApp, Name: "WA"})
+	name := "John <Doe>"
+	user, _ := f.userRepo.Create(&models.CreateExternalUserRequest{ChannelID: 1, PlatformUserID: "user-1", DisplayName: &name})
+	subject := "Refund"
+	f.conv, _ = f.convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: user.ID})
+	f.conv.Subject = &subject
+
+	start := time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)
+	media := "https://cdn.example.com/receipt.pdf"
+	f.historyRepo.Messages = []*models.Message{
+		{ID: 1, ConversationID: f.conv.ID, SenderType: models.SenderExternal, Direction: models.DirectionInbound,
+			MessageType: models.MessageTypeFile, Content: "Here is my receipt", MediaURL: &media, CreatedAt: start},
+		{ID: 2, ConversationID: f.conv.ID, SenderType: models.SenderInternal, Direction: models.DirectionOutbound,
+			MessageType: models.MessageTypeNote, Content: "Customer is a reseller", CreatedAt: start.Add(2 * time.Minute)},
+		{ID: 3, ConversationID: f.conv.ID, SenderType: models.SenderInternal, Direction: models.DirectionOutbound,
+			MessageType: models.MessageTypeText, Content: "Refund issued", CreatedAt: start.Add(3 * time.Minute)},
+	}
+	agent, reason := "agent-1", models.AssignReasonManual
+	require.NoError(t, f.historyRepo.Create(&models.ConversationEvent{
+		ConversationID: f.conv.ID, Type: models.ConversationEventAssigneeChanged, ToValue: &agent, Reason: &reason,
+		CreatedAt: start.Add(time.Minute),
+	}))
+	return f
+}
+
+func TestTranscriptService_Build(t *testing.T) {
+	f := newTranscriptFixture(t)
+	ctx := context.Background()
+	kathmandu, err := time.LoadLocation("Asia/Kathmandu")
+	require.NoError(t, err)
+
+	transcript, err := f.service.Build(ctx, f.conv.ID, &models.TranscriptOptions{Location: kathmandu})
+	require.NoError(t, err)
+	assert.Equal(t, "John <Doe>", transcript.Customer)
+	assert.Equal(t, "Asia/Kathmandu", transcript.Timezone)
+	require.Len(t, transcript.Entries, 4)
+
+	first := transcript.Entries[0]
+	assert.Equal(t, "John <Doe>", first.Author)
+	assert.Equal(t, "https://cdn.example.com/receipt.pdf", *first.AttachmentURL)
+	assert.Equal(t, "2026-10-18 09:45:00 +05:45", first.At.Format(transcriptTimeLayout))
+
+	assigned := transcript.Entries[1]
+	assert.Equal(t, models.TimelineEntryEvent, assigned.Kind)
+	assert.Equal(t, "Assigned to agent-1 (manual)", assigned.Text)
+	assert.Equal(t, "System", assigned.Author)
+
+	assert.Equal(t, "Customer is a reseller", transcript.Entries[2].Text)
+
+	t.Run("redacts notes", func(t *testing.T) {
+		transcript, err := f.service.Build(ctx, f.conv.ID, &models.TranscriptOptions{RedactNotes: true})
+		require.NoError(t, err)
+		note := transcript.Entries[2]
+		assert.True(t, note.Redacted)
+		assert.Equal(t, redactedNote, note.Text)
+		assert.Equal(t, "UTC", transcript.Timezone)
+	})
+
+	t.Run("renders every format", func(t *testing.T) {
+		for format, want := range map[models.TranscriptFormat]string{
+			models.TranscriptFormatJSON:     `"timezone": "Asia/Kathmandu"`,
+			models.TranscriptFormatText:     "[2026-10-18 09:48:00 +05:45] Agent: Refund issued",
+			models.TranscriptFormatMarkdown: `**John \<Doe\>**`,
+			models.TranscriptFormatHTML:     "<strong>John &lt;Doe&gt;</strong>",
+		} {
+			body, _, err := RenderTranscript(transcript, format)
+			require.NoError(t, err)
+			assert.Contains(t, body, want, format)
+			assert.Contains(t, body, "Refund", format)
+		}
+	})
+
+	t.Run("unknown conversation", func(t *testing.T) {
+		_, err := f.service.Build(ctx, 99, &models.TranscriptOptions{})
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+func TestTranscriptService_Email(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires a customer address", func(t *testing.T) {
+		f := newTranscriptFixture(t)
+		err := f.service.Email(ctx, f.conv.ID, nil, &models.TranscriptOptions{})
+		assert.ErrorIs(t, err, models.ErrTranscriptUndeliverable)
+	})
+
+	t.Run("requires an active email channel", func(t *testing.T) {
+		f := newTranscriptFixture(t)
+		to := "john@example.com"
+		f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformEmail, Name: "Support"})
+		err := f.service.Email(ctx, f.conv.ID, &to, &models.TranscriptOptions{})
+		assert.ErrorIs(t, err, models.ErrTranscriptUndeliverable)
+	})
+
+	t.Run("sends through the email channel without notes", func(t *testing.T) {
+		f := newTranscriptFixture(t)
+		email := "john@example.com"
+		f.userRepo.Users[f.conv.ExternalUserID].Email = &email
+		channel, _ := f.channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformEmail, Name: "Support"})
+		require.NoError(t, f.channelRepo.UpdateStatus(channel.ID, models.ChannelStatusActive))
+
+		require.NoError(t, f.service.Email(ctx, f.conv.ID, nil, &models.TranscriptOptions{}))
+
+		time.Sleep(10 * time.Millisecond)
+		require.Len(t, f.emitter.EmittedEvents, 1)
+		event := f.emitter.EmittedEvents[0]
+		assert.Equal(t, events.EventTranscriptEmail, event.EventType)
+		assert.Equal(t, channel.ID, event.Payload["channel_id"])
+		assert.Equal(t, "john@example.com", event.Payload["to"])
+		assert.Equal(t, "Conversation #1: Refund", event.Payload["subject"])
+		assert.True(t, strings.HasPrefix(event.Payload["content_type"].(string), "text/html"))
+		body := event.Payload["body"].(string)
+		assert.NotContains(t, body, redactedNote)
+		assert.NotContains(t, body, "reseller")
+		assert.Contains(t, body, "Refund")
+	})
+}
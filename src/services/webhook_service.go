@@ -1,35 +1,46 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/repositories"
 )
 
 
 type WebhookService interface {
-	ProcessWebhook(channelID int64, eventType string, payload interface{}) error
+	ProcessWebhook(ctx context.Context, channelID int64, eventType string, payload interface{}) error
 }
 
 type webhookService struct {
-	eventRepo  repositories.WebhookEventRepository
-	msgService MessageService
+	eventRepo    repositories.WebhookEventRepository
+	msgService   MessageService
+	participants ParticipantService
+	edits        MessageEditService
+	reactions    ReactionService
 }
 
 func NewWebhookService(
 	eventRepo repositories.WebhookEventRepository,
 	msgService MessageService,
+	participants ParticipantService,
+	edits MessageEditService,
+	reactions ReactionService,
 ) WebhookService {
 	return &webhookService{
-		eventRepo:  eventRepo,
-		msgService: msgService,
+		eventRepo:    eventRepo,
+		msgService:   msgService,
+		participants: participants,
+		edits:        edits,
+		reactions:    reactions,
 	}
 }
 
-func (s *webhookService) ProcessWebhook(channelID int64, eventType string, payload interface{}) error {
+func (s *webhookService) ProcessWebhook(ctx context.Context, channelID int64, eventType string, payload interface{}) error {
 	
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -42,19 +53,31 @@ func (s *webhookService) ProcessWebhook(channelID int64, eventType string, paylo
 		Payload:   string(payloadJSON),
 		CreatedAt: time.Now(),
 	}
+	if correlationID := events.TraceFrom(ctx).CorrelationID; correlationID != "" {
+		webhookEvent.CorrelationID = &correlationID
+	}
 
 	savedEvent, err := s.eventRepo.Create(webhookEvent)
 	if err != nil {
 		return fmt.Errorf("failed to store webhook event: %w", err)
 	}
 
+	// Events emitted while processing are caused by the stored webhook event
+	ctx = events.WithCause(ctx, fmt.Sprintf("webhook_event:%d", savedEvent.ID))
+
 	
 	var processErr error
 	switch eventType {
 	case "message":
-		processErr = s.processMessageEvent(channelID, payload)
+		processErr = s.processMessageEvent(ctx, channelID, payload)
 	case "status_update":
-		processErr = s.processStatusUpdate(channelID, payload)
+		processErr = s.processStatusUpdate(ctx, channelID, payload)
+	case "participant_joined", "participant_left":
+		processErr = s.processParticipantEvent(ctx, channelID, eventType, payload)
+	case "edited_message", "revoke":
+		processErr = s.processMessageChange(ctx, channelID, eventType, payload)
+	case "reaction":
+		processErr = s.processReaction(ctx, channelID, payload)
 	default:
 		processErr = fmt.Errorf("unknown event type: %s", eventType)
 	}
@@ -69,7 +92,7 @@ func (s *webhookService) ProcessWebhook(channelID int64, eventType string, paylo
 	return nil
 }
 
-func (s *webhookService) processMessageEvent(channelID int64, payload interface{}) error {
+func (s *webhookService) processMessageEvent(ctx context.Context, channelID int64, payload interface{}) error {
 	
 	data, ok := payload.(map[string]interface{})
 	if !ok {
@@ -80,8 +103,19 @@ func (s *webhookService) processMessageEvent(channelID int64, payload interface{
 	platformMsgID, _ := data["message_id"].(string)
 	platformUserID, _ := data["user_id"].(string)
 	userDisplayName, _ := data["user_name"].(string)
+	chatID, _ := data["chat_id"].(string)
 	content, _ := data["content"].(string)
 	msgTypeStr, _ := data["message_type"].(string)
+	replyTo, _ := data["reply_to"].(string)
+
+	var cc []string
+	if list, ok := data["cc"].([]interface{}); ok {
+		for _, item := range list {
+			if id, ok := item.(string); ok {
+				cc = append(cc, id)
+			}
+		}
+	}
 
 	if platformUserID == "" || content == "" {
 		return fmt.Errorf("missing required fields")
@@ -93,11 +127,14 @@ func (s *webhookService) processMessageEvent(channelID int64, payload interface{
 	}
 
 	
-	_, err := s.msgService.ProcessIncomingMessage(&ProcessIncomingMessageRequest{
+	_, err := s.msgService.ProcessIncomingMessage(ctx, &ProcessIncomingMessageRequest{
 		ChannelID:         channelID,
 		PlatformMessageID: platformMsgID,
 		PlatformUserID:    platformUserID,
+		PlatformChatID:    chatID,
 		UserDisplayName:   userDisplayName,
+		CC:                cc,
+		ReplyToPlatformID: replyTo,
 		Content:           content,
 		MessageType:       msgType,
 	})
@@ -105,7 +142,66 @@ func (s *webhookService) processMessageEvent(channelID int64, payload interface{
 	return err
 }
 
-func (s *webhookService) processStatusUpdate(channelID int64, payload interface{}) error {
+// processParticipantEvent records a user joining or leaving a group chat
+func (s *webhookService) processParticipantEvent(ctx context.Context, channelID int64, eventType string, payload interface{}) error {
+	data, ok := payload.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid payload format")
+	}
+
+	chatID, _ := data["chat_id"].(string)
+	platformUserID, _ := data["user_id"].(string)
+	userDisplayName, _ := data["user_name"].(string)
+	if chatID == "" || platformUserID == "" {
+		return fmt.Errorf("missing required fields")
+	}
+
+	if eventType == "participant_left" {
+		return s.participants.LeaveChat(ctx, channelID, chatID, platformUserID, time.Now())
+	}
+	return s.participants.JoinChat(ctx, channelID, chatID, platformUserID, userDisplayName, time.Now())
+}
+
+// processMessageChange applies a message the customer edited or deleted
+// on the platform
+func (s *webhookService) processMessageChange(ctx context.Context, channelID int64, eventType string, payload interface{}) error {
+	data, ok := payload.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid payload format")
+	}
+
+	platformMsgID, _ := data["message_id"].(string)
+	content, _ := data["content"].(string)
+	if platformMsgID == "" || (eventType == "edited_message" && content == "") {
+		return fmt.Errorf("missing required fields")
+	}
+
+	if eventType == "revoke" {
+		return s.edits.ApplyPlatformDelete(ctx, channelID, platformMsgID, time.Now())
+	}
+	return s.edits.ApplyPlatformEdit(ctx, channelID, platformMsgID, content, time.Now())
+}
+
+// processReaction applies a reaction the customer added or took back on the
+// platform
+func (s *webhookService) processReaction(ctx context.Context, channelID int64, payload interface{}) error {
+	data, ok := payload.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid payload format")
+	}
+
+	platformMsgID, _ := data["message_id"].(string)
+	platformUserID, _ := data["user_id"].(string)
+	emoji, _ := data["emoji"].(string)
+	removed, _ := data["removed"].(bool)
+	if platformMsgID == "" || platformUserID == "" {
+		return fmt.Errorf("missing required fields")
+	}
+
+	return s.reactions.ApplyPlatformReaction(ctx, channelID, platformMsgID, platformUserID, emoji, removed, time.Now())
+}
+
+func (s *webhookService) processStatusUpdate(ctx context.Context, channelID int64, payload interface{}) error {
 	
 	data, ok := payload.(map[string]interface{})
 	if !ok {
@@ -121,9 +217,9 @@ func (s *webhookService) processStatusUpdate(channelID int64, payload interface{
 
 	switch status {
 	case "delivered":
-		return s.msgService.MarkDelivered(int64(messageID))
+		return s.msgService.MarkDelivered(ctx, int64(messageID))
 	case "read":
-		return s.msgService.MarkRead(int64(messageID))
+		return s.msgService.MarkRead(ctx, int64(messageID))
 	}
 
 	return nil
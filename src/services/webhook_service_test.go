@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/events"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/testutils"
 
@@ -22,6 +24,7 @@ type mockMessageService struct {
 	MarkDeliveredError error
 	MarkReadError      error
 	ReturnMessage      *models.Message
+	LastTrace          events.Trace
 }
 
 func newMockMessageService() *mockMessageService {
@@ -37,7 +40,8 @@ func newMockMessageService() *mockMessageService {
 	}
 }
 
-func (m *mockMessageService) ProcessIncomingMessage(req *ProcessIncomingMessageRequest) (*models.Message, error) {
+func (m *mockMessageService) ProcessIncomingMessage(ctx context.Context, req *ProcessIncomingMessageRequest) (*models.Message, error) {
+	m.LastTrace = events.TraceFrom(ctx)
 	if m.ProcessError != nil {
 		return nil, m.ProcessError
 	}
@@ -45,7 +49,7 @@ func (m *mockMessageService) ProcessIncomingMessage(req *ProcessIncomingMessageR
 	return m.ReturnMessage, nil
 }
 
-func (m *mockMessageService) SendOutgoingMessage(req *SendOutgoingMessageRequest) (*models.Message, error) {
+func (m *mockMessageService) SendOutgoingMessage(ctx context.Context, req *SendOutgoingMessageRequest) (*models.Message, error) {
 	if m.SendError != nil {
 		return nil, m.SendError
 	}
@@ -53,11 +57,11 @@ func (m *mockMessageService) SendOutgoingMessage(req *SendOutgoingMessageRequest
 	return m.ReturnMessage, nil
 }
 
-func (m *mockMessageService) GetMessageHistory(conversationID int64, limit, offset int, before *int64) ([]*models.Message, error) {
+func (m *mockMessageService) GetMessageHistory(ctx context.Context, query *models.MessageListQuery) ([]*models.Message, error) {
 	return nil, nil
 }
 
-func (m *mockMessageService) MarkDelivered(messageID int64) error {
+func (m *mockMessageService) MarkDelivered(ctx context.Context, messageID int64) error {
 	if m.MarkDeliveredError != nil {
 		return m.MarkDeliveredError
 	}
@@ -65,7 +69,7 @@ func (m *mockMessageService) MarkDelivered(messageID int64) error {
 	return nil
 }
 
-func (m *mockMessageService) MarkRead(messageID int64) error {
+func (m *mockMessageService) MarkRead(ctx context.Context, messageID int64) error {
 	if m.MarkReadError != nil {
 		return m.MarkReadError
 	}
@@ -76,7 +80,7 @@ func (m *mockMessageService) MarkRead(messageID int64) error {
 func TestWebhookService_ProcessWebhook_MessageEvent(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id":   "msg-123",
@@ -86,7 +90,7 @@ func TestWebhookService_ProcessWebhook_MessageEvent(t *testing.T) {
 		"message_type": "text",
 	}
 
-	err := service.ProcessWebhook(1, "message", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "message", payload)
 	require.NoError(t, err)
 
 	// Verify webhook event was stored
@@ -98,17 +102,60 @@ func TestWebhookService_ProcessWebhook_MessageEvent(t *testing.T) {
 	assert.Equal(t, "Hello from webhook!", msgService.ProcessedMessages[0].Content)
 }
 
+func TestWebhookService_ProcessWebhook_GroupMessage(t *testing.T) {
+	eventRepo := testutils.NewMockWebhookEventRepository()
+	msgService := newMockMessageService()
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
+
+	err := service.ProcessWebhook(context.Background(), 1, "message", map[string]interface{}{
+		"message_id": "msg-123",
+		"user_id":    "john@example.com",
+		"chat_id":    "<thread-1@example.com>",
+		"cc":         []interface{}{"jane@example.com", "ops@example.com"},
+		"content":    "Forwarding this",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, msgService.ProcessedMessages, 1)
+	assert.Equal(t, "<thread-1@example.com>", msgService.ProcessedMessages[0].PlatformChatID)
+	assert.Equal(t, []string{"jane@example.com", "ops@example.com"}, msgService.ProcessedMessages[0].CC)
+}
+
+func TestWebhookService_ProcessWebhook_Correlation(t *testing.T) {
+	eventRepo := testutils.NewMockWebhookEventRepository()
+	msgService := newMockMessageService()
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
+
+	ctx := events.WithTrace(context.Background(), events.Trace{
+		CorrelationID: "req-1",
+		CausationID:   "req-1",
+	})
+	err := service.ProcessWebhook(ctx, 1, "message", map[string]interface{}{
+		"user_id": "user-456",
+		"content": "Hello",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, eventRepo.Events, 1)
+	require.NotNil(t, eventRepo.Events[1].CorrelationID)
+	assert.Equal(t, "req-1", *eventRepo.Events[1].CorrelationID)
+
+	// Downstream processing is caused by the stored webhook event
+	assert.Equal(t, "req-1", msgService.LastTrace.CorrelationID)
+	assert.Equal(t, "webhook_event:1", msgService.LastTrace.CausationID)
+}
+
 func TestWebhookService_ProcessWebhook_StatusUpdateDelivered(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id": float64(123),
 		"status":     "delivered",
 	}
 
-	err := service.ProcessWebhook(1, "status_update", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "status_update", payload)
 	require.NoError(t, err)
 
 	// Verify webhook event was stored
@@ -122,14 +169,14 @@ func TestWebhookService_ProcessWebhook_StatusUpdateDelivered(t *testing.T) {
 func TestWebhookService_ProcessWebhook_StatusUpdateRead(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id": float64(123),
 		"status":     "read",
 	}
 
-	err := service.ProcessWebhook(1, "status_update", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "status_update", payload)
 	require.NoError(t, err)
 
 	// Verify mark read was called
@@ -140,13 +187,13 @@ func TestWebhookService_ProcessWebhook_StatusUpdateRead(t *testing.T) {
 func TestWebhookService_ProcessWebhook_UnknownEventType(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"data": "test",
 	}
 
-	err := service.ProcessWebhook(1, "unknown_event", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "unknown_event", payload)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown event type")
 
@@ -158,13 +205,13 @@ func TestWebhookService_ProcessWebhook_EventRepoError(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	eventRepo.CreateError = errors.New("database error")
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"data": "test",
 	}
 
-	err := service.ProcessWebhook(1, "message", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "message", payload)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to store webhook event")
 }
@@ -173,7 +220,7 @@ func TestWebhookService_ProcessWebhook_MessageProcessError(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
 	msgService.ProcessError = errors.New("processing failed")
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id": "msg-123",
@@ -181,7 +228,7 @@ func TestWebhookService_ProcessWebhook_MessageProcessError(t *testing.T) {
 		"content":    "Hello!",
 	}
 
-	err := service.ProcessWebhook(1, "message", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "message", payload)
 	assert.Error(t, err)
 
 	// Verify event was marked as failed
@@ -192,12 +239,12 @@ func TestWebhookService_ProcessWebhook_MessageProcessError(t *testing.T) {
 func TestWebhookService_ProcessWebhook_InvalidPayloadFormat(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	// Pass a non-map payload
 	payload := "invalid"
 
-	err := service.ProcessWebhook(1, "message", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "message", payload)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid payload format")
 }
@@ -205,14 +252,14 @@ func TestWebhookService_ProcessWebhook_InvalidPayloadFormat(t *testing.T) {
 func TestWebhookService_ProcessWebhook_MissingRequiredFields(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	// Payload missing user_id and content
 	payload := map[string]interface{}{
 		"message_id": "msg-123",
 	}
 
-	err := service.ProcessWebhook(1, "message", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "message", payload)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing required fields")
 }
@@ -220,13 +267,13 @@ func TestWebhookService_ProcessWebhook_MissingRequiredFields(t *testing.T) {
 func TestWebhookService_ProcessWebhook_StatusUpdateMissingMessageID(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"status": "delivered",
 	}
 
-	err := service.ProcessWebhook(1, "status_update", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "status_update", payload)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing message_id")
 }
@@ -235,14 +282,14 @@ func TestWebhookService_ProcessWebhook_MarkDeliveredError(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
 	msgService.MarkDeliveredError = errors.New("mark delivered failed")
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id": float64(123),
 		"status":     "delivered",
 	}
 
-	err := service.ProcessWebhook(1, "status_update", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "status_update", payload)
 	assert.Error(t, err)
 }
 
@@ -250,13 +297,90 @@ func TestWebhookService_ProcessWebhook_MarkReadError(t *testing.T) {
 	eventRepo := testutils.NewMockWebhookEventRepository()
 	msgService := newMockMessageService()
 	msgService.MarkReadError = errors.New("mark read failed")
-	service := NewWebhookService(eventRepo, msgService)
+	service := NewWebhookService(eventRepo, msgService, nil, nil, nil)
 
 	payload := map[string]interface{}{
 		"message_id": float64(123),
 		"status":     "read",
 	}
 
-	err := service.ProcessWebhook(1, "status_update", payload)
+	err := service.ProcessWebhook(context.Background(), 1, "status_update", payload)
 	assert.Error(t, err)
 }
+
+func TestWebhookService_ProcessWebhook_MessageChanges(t *testing.T) {
+	eventRepo := testutils.NewMockWebhookEventRepository()
+	msgRepo := testutils.NewMockMessageRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	edits := NewMessageEditService(msgRepo, convRepo, channelRepo, testutils.NewMockEmitter())
+	service := NewWebhookService(eventRepo, newMockMessageService(), nil, edits, nil)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformTelegram, Name: "TG"})
+	conv, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	platformID := "tg-42"
+	msg, _ := msgRepo.Create(&models.Message{ConversationID: conv.ID, PlatformMessageID: &platformID, Content: "helo", Direction: models.DirectionInbound})
+
+	err := service.ProcessWebhook(context.Background(), 1, "edited_message", map[string]interface{}{
+		"message_id": "tg-42",
+		"content":    "hello",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.Content)
+
+	err = service.ProcessWebhook(context.Background(), 1, "revoke", map[string]interface{}{
+		"message_id": "tg-42",
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, msg.DeletedAt)
+
+	err = service.ProcessWebhook(context.Background(), 1, "edited_message", map[string]interface{}{
+		"message_id": "tg-42",
+	})
+	assert.Error(t, err)
+	assert.NotNil(t, eventRepo.Events[3].Error)
+}
+
+func TestWebhookService_ProcessWebhook_Reactions(t *testing.T) {
+	eventRepo := testutils.NewMockWebhookEventRepository()
+	msgRepo := testutils.NewMockMessageRepository()
+	convRepo := testutils.NewMockConversationRepository()
+	channelRepo := testutils.NewMockChannelRepository()
+	reactionRepo := testutils.NewMockReactionRepository()
+	reactions := NewReactionService(reactionRepo, msgRepo, convRepo, channelRepo, testutils.NewMockEmitter())
+	msgService := newMockMessageService()
+	service := NewWebhookService(eventRepo, msgService, nil, nil, reactions)
+
+	channelRepo.Create(&models.CreateChannelRequest{OrganizationID: 1, Platform: models.PlatformTelegram, Name: "TG"})
+	conv, _ := convRepo.Create(&models.CreateConversationRequest{ChannelID: 1, ExternalUserID: 1})
+	platformID := "tg-42"
+	msgRepo.Create(&models.Message{ConversationID: conv.ID, PlatformMessageID: &platformID, Content: "hello", Direction: models.DirectionInbound})
+
+	err := service.ProcessWebhook(context.Background(), 1, "reaction", map[string]interface{}{
+		"message_id": "tg-42",
+		"user_id":    "user-1",
+		"emoji":      "👍",
+	})
+	require.NoError(t, err)
+	require.Len(t, reactionRepo.Reactions, 1)
+	assert.Equal(t, "user-1", reactionRepo.Reactions[0].SenderID)
+
+	err = service.ProcessWebhook(context.Background(), 1, "reaction", map[string]interface{}{
+		"message_id": "tg-42",
+		"user_id":    "user-1",
+		"emoji":      "👍",
+		"removed":    true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, reactionRepo.Reactions)
+
+	err = service.ProcessWebhook(context.Background(), 1, "message", map[string]interface{}{
+		"message_id": "tg-43",
+		"user_id":    "user-1",
+		"content":    "see above",
+		"reply_to":   "tg-42",
+	})
+	require.NoError(t, err)
+	require.Len(t, msgService.ProcessedMessages, 1)
+	assert.Equal(t, "tg-42", msgService.ProcessedMessages[0].ReplyToPlatformID)
+}
@@ -0,0 +1,141 @@
+package testutils
+
+import (
+	"slices"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockAgentRepository is a mock implementation of AgentRepository.
+// OpenCounts sets the open conversation count reported by ListAvailable.
+type MockAgentRepository struct {
+	Agents      map[int64]*models.Agent
+	OpenCounts  map[string]int
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+}
+
+func NewMockAgentRepository() *MockAgentRepository {
+	return &MockAgentRepository{
+		Agents:     make(map[int64]*models.Agent),
+		OpenCounts: make(map[string]int),
+		NextID:     1,
+	}
+}
+
+func (m *MockAgentRepository) Create(req *models.CreateAgentRequest) (*models.Agent, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	status := req.Status
+	if status == "" {
+		status = models.AgentStatusOffline
+	}
+	agent := &models.Agent{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		ExternalID:     req.ExternalID,
+		Name:           req.Name,
+		Status:         status,
+		MaxConcurrent:  req.MaxConcurrent,
+	}
+	m.Agents[agent.ID] = agent
+	m.NextID++
+	return agent, nil
+}
+
+func (m *MockAgentRepository) GetByID(id int64) (*models.Agent, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	agent, ok := m.Agents[id]
+	if !ok {
+		return nil, nil
+	}
+	return agent, nil
+}
+
+func (m *MockAgentRepository) GetByExternalID(orgID int64, externalID string) (*models.Agent, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	for _, agent := range m.Agents {
+		if agent.OrganizationID == orgID && agent.ExternalID == externalID {
+			return agent, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAgentRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Agent, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Agent, 0)
+	for _, agent := range m.Agents {
+		if agent.OrganizationID == orgID {
+			result = append(result, agent)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAgentRepository) Update(id int64, req *models.UpdateAgentRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	agent, ok := m.Agents[id]
+	if !ok {
+		return nil
+	}
+	if req.Name != nil {
+		agent.Name = *req.Name
+	}
+	if req.MaxConcurrent != nil {
+		agent.MaxConcurrent = *req.MaxConcurrent
+	}
+	return nil
+}
+
+func (m *MockAgentRepository) UpdateStatus(id int64, status models.AgentStatus) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if agent, ok := m.Agents[id]; ok {
+		agent.Status = status
+	}
+	return nil
+}
+
+func (m *MockAgentRepository) ListAvailable(orgID int64) ([]*models.AgentLoad, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.AgentLoad, 0)
+	for _, agent := range m.Agents {
+		if agent.OrganizationID == orgID && agent.Status == models.AgentStatusOnline {
+			result = append(result, &models.AgentLoad{
+				Agent:             *agent,
+				OpenConversations: m.OpenCounts[agent.ExternalID],
+			})
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.AgentLoad) int { return int(a.ID - b.ID) })
+	return result, nil
+}
+
+func (m *MockAgentRepository) MarkAssigned(id int64) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if agent, ok := m.Agents[id]; ok {
+		now := time.Now()
+		agent.LastAssignedAt = &now
+		m.OpenCounts[agent.ExternalID]++
+	}
+	return nil
+}
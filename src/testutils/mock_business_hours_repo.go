@@ -0,0 +1,59 @@
+package testutils
+
+import "github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+type businessHoursScope struct {
+	orgID, channelID int64
+}
+
+// MockBusinessHoursRepository is a mock implementation of
+// BusinessHoursRepository
+type MockBusinessHoursRepository struct {
+	Hours       map[businessHoursScope]*models.BusinessHours
+	NextID      int64
+	GetError    error
+	UpsertError error
+}
+
+func NewMockBusinessHoursRepository() *MockBusinessHoursRepository {
+	return &MockBusinessHoursRepository{
+		Hours:  make(map[businessHoursScope]*models.BusinessHours),
+		NextID: 1,
+	}
+}
+
+func (m *MockBusinessHoursRepository) Find(orgID, channelID int64) (*models.BusinessHours, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	return m.Hours[businessHoursScope{orgID, channelID}], nil
+}
+
+func (m *MockBusinessHoursRepository) Resolve(orgID, channelID int64) (*models.BusinessHours, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	if hours, ok := m.Hours[businessHoursScope{orgID, channelID}]; ok {
+		return hours, nil
+	}
+	return m.Hours[businessHoursScope{orgID, 0}], nil
+}
+
+func (m *MockBusinessHoursRepository) Upsert(orgID, channelID int64, req *models.SetBusinessHoursRequest) (*models.BusinessHours, error) {
+	if m.UpsertError != nil {
+		return nil, m.UpsertError
+	}
+	scope := businessHoursScope{orgID, channelID}
+	hours, ok := m.Hours[scope]
+	if !ok {
+		hours = &models.BusinessHours{ID: m.NextID, OrganizationID: orgID, ChannelID: channelID}
+		m.NextID++
+		m.Hours[scope] = hours
+	}
+	hours.Timezone = req.Timezone
+	hours.Windows = req.Windows
+	hours.Holidays = req.Holidays
+	hours.AwayMessage = req.AwayMessage
+	hours.AwayCooldownSeconds = req.AwayCooldownSeconds
+	return hours, nil
+}
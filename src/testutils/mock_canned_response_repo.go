@@ -0,0 +1,111 @@
+package testutils
+
+import (
+	"slices"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockCannedResponseRepository is a mock implementation of
+// CannedResponseRepository
+type MockCannedResponseRepository struct {
+	Responses   map[int64]*models.CannedResponse
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+	DeleteError error
+}
+
+func NewMockCannedResponseRepository() *MockCannedResponseRepository {
+	return &MockCannedResponseRepository{
+		Responses: make(map[int64]*models.CannedResponse),
+		NextID:    1,
+	}
+}
+
+func (m *MockCannedResponseRepository) Create(req *models.CreateCannedResponseRequest) (*models.CannedResponse, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	response := &models.CannedResponse{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		Shortcut:       req.Shortcut,
+		Title:          req.Title,
+		Content:        req.Content,
+	}
+	m.Responses[response.ID] = response
+	m.NextID++
+	return response, nil
+}
+
+func (m *MockCannedResponseRepository) GetByID(id int64) (*models.CannedResponse, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	response, ok := m.Responses[id]
+	if !ok {
+		return nil, nil
+	}
+	return response, nil
+}
+
+func (m *MockCannedResponseRepository) List(q *models.CannedResponseQuery) ([]*models.CannedResponse, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	search := strings.ToLower(q.Search)
+	result := make([]*models.CannedResponse, 0)
+	for _, response := range m.Responses {
+		if response.OrganizationID != q.OrganizationID {
+			continue
+		}
+		if q.TeamID != nil && response.TeamID != nil && *response.TeamID != *q.TeamID {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(response.Shortcut+"\n"+response.Title+"\n"+response.Content), search) {
+			continue
+		}
+		result = append(result, response)
+	}
+	slices.SortFunc(result, func(a, b *models.CannedResponse) int { return strings.Compare(a.Shortcut, b.Shortcut) })
+	return result, nil
+}
+
+func (m *MockCannedResponseRepository) Update(id int64, req *models.UpdateCannedResponseRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	response, ok := m.Responses[id]
+	if !ok {
+		return nil
+	}
+	if req.TeamID != nil {
+		response.TeamID = req.TeamID
+		if *req.TeamID == 0 {
+			response.TeamID = nil
+		}
+	}
+	if req.Shortcut != nil {
+		response.Shortcut = *req.Shortcut
+	}
+	if req.Title != nil {
+		response.Title = *req.Title
+	}
+	if req.Content != nil {
+		response.Content = *req.Content
+	}
+	return nil
+}
+
+func (m *MockCannedResponseRepository) Delete(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Responses, id)
+	return nil
+}
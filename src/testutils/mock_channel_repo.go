@@ -32,6 +32,8 @@ func (m *MockChannelRepository) Create(req *models.CreateChannelRequest) (*model
 		AccountIdentifier: req.AccountIdentifier,
 		Status:            models.ChannelStatusPending,
 		IsActive:          true,
+		ActivityMessages:  req.ActivityMessages,
+		RetainDeletedDays: req.RetainDeletedDays,
 	}
 	m.Channels[channel.ID] = channel
 	m.NextID++
@@ -76,6 +78,12 @@ func (m *MockChannelRepository) Update(id int64, req *models.UpdateChannelReques
 	if req.Status != nil {
 		channel.Status = *req.Status
 	}
+	if req.ActivityMessages != nil {
+		channel.ActivityMessages = *req.ActivityMessages
+	}
+	if req.RetainDeletedDays != nil {
+		channel.RetainDeletedDays = *req.RetainDeletedDays
+	}
 	return nil
 }
 
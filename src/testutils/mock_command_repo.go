@@ -0,0 +1,63 @@
+package testutils
+
+import (
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockCommandRepository is a mock implementation of CommandRepository
+type MockCommandRepository struct {
+	Commands    map[string]*models.ProcessedCommand
+	ClaimError  error
+	UpdateError error
+}
+
+func NewMockCommandRepository() *MockCommandRepository {
+	return &MockCommandRepository{
+		Commands: make(map[string]*models.ProcessedCommand),
+	}
+}
+
+func (m *MockCommandRepository) Claim(commandID string, name models.CommandName, now, staleBefore time.Time) (bool, error) {
+	if m.ClaimError != nil {
+		return false, m.ClaimError
+	}
+	if cmd, ok := m.Commands[commandID]; ok {
+		if cmd.Status != models.CommandStatusPending || cmd.ClaimedAt.After(staleBefore) {
+			return false, nil
+		}
+		cmd.ClaimedAt = now
+		return true, nil
+	}
+	m.Commands[commandID] = &models.ProcessedCommand{
+		CommandID: commandID,
+		Name:      name,
+		Status:    models.CommandStatusPending,
+		ClaimedAt: now,
+	}
+	return true, nil
+}
+
+func (m *MockCommandRepository) GetByID(commandID string) (*models.ProcessedCommand, error) {
+	cmd, ok := m.Commands[commandID]
+	if !ok {
+		return nil, fmt.Errorf("command not found")
+	}
+	return cmd, nil
+}
+
+func (m *MockCommandRepository) Complete(commandID string, status models.CommandStatus, result *string, errorMsg *string) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	cmd, ok := m.Commands[commandID]
+	if !ok {
+		return fmt.Errorf("command not found")
+	}
+	cmd.Status = status
+	cmd.Result = result
+	cmd.Error = errorMsg
+	return nil
+}
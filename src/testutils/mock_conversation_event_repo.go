@@ -0,0 +1,65 @@
+package testutils
+
+import (
+	"sort"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockConversationEventRepository is a mock implementation of
+// ConversationEventRepository that keeps events in insertion order.
+// Messages are interleaved into timelines that include messages.
+type MockConversationEventRepository struct {
+	Events      []*models.ConversationEvent
+	Messages    []*models.Message
+	NextID      int64
+	CreateError error
+	ListError   error
+}
+
+func NewMockConversationEventRepository() *MockConversationEventRepository {
+	return &MockConversationEventRepository{NextID: 1}
+}
+
+func (m *MockConversationEventRepository) Create(event *models.ConversationEvent) error {
+	if m.CreateError != nil {
+		return m.CreateError
+	}
+	event.ID = m.NextID
+	m.NextID++
+	m.Events = append(m.Events, event)
+	return nil
+}
+
+func (m *MockConversationEventRepository) ListByConversation(conversationID int64, limit, offset int) ([]*models.ConversationEvent, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.ConversationEvent, 0)
+	for _, event := range m.Events {
+		if event.ConversationID == conversationID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockConversationEventRepository) ListTimeline(conversationID int64, includeMessages bool, limit, offset int) ([]*models.TimelineEntry, error) {
+	history, err := m.ListByConversation(conversationID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	timeline := make([]*models.TimelineEntry, 0, len(history))
+	for _, event := range history {
+		timeline = append(timeline, &models.TimelineEntry{Kind: models.TimelineEntryEvent, At: event.CreatedAt, Event: event})
+	}
+	if includeMessages {
+		for _, msg := range m.Messages {
+			if msg.ConversationID == conversationID {
+				timeline = append(timeline, &models.TimelineEntry{Kind: models.TimelineEntryMessage, At: msg.CreatedAt, Message: msg})
+			}
+		}
+		sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].At.Before(timeline[j].At) })
+	}
+	return timeline, nil
+}
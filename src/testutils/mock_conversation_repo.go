@@ -1,6 +1,12 @@
 package testutils
 
-import "github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
 
 // MockConversationRepository is a mock implementation of ConversationRepository
 type MockConversationRepository struct {
@@ -10,6 +16,15 @@ type MockConversationRepository struct {
 	GetError      error
 	ListError     error
 	UpdateError   error
+
+	LastInboxQuery *models.InboxQuery
+	Unanswered     []*models.Conversation
+	Idle           []*models.Conversation
+
+	// Moved is the message count returned by Merge and Split
+	Moved int64
+	// History holds the history entries recorded by Merge
+	History []*models.ConversationEvent
 }
 
 func NewMockConversationRepository() *MockConversationRepository {
@@ -27,6 +42,7 @@ func (m *MockConversationRepository) Create(req *models.CreateConversationReques
 		ID:             m.NextID,
 		ChannelID:      req.ChannelID,
 		ExternalUserID: req.ExternalUserID,
+		PlatformChatID: req.PlatformChatID,
 		Status:         models.ConversationStatusOpen,
 		Priority:       req.Priority,
 	}
@@ -46,23 +62,44 @@ func (m *MockConversationRepository) GetByID(id int64) (*models.Conversation, er
 	return conv, nil
 }
 
-func (m *MockConversationRepository) GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, error) {
+func (m *MockConversationRepository) GetOrCreateByUser(channelID, externalUserID int64) (*models.Conversation, bool, error) {
+	return m.getOrCreate(func(conv *models.Conversation) bool {
+		return conv.ChannelID == channelID && conv.ExternalUserID == externalUserID && conv.PlatformChatID == nil
+	}, &models.CreateConversationRequest{
+		ChannelID:      channelID,
+		ExternalUserID: externalUserID,
+		Priority:       models.PriorityNormal,
+	})
+}
+
+func (m *MockConversationRepository) GetOrCreateByChat(channelID int64, platformChatID string, externalUserID int64) (*models.Conversation, bool, error) {
+	return m.getOrCreate(func(conv *models.Conversation) bool {
+		return conv.ChannelID == channelID && conv.PlatformChatID != nil && *conv.PlatformChatID == platformChatID
+	}, &models.CreateConversationRequest{
+		ChannelID:      channelID,
+		ExternalUserID: externalUserID,
+		PlatformChatID: &platformChatID,
+		Priority:       models.PriorityNormal,
+	})
+}
+
+func (m *MockConversationRepository) getOrCreate(match func(*models.Conversation) bool, req *models.CreateConversationRequest) (*models.Conversation, bool, error) {
 	if m.GetError != nil {
-		return nil, m.GetError
+		return nil, false, m.GetError
 	}
 	for _, conv := range m.Conversations {
-		if conv.ChannelID == channelID && conv.ExternalUserID == externalUserID {
-			return conv, nil
+		if match(conv) &&
+			(conv.Status == models.ConversationStatusOpen || conv.Status == models.ConversationStatusPending ||
+				conv.Status == models.ConversationStatusSnoozed) {
+			return conv, false, nil
 		}
 	}
-	return m.Create(&models.CreateConversationRequest{
-		ChannelID:      channelID,
-		ExternalUserID: externalUserID,
-		Priority:       models.PriorityNormal,
-	})
+	conv, err := m.Create(req)
+	return conv, err == nil, err
 }
 
-func (m *MockConversationRepository) List(channelID int64, status *models.ConversationStatus, limit, offset int) ([]*models.Conversation, error) {
+// List ignores the tag and custom attribute filters
+func (m *MockConversationRepository) List(channelID int64, status *models.ConversationStatus, tags []string, attrs *models.AttributeQuery, limit, offset int) ([]*models.Conversation, error) {
 	if m.ListError != nil {
 		return nil, m.ListError
 	}
@@ -77,6 +114,18 @@ func (m *MockConversationRepository) List(channelID int64, status *models.Conver
 	return result, nil
 }
 
+func (m *MockConversationRepository) SetAttributes(id int64, values models.CustomAttributes) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	conv, ok := m.Conversations[id]
+	if !ok {
+		return nil
+	}
+	conv.CustomAttributes = mergeAttributes(conv.CustomAttributes, values)
+	return nil
+}
+
 func (m *MockConversationRepository) Update(id int64, req *models.UpdateConversationRequest) error {
 	if m.UpdateError != nil {
 		return m.UpdateError
@@ -87,12 +136,44 @@ func (m *MockConversationRepository) Update(id int64, req *models.UpdateConversa
 	}
 	if req.Status != nil {
 		conv.Status = *req.Status
+		conv.SnoozedUntil = nil
+		if conv.Status == models.ConversationStatusSnoozed && req.SnoozedUntil != nil {
+			until := *req.SnoozedUntil
+			conv.SnoozedUntil = &until
+		}
+		switch conv.Status {
+		case models.ConversationStatusResolved:
+			now := time.Now()
+			conv.ResolvedAt = &now
+		case models.ConversationStatusClosed:
+			if conv.ResolvedAt == nil {
+				now := time.Now()
+				conv.ResolvedAt = &now
+			}
+		default:
+			conv.ResolvedAt = nil
+		}
 	}
 	if req.Priority != nil {
 		conv.Priority = *req.Priority
 	}
+	if req.Subject != nil {
+		subject := *req.Subject
+		conv.Subject = &subject
+	}
 	if req.AssignedToExternalID != nil {
-		conv.AssignedToExternalID = req.AssignedToExternalID
+		if assignee := *req.AssignedToExternalID; assignee != "" {
+			conv.AssignedToExternalID = &assignee
+		} else {
+			conv.AssignedToExternalID = nil
+		}
+	}
+	if req.TeamID != nil {
+		if teamID := *req.TeamID; teamID != 0 {
+			conv.TeamID = &teamID
+		} else {
+			conv.TeamID = nil
+		}
 	}
 	return nil
 }
@@ -103,3 +184,205 @@ func (m *MockConversationRepository) UpdateLastMessage(id int64) error {
 	}
 	return nil
 }
+
+func (m *MockConversationRepository) ClaimAwayReply(id int64, at, since time.Time) (bool, error) {
+	if m.UpdateError != nil {
+		return false, m.UpdateError
+	}
+	conv, ok := m.Conversations[id]
+	if !ok || (conv.AwayRepliedAt != nil && conv.AwayRepliedAt.After(since)) {
+		return false, nil
+	}
+	conv.AwayRepliedAt = &at
+	return true, nil
+}
+
+func (m *MockConversationRepository) ListInbox(q *models.InboxQuery) (*models.InboxPage, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	m.LastInboxQuery = q
+	page := &models.InboxPage{Data: make([]*models.Conversation, 0), Limit: q.Limit}
+	for _, conv := range m.Conversations {
+		if (len(q.Statuses) == 0 && conv.Status != models.ConversationStatusSnoozed) || slices.Contains(q.Statuses, conv.Status) {
+			page.Data = append(page.Data, conv)
+		}
+	}
+	page.Total = int64(len(page.Data))
+	return page, nil
+}
+
+func (m *MockConversationRepository) LastAssigneeForUser(externalUserID, excludeID int64) (*string, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	var latest *models.Conversation
+	for _, conv := range m.Conversations {
+		if conv.ExternalUserID == externalUserID && conv.ID != excludeID && conv.AssignedToExternalID != nil {
+			if latest == nil || conv.ID > latest.ID {
+				latest = conv
+			}
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return latest.AssignedToExternalID, nil
+}
+
+func (m *MockConversationRepository) ListOpenByAssignee(orgID int64, assigneeID string) ([]*models.Conversation, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Conversation, 0)
+	for _, conv := range m.Conversations {
+		if conv.AssignedToExternalID != nil && *conv.AssignedToExternalID == assigneeID &&
+			(conv.Status == models.ConversationStatusOpen || conv.Status == models.ConversationStatusPending) {
+			result = append(result, conv)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Conversation) int { return int(a.ID - b.ID) })
+	return result, nil
+}
+
+// ListUnanswered returns the conversations listed in Unanswered
+func (m *MockConversationRepository) ListUnanswered(now time.Time) ([]*models.Conversation, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.Unanswered, nil
+}
+
+// ListIdle returns the conversations listed in Idle
+func (m *MockConversationRepository) ListIdle(now time.Time) ([]*models.Conversation, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	return m.Idle, nil
+}
+
+func (m *MockConversationRepository) ListSnoozedDue(now time.Time) ([]*models.Conversation, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Conversation, 0)
+	for _, conv := range m.Conversations {
+		if conv.Status == models.ConversationStatusSnoozed && conv.SnoozedUntil != nil && !conv.SnoozedUntil.After(now) {
+			result = append(result, conv)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Conversation) int { return int(a.ID - b.ID) })
+	return result, nil
+}
+
+func (m *MockConversationRepository) LatestByUser(channelID, externalUserID int64) (*models.Conversation, error) {
+	return m.latest(func(conv *models.Conversation) bool {
+		return conv.ChannelID == channelID && conv.ExternalUserID == externalUserID && conv.PlatformChatID == nil
+	})
+}
+
+func (m *MockConversationRepository) LatestByChat(channelID int64, platformChatID string) (*models.Conversation, error) {
+	return m.latest(func(conv *models.Conversation) bool {
+		return conv.ChannelID == channelID && conv.PlatformChatID != nil && *conv.PlatformChatID == platformChatID
+	})
+}
+
+func (m *MockConversationRepository) latest(match func(*models.Conversation) bool) (*models.Conversation, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	var latest *models.Conversation
+	for _, conv := range m.Conversations {
+		if match(conv) && conv.MergedIntoID == nil && (latest == nil || conv.ID > latest.ID) {
+			latest = conv
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockConversationRepository) SaveSLA(conv *models.Conversation) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	stored, ok := m.Conversations[conv.ID]
+	if !ok {
+		return nil
+	}
+	stored.SLAPolicyID = conv.SLAPolicyID
+	stored.SLAStatus = conv.SLAStatus
+	stored.FirstResponseAt = conv.FirstResponseAt
+	stored.FirstResponseDueAt = conv.FirstResponseDueAt
+	stored.NextResponseDueAt = conv.NextResponseDueAt
+	stored.ResolutionDueAt = conv.ResolutionDueAt
+	return nil
+}
+
+// ListSLADue returns every open or pending conversation with an SLA policy,
+// sorted by ID, leaving the due time checks to the caller
+func (m *MockConversationRepository) ListSLADue(now time.Time) ([]*models.Conversation, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Conversation, 0)
+	for _, conv := range m.Conversations {
+		if conv.SLAPolicyID != nil &&
+			(conv.Status == models.ConversationStatusOpen || conv.Status == models.ConversationStatusPending) {
+			result = append(result, conv)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Conversation) int { return int(a.ID - b.ID) })
+	return result, nil
+}
+
+// Merge closes the source with a pointer to the target and returns Moved
+func (m *MockConversationRepository) Merge(sourceID, targetID int64, history []*models.ConversationEvent) (int64, error) {
+	if m.UpdateError != nil {
+		return 0, m.UpdateError
+	}
+	source, ok := m.Conversations[sourceID]
+	if !ok {
+		return 0, nil
+	}
+	source.MergedIntoID = &targetID
+	source.Status = models.ConversationStatusClosed
+	m.History = append(m.History, history...)
+	return m.Moved, nil
+}
+
+// Split creates a conversation for the source's user and returns it with
+// Moved. It is resolved while the source is active, and otherwise opened
+// unless the user has another active conversation.
+func (m *MockConversationRepository) Split(sourceID, fromMessageID, toMessageID int64) (*models.Conversation, int64, error) {
+	if m.UpdateError != nil {
+		return nil, 0, m.UpdateError
+	}
+	source, ok := m.Conversations[sourceID]
+	if !ok {
+		return nil, 0, nil
+	}
+	status := models.ConversationStatusOpen
+	if isActive(source) {
+		status = models.ConversationStatusResolved
+	} else {
+		for _, conv := range m.Conversations {
+			if conv.ChannelID == source.ChannelID && conv.ExternalUserID == source.ExternalUserID && isActive(conv) {
+				return nil, 0, fmt.Errorf("%w: conversation %d is still open", models.ErrInvalidMerge, conv.ID)
+			}
+		}
+	}
+	conv, err := m.Create(&models.CreateConversationRequest{
+		ChannelID:      source.ChannelID,
+		ExternalUserID: source.ExternalUserID,
+		Priority:       source.Priority,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	conv.Status = status
+	return conv, m.Moved, nil
+}
+
+func isActive(conv *models.Conversation) bool {
+	return conv.Status == models.ConversationStatusOpen || conv.Status == models.ConversationStatusPending ||
+		conv.Status == models.ConversationStatusSnoozed
+}
@@ -0,0 +1,131 @@
+package testutils
+
+import (
+	"sort"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockCSATRepository is a mock implementation of CSATRepository. Report
+// returns ReportResult.
+type MockCSATRepository struct {
+	Policies     map[int64]*models.CSATPolicy
+	Surveys      map[int64]*models.CSATSurvey
+	ReportResult *models.CSATReport
+	NextID       int64
+	CreateError  error
+	GetError     error
+	UpdateError  error
+}
+
+func NewMockCSATRepository() *MockCSATRepository {
+	return &MockCSATRepository{
+		Policies: make(map[int64]*models.CSATPolicy),
+		Surveys:  make(map[int64]*models.CSATSurvey),
+		NextID:   1,
+	}
+}
+
+func (m *MockCSATRepository) FindPolicy(channelID int64) (*models.CSATPolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	return m.Policies[channelID], nil
+}
+
+func (m *MockCSATRepository) UpsertPolicy(channelID int64, req *models.UpsertCSATPolicyRequest) (*models.CSATPolicy, error) {
+	if m.UpdateError != nil {
+		return nil, m.UpdateError
+	}
+	policy, ok := m.Policies[channelID]
+	if !ok {
+		policy = &models.CSATPolicy{ID: m.NextID, ChannelID: channelID}
+		m.Policies[channelID] = policy
+		m.NextID++
+	}
+	policy.Enabled = req.Enabled
+	policy.Question = req.Question
+	policy.CommentPrompt = req.CommentPrompt
+	policy.ResponseWindowSeconds = req.ResponseWindowSeconds
+	if policy.ResponseWindowSeconds == 0 {
+		policy.ResponseWindowSeconds = int(models.DefaultCSATResponseWindow / time.Second)
+	}
+	return policy, nil
+}
+
+func (m *MockCSATRepository) CreateSurvey(survey *models.CSATSurvey) (*models.CSATSurvey, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	survey.ID = m.NextID
+	m.Surveys[survey.ID] = survey
+	m.NextID++
+	return survey, nil
+}
+
+func (m *MockCSATRepository) FindOpen(channelID, externalUserID int64, at time.Time) (*models.CSATSurvey, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	var latest *models.CSATSurvey
+	for _, survey := range m.Surveys {
+		if survey.ChannelID != channelID || survey.ExternalUserID != externalUserID ||
+			survey.Status == models.CSATStatusCompleted || !survey.ExpiresAt.After(at) {
+			continue
+		}
+		if latest == nil || survey.ID > latest.ID {
+			latest = survey
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+func (m *MockCSATRepository) Rate(id int64, rating int, comment *string, status models.CSATStatus, at time.Time) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if survey, ok := m.Surveys[id]; ok {
+		survey.Rating = &rating
+		survey.Comment = comment
+		survey.Status = status
+		survey.RatedAt = &at
+	}
+	return nil
+}
+
+func (m *MockCSATRepository) Comment(id int64, comment string) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if survey, ok := m.Surveys[id]; ok {
+		survey.Comment = &comment
+		survey.Status = models.CSATStatusCompleted
+	}
+	return nil
+}
+
+func (m *MockCSATRepository) ListByConversation(conversationID int64) ([]*models.CSATSurvey, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	surveys := make([]*models.CSATSurvey, 0)
+	for _, survey := range m.Surveys {
+		if survey.ConversationID == conversationID {
+			surveys = append(surveys, survey)
+		}
+	}
+	sort.Slice(surveys, func(i, j int) bool { return surveys[i].ID < surveys[j].ID })
+	return surveys, nil
+}
+
+func (m *MockCSATRepository) Report(q *models.CSATReportQuery) (*models.CSATReport, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	return m.ReportResult, nil
+}
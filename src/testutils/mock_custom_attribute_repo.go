@@ -0,0 +1,111 @@
+package testutils
+
+import (
+	"sort"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockCustomAttributeRepository is a mock implementation of
+// CustomAttributeRepository
+type MockCustomAttributeRepository struct {
+	Attributes  map[int64]*models.CustomAttributeDefinition
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+	DeleteError error
+}
+
+func NewMockCustomAttributeRepository() *MockCustomAttributeRepository {
+	return &MockCustomAttributeRepository{
+		Attributes: make(map[int64]*models.CustomAttributeDefinition),
+		NextID:     1,
+	}
+}
+
+func (m *MockCustomAttributeRepository) Create(req *models.CreateCustomAttributeRequest) (*models.CustomAttributeDefinition, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	def := &models.CustomAttributeDefinition{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		Entity:         req.Entity,
+		Key:            req.Key,
+		Label:          req.Label,
+		Type:           req.Type,
+		Options:        req.Options,
+		Description:    req.Description,
+	}
+	m.Attributes[def.ID] = def
+	m.NextID++
+	return def, nil
+}
+
+func (m *MockCustomAttributeRepository) GetByID(id int64) (*models.CustomAttributeDefinition, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	def, ok := m.Attributes[id]
+	if !ok {
+		return nil, nil
+	}
+	return def, nil
+}
+
+func (m *MockCustomAttributeRepository) ListByOrganization(orgID int64, entity *models.CustomAttributeEntity, limit, offset int) ([]*models.CustomAttributeDefinition, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.CustomAttributeDefinition, 0)
+	for _, def := range m.Attributes {
+		if def.OrganizationID == orgID && (entity == nil || def.Entity == *entity) {
+			result = append(result, def)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *MockCustomAttributeRepository) Definitions(orgID int64, entity models.CustomAttributeEntity) (map[string]*models.CustomAttributeDefinition, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make(map[string]*models.CustomAttributeDefinition)
+	for _, def := range m.Attributes {
+		if def.OrganizationID == orgID && def.Entity == entity {
+			result[def.Key] = def
+		}
+	}
+	return result, nil
+}
+
+func (m *MockCustomAttributeRepository) Update(id int64, req *models.UpdateCustomAttributeRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	def, ok := m.Attributes[id]
+	if !ok {
+		return nil
+	}
+	if req.Label != nil {
+		def.Label = *req.Label
+	}
+	if req.Options != nil {
+		def.Options = req.Options
+	}
+	if req.Description != nil {
+		def.Description = req.Description
+	}
+	return nil
+}
+
+func (m *MockCustomAttributeRepository) Delete(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Attributes, id)
+	return nil
+}
@@ -1,9 +1,13 @@
 package testutils
 
-// MockEmitter is a mock implementation of events.Emitter
+import "sync"
+
+// MockEmitter is a mock implementation of events.Emitter. Services publish
+// from their own goroutines, so emits are serialized.
 type MockEmitter struct {
 	EmittedEvents []EmittedEvent
 	EmitError     error
+	mu            sync.Mutex
 }
 
 type EmittedEvent struct {
@@ -19,6 +23,9 @@ func NewMockEmitter() *MockEmitter {
 }
 
 func (m *MockEmitter) Emit(eventType string, payload map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.EmitError != nil {
 		return m.EmitError
 	}
@@ -30,6 +37,9 @@ func (m *MockEmitter) Emit(eventType string, payload map[string]interface{}) err
 }
 
 func (m *MockEmitter) EmitWithMetadata(eventType string, payload map[string]interface{}, metadata map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.EmitError != nil {
 		return m.EmitError
 	}
@@ -73,9 +73,61 @@ func (m *MockExternalUserRepository) Update(id int64, req *models.UpdateExternal
 	if m.UpdateError != nil {
 		return m.UpdateError
 	}
+	user, ok := m.Users[id]
+	if !ok {
+		return nil
+	}
+	if req.DisplayName != nil {
+		user.DisplayName = req.DisplayName
+	}
+	if req.IsBlocked != nil {
+		user.IsBlocked = *req.IsBlocked
+	}
 	return nil
 }
 
+// List ignores the organization and custom attribute query
+func (m *MockExternalUserRepository) List(q *models.ExternalUserQuery) ([]*models.ExternalUser, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	result := make([]*models.ExternalUser, 0)
+	for _, user := range m.Users {
+		if q.ChannelID == nil || user.ChannelID == *q.ChannelID {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockExternalUserRepository) SetAttributes(id int64, values models.CustomAttributes) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	user, ok := m.Users[id]
+	if !ok {
+		return nil
+	}
+	user.CustomAttributes = mergeAttributes(user.CustomAttributes, values)
+	return nil
+}
+
+// mergeAttributes applies values the way json_patch does
+func mergeAttributes(attrs, values models.CustomAttributes) models.CustomAttributes {
+	merged := make(models.CustomAttributes, len(attrs)+len(values))
+	for key, value := range attrs {
+		merged[key] = value
+	}
+	for key, value := range values {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
 func (m *MockExternalUserRepository) UpdateLastSeen(id int64) error {
 	if m.UpdateError != nil {
 		return m.UpdateError
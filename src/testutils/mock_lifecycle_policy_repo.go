@@ -0,0 +1,45 @@
+package testutils
+
+import "github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+// MockLifecyclePolicyRepository is a mock implementation of LifecyclePolicyRepository
+type MockLifecyclePolicyRepository struct {
+	Policies    map[int64]*models.LifecyclePolicy
+	NextID      int64
+	GetError    error
+	UpsertError error
+}
+
+func NewMockLifecyclePolicyRepository() *MockLifecyclePolicyRepository {
+	return &MockLifecyclePolicyRepository{
+		Policies: make(map[int64]*models.LifecyclePolicy),
+		NextID:   1,
+	}
+}
+
+func (m *MockLifecyclePolicyRepository) FindByChannel(channelID int64) (*models.LifecyclePolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	return m.Policies[channelID], nil
+}
+
+func (m *MockLifecyclePolicyRepository) Upsert(channelID int64, req *models.UpsertLifecyclePolicyRequest) (*models.LifecyclePolicy, error) {
+	if m.UpsertError != nil {
+		return nil, m.UpsertError
+	}
+	policy, ok := m.Policies[channelID]
+	if !ok {
+		policy = &models.LifecyclePolicy{ID: m.NextID, ChannelID: channelID}
+		m.Policies[channelID] = policy
+		m.NextID++
+	}
+	policy.ReopenWithinSeconds = req.ReopenWithinSeconds
+	policy.IdleAfterSeconds = req.IdleAfterSeconds
+	policy.IdleStatus = req.IdleStatus
+	if policy.IdleStatus == "" {
+		policy.IdleStatus = models.ConversationStatusResolved
+	}
+	policy.ClosingMessage = req.ClosingMessage
+	return policy, nil
+}
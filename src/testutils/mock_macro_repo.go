@@ -0,0 +1,182 @@
+package testutils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockMacroRepository is a mock implementation of MacroRepository. Runs
+// are kept in the order they were recorded. Apply changes the priority and
+// status of Conversations, which tests share with a conversation mock, and
+// keeps the steps in Applied.
+type MockMacroRepository struct {
+	Macros        map[int64]*models.Macro
+	Runs          []*models.MacroRun
+	Conversations map[int64]*models.Conversation
+	Applied       []*models.MacroStep
+	NextMessageID int64
+	NextID        int64
+	CreateError   error
+	GetError      error
+	ListError     error
+	UpdateError   error
+	DeleteError   error
+	ApplyError    error
+}
+
+func NewMockMacroRepository() *MockMacroRepository {
+	return &MockMacroRepository{
+		Macros:        make(map[int64]*models.Macro),
+		Conversations: make(map[int64]*models.Conversation),
+		NextID:        1,
+		NextMessageID: 1,
+	}
+}
+
+func (m *MockMacroRepository) Create(req *models.CreateMacroRequest) (*models.Macro, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	macro := &models.Macro{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		TeamID:         req.TeamID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Actions:        req.Actions,
+	}
+	m.Macros[macro.ID] = macro
+	m.NextID++
+	return macro, nil
+}
+
+func (m *MockMacroRepository) GetByID(id int64) (*models.Macro, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	macro, ok := m.Macros[id]
+	if !ok {
+		return nil, nil
+	}
+	return macro, nil
+}
+
+func (m *MockMacroRepository) ListByOrganization(orgID int64, teamID *int64, limit, offset int) ([]*models.Macro, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Macro, 0)
+	for _, macro := range m.Macros {
+		if macro.OrganizationID != orgID {
+			continue
+		}
+		if teamID != nil && macro.TeamID != nil && *macro.TeamID != *teamID {
+			continue
+		}
+		result = append(result, macro)
+	}
+	slices.SortFunc(result, func(a, b *models.Macro) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+func (m *MockMacroRepository) Update(id int64, req *models.UpdateMacroRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	macro, ok := m.Macros[id]
+	if !ok {
+		return nil
+	}
+	if req.TeamID != nil {
+		macro.TeamID = req.TeamID
+		if *req.TeamID == 0 {
+			macro.TeamID = nil
+		}
+	}
+	if req.Name != nil {
+		macro.Name = *req.Name
+	}
+	if req.Description != nil {
+		macro.Description = req.Description
+	}
+	if req.Actions != nil {
+		macro.Actions = req.Actions
+	}
+	return nil
+}
+
+func (m *MockMacroRepository) Delete(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Macros, id)
+	return nil
+}
+
+func (m *MockMacroRepository) Apply(conversationID int64, steps []*models.MacroStep, actorID *string, reason string) error {
+	if m.ApplyError != nil {
+		return m.ApplyError
+	}
+	conv, ok := m.Conversations[conversationID]
+	if !ok {
+		return fmt.Errorf("conversation %w", models.ErrNotFound)
+	}
+
+	for _, step := range steps {
+		switch step.Action.Type {
+		case models.MacroActionSendReply:
+			step.Message = &models.Message{
+				ID:             m.NextMessageID,
+				ConversationID: conversationID,
+				SenderType:     models.SenderInternal,
+				Content:        step.Reply,
+				MessageType:    models.MessageTypeText,
+				Direction:      models.DirectionOutbound,
+				Status:         models.MessageStatusSent,
+				CreatedAt:      time.Now(),
+			}
+			m.NextMessageID++
+		case models.MacroActionAddTags, models.MacroActionRemoveTags:
+			for _, tag := range step.Tags {
+				step.Changed = append(step.Changed, tag.Name)
+			}
+		case models.MacroActionSetPriority:
+			if conv.Priority != *step.Action.Priority {
+				previous := string(conv.Priority)
+				step.Previous = &previous
+				conv.Priority = *step.Action.Priority
+			}
+		case models.MacroActionSetStatus:
+			if conv.Status != *step.Action.Status {
+				previous := string(conv.Status)
+				step.Previous = &previous
+				conv.Status = *step.Action.Status
+			}
+		}
+	}
+	m.Applied = append(m.Applied, steps...)
+	return nil
+}
+
+func (m *MockMacroRepository) RecordRun(run *models.MacroRun) error {
+	run.ID = int64(len(m.Runs) + 1)
+	m.Runs = append(m.Runs, run)
+	return nil
+}
+
+func (m *MockMacroRepository) ListRuns(macroID int64, limit, offset int) ([]*models.MacroRun, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	runs := make([]*models.MacroRun, 0)
+	for i := len(m.Runs) - 1; i >= 0; i-- {
+		if m.Runs[i].MacroID == macroID {
+			runs = append(runs, m.Runs[i])
+		}
+	}
+	return runs, nil
+}
@@ -1,10 +1,16 @@
 package testutils
 
-import "github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+import (
+	"fmt"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
 
 // MockMessageRepository is a mock implementation of MessageRepository
 type MockMessageRepository struct {
 	Messages    map[int64]*models.Message
+	Edits       []*models.MessageEdit
 	NextID      int64
 	CreateError error
 	GetError    error
@@ -35,7 +41,7 @@ func (m *MockMessageRepository) GetByID(id int64) (*models.Message, error) {
 	}
 	msg, ok := m.Messages[id]
 	if !ok {
-		return nil, nil
+		return nil, fmt.Errorf("message %w", models.ErrNotFound)
 	}
 	return msg, nil
 }
@@ -53,6 +59,20 @@ func (m *MockMessageRepository) ListByConversation(conversationID int64, limit,
 	return result, nil
 }
 
+func (m *MockMessageRepository) ListHistory(query *models.MessageListQuery) ([]*models.Message, error) {
+	messages, err := m.ListByConversation(query.ConversationID, query.Limit, query.Offset, query.Before)
+	if err != nil || !query.HideSystem {
+		return messages, err
+	}
+	result := make([]*models.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.MessageType != models.MessageTypeSystem {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockMessageRepository) UpdateStatus(id int64, status models.MessageStatus) error {
 	if m.UpdateError != nil {
 		return m.UpdateError
@@ -63,3 +83,90 @@ func (m *MockMessageRepository) UpdateStatus(id int64, status models.MessageStat
 	}
 	return nil
 }
+
+func (m *MockMessageRepository) Edit(id int64, content string, editedBy *string, at time.Time) (*models.Message, error) {
+	if m.UpdateError != nil {
+		return nil, m.UpdateError
+	}
+	msg, ok := m.Messages[id]
+	if !ok || msg.DeletedAt != nil {
+		return nil, fmt.Errorf("message not found")
+	}
+	m.Edits = append(m.Edits, &models.MessageEdit{
+		ID:        int64(len(m.Edits) + 1),
+		MessageID: id,
+		Content:   msg.Content,
+		EditedBy:  editedBy,
+		EditedAt:  at,
+	})
+	msg.Content = content
+	msg.EditedAt = &at
+	return msg, nil
+}
+
+func (m *MockMessageRepository) Delete(id int64, deletedBy *string, at time.Time, retain bool) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	msg, ok := m.Messages[id]
+	if !ok || msg.DeletedAt != nil {
+		return fmt.Errorf("message not found")
+	}
+	content := msg.Content
+	msg.Content = ""
+	msg.MediaURL = nil
+	msg.DeletedAt = &at
+
+	if retain {
+		m.Edits = append(m.Edits, &models.MessageEdit{
+			ID:        int64(len(m.Edits) + 1),
+			MessageID: id,
+			Content:   content,
+			EditedBy:  deletedBy,
+			EditedAt:  at,
+		})
+		return nil
+	}
+	kept := m.Edits[:0]
+	for _, edit := range m.Edits {
+		if edit.MessageID != id {
+			kept = append(kept, edit)
+		}
+	}
+	m.Edits = kept
+	return nil
+}
+
+func (m *MockMessageRepository) ListEdits(messageID int64) ([]*models.MessageEdit, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	edits := make([]*models.MessageEdit, 0)
+	for _, edit := range m.Edits {
+		if edit.MessageID == messageID {
+			edits = append(edits, edit)
+		}
+	}
+	return edits, nil
+}
+
+// FindByPlatformID matches the platform message ID only, as the mock does
+// not know the conversations' channels
+func (m *MockMessageRepository) FindByPlatformID(channelID int64, platformMessageID string) (*models.Message, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	for id := int64(1); id < m.NextID; id++ {
+		msg, ok := m.Messages[id]
+		if ok && msg.PlatformMessageID != nil && *msg.PlatformMessageID == platformMessageID {
+			return msg, nil
+		}
+	}
+	return nil, nil
+}
+
+// PurgeDeleted does nothing, as the mock does not know the channels'
+// retention
+func (m *MockMessageRepository) PurgeDeleted(now time.Time) (int64, error) {
+	return 0, nil
+}
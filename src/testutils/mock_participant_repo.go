@@ -0,0 +1,80 @@
+package testutils
+
+import (
+	"slices"
+	"time"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockParticipantRepository is a mock implementation of
+// ParticipantRepository
+type MockParticipantRepository struct {
+	Participants []*models.ConversationParticipant
+	NextID       int64
+	GetError     error
+	UpdateError  error
+}
+
+func NewMockParticipantRepository() *MockParticipantRepository {
+	return &MockParticipantRepository{NextID: 1}
+}
+
+func (m *MockParticipantRepository) find(conversationID, externalUserID int64) *models.ConversationParticipant {
+	for _, p := range m.Participants {
+		if p.ConversationID == conversationID && p.ExternalUserID == externalUserID {
+			return p
+		}
+	}
+	return nil
+}
+
+func (m *MockParticipantRepository) Join(conversationID, externalUserID int64, role models.ParticipantRole, at time.Time) (*models.ConversationParticipant, bool, error) {
+	if m.UpdateError != nil {
+		return nil, false, m.UpdateError
+	}
+	if p := m.find(conversationID, externalUserID); p != nil {
+		if p.LeftAt == nil {
+			return p, false, nil
+		}
+		p.JoinedAt = at
+		p.LeftAt = nil
+		return p, true, nil
+	}
+	p := &models.ConversationParticipant{
+		ID:             m.NextID,
+		ConversationID: conversationID,
+		ExternalUserID: externalUserID,
+		Role:           role,
+		JoinedAt:       at,
+	}
+	m.NextID++
+	m.Participants = append(m.Participants, p)
+	return p, true, nil
+}
+
+func (m *MockParticipantRepository) Leave(conversationID, externalUserID int64, at time.Time) (bool, error) {
+	if m.UpdateError != nil {
+		return false, m.UpdateError
+	}
+	p := m.find(conversationID, externalUserID)
+	if p == nil || p.LeftAt != nil {
+		return false, nil
+	}
+	p.LeftAt = &at
+	return true, nil
+}
+
+func (m *MockParticipantRepository) ListByConversation(conversationID int64) ([]*models.ConversationParticipant, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	result := make([]*models.ConversationParticipant, 0)
+	for _, p := range m.Participants {
+		if p.ConversationID == conversationID {
+			result = append(result, p)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b *models.ConversationParticipant) int { return a.JoinedAt.Compare(b.JoinedAt) })
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package testutils
+
+import (
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockReactionRepository is a mock implementation of ReactionRepository.
+// Reactions are kept in the order they were added.
+type MockReactionRepository struct {
+	Reactions   []*models.MessageReaction
+	NextID      int64
+	CreateError error
+	DeleteError error
+	ListError   error
+}
+
+func NewMockReactionRepository() *MockReactionRepository {
+	return &MockReactionRepository{NextID: 1}
+}
+
+func (m *MockReactionRepository) Add(reaction *models.MessageReaction) (bool, error) {
+	if m.CreateError != nil {
+		return false, m.CreateError
+	}
+	for _, r := range m.Reactions {
+		if sameReaction(r, reaction.MessageID, reaction.SenderType, reaction.SenderID, reaction.Emoji) {
+			return false, nil
+		}
+	}
+	reaction.ID = m.NextID
+	m.NextID++
+	m.Reactions = append(m.Reactions, reaction)
+	return true, nil
+}
+
+func (m *MockReactionRepository) Remove(messageID int64, senderType models.MessageSenderType, senderID, emoji string) (bool, error) {
+	if m.DeleteError != nil {
+		return false, m.DeleteError
+	}
+	for i, r := range m.Reactions {
+		if sameReaction(r, messageID, senderType, senderID, emoji) {
+			m.Reactions = append(m.Reactions[:i], m.Reactions[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockReactionRepository) ListByMessage(messageID int64) ([]*models.MessageReaction, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	reactions := make([]*models.MessageReaction, 0)
+	for _, r := range m.Reactions {
+		if r.MessageID == messageID {
+			reactions = append(reactions, r)
+		}
+	}
+	return reactions, nil
+}
+
+func sameReaction(r *models.MessageReaction, messageID int64, senderType models.MessageSenderType, senderID, emoji string) bool {
+	return r.MessageID == messageID && r.SenderType == senderType && r.SenderID == senderID && r.Emoji == emoji
+}
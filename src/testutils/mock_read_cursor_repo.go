@@ -0,0 +1,62 @@
+package testutils
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockReadCursorRepository is a mock implementation of ReadCursorRepository.
+// Unread sets the unread count reported for each conversation; Totals sums
+// it over every conversation.
+type MockReadCursorRepository struct {
+	Cursors     map[string]*models.ReadCursor
+	Unread      map[int64]int64
+	NextID      int64
+	GetError    error
+	UpsertError error
+}
+
+func NewMockReadCursorRepository() *MockReadCursorRepository {
+	return &MockReadCursorRepository{
+		Cursors: make(map[string]*models.ReadCursor),
+		Unread:  make(map[int64]int64),
+		NextID:  1,
+	}
+}
+
+func (m *MockReadCursorRepository) Advance(conversationID int64, agentID string, messageID int64) (*models.ReadCursor, error) {
+	if m.UpsertError != nil {
+		return nil, m.UpsertError
+	}
+	key := fmt.Sprintf("%d/%s", conversationID, agentID)
+	cursor, ok := m.Cursors[key]
+	if !ok {
+		cursor = &models.ReadCursor{ID: m.NextID, ConversationID: conversationID, AgentID: agentID}
+		m.Cursors[key] = cursor
+		m.NextID++
+	}
+	cursor.LastReadMessageID = max(cursor.LastReadMessageID, messageID)
+	return cursor, nil
+}
+
+func (m *MockReadCursorRepository) UnreadCount(conversationID int64, agentID string) (int64, error) {
+	if m.GetError != nil {
+		return 0, m.GetError
+	}
+	return m.Unread[conversationID], nil
+}
+
+func (m *MockReadCursorRepository) Totals(orgID int64, agentID string) (*models.UnreadTotals, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	totals := &models.UnreadTotals{AgentID: agentID}
+	for _, n := range m.Unread {
+		totals.Messages += n
+		if n > 0 {
+			totals.Conversations++
+		}
+	}
+	return totals, nil
+}
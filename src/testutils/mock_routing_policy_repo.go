@@ -0,0 +1,41 @@
+package testutils
+
+import "github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+
+// MockRoutingPolicyRepository is a mock implementation of RoutingPolicyRepository
+type MockRoutingPolicyRepository struct {
+	Policies    map[int64]*models.RoutingPolicy
+	NextID      int64
+	GetError    error
+	UpsertError error
+}
+
+func NewMockRoutingPolicyRepository() *MockRoutingPolicyRepository {
+	return &MockRoutingPolicyRepository{
+		Policies: make(map[int64]*models.RoutingPolicy),
+		NextID:   1,
+	}
+}
+
+func (m *MockRoutingPolicyRepository) FindByChannel(channelID int64) (*models.RoutingPolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	return m.Policies[channelID], nil
+}
+
+func (m *MockRoutingPolicyRepository) Upsert(channelID int64, req *models.UpsertRoutingPolicyRequest) (*models.RoutingPolicy, error) {
+	if m.UpsertError != nil {
+		return nil, m.UpsertError
+	}
+	policy, ok := m.Policies[channelID]
+	if !ok {
+		policy = &models.RoutingPolicy{ID: m.NextID, ChannelID: channelID}
+		m.Policies[channelID] = policy
+		m.NextID++
+	}
+	policy.Strategy = req.Strategy
+	policy.ReassignAfterSeconds = req.ReassignAfterSeconds
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+	return policy, nil
+}
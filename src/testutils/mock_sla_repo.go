@@ -0,0 +1,148 @@
+package testutils
+
+import (
+	"fmt"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockSLARepository is a mock implementation of SLARepository. MatchPolicy
+// applies the same precedence as the real repository.
+type MockSLARepository struct {
+	Policies    map[int64]*models.SLAPolicy
+	Alerts      []*models.SLAAlert
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+	DeleteError error
+	AlertError  error
+}
+
+func NewMockSLARepository() *MockSLARepository {
+	return &MockSLARepository{
+		Policies: make(map[int64]*models.SLAPolicy),
+		NextID:   1,
+	}
+}
+
+func (m *MockSLARepository) CreatePolicy(req *models.CreateSLAPolicyRequest) (*models.SLAPolicy, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	policy := &models.SLAPolicy{
+		ID:                   m.NextID,
+		OrganizationID:       req.OrganizationID,
+		Name:                 req.Name,
+		ChannelID:            req.ChannelID,
+		Priority:             req.Priority,
+		FirstResponseSeconds: req.FirstResponseSeconds,
+		NextResponseSeconds:  req.NextResponseSeconds,
+		ResolutionSeconds:    req.ResolutionSeconds,
+		WarnBeforeSeconds:    req.WarnBeforeSeconds,
+		BusinessHoursOnly:    req.BusinessHoursOnly,
+		Enabled:              enabled,
+	}
+	m.Policies[policy.ID] = policy
+	m.NextID++
+	return policy, nil
+}
+
+func (m *MockSLARepository) GetPolicy(id int64) (*models.SLAPolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	policy, ok := m.Policies[id]
+	if !ok {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+func (m *MockSLARepository) ListPolicies(orgID int64, limit, offset int) ([]*models.SLAPolicy, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.SLAPolicy, 0)
+	for _, policy := range m.Policies {
+		if policy.OrganizationID == orgID {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSLARepository) UpdatePolicy(id int64, req *models.UpdateSLAPolicyRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	policy, ok := m.Policies[id]
+	if !ok {
+		return fmt.Errorf("SLA policy not found")
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.FirstResponseSeconds != nil {
+		policy.FirstResponseSeconds = *req.FirstResponseSeconds
+	}
+	return nil
+}
+
+func (m *MockSLARepository) DeletePolicy(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Policies, id)
+	return nil
+}
+
+func (m *MockSLARepository) MatchPolicy(orgID, channelID int64, priority models.ConversationPriority) (*models.SLAPolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	var best *models.SLAPolicy
+	bestScore := -1
+	for id := int64(1); id < m.NextID; id++ {
+		policy, ok := m.Policies[id]
+		if !ok || !policy.Enabled || policy.OrganizationID != orgID {
+			continue
+		}
+		score := 0
+		if policy.ChannelID != nil {
+			if *policy.ChannelID != channelID {
+				continue
+			}
+			score += 2
+		}
+		if policy.Priority != nil {
+			if *policy.Priority != priority {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
+	}
+	return best, nil
+}
+
+func (m *MockSLARepository) RecordAlert(alert *models.SLAAlert) (bool, error) {
+	if m.AlertError != nil {
+		return false, m.AlertError
+	}
+	for _, a := range m.Alerts {
+		if a.ConversationID == alert.ConversationID && a.Target == alert.Target &&
+			a.Kind == alert.Kind && a.DueAt.Equal(alert.DueAt) {
+			return false, nil
+		}
+	}
+	m.Alerts = append(m.Alerts, alert)
+	return true, nil
+}
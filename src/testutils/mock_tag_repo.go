@@ -0,0 +1,184 @@
+package testutils
+
+import (
+	"slices"
+	"strings"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockTagRepository is a mock implementation of TagRepository.
+// Links maps each conversation to its tag IDs.
+type MockTagRepository struct {
+	Tags        map[int64]*models.Tag
+	Links       map[int64][]int64
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+	DeleteError error
+}
+
+func NewMockTagRepository() *MockTagRepository {
+	return &MockTagRepository{
+		Tags:   make(map[int64]*models.Tag),
+		Links:  make(map[int64][]int64),
+		NextID: 1,
+	}
+}
+
+func (m *MockTagRepository) Create(req *models.CreateTagRequest) (*models.Tag, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	tag := &models.Tag{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		Name:           models.NormalizeTagName(req.Name),
+		Color:          req.Color,
+		Description:    req.Description,
+	}
+	m.Tags[tag.ID] = tag
+	m.NextID++
+	return tag, nil
+}
+
+func (m *MockTagRepository) GetByID(id int64) (*models.Tag, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	tag, ok := m.Tags[id]
+	if !ok {
+		return nil, nil
+	}
+	return tag, nil
+}
+
+func (m *MockTagRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Tag, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Tag, 0)
+	for _, tag := range m.Tags {
+		if tag.OrganizationID == orgID {
+			result = append(result, tag)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+func (m *MockTagRepository) Update(id int64, req *models.UpdateTagRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	tag, ok := m.Tags[id]
+	if !ok {
+		return nil
+	}
+	if req.Name != nil {
+		tag.Name = models.NormalizeTagName(*req.Name)
+	}
+	if req.Color != nil {
+		tag.Color = req.Color
+	}
+	if req.Description != nil {
+		tag.Description = req.Description
+	}
+	return nil
+}
+
+func (m *MockTagRepository) Delete(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Tags, id)
+	for convID, tagIDs := range m.Links {
+		m.Links[convID] = slices.DeleteFunc(tagIDs, func(tagID int64) bool { return tagID == id })
+	}
+	return nil
+}
+
+func (m *MockTagRepository) FindByNames(orgID int64, names []string) ([]*models.Tag, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	result := make([]*models.Tag, 0)
+	for _, tag := range m.Tags {
+		if tag.OrganizationID == orgID && slices.ContainsFunc(names, func(name string) bool {
+			return models.NormalizeTagName(name) == tag.Name
+		}) {
+			result = append(result, tag)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+func (m *MockTagRepository) ListByConversation(conversationID int64) ([]*models.Tag, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Tag, 0)
+	for _, tagID := range m.Links[conversationID] {
+		if tag, ok := m.Tags[tagID]; ok {
+			result = append(result, tag)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+func (m *MockTagRepository) AddToConversation(conversationID int64, tagIDs []int64) ([]int64, error) {
+	if m.UpdateError != nil {
+		return nil, m.UpdateError
+	}
+	added := make([]int64, 0)
+	for _, tagID := range tagIDs {
+		if !slices.Contains(m.Links[conversationID], tagID) {
+			m.Links[conversationID] = append(m.Links[conversationID], tagID)
+			added = append(added, tagID)
+		}
+	}
+	return added, nil
+}
+
+func (m *MockTagRepository) RemoveFromConversation(conversationID int64, tagIDs []int64) ([]int64, error) {
+	if m.UpdateError != nil {
+		return nil, m.UpdateError
+	}
+	removed := make([]int64, 0)
+	for _, tagID := range tagIDs {
+		if slices.Contains(m.Links[conversationID], tagID) {
+			removed = append(removed, tagID)
+		}
+	}
+	m.Links[conversationID] = slices.DeleteFunc(m.Links[conversationID], func(tagID int64) bool {
+		return slices.Contains(removed, tagID)
+	})
+	return removed, nil
+}
+
+// Counts counts links per tag of the organization, ignoring the
+// conversation filters
+func (m *MockTagRepository) Counts(q *models.TagCountQuery) ([]*models.TagCount, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.TagCount, 0)
+	for _, tag := range m.Tags {
+		if tag.OrganizationID != q.OrganizationID {
+			continue
+		}
+		count := &models.TagCount{TagID: tag.ID, Name: tag.Name, Color: tag.Color}
+		for _, tagIDs := range m.Links {
+			if slices.Contains(tagIDs, tag.ID) {
+				count.Conversations++
+			}
+		}
+		result = append(result, count)
+	}
+	slices.SortFunc(result, func(a, b *models.TagCount) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package testutils
+
+import (
+	"slices"
+
+	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
+)
+
+// MockTeamRepository is a mock implementation of TeamRepository
+type MockTeamRepository struct {
+	Teams       map[int64]*models.Team
+	NextID      int64
+	CreateError error
+	GetError    error
+	ListError   error
+	UpdateError error
+	DeleteError error
+}
+
+func NewMockTeamRepository() *MockTeamRepository {
+	return &MockTeamRepository{
+		Teams:  make(map[int64]*models.Team),
+		NextID: 1,
+	}
+}
+
+func (m *MockTeamRepository) Create(req *models.CreateTeamRequest) (*models.Team, error) {
+	if m.CreateError != nil {
+		return nil, m.CreateError
+	}
+	team := &models.Team{
+		ID:             m.NextID,
+		OrganizationID: req.OrganizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+		MemberIDs:      append([]string{}, req.MemberIDs...),
+	}
+	m.Teams[team.ID] = team
+	m.NextID++
+	return team, nil
+}
+
+func (m *MockTeamRepository) GetByID(id int64) (*models.Team, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	team, ok := m.Teams[id]
+	if !ok {
+		return nil, nil
+	}
+	return team, nil
+}
+
+func (m *MockTeamRepository) ListByOrganization(orgID int64, limit, offset int) ([]*models.Team, error) {
+	if m.ListError != nil {
+		return nil, m.ListError
+	}
+	result := make([]*models.Team, 0)
+	for _, team := range m.Teams {
+		if team.OrganizationID == orgID {
+			result = append(result, team)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockTeamRepository) Update(id int64, req *models.UpdateTeamRequest) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if team, ok := m.Teams[id]; ok {
+		if req.Name != nil {
+			team.Name = *req.Name
+		}
+		if req.Description != nil {
+			team.Description = req.Description
+		}
+	}
+	return nil
+}
+
+func (m *MockTeamRepository) Delete(id int64) error {
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	delete(m.Teams, id)
+	return nil
+}
+
+func (m *MockTeamRepository) SetMembers(id int64, agentIDs []string) error {
+	if m.UpdateError != nil {
+		return m.UpdateError
+	}
+	if team, ok := m.Teams[id]; ok {
+		team.MemberIDs = append([]string{}, agentIDs...)
+	}
+	return nil
+}
+
+func (m *MockTeamRepository) ListMembers(id int64) ([]string, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	team, ok := m.Teams[id]
+	if !ok {
+		return []string{}, nil
+	}
+	return team.MemberIDs, nil
+}
+
+func (m *MockTeamRepository) IsMember(id int64, agentID string) (bool, error) {
+	if m.GetError != nil {
+		return false, m.GetError
+	}
+	team, ok := m.Teams[id]
+	return ok && slices.Contains(team.MemberIDs, agentID), nil
+}
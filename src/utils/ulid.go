@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Crockford's base32 alphabet, as used by ULIDs
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidState struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// NewULID returns a 26-character ULID: a 48-bit millisecond timestamp
+// followed by 80 random bits. IDs generated within the same millisecond
+// increment the random part, so IDs sort in generation order.
+func NewULID() string {
+	return newULIDAt(time.Now())
+}
+
+func newULIDAt(t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	ulidState.mu.Lock()
+	if ms <= ulidState.lastMs {
+		// Same (or earlier, if the clock stepped back) millisecond:
+		// keep the previous timestamp and bump the entropy
+		ms = ulidState.lastMs
+		incrementEntropy(&ulidState.entropy)
+	} else {
+		ulidState.lastMs = ms
+		if _, err := rand.Read(ulidState.entropy[:]); err != nil {
+			panic("ulid: crypto/rand failed: " + err.Error())
+		}
+	}
+
+	var id [16]byte
+	var tsBuf [8]byte
+	binary.BigEndian.PutUint64(tsBuf[:], ms)
+	copy(id[:6], tsBuf[2:])
+	copy(id[6:], ulidState.entropy[:])
+	ulidState.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+func incrementEntropy(e *[10]byte) {
+	for i := len(e) - 1; i >= 0; i-- {
+		e[i]++
+		if e[i] != 0 {
+			return
+		}
+	}
+}
+
+// encodeULID renders 128 bits as 26 base32 characters (the first holds 3 bits)
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = ulidAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
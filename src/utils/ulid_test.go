@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewULID_Format(t *testing.T) {
+	id := NewULID()
+
+	assert.Len(t, id, 26)
+	for _, c := range id {
+		assert.Contains(t, ulidAlphabet, string(c))
+	}
+}
+
+func TestNewULID_UniqueAndSorted(t *testing.T) {
+	prev := NewULID()
+	seen := map[string]bool{prev: true}
+
+	for i := 0; i < 10000; i++ {
+		id := NewULID()
+		assert.False(t, seen[id], "duplicate id %s", id)
+		assert.Greater(t, id, prev)
+		seen[id] = true
+		prev = id
+	}
+}
+
+func TestNewULID_TimestampPrefix(t *testing.T) {
+	earlier := newULIDAt(time.Now().Add(time.Hour))
+	later := newULIDAt(time.Now().Add(2 * time.Hour))
+
+	assert.Greater(t, later, earlier)
+	assert.NotEqual(t, earlier[:10], later[:10])
+}
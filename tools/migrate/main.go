@@ -9,7 +9,6 @@ import (
 
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/config"
 	"github/sarthak-pokharel/sqlite-d1-gochat/src/database"
-	"github/sarthak-pokharel/sqlite-d1-gochat/src/models"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -30,10 +29,11 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if err := database.InitDB(cfg.Database.Path); err != nil {
+	db, err := database.InitDB(cfg.Database.Path)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.Close()
+	defer database.Close(db)
 
 	command := os.Args[1]
 
@@ -42,23 +42,23 @@ func main() {
 		if len(os.Args) < 3 {
 			log.Fatal("Please provide a migration name")
 		}
-		generateMigration(os.Args[2])
+		generateMigration(db, os.Args[2])
 	case "run":
-		runMigrations()
+		runMigrations(db)
 	case "status":
-		showStatus()
+		showStatus(db)
 	default:
 		log.Fatalf("Unknown command: %s", command)
 	}
 }
 
-func generateMigration(name string) {
+func generateMigration(db *gorm.DB, name string) {
 	timestamp := time.Now().Format("20060102150405")
 	filename := fmt.Sprintf("%s_%s.sql", timestamp, name)
 	filepath := filepath.Join("migrations", filename)
 
 	// Get current schema using GORM migrator
-	statements := generateDDL()
+	statements := generateDDL(db)
 
 	content := fmt.Sprintf("-- Migration: %s\n-- Generated: %s\n\n%s",
 		name,
@@ -73,26 +73,16 @@ func generateMigration(name string) {
 	fmt.Printf("Generated migration: %s\n", filepath)
 }
 
-func generateDDL() string {
-	db := database.DB
+func generateDDL(db *gorm.DB) string {
 	migrator := db.Migrator()
 
 	ddl := ""
 
 	// Generate CREATE TABLE statements for all models
-	models := []interface{}{
-		&models.Organization{},
-		&models.ChatChannel{},
-		&models.ExternalUser{},
-		&models.Conversation{},
-		&models.Message{},
-		&models.WebhookEvent{},
-	}
-
-	for _, model := range models {
-		tableName := db.Statement.Table
-		db.Statement.Parse(model)
-		tableName = db.Statement.Table
+	for _, model := range database.Models() {
+		stmt := &gorm.Statement{DB: db}
+		stmt.Parse(model)
+		tableName := stmt.Table
 
 		if !migrator.HasTable(model) {
 			// Generate CREATE TABLE
@@ -209,31 +199,21 @@ func joinFields(fields []string, sep string) string {
 	return result
 }
 
-func runMigrations() {
-	if err := database.AutoMigrate(); err != nil {
+func runMigrations(db *gorm.DB) {
+	if err := database.AutoMigrate(db); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 	fmt.Println("Migrations completed successfully")
 }
 
-func showStatus() {
-	db := database.DB
-
-	models := []interface{}{
-		&models.Organization{},
-		&models.ChatChannel{},
-		&models.ExternalUser{},
-		&models.Conversation{},
-		&models.Message{},
-		&models.WebhookEvent{},
-	}
-
+func showStatus(db *gorm.DB) {
 	fmt.Println("Migration Status:")
 	fmt.Println("================")
 
-	for _, model := range models {
-		db.Statement.Parse(model)
-		tableName := db.Statement.Table
+	for _, model := range database.Models() {
+		stmt := &gorm.Statement{DB: db}
+		stmt.Parse(model)
+		tableName := stmt.Table
 
 		if db.Migrator().HasTable(model) {
 			fmt.Printf("✓ %s - exists\n", tableName)